@@ -8,6 +8,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"plandex-cli/types"
 	"strings"
 
@@ -376,6 +377,65 @@ func (a *Api) CreatePlan(projectId string, req shared.CreatePlanRequest) (*share
 	return &respBody, nil
 }
 
+func (a *Api) ExportPlan(planId string) ([]byte, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/plans/%s/export", GetApiHost(), planId)
+
+	// use the slow client since plans with long histories can be large
+	resp, err := authenticatedSlowClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ExportPlan(planId)
+		}
+		return nil, apiErr
+	}
+
+	archive, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error reading response: %v", err)}
+	}
+
+	return archive, nil
+}
+
+func (a *Api) ImportPlan(projectId, name string, archive []byte) (*shared.CreatePlanResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/projects/%s/plans/import", GetApiHost(), projectId)
+	if name != "" {
+		serverUrl += "?name=" + url.QueryEscape(name)
+	}
+
+	resp, err := authenticatedSlowClient.Post(serverUrl, "application/gzip", bytes.NewReader(archive))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ImportPlan(projectId, name, archive)
+		}
+		return nil, apiErr
+	}
+
+	var respBody shared.CreatePlanResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &respBody, nil
+}
+
 func (a *Api) GetPlan(planId string) (*shared.Plan, *shared.ApiError) {
 	serverUrl := fmt.Sprintf("%s/plans/%s", GetApiHost(), planId)
 
@@ -929,6 +989,40 @@ func (a *Api) RejectFiles(planId, branch string, paths []string) *shared.ApiErro
 	return nil
 }
 
+func (a *Api) ReviewReplacements(planId, branch string, req shared.ReviewReplacementsRequest) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/plans/%s/%s/review_replacements", GetApiHost(), planId, branch)
+
+	reqBytes, err := json.Marshal(req)
+
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPatch, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(httpReq)
+	if err != nil {
+		return &shared.ApiError{Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		didRefresh, apiErr := refreshAuthIfNeeded(apiErr)
+		if didRefresh {
+			return a.ReviewReplacements(planId, branch, req)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
 func (a *Api) LoadContext(planId, branch string, req shared.LoadContextRequest) (*shared.LoadContextResponse, *shared.ApiError) {
 	serverUrl := fmt.Sprintf("%s/plans/%s/%s/context", GetApiHost(), planId, branch)
 	reqBytes, err := json.Marshal(req)
@@ -1126,6 +1220,42 @@ func (a *Api) ListConvo(planId, branch string) ([]*shared.ConvoMessage, *shared.
 	return convos, nil
 }
 
+func (a *Api) SearchConvo(query string, projectIds []string, limit int) ([]*shared.ConvoSearchResult, *shared.ApiError) {
+	q := url.Values{}
+	q.Set("q", query)
+	for _, projectId := range projectIds {
+		q.Add("projectId", projectId)
+	}
+	if limit > 0 {
+		q.Set("limit", fmt.Sprintf("%d", limit))
+	}
+	serverUrl := fmt.Sprintf("%s/search?%s", GetApiHost(), q.Encode())
+
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.SearchConvo(query, projectIds, limit)
+		}
+		return nil, apiErr
+	}
+
+	var results []*shared.ConvoSearchResult
+	err = json.NewDecoder(resp.Body).Decode(&results)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return results, nil
+}
+
 func (a *Api) GetPlanStatus(planId, branch string) (string, *shared.ApiError) {
 	serverUrl := fmt.Sprintf("%s/plans/%s/%s/status", GetApiHost(), planId, branch)
 
@@ -1154,6 +1284,34 @@ func (a *Api) GetPlanStatus(planId, branch string) (string, *shared.ApiError) {
 	return string(body), nil
 }
 
+func (a *Api) GetPlanPR(planId, branch string) (*shared.PlanPRResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/plans/%s/%s/pr", GetApiHost(), planId, branch)
+
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.GetPlanPR(planId, branch)
+		}
+		return nil, apiErr
+	}
+
+	var res shared.PlanPRResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &res, nil
+}
+
 func (a *Api) GetPlanDiffs(planId, branch string, plain bool) (string, *shared.ApiError) {
 	serverUrl := fmt.Sprintf("%s/plans/%s/%s/diffs", GetApiHost(), planId, branch)
 
@@ -1186,6 +1344,42 @@ func (a *Api) GetPlanDiffs(planId, branch string, plain bool) (string, *shared.A
 	return string(body), nil
 }
 
+func (a *Api) GetLogDiff(planId, branch, from, to string, plain bool) (*shared.PlanLogDiffResponse, *shared.ApiError) {
+	query := url.Values{}
+	query.Set("from", from)
+	if to != "" {
+		query.Set("to", to)
+	}
+	if plain {
+		query.Set("plain", "true")
+	}
+	serverUrl := fmt.Sprintf("%s/plans/%s/%s/logs/diff?%s", GetApiHost(), planId, branch, query.Encode())
+
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.GetLogDiff(planId, branch, from, to, plain)
+		}
+		return nil, apiErr
+	}
+
+	var res shared.PlanLogDiffResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &res, nil
+}
+
 func (a *Api) ListLogs(planId, branch string) (*shared.LogResponse, *shared.ApiError) {
 	serverUrl := fmt.Sprintf("%s/plans/%s/%s/logs", GetApiHost(), planId, branch)
 
@@ -1468,14 +1662,49 @@ func (a *Api) UpdateOrgUserConfig(c shared.OrgUserConfig) *shared.ApiError {
 	return nil
 }
 
-func (a *Api) DeleteUser(userId string) *shared.ApiError {
-	serverUrl := fmt.Sprintf("%s/orgs/users/%s", GetApiHost(), userId)
-	req, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+func (a *Api) ListOrgSecrets() ([]*shared.OrgSecret, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/org_secrets"
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListOrgSecrets()
+		}
+		return nil, apiErr
+	}
+
+	var secrets []*shared.OrgSecret
+	err = json.NewDecoder(resp.Body).Decode(&secrets)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return secrets, nil
+}
+
+func (a *Api) SetOrgSecret(req shared.SetOrgSecretRequest) *shared.ApiError {
+	serverUrl := GetApiHost() + "/org_secrets"
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	request, err := http.NewRequest(http.MethodPut, serverUrl, bytes.NewBuffer(reqBytes))
 	if err != nil {
 		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
 	}
 
-	resp, err := authenticatedFastClient.Do(req)
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(request)
 	if err != nil {
 		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
 	}
@@ -1486,7 +1715,7 @@ func (a *Api) DeleteUser(userId string) *shared.ApiError {
 		apiErr := HandleApiError(resp, errorBody)
 		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
 		if authRefreshed {
-			return a.DeleteUser(userId)
+			return a.SetOrgSecret(req)
 		}
 		return apiErr
 	}
@@ -1494,42 +1723,40 @@ func (a *Api) DeleteUser(userId string) *shared.ApiError {
 	return nil
 }
 
-func (a *Api) ListOrgRoles() ([]*shared.OrgRole, *shared.ApiError) {
-	serverUrl := GetApiHost() + "/orgs/roles"
-	resp, err := authenticatedFastClient.Get(serverUrl)
+func (a *Api) DeleteOrgSecret(name string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/org_secrets/%s", GetApiHost(), url.PathEscape(name))
+	req, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
 	if err != nil {
-		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %s", err)}
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
 		errorBody, _ := io.ReadAll(resp.Body)
-
 		apiErr := HandleApiError(resp, errorBody)
 		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
 		if authRefreshed {
-			return a.ListOrgRoles()
+			return a.DeleteOrgSecret(name)
 		}
-		return nil, apiErr
-	}
-
-	var roles []*shared.OrgRole
-	err = json.NewDecoder(resp.Body).Decode(&roles)
-	if err != nil {
-		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %s", err)}
+		return apiErr
 	}
 
-	return roles, nil
+	return nil
 }
 
-func (a *Api) InviteUser(req shared.InviteRequest) *shared.ApiError {
-	serverUrl := GetApiHost() + "/invites"
-	reqBytes, err := json.Marshal(req)
+func (a *Api) DeleteUser(userId string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/orgs/users/%s", GetApiHost(), userId)
+	req, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
 	if err != nil {
-		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
 	}
 
-	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	resp, err := authenticatedFastClient.Do(req)
 	if err != nil {
 		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
 	}
@@ -1538,10 +1765,9 @@ func (a *Api) InviteUser(req shared.InviteRequest) *shared.ApiError {
 	if resp.StatusCode >= 400 {
 		errorBody, _ := io.ReadAll(resp.Body)
 		apiErr := HandleApiError(resp, errorBody)
-
 		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
 		if authRefreshed {
-			return a.InviteUser(req)
+			return a.DeleteUser(userId)
 		}
 		return apiErr
 	}
@@ -1549,36 +1775,42 @@ func (a *Api) InviteUser(req shared.InviteRequest) *shared.ApiError {
 	return nil
 }
 
-func (a *Api) ListPendingInvites() ([]*shared.Invite, *shared.ApiError) {
-	serverUrl := GetApiHost() + "/invites/pending"
+func (a *Api) ListOrgRoles() ([]*shared.OrgRole, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/orgs/roles"
 	resp, err := authenticatedFastClient.Get(serverUrl)
 	if err != nil {
-		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %s", err)}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
 		errorBody, _ := io.ReadAll(resp.Body)
+
 		apiErr := HandleApiError(resp, errorBody)
 		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
 		if authRefreshed {
-			return a.ListPendingInvites()
+			return a.ListOrgRoles()
 		}
 		return nil, apiErr
 	}
 
-	var invites []*shared.Invite
-	err = json.NewDecoder(resp.Body).Decode(&invites)
+	var roles []*shared.OrgRole
+	err = json.NewDecoder(resp.Body).Decode(&roles)
 	if err != nil {
-		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %s", err)}
 	}
 
-	return invites, nil
+	return roles, nil
 }
 
-func (a *Api) ListAcceptedInvites() ([]*shared.Invite, *shared.ApiError) {
-	serverUrl := GetApiHost() + "/invites/accepted"
-	resp, err := authenticatedFastClient.Get(serverUrl)
+func (a *Api) CreateOrgRole(req shared.CreateOrgRoleRequest) (*shared.OrgRole, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/orgs/roles"
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
 	if err != nil {
 		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
 	}
@@ -1589,25 +1821,432 @@ func (a *Api) ListAcceptedInvites() ([]*shared.Invite, *shared.ApiError) {
 		apiErr := HandleApiError(resp, errorBody)
 		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
 		if authRefreshed {
-			return a.ListAcceptedInvites()
+			return a.CreateOrgRole(req)
 		}
 		return nil, apiErr
 	}
 
-	var invites []*shared.Invite
-	err = json.NewDecoder(resp.Body).Decode(&invites)
+	var role shared.OrgRole
+	err = json.NewDecoder(resp.Body).Decode(&role)
 	if err != nil {
 		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
 	}
 
-	return invites, nil
+	return &role, nil
 }
 
-func (a *Api) ListAllInvites() ([]*shared.Invite, *shared.ApiError) {
-	serverUrl := GetApiHost() + "/invites/all"
-	resp, err := authenticatedFastClient.Get(serverUrl)
+func (a *Api) UpdateOrgRole(roleId string, req shared.UpdateOrgRoleRequest) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/orgs/roles/%s", GetApiHost(), roleId)
+	reqBytes, err := json.Marshal(req)
 	if err != nil {
-		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	request, err := http.NewRequest(http.MethodPut, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.UpdateOrgRole(roleId, req)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) DeleteOrgRole(roleId string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/orgs/roles/%s", GetApiHost(), roleId)
+	req, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.DeleteOrgRole(roleId)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) SetOrgUserRole(userId string, req shared.SetOrgUserRoleRequest) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/orgs/users/%s/role", GetApiHost(), userId)
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	request, err := http.NewRequest(http.MethodPut, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.SetOrgUserRole(userId, req)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) GetOrgPolicy() (*shared.OrgPolicy, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/orgs/policy"
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.GetOrgPolicy()
+		}
+		return nil, apiErr
+	}
+
+	var policy shared.OrgPolicy
+	err = json.NewDecoder(resp.Body).Decode(&policy)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &policy, nil
+}
+
+func (a *Api) UpdateOrgPolicy(req shared.UpdateOrgPolicyRequest) *shared.ApiError {
+	serverUrl := GetApiHost() + "/orgs/policy"
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	request, err := http.NewRequest(http.MethodPut, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.UpdateOrgPolicy(req)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) ListPlanTemplates() ([]*shared.PlanTemplate, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/orgs/plan_templates"
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListPlanTemplates()
+		}
+		return nil, apiErr
+	}
+
+	var templates []*shared.PlanTemplate
+	err = json.NewDecoder(resp.Body).Decode(&templates)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return templates, nil
+}
+
+func (a *Api) UpsertPlanTemplate(req shared.UpsertPlanTemplateRequest) *shared.ApiError {
+	serverUrl := GetApiHost() + "/orgs/plan_templates"
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	request, err := http.NewRequest(http.MethodPut, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.UpsertPlanTemplate(req)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) DeletePlanTemplate(name string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/orgs/plan_templates/%s", GetApiHost(), url.PathEscape(name))
+
+	request, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.DeletePlanTemplate(name)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) ListContextSets(projectId string) ([]*shared.ContextSet, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/projects/%s/context_sets", GetApiHost(), projectId)
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListContextSets(projectId)
+		}
+		return nil, apiErr
+	}
+
+	var sets []*shared.ContextSet
+	err = json.NewDecoder(resp.Body).Decode(&sets)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return sets, nil
+}
+
+func (a *Api) UpsertContextSet(projectId string, req shared.UpsertContextSetRequest) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/projects/%s/context_sets", GetApiHost(), projectId)
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	request, err := http.NewRequest(http.MethodPut, serverUrl, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.UpsertContextSet(projectId, req)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) DeleteContextSet(projectId, name string) *shared.ApiError {
+	serverUrl := fmt.Sprintf("%s/projects/%s/context_sets/%s", GetApiHost(), projectId, url.PathEscape(name))
+
+	request, err := http.NewRequest(http.MethodDelete, serverUrl, nil)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error creating request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Do(request)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.DeleteContextSet(projectId, name)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) InviteUser(req shared.InviteRequest) *shared.ApiError {
+	serverUrl := GetApiHost() + "/invites"
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.InviteUser(req)
+		}
+		return apiErr
+	}
+
+	return nil
+}
+
+func (a *Api) ListPendingInvites() ([]*shared.Invite, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/invites/pending"
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListPendingInvites()
+		}
+		return nil, apiErr
+	}
+
+	var invites []*shared.Invite
+	err = json.NewDecoder(resp.Body).Decode(&invites)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return invites, nil
+}
+
+func (a *Api) ListAcceptedInvites() ([]*shared.Invite, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/invites/accepted"
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.ListAcceptedInvites()
+		}
+		return nil, apiErr
+	}
+
+	var invites []*shared.Invite
+	err = json.NewDecoder(resp.Body).Decode(&invites)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return invites, nil
+}
+
+func (a *Api) ListAllInvites() ([]*shared.Invite, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/invites/all"
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
 	}
 	defer resp.Body.Close()
 
@@ -1823,6 +2462,74 @@ func (a *Api) CreateBranch(planId, branch string, req shared.CreateBranchRequest
 	return nil
 }
 
+func (a *Api) MergeBranch(planId, branch string, req shared.MergeBranchRequest) (*shared.BranchMergeResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/plans/%s/%s/merge", GetApiHost(), planId, branch)
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %s", err)}
+	}
+
+	resp, err := authenticatedSlowClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %s", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.MergeBranch(planId, branch, req)
+		}
+		return nil, apiErr
+	}
+
+	var res shared.BranchMergeResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %s", err)}
+	}
+
+	return &res, nil
+}
+
+func (a *Api) CherryPick(planId, branch string, req shared.CherryPickRequest) (*shared.BranchMergeResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/plans/%s/%s/cherry_pick", GetApiHost(), planId, branch)
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %s", err)}
+	}
+
+	resp, err := authenticatedSlowClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %s", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.CherryPick(planId, branch, req)
+		}
+		return nil, apiErr
+	}
+
+	var res shared.BranchMergeResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %s", err)}
+	}
+
+	return &res, nil
+}
+
 func (a *Api) DeleteBranch(planId, branch string) *shared.ApiError {
 	serverUrl := fmt.Sprintf("%s/plans/%s/branches/%s", GetApiHost(), planId, branch)
 
@@ -2355,6 +3062,70 @@ func (a *Api) GetFileMap(req shared.GetFileMapRequest) (*shared.GetFileMapRespon
 	return &respBody, nil
 }
 
+func (a *Api) GetFileChunks(req shared.GetFileChunksRequest) (*shared.GetFileChunksResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/file_chunks", GetApiHost())
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedSlowClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.GetFileChunks(req)
+		}
+		return nil, apiErr
+	}
+
+	var respBody shared.GetFileChunksResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &respBody, nil
+}
+
+func (a *Api) GetFileSymbols(req shared.GetFileSymbolsRequest) (*shared.GetFileSymbolsResponse, *shared.ApiError) {
+	serverUrl := fmt.Sprintf("%s/file_symbols", GetApiHost())
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error marshalling request: %v", err)}
+	}
+
+	resp, err := authenticatedFastClient.Post(serverUrl, "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.GetFileSymbols(req)
+		}
+		return nil, apiErr
+	}
+
+	var respBody shared.GetFileSymbolsResponse
+	err = json.NewDecoder(resp.Body).Decode(&respBody)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &respBody, nil
+}
+
 func (a *Api) GetContextBody(planId, branch, contextId string) (*shared.GetContextBodyResponse, *shared.ApiError) {
 	serverUrl := fmt.Sprintf("%s/plans/%s/%s/context/%s/body", GetApiHost(), planId, branch, contextId)
 
@@ -2452,3 +3223,30 @@ func (a *Api) GetBuildStatus(planId, branch string) (*shared.GetBuildStatusRespo
 
 	return &respBody, nil
 }
+
+func (a *Api) GetRateLimitStatus() (*shared.RateLimitStatusResponse, *shared.ApiError) {
+	serverUrl := GetApiHost() + "/rate_limits"
+	resp, err := authenticatedFastClient.Get(serverUrl)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error sending request: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		errorBody, _ := io.ReadAll(resp.Body)
+		apiErr := HandleApiError(resp, errorBody)
+		authRefreshed, apiErr := refreshAuthIfNeeded(apiErr)
+		if authRefreshed {
+			return a.GetRateLimitStatus()
+		}
+		return nil, apiErr
+	}
+
+	var status shared.RateLimitStatusResponse
+	err = json.NewDecoder(resp.Body).Decode(&status)
+	if err != nil {
+		return nil, &shared.ApiError{Type: shared.ApiErrorTypeOther, Msg: fmt.Sprintf("error decoding response: %v", err)}
+	}
+
+	return &status, nil
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var cherryPickFrom string
+
+var cherryPickCmd = &cobra.Command{
+	Use:   "cherry-pick <convo-msg-id-or-sha>",
+	Short: "Bring a single convo message or plan update from another branch into the current branch",
+	Run:   cherryPick,
+	Args:  cobra.ExactArgs(1),
+}
+
+func init() {
+	RootCmd.AddCommand(cherryPickCmd)
+
+	cherryPickCmd.Flags().StringVarP(&cherryPickFrom, "from", "f", "", "Branch to cherry-pick from")
+}
+
+func cherryPick(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	ref := strings.TrimSpace(args[0])
+	source := mustResolveSourceBranch(strings.TrimSpace(cherryPickFrom), "Select a branch to cherry-pick from")
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.CherryPick(lib.CurrentPlanId, lib.CurrentBranch, shared.CherryPickRequest{
+		SourceBranch: source,
+		Ref:          ref,
+	})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error cherry-picking: %v", apiErr)
+		return
+	}
+
+	printBranchMergeResult(res, fmt.Sprintf("Cherry-picked %s from %s into %s", ref, color.New(color.Bold, term.ColorHiCyan).Sprint(source), color.New(color.Bold, term.ColorHiCyan).Sprint(lib.CurrentBranch)))
+}
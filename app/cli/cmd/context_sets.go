@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var contextSetsCmd = &cobra.Command{
+	Use:   "context",
+	Short: "Save and reapply named context sets",
+	Long: `Save and reapply named context sets. A set stores paths, globs, maps, notes, and urls for an area of the project (e.g. 'payments-backend'). Sets are shared with everyone in your org who has access to the project.
+
+Paths and globs are stored relative to the project root and resolved against the current tree each time the set is applied, so new files matching a glob are picked up.`,
+	Args: cobra.NoArgs,
+	Run:  listContextSets,
+}
+
+var saveContextSetCmd = &cobra.Command{
+	Use:   "save <name> [paths-or-globs...]",
+	Short: "Save the current plan's context as a named set",
+	Long: `Save the current plan's files, maps, notes, and urls as a named context set, replacing any set with the same name. Other kinds of context (line ranges, git diffs, docs, commands, etc.) aren't saved.
+
+Extra paths, directories, or globs can be added after the name, e.g. 'plandex context save payments "app/payments/**/*.go"'. Quote globs so the shell doesn't expand them.`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  saveContextSet,
+}
+
+var useContextSetCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Load a named context set into the current plan",
+	Args:  cobra.ExactArgs(1),
+	Run:   useContextSet,
+}
+
+var lsContextSetsCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List the project's context sets",
+	Args:    cobra.NoArgs,
+	Run:     listContextSets,
+}
+
+var rmContextSetCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove", "delete"},
+	Short:   "Remove a context set",
+	Args:    cobra.ExactArgs(1),
+	Run:     rmContextSet,
+}
+
+var contextSetPathsOnly bool
+
+func init() {
+	RootCmd.AddCommand(contextSetsCmd)
+	contextSetsCmd.AddCommand(saveContextSetCmd)
+	contextSetsCmd.AddCommand(useContextSetCmd)
+	contextSetsCmd.AddCommand(lsContextSetsCmd)
+	contextSetsCmd.AddCommand(rmContextSetCmd)
+
+	saveContextSetCmd.Flags().BoolVar(&contextSetPathsOnly, "paths-only", false, "Only save the paths and globs given as arguments, not the current plan's context")
+}
+
+func listContextSets(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	term.StartSpinner("")
+	sets, err := lib.ListContextSets()
+	term.StopSpinner()
+
+	if err != nil {
+		term.OutputErrorAndExit("%v", err)
+	}
+
+	if len(sets) == 0 {
+		fmt.Println("🤷‍♂️  No context sets")
+		fmt.Println()
+		term.PrintCmds("", "context save")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Name", "Paths", "Maps", "Notes", "Urls"})
+
+	for _, set := range sets {
+		table.Append([]string{
+			set.Name,
+			strings.Join(set.Paths, ", "),
+			strings.Join(set.Maps, ", "),
+			strconv.Itoa(len(set.Notes)),
+			strconv.Itoa(len(set.Urls)),
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "context use", "context save", "context rm")
+}
+
+func saveContextSet(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	name := args[0]
+	patterns := args[1:]
+
+	if contextSetPathsOnly && len(patterns) == 0 {
+		term.OutputErrorAndExit("No paths or globs given")
+	}
+
+	term.StartSpinner("")
+
+	var set *shared.ContextSet
+	numSkipped := 0
+	if contextSetPathsOnly || lib.CurrentPlanId == "" {
+		set = &shared.ContextSet{Name: name}
+	} else {
+		var err error
+		set, numSkipped, err = lib.ContextSetFromPlan(name)
+		if err != nil {
+			term.StopSpinner()
+			term.OutputErrorAndExit("%v", err)
+		}
+	}
+
+	err := lib.AddContextSetPaths(set, patterns)
+	if err == nil {
+		err = lib.SaveContextSet(set)
+	}
+	term.StopSpinner()
+
+	if err != nil {
+		term.OutputErrorAndExit("Error saving context set: %v", err)
+	}
+
+	fmt.Printf("✅ Saved context set %s | %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(name), contextSetSummary(len(set.Paths), len(set.Maps), len(set.Notes), len(set.Urls)))
+	if numSkipped > 0 {
+		fmt.Printf("ℹ️  %d other context %s (ranges, diffs, docs, etc.) weren't saved\n", numSkipped, pluralize("item", numSkipped))
+	}
+	fmt.Println()
+	term.PrintCmds("", "context use", "context")
+}
+
+func useContextSet(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	term.StartSpinner("")
+	set, err := lib.GetContextSet(args[0])
+	var resolved *lib.ResolvedContextSet
+	if err == nil {
+		resolved, err = lib.ResolveContextSet(set)
+	}
+	term.StopSpinner()
+
+	if err != nil {
+		term.OutputErrorAndExit("%v", err)
+	}
+
+	for _, pattern := range resolved.Unmatched {
+		fmt.Printf("⚠️  Nothing in the project matches %s\n", pattern)
+	}
+
+	if len(resolved.Paths) == 0 && len(resolved.Maps) == 0 && len(set.Notes) == 0 && len(set.Urls) == 0 {
+		term.OutputErrorAndExit("Nothing to load from context set %s", set.Name)
+	}
+
+	// sets saved by someone else are shown before anything is loaded and sent to the server
+	if set.UpdatedBy != auth.Current.UserId {
+		fmt.Printf("Context set %s was saved by another member of your org. It will load:\n", color.New(color.Bold).Sprint(set.Name))
+		for _, path := range resolved.Paths {
+			fmt.Printf("  • %s\n", path)
+		}
+		for _, path := range resolved.Maps {
+			fmt.Printf("  • %s (map)\n", path)
+		}
+		for _, url := range set.Urls {
+			fmt.Printf("  • %s\n", url)
+		}
+		if len(set.Notes) > 0 {
+			fmt.Printf("  • %d %s\n", len(set.Notes), pluralize("note", len(set.Notes)))
+		}
+		fmt.Println()
+
+		confirmed, err := term.ConfirmYesNo("Load this context?")
+		if err != nil {
+			term.OutputErrorAndExit("Error confirming: %v", err)
+		}
+		if !confirmed {
+			return
+		}
+	}
+
+	lib.MustApplyContextSet(set, resolved)
+
+	fmt.Println()
+	term.PrintCmds("", "ls", "tell")
+}
+
+func rmContextSet(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	term.StartSpinner("")
+	err := lib.DeleteContextSet(args[0])
+	term.StopSpinner()
+
+	if err != nil {
+		term.OutputErrorAndExit("%v", err)
+	}
+
+	fmt.Printf("✅ Removed context set %s\n", args[0])
+}
+
+func contextSetSummary(numPaths, numMaps, numNotes, numUrls int) string {
+	var parts []string
+	if numPaths > 0 {
+		parts = append(parts, fmt.Sprintf("%d %s", numPaths, pluralize("path", numPaths)))
+	}
+	if numMaps > 0 {
+		parts = append(parts, fmt.Sprintf("%d %s", numMaps, pluralize("map", numMaps)))
+	}
+	if numNotes > 0 {
+		parts = append(parts, fmt.Sprintf("%d %s", numNotes, pluralize("note", numNotes)))
+	}
+	if numUrls > 0 {
+		parts = append(parts, fmt.Sprintf("%d %s", numUrls, pluralize("url", numUrls)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func pluralize(word string, n int) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
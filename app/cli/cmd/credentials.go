@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var credentialsOrg bool
+
+var credentialsCmd = &cobra.Command{
+	Use:     "credentials",
+	Aliases: []string{"creds"},
+	Short:   "List model provider credentials stored in the local keyring and org vault",
+	Run:     listCredentials,
+}
+
+var setCredentialCmd = &cobra.Command{
+	Use:   "set <NAME>",
+	Short: "Store a model provider credential, e.g. OPENAI_API_KEY",
+	Run:   setCredential,
+	Args:  cobra.ExactArgs(1),
+}
+
+var rmCredentialCmd = &cobra.Command{
+	Use:     "rm <NAME>",
+	Aliases: []string{"remove", "delete"},
+	Short:   "Remove a stored model provider credential",
+	Run:     rmCredential,
+	Args:    cobra.ExactArgs(1),
+}
+
+func init() {
+	RootCmd.AddCommand(credentialsCmd)
+	credentialsCmd.AddCommand(setCredentialCmd)
+	credentialsCmd.AddCommand(rmCredentialCmd)
+
+	setCredentialCmd.Flags().BoolVar(&credentialsOrg, "org", false, "Store in the org vault on the server instead of the local keyring")
+	rmCredentialCmd.Flags().BoolVar(&credentialsOrg, "org", false, "Remove from the org vault on the server instead of the local keyring")
+}
+
+func listCredentials(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	keyringNames, err := lib.ListKeyringCredentialNames()
+	if err != nil {
+		term.OutputErrorAndExit("Error reading keyring: %v", err)
+	}
+
+	var orgSecrets []*shared.OrgSecret
+	if !auth.Current.IsCloud {
+		term.StartSpinner("")
+		var apiErr *shared.ApiError
+		orgSecrets, apiErr = api.Client.ListOrgSecrets()
+		term.StopSpinner()
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error listing org secrets: %v", apiErr.Msg)
+		}
+	}
+
+	if len(keyringNames) == 0 && len(orgSecrets) == 0 {
+		fmt.Println("🤷‍♂️  No stored credentials")
+		fmt.Println()
+		term.PrintCmds("", "credentials set", "credentials set --org")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Name", "Source", "Active Source"})
+
+	addRow := func(name string, source shared.CredentialSourceType) {
+		active, err := lib.ResolveCredentialSource(name)
+		if err != nil {
+			term.OutputErrorAndExit("Error resolving credential: %v", err)
+		}
+
+		activeLabel := string(active)
+		if active != source {
+			// overridden by a higher-priority source, e.g. an env var
+			activeLabel = color.New(term.ColorHiYellow).Sprint(activeLabel)
+		}
+		table.Append([]string{name, string(source), activeLabel})
+	}
+
+	for _, name := range keyringNames {
+		addRow(name, shared.CredentialSourceKeyring)
+	}
+	for _, secret := range orgSecrets {
+		addRow(secret.Name, shared.CredentialSourceOrgVault)
+	}
+
+	table.Render()
+	fmt.Println()
+	fmt.Println("Credentials are resolved in order: env vars → local keyring → Vault (if PLANDEX_VAULT_ADDR is set) → org vault")
+}
+
+func setCredential(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	name := strings.TrimSpace(args[0])
+
+	value, err := term.GetUserPasswordInput(fmt.Sprintf("Value for %s:", name))
+	if err != nil {
+		term.OutputErrorAndExit("Error reading value: %v", err)
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		term.OutputErrorAndExit("Value is required")
+	}
+
+	if credentialsOrg {
+		if auth.Current.IsCloud {
+			term.OutputErrorAndExit("The org vault is not supported on Plandex Cloud")
+		}
+
+		term.StartSpinner("")
+		apiErr := api.Client.SetOrgSecret(shared.SetOrgSecretRequest{Name: name, Value: value})
+		term.StopSpinner()
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error storing credential in org vault: %v", apiErr.Msg)
+		}
+
+		fmt.Printf("✅ Stored %s in the org vault\n", color.New(color.Bold).Sprint(name))
+		return
+	}
+
+	err = lib.SetKeyringCredential(name, value)
+	if err != nil {
+		term.OutputErrorAndExit("Error storing credential in keyring: %v", err)
+	}
+
+	fmt.Printf("✅ Stored %s in the local keyring\n", color.New(color.Bold).Sprint(name))
+
+	if os.Getenv(name) != "" {
+		fmt.Println()
+		color.New(term.ColorHiYellow).Printf("⚠️  %s is also set in your environment, which takes precedence\n", name)
+	}
+}
+
+func rmCredential(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	name := strings.TrimSpace(args[0])
+
+	if credentialsOrg {
+		if auth.Current.IsCloud {
+			term.OutputErrorAndExit("The org vault is not supported on Plandex Cloud")
+		}
+
+		term.StartSpinner("")
+		apiErr := api.Client.DeleteOrgSecret(name)
+		term.StopSpinner()
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error removing credential from org vault: %v", apiErr.Msg)
+		}
+
+		fmt.Printf("✅ Removed %s from the org vault\n", color.New(color.Bold).Sprint(name))
+		return
+	}
+
+	removed, err := lib.DeleteKeyringCredential(name)
+	if err != nil {
+		term.OutputErrorAndExit("Error removing credential from keyring: %v", err)
+	}
+
+	if !removed {
+		term.OutputErrorAndExit("No credential named %s in the local keyring", name)
+	}
+
+	fmt.Printf("✅ Removed %s from the local keyring\n", color.New(color.Bold).Sprint(name))
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var docsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "List the project's cached docs bundles",
+	Long: `List the project's cached docs bundles. Bundles are crawled with 'plandex load --crawl <url>' and can be loaded into any plan in the project with 'plandex load --docs <name>'.
+
+Refreshing a bundle re-crawls it with the same settings. Plans that have it loaded pick up the changes the next time their context is updated.`,
+	Args: cobra.NoArgs,
+	Run:  listDocs,
+}
+
+var refreshDocsCmd = &cobra.Command{
+	Use:   "refresh <name>",
+	Short: "Re-crawl a cached docs bundle",
+	Args:  cobra.ExactArgs(1),
+	Run:   refreshDocsBundle,
+}
+
+var rmDocsCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove", "delete"},
+	Short:   "Remove a cached docs bundle",
+	Args:    cobra.ExactArgs(1),
+	Run:     rmDocsBundle,
+}
+
+func init() {
+	RootCmd.AddCommand(docsCmd)
+	docsCmd.AddCommand(refreshDocsCmd)
+	docsCmd.AddCommand(rmDocsCmd)
+}
+
+func listDocs(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	bundles, err := lib.ListDocsBundles()
+	if err != nil {
+		term.OutputErrorAndExit("Error listing docs bundles: %v", err)
+	}
+
+	if len(bundles) == 0 {
+		fmt.Println("🤷‍♂️  No docs bundles")
+		fmt.Println()
+		term.PrintCmds("", "load --crawl")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Name", "Url", "Pages", "Version", "Crawled"})
+
+	for _, bundle := range bundles {
+		url := bundle.StartUrl
+		if len(bundle.Include) > 0 {
+			url += " (" + strings.Join(bundle.Include, ", ") + ")"
+		}
+		table.Append([]string{
+			bundle.Name,
+			url,
+			strconv.Itoa(len(bundle.Pages)),
+			strconv.Itoa(bundle.Version),
+			bundle.CrawledAt.Local().Format("Jan 2, 2006 3:04pm"),
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "load --docs", "docs refresh", "docs rm")
+}
+
+func refreshDocsBundle(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	name := args[0]
+	bundle, err := lib.LoadDocsBundle(name)
+	if err != nil {
+		term.OutputErrorAndExit("Error loading docs bundle: %v", err)
+	}
+	if bundle == nil {
+		term.OutputErrorAndExit("No docs bundle named %s", name)
+	}
+
+	prevVersion := bundle.Version
+
+	term.StartSpinner(fmt.Sprintf("🕸️  Crawling %s...", name))
+	err = bundle.Crawl()
+	term.StopSpinner()
+	if err != nil {
+		term.OutputErrorAndExit("Error refreshing docs bundle: %v", err)
+	}
+
+	if bundle.Version == prevVersion {
+		fmt.Printf("✅ %s is up to date | %d pages\n", name, len(bundle.Pages))
+		return
+	}
+
+	fmt.Printf("✅ Refreshed %s | %d pages | version %d\n", name, len(bundle.Pages), bundle.Version)
+	fmt.Println()
+	term.PrintCmds("", "update")
+}
+
+func rmDocsBundle(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	err := lib.DeleteDocsBundle(args[0])
+	if err != nil {
+		term.OutputErrorAndExit("Error removing docs bundle: %v", err)
+	}
+
+	fmt.Printf("✅ Removed docs bundle %s\n", args[0])
+}
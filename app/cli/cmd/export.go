@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"regexp"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var exportOutput string
+
+var exportCmd = &cobra.Command{
+	Use:   "export [name-or-index]",
+	Short: "Export a plan to a portable archive",
+	Long:  "Export a plan's conversation, summaries, context, pending and applied changes, and every branch with its full history to an archive that can be restored with 'plandex import'. Defaults to the current plan.",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   export,
+}
+
+func init() {
+	RootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Path to write the archive to (defaults to <plan-name>.plandex.tar.gz)")
+}
+
+var exportFileNameRe = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+func export(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	var nameOrIdx string
+	if len(args) > 0 {
+		nameOrIdx = strings.TrimSpace(args[0])
+	}
+
+	var plan *shared.Plan
+
+	term.StartSpinner("")
+
+	if nameOrIdx == "" {
+		if lib.CurrentPlanId == "" {
+			term.StopSpinner()
+			term.OutputNoCurrentPlanErrorAndExit()
+		}
+
+		var apiErr *shared.ApiError
+		plan, apiErr = api.Client.GetPlan(lib.CurrentPlanId)
+		if apiErr != nil {
+			term.StopSpinner()
+			term.OutputErrorAndExit("Error getting plan: %v", apiErr)
+		}
+	} else {
+		plans, apiErr := api.Client.ListPlans([]string{lib.CurrentProjectId})
+		if apiErr != nil {
+			term.StopSpinner()
+			term.OutputErrorAndExit("Error getting plans: %v", apiErr)
+		}
+
+		idx, err := strconv.Atoi(nameOrIdx)
+		if err == nil && idx > 0 && idx <= len(plans) {
+			plan = plans[idx-1]
+		} else {
+			for _, p := range plans {
+				if p.Name == nameOrIdx {
+					plan = p
+					break
+				}
+			}
+		}
+	}
+
+	if plan == nil {
+		term.StopSpinner()
+		term.OutputErrorAndExit("Plan not found")
+	}
+
+	outputPath := exportOutput
+	if outputPath == "" {
+		outputPath = exportFileNameRe.ReplaceAllString(plan.Name, "-") + ".plandex.tar.gz"
+	}
+
+	if _, err := os.Stat(outputPath); err == nil {
+		term.StopSpinner()
+		term.OutputErrorAndExit("%s already exists. Use --output/-o to write the archive somewhere else.", outputPath)
+	}
+
+	archive, apiErr := api.Client.ExportPlan(plan.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error exporting plan: %v", apiErr)
+	}
+
+	err := os.WriteFile(outputPath, archive, 0644)
+	if err != nil {
+		term.OutputErrorAndExit("Error writing archive: %v", err)
+	}
+
+	fmt.Printf("✅ Exported plan %s to %s\n", color.New(color.Bold, term.ColorHiGreen).Sprint(plan.Name), outputPath)
+	fmt.Println()
+	term.PrintCmds("", "import")
+}
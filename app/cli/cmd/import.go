@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var importName string
+
+var importCmd = &cobra.Command{
+	Use:   "import <archive>",
+	Short: "Import a plan from an archive created with 'plandex export'",
+	Args:  cobra.ExactArgs(1),
+	Run:   importPlan,
+}
+
+func init() {
+	RootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringVarP(&importName, "name", "n", "", "Name for the imported plan (defaults to the exported plan's name)")
+}
+
+func importPlan(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveOrCreateProject()
+
+	archive, err := os.ReadFile(args[0])
+	if err != nil {
+		term.OutputErrorAndExit("Error reading archive: %v", err)
+	}
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.ImportPlan(lib.CurrentProjectId, importName, archive)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error importing plan: %v", apiErr)
+	}
+
+	err = lib.WriteCurrentPlan(res.Id)
+	if err != nil {
+		term.OutputErrorAndExit("Error setting current plan: %v", err)
+	}
+
+	err = lib.WriteCurrentBranch("main")
+	if err != nil {
+		term.OutputErrorAndExit("Error setting current branch: %v", err)
+	}
+
+	fmt.Printf("✅ Imported plan %s and set it to current plan\n", color.New(color.Bold, term.ColorHiGreen).Sprint(res.Name))
+	fmt.Println()
+	term.PrintCmds("", "convo", "ls", "branches", "diff")
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/fs"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var indexReset bool
+
+var indexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Build or update the project's embeddings index for retrieval",
+	Long: `Build or update the project's embeddings index for retrieval. Files are chunked by their definitions and embedded with the endpoint and model from the 'embeddings-base-url' and 'embeddings-model' config settings. Only files that changed since the last update are re-embedded.
+
+When the 'retrieval' config setting is enabled, the index is also updated automatically before each prompt that auto-loads context.`,
+	Args: cobra.NoArgs,
+	Run:  index,
+}
+
+func init() {
+	RootCmd.AddCommand(indexCmd)
+
+	indexCmd.Flags().BoolVar(&indexReset, "reset", false, "Discard the existing index and rebuild it")
+}
+
+func index(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	if indexReset {
+		err := lib.ClearRetrievalIndex()
+		if err != nil {
+			term.OutputErrorAndExit("Error resetting index: %v", err)
+		}
+	}
+
+	term.StartSpinner("🔎 Indexing project...")
+
+	contexts, apiErr := api.Client.ListContext(lib.CurrentPlanId, lib.CurrentBranch)
+	if apiErr != nil {
+		term.StopSpinner()
+		term.OutputErrorAndExit("Error getting context: %v", apiErr.Msg)
+	}
+
+	paths, err := fs.GetProjectPaths(fs.GetBaseDirForContexts(contexts))
+	if err != nil {
+		term.StopSpinner()
+		term.OutputErrorAndExit("Error getting project paths: %v", err)
+	}
+
+	res, err := lib.UpdateRetrievalIndex(paths.ActivePaths)
+	term.StopSpinner()
+
+	if err != nil {
+		term.OutputErrorAndExit("Error updating index: %v", err)
+	}
+
+	fmt.Printf("✅ Indexed %d files in %d chunks", res.NumFiles, res.NumChunks)
+	if res.NumUpdated > 0 || res.NumRemoved > 0 {
+		fmt.Printf(" (%d updated, %d removed)", res.NumUpdated, res.NumRemoved)
+	}
+	fmt.Println()
+
+	if !lib.MustGetCurrentPlanConfig().Retrieval {
+		fmt.Println()
+		fmt.Printf("Retrieval is disabled for this plan. Enable it with %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint("plandex set-config retrieval true"))
+	}
+}
@@ -6,8 +6,12 @@ import (
 	"plandex-cli/auth"
 	"plandex-cli/lib"
 	"plandex-cli/term"
+	"strings"
 	"time"
 
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
@@ -21,9 +25,20 @@ var logCmd = &cobra.Command{
 	Run:     runLog,
 }
 
+var logDiffCmd = &cobra.Command{
+	Use:   "diff <sha1> [sha2]",
+	Short: "Show changes to pending files, context, and the conversation between two points in plan history",
+	Long:  `Show changes to pending files, context, and the conversation between two points in plan history. If sha2 is omitted, sha1 is compared with the latest point on the current branch.`,
+	Args:  cobra.RangeArgs(1, 2),
+	Run:   runLogDiff,
+}
+
 func init() {
 	// Add log command
 	RootCmd.AddCommand(logCmd)
+
+	logCmd.AddCommand(logDiffCmd)
+	logDiffCmd.Flags().BoolVarP(&plainTextOutput, "plain", "p", false, "Output in plain text with no ANSI codes")
 }
 
 func runLog(cmd *cobra.Command, args []string) {
@@ -51,8 +66,120 @@ func runLog(cmd *cobra.Command, args []string) {
 	term.PageOutput(withLocalTimestamps)
 
 	fmt.Println()
-	term.PrintCmds("", "rewind", "continue", "convo", "convo 1", "convo 2-5")
+	term.PrintCmds("", "rewind", "log diff", "continue", "convo", "convo 1", "convo 2-5")
+
+}
+
+func runLogDiff(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	var to string
+	if len(args) > 1 {
+		to = args[1]
+	}
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.GetLogDiff(lib.CurrentPlanId, lib.CurrentBranch, args[0], to, plainTextOutput)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting log diff: %v", apiErr.Msg)
+	}
+
+	if len(res.ContextChanges) == 0 && len(res.AddedConvoMessages) == 0 && len(res.RemovedConvoMessages) == 0 && res.Diffs == "" {
+		fmt.Printf("🤷‍♂️ No changes between %s and %s\n", shortSha(res.FromSha), shortSha(res.ToSha))
+		return
+	}
+
+	sectionColor := color.New(color.Bold, term.ColorHiCyan)
+	section := func(title string) string {
+		if plainTextOutput {
+			return title + "\n\n"
+		}
+		return sectionColor.Sprint(title) + "\n\n"
+	}
+
+	var out strings.Builder
+	out.WriteString(section(fmt.Sprintf("📝 Changes from %s to %s", shortSha(res.FromSha), shortSha(res.ToSha))))
+
+	if len(res.ContextChanges) > 0 {
+		out.WriteString(section("📥 Context"))
+		for _, change := range res.ContextChanges {
+			_, icon := lib.GetContextLabelAndIcon(change.Context.ContextType)
+			var sign string
+			var c *color.Color
+			switch change.Change {
+			case shared.PlanLogContextAdded:
+				sign, c = "+", color.New(term.ColorHiGreen)
+			case shared.PlanLogContextRemoved:
+				sign, c = "-", color.New(term.ColorHiRed)
+			default:
+				sign, c = "~", color.New(term.ColorHiYellow)
+			}
+			line := fmt.Sprintf("%s %s %s (%d 🪙)", sign, icon, change.Context.Name, change.Context.NumTokens)
+			if plainTextOutput {
+				out.WriteString(line + "\n")
+			} else {
+				out.WriteString(c.Sprint(line) + "\n")
+			}
+		}
+		out.WriteString("\n")
+	}
+
+	writeMessages := func(title string, msgs []*shared.ConvoMessage) {
+		if len(msgs) == 0 {
+			return
+		}
+		out.WriteString(section(title))
+		for _, msg := range msgs {
+			author := msg.Role
+			if msg.Role == "assistant" {
+				author = "🤖 Plandex"
+			} else if msg.Role == "user" {
+				author = "💬 You"
+			}
+
+			txt := fmt.Sprintf("#### %d | %s | %d 🪙\n%s\n\n", msg.Num, author, msg.Tokens, convertCodeBlocks(msg.Message))
+			if plainTextOutput {
+				out.WriteString(txt)
+			} else {
+				md, err := term.GetMarkdown(txt)
+				if err != nil {
+					term.OutputErrorAndExit("Error creating markdown representation: %v", err)
+				}
+				out.WriteString(md)
+			}
+		}
+	}
+	writeMessages("💬 New messages", res.AddedConvoMessages)
+	writeMessages("🗑️  Removed messages", res.RemovedConvoMessages)
+
+	if res.Diffs != "" {
+		out.WriteString(section("📄 Pending changes"))
+		out.WriteString(res.Diffs)
+	}
+
+	if plainTextOutput {
+		fmt.Println(out.String())
+		return
+	}
+
+	term.PageOutput(out.String())
+
+	fmt.Println()
+	term.PrintCmds("", "log", "rewind", "diff")
+}
 
+func shortSha(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
 }
 
 func convertTimestampsToLocal(input string) (string, error) {
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge [branch]",
+	Short: "Merge another branch's convo, context, and pending changes into the current branch",
+	Run:   merge,
+	Args:  cobra.MaximumNArgs(1),
+}
+
+func init() {
+	RootCmd.AddCommand(mergeCmd)
+}
+
+func merge(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	var source string
+	if len(args) > 0 {
+		source = strings.TrimSpace(args[0])
+	}
+
+	source = mustResolveSourceBranch(source, "Select a branch to merge into "+lib.CurrentBranch)
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.MergeBranch(lib.CurrentPlanId, lib.CurrentBranch, shared.MergeBranchRequest{
+		SourceBranch: source,
+	})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error merging branch: %v", apiErr)
+		return
+	}
+
+	printBranchMergeResult(res, fmt.Sprintf("Merged branch %s into %s", color.New(color.Bold, term.ColorHiCyan).Sprint(source), color.New(color.Bold, term.ColorHiCyan).Sprint(lib.CurrentBranch)))
+}
+
+// mustResolveSourceBranch checks that source is an existing branch other than the current one, or prompts to select one if it's empty
+func mustResolveSourceBranch(source, selectMsg string) string {
+	term.StartSpinner("")
+	branches, apiErr := api.Client.ListBranches(lib.CurrentPlanId)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting branches: %v", apiErr)
+	}
+
+	var opts []string
+	for _, b := range branches {
+		if b.Name != lib.CurrentBranch {
+			opts = append(opts, b.Name)
+		}
+	}
+
+	if source == "" {
+		if len(opts) == 0 {
+			fmt.Println("🤷‍♂️ No other branches")
+			os.Exit(0)
+		}
+
+		sel, err := term.SelectFromList(selectMsg, opts)
+		if err != nil {
+			term.OutputErrorAndExit("Error selecting branch: %v", err)
+		}
+		return sel
+	}
+
+	if source == lib.CurrentBranch {
+		term.OutputErrorAndExit("Already on branch %s", source)
+	}
+
+	for _, name := range opts {
+		if name == source {
+			return source
+		}
+	}
+
+	fmt.Printf("🤷‍♂️ Branch %s does not exist\n", color.New(color.Bold, term.ColorHiCyan).Sprint(source))
+	os.Exit(1)
+	return ""
+}
+
+func printBranchMergeResult(res *shared.BranchMergeResponse, successMsg string) {
+	if res.UpToDate {
+		fmt.Printf("🤷‍♂️ Nothing to bring in — %s already has these changes\n", color.New(color.Bold, term.ColorHiCyan).Sprint(res.TargetBranch))
+		return
+	}
+
+	if len(res.Conflicts) > 0 {
+		color.New(color.Bold, term.ColorHiRed).Println("🚨 Conflicts — nothing was changed")
+		fmt.Println()
+		for _, conflict := range res.Conflicts {
+			fmt.Printf("• %s → %s\n", color.New(color.Bold).Sprint(conflict.Path), conflict.Reason)
+		}
+		fmt.Println()
+		fmt.Println("Apply or reject the conflicting pending changes on one of the branches, then try again.")
+		fmt.Println()
+		term.PrintCmds("", "diff", "reject")
+		os.Exit(1)
+	}
+
+	fmt.Println("✅ " + successMsg)
+	fmt.Println()
+
+	lines := []struct {
+		n     int
+		label string
+	}{
+		{res.ConvoMessagesAdded, "convo messages added"},
+		{res.ContextsAdded, "context added"},
+		{res.ContextsUpdated, "context updated"},
+		{res.ContextsRemoved, "context removed"},
+		{res.ResultsAdded, "pending results added"},
+		{res.ResultsUpdated, "pending results updated"},
+		{res.SubtasksAdded, "subtasks added"},
+	}
+
+	printedAny := false
+	for _, line := range lines {
+		if line.n > 0 {
+			fmt.Printf("• %d %s\n", line.n, line.label)
+			printedAny = true
+		}
+	}
+	if !printedAny {
+		fmt.Println("• No new messages, context, or changes")
+	}
+
+	if len(res.PendingPaths) > 0 {
+		fmt.Println()
+		fmt.Println("Pending changes to:")
+		for _, path := range res.PendingPaths {
+			fmt.Println("• " + path)
+		}
+	}
+
+	fmt.Println()
+	term.PrintCmds("", "diff", "convo", "log")
+}
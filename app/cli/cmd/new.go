@@ -17,6 +17,8 @@ import (
 
 var name string
 var contextBaseDir string
+var newTemplateName string
+var newTemplateVars []string
 
 // newCmd represents the new command
 var newCmd = &cobra.Command{
@@ -32,6 +34,8 @@ func init() {
 	RootCmd.AddCommand(newCmd)
 	newCmd.Flags().StringVarP(&name, "name", "n", "", "Name of the new plan")
 	newCmd.Flags().StringVar(&contextBaseDir, "context-dir", ".", "Base directory to auto-load context from")
+	newCmd.Flags().StringVar(&newTemplateName, "template", "", "Start the plan from a template in "+lib.PlanTemplatesDirName+" or your org and run its steps")
+	newCmd.Flags().StringArrayVar(&newTemplateVars, "var", nil, "Set a template var with name=value (repeatable)")
 
 	AddNewPlanFlags(newCmd)
 }
@@ -40,6 +44,35 @@ func new(cmd *cobra.Command, args []string) {
 	auth.MustResolveAuthWithOrg()
 	lib.MustResolveOrCreateProject()
 
+	if newTemplateName == "" && len(newTemplateVars) > 0 {
+		term.OutputErrorAndExit("Error: --var requires --template")
+	}
+
+	// resolve and render the template before creating the plan so a missing var doesn't leave an empty plan behind
+	var template *shared.PlanTemplate
+	if newTemplateName != "" {
+		vars, err := parseTemplateVars(newTemplateVars)
+		if err != nil {
+			term.OutputErrorAndExit("Error: %v", err)
+		}
+
+		term.StartSpinner("")
+		resolved, err := lib.GetPlanTemplate(newTemplateName)
+		term.StopSpinner()
+		if err != nil {
+			term.OutputErrorAndExit("Error: %v", err)
+		}
+
+		template, err = resolved.Render(vars)
+		if err != nil {
+			term.OutputErrorAndExit("Error: %v", err)
+		}
+
+		if name == "" {
+			name = template.Name
+		}
+	}
+
 	term.StartSpinner("")
 
 	errCh := make(chan error, 2)
@@ -92,7 +125,12 @@ func new(cmd *cobra.Command, args []string) {
 	term.StopSpinner()
 
 	fmt.Printf("✅ Started new plan %s and set it to current plan\n", color.New(color.Bold, term.ColorHiGreen).Sprint(name))
-	fmt.Printf("⚙️  Using default config\n")
+	if template != nil {
+		fmt.Printf("📋 Using template %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(template.Name))
+		config = applyPlanTemplateSettings(template, config)
+	} else {
+		fmt.Printf("⚙️  Using default config\n")
+	}
 
 	resolveAutoMode(config)
 
@@ -118,6 +156,12 @@ func new(cmd *cobra.Command, args []string) {
 		fmt.Println()
 	}
 
+	if template != nil {
+		loadPlanTemplateContext(template)
+		runPlanTemplateSteps(cmd, template)
+		return
+	}
+
 	var cmds []string
 	if term.IsRepl {
 		cmds = []string{"config", "plans", "cd", "models"}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/term"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var policyAllowedProviders []string
+var policyAllowedModelPacks []string
+var policyMaxAutoMode string
+var policyDisableExec bool
+var policyMaxContextTokens int
+
+var orgPolicyCmd = &cobra.Command{
+	Use:   "org-policy",
+	Short: "Show the org policy for providers, model packs, auto mode, command execution, and context size",
+	Run:   showOrgPolicy,
+}
+
+var setOrgPolicyCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Update the org policy — only the flags you pass are changed",
+	Run:   setOrgPolicy,
+}
+
+var clearOrgPolicyCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all org policy restrictions",
+	Run:   clearOrgPolicy,
+}
+
+func init() {
+	RootCmd.AddCommand(orgPolicyCmd)
+	orgPolicyCmd.AddCommand(setOrgPolicyCmd)
+	orgPolicyCmd.AddCommand(clearOrgPolicyCmd)
+
+	setOrgPolicyCmd.Flags().StringSliceVar(&policyAllowedProviders, "providers", nil, "Comma-separated providers models may be served by, e.g. openai,anthropic (empty to allow all)")
+	setOrgPolicyCmd.Flags().StringSliceVar(&policyAllowedModelPacks, "model-packs", nil, "Comma-separated model packs users may select (empty to allow all)")
+	setOrgPolicyCmd.Flags().StringVar(&policyMaxAutoMode, "max-auto-mode", "", "Most automated auto mode allowed: none, basic, plus, semi, or full")
+	setOrgPolicyCmd.Flags().BoolVar(&policyDisableExec, "disable-exec", false, "Don't allow executing commands")
+	setOrgPolicyCmd.Flags().IntVar(&policyMaxContextTokens, "max-context-tokens", 0, "Maximum tokens of context per plan (0 for no cap beyond model limits)")
+}
+
+func showOrgPolicy(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	policy, apiErr := api.Client.GetOrgPolicy()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting org policy: %v", apiErr.Msg)
+	}
+
+	fmt.Println(color.New(color.Bold, term.ColorHiCyan).Sprint("Org Policy"))
+	fmt.Println()
+	fmt.Println(policy.Describe())
+	fmt.Println()
+	term.PrintCmds("", "org-policy set", "org-policy clear")
+}
+
+func setOrgPolicy(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	policy, apiErr := api.Client.GetOrgPolicy()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting org policy: %v", apiErr.Msg)
+	}
+
+	flags := cmd.Flags()
+	if flags.NFlag() == 0 {
+		term.OutputErrorAndExit("No policy changes specified. Run 'plandex org-policy set --help' for available flags.")
+	}
+
+	if flags.Changed("providers") {
+		policy.AllowedProviders = policyAllowedProviders
+	}
+	if flags.Changed("model-packs") {
+		policy.AllowedModelPacks = policyAllowedModelPacks
+	}
+	if flags.Changed("max-auto-mode") {
+		policy.MaxAutoMode = shared.AutoModeType(policyMaxAutoMode)
+	}
+	if flags.Changed("disable-exec") {
+		policy.DisableExec = policyDisableExec
+	}
+	if flags.Changed("max-context-tokens") {
+		policy.MaxContextTokens = policyMaxContextTokens
+	}
+
+	err := policy.Validate()
+	if err != nil {
+		term.OutputErrorAndExit("Invalid org policy: %v", err)
+	}
+
+	term.StartSpinner("")
+	apiErr = api.Client.UpdateOrgPolicy(shared.UpdateOrgPolicyRequest{Policy: policy})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error updating org policy: %v", apiErr.Msg)
+	}
+
+	fmt.Println("✅ Updated org policy")
+	fmt.Println()
+	fmt.Println(policy.Describe())
+}
+
+func clearOrgPolicy(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	apiErr := api.Client.UpdateOrgPolicy(shared.UpdateOrgPolicyRequest{Policy: &shared.OrgPolicy{}})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error clearing org policy: %v", apiErr.Msg)
+	}
+
+	fmt.Println("✅ Cleared org policy")
+}
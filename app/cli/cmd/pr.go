@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/fs"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var prBranchName string
+var prPush bool
+var prRemote string
+var prOutput string
+var prAutoConfirm bool
+
+var prCmd = &cobra.Command{
+	Use:   "pr",
+	Short: "Create a git branch with a commit per subtask and a PR description from the latest apply",
+	Long: `Create a git branch from the plan's latest apply, with a commit per subtask and a markdown PR description built from the plan summary and conversation.
+
+If the applied changes are uncommitted, they're committed on the new branch. If they were auto-committed in a single commit at HEAD, that commit is split into one commit per subtask on the new branch.`,
+	Args: cobra.NoArgs,
+	Run:  runPR,
+}
+
+func init() {
+	RootCmd.AddCommand(prCmd)
+
+	prCmd.Flags().StringVarP(&prBranchName, "branch", "b", "", "Name of the git branch to create (defaults to plandex/<plan-name>)")
+	prCmd.Flags().BoolVar(&prPush, "push", false, "Push the branch after creating it")
+	prCmd.Flags().StringVar(&prRemote, "remote", "", "Git remote to push to (defaults to the pr-remote config setting, or origin)")
+	prCmd.Flags().StringVarP(&prOutput, "output", "o", "", "Write the PR description to a file instead of printing it")
+	prCmd.Flags().BoolVarP(&prAutoConfirm, "yes", "y", false, "Skip confirmation")
+}
+
+func runPR(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	if !fs.ProjectRootIsGitRepo() {
+		term.OutputErrorAndExit("'plandex pr' requires the project to be in a git repository")
+	}
+
+	term.StartSpinner("")
+	pr, apiErr := api.Client.GetPlanPR(lib.CurrentPlanId, lib.CurrentBranch)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		if apiErr.Status == 404 {
+			fmt.Println("🤷‍♂️ No applied changes")
+			fmt.Println()
+			term.PrintCmds("", "apply")
+			return
+		}
+		term.OutputErrorAndExit("Error getting plan changes: %v", apiErr.Msg)
+	}
+
+	branch := prBranchName
+	if branch == "" {
+		branch = pr.BranchName
+	}
+
+	if lib.GitBranchExists(fs.ProjectRoot, branch) {
+		term.OutputErrorAndExit("Git branch %s already exists - use --branch to choose another name", branch)
+	}
+
+	// applied changes are either still uncommitted, or were committed together at HEAD by apply
+	var uncommitted []*shared.PlanPRCommit
+	numUncommitted := 0
+	for _, commit := range pr.Commits {
+		var paths []string
+		for _, path := range commit.Paths {
+			hasChanges, err := lib.GitFileHasUncommittedChanges(filepath.Join(fs.ProjectRoot, path))
+			if err != nil {
+				term.OutputErrorAndExit("Error checking git status: %v", err)
+			}
+			if hasChanges {
+				paths = append(paths, path)
+			}
+		}
+		if len(paths) > 0 {
+			uncommitted = append(uncommitted, &shared.PlanPRCommit{Message: commit.Message, Paths: paths})
+			numUncommitted += len(paths)
+		}
+	}
+
+	commits := uncommitted
+	splitHead := false
+	var headOnlyPaths []string
+	var headMsg string
+
+	if numUncommitted == 0 {
+		var headPaths []string
+		var err error
+		headMsg, headPaths, err = lib.GitHeadCommit(fs.ProjectRoot)
+		if err != nil {
+			term.OutputErrorAndExit("Error reading HEAD commit: %v", err)
+		}
+
+		inHead := map[string]bool{}
+		for _, path := range headPaths {
+			inHead[path] = true
+		}
+
+		splitHead = pr.ApplyCommitMsg != "" && strings.Contains(headMsg, pr.ApplyCommitMsg)
+		inPR := map[string]bool{}
+		for _, commit := range pr.Commits {
+			for _, path := range commit.Paths {
+				inPR[path] = true
+				if !inHead[path] {
+					splitHead = false
+				}
+			}
+		}
+
+		if splitHead {
+			commits = pr.Commits
+			for _, path := range headPaths {
+				if !inPR[path] {
+					headOnlyPaths = append(headOnlyPaths, path)
+				}
+			}
+		} else {
+			commits = nil
+		}
+	}
+
+	bold := color.New(color.Bold, term.ColorHiCyan)
+
+	fmt.Printf("Branch: %s\n", bold.Sprint(branch))
+	fmt.Printf("Title: %s\n", color.New(color.Bold).Sprint(pr.Title))
+	fmt.Println()
+
+	if splitHead {
+		fmt.Println("✂️  The HEAD commit from apply will be split into:")
+	} else if len(commits) > 0 {
+		fmt.Println("✏️  Uncommitted changes from apply will be committed as:")
+	} else {
+		fmt.Println("⚠️  The applied changes are already committed, but not in a single commit at HEAD. The branch will be created without new commits.")
+	}
+	for i, commit := range commits {
+		fmt.Printf("  %d. %s\n", i+1, color.New(color.Bold).Sprint(strings.SplitN(commit.Message, "\n", 2)[0]))
+		for _, path := range commit.Paths {
+			fmt.Printf("     • %s\n", path)
+		}
+	}
+	fmt.Println()
+
+	if !prAutoConfirm {
+		confirmed, err := term.ConfirmYesNo("Create branch?")
+		if err != nil {
+			term.OutputErrorAndExit("Error getting confirmation user input: %v", err)
+		}
+		if !confirmed {
+			fmt.Println("PR canceled")
+			return
+		}
+	}
+
+	term.StartSpinner("")
+
+	err := lib.GitCheckoutNewBranch(fs.ProjectRoot, branch)
+	if err != nil {
+		term.StopSpinner()
+		term.OutputErrorAndExit("Error: %v", err)
+	}
+
+	if splitHead {
+		err = lib.GitUndoHeadCommit(fs.ProjectRoot)
+		if err != nil {
+			term.StopSpinner()
+			term.OutputErrorAndExit("Error: %v", err)
+		}
+	}
+
+	for _, commit := range commits {
+		err = lib.GitAddAndCommitPaths(fs.ProjectRoot, commit.Message, commit.Paths, true)
+		if err != nil {
+			term.StopSpinner()
+			term.OutputErrorAndExit("Error committing '%s': %v", commit.Message, err)
+		}
+	}
+
+	// anything else that was in the apply commit keeps the original message
+	if len(headOnlyPaths) > 0 {
+		err = lib.GitAddAndCommitPaths(fs.ProjectRoot, headMsg, headOnlyPaths, true)
+		if err != nil {
+			term.StopSpinner()
+			term.OutputErrorAndExit("Error committing remaining changes: %v", err)
+		}
+	}
+
+	term.StopSpinner()
+
+	fmt.Printf("✅ Created branch %s with %d commits\n", bold.Sprint(branch), len(commits))
+
+	if prPush {
+		remote := prRemote
+		if remote == "" {
+			remote = lib.MustGetCurrentPlanConfig().GetPRRemote()
+		}
+
+		fmt.Println()
+		fmt.Printf("🚀 Pushing %s to %s\n", branch, remote)
+		err = lib.GitPushBranch(fs.ProjectRoot, remote, branch)
+		if err != nil {
+			term.OutputErrorAndExit("Error: %v", err)
+		}
+	}
+
+	fmt.Println()
+
+	if prOutput != "" {
+		err = os.WriteFile(prOutput, []byte("# "+pr.Title+"\n\n"+pr.Description), 0644)
+		if err != nil {
+			term.OutputErrorAndExit("Error writing PR description: %v", err)
+		}
+		fmt.Printf("📝 Wrote PR description to %s\n", prOutput)
+		return
+	}
+
+	bold.Println("PR description")
+	fmt.Println()
+	fmt.Println("# " + pr.Title)
+	fmt.Println()
+	fmt.Println(pr.Description)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/term"
+	"strconv"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var rateLimitsCmd = &cobra.Command{
+	Use:   "rate-limits",
+	Short: "Show the server's per-user and per-org rate limits and your current usage",
+	Run:   showRateLimits,
+}
+
+func init() {
+	RootCmd.AddCommand(rateLimitsCmd)
+}
+
+func showRateLimits(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	status, apiErr := api.Client.GetRateLimitStatus()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting rate limits: %v", apiErr.Msg)
+	}
+
+	fmt.Println(color.New(color.Bold, term.ColorHiCyan).Sprint("Rate Limits"))
+	fmt.Println()
+
+	if status.UserLimits.IsEmpty() && status.OrgLimits.IsEmpty() {
+		fmt.Println("No rate limits are set on this server")
+		return
+	}
+
+	mode := "requests over a limit are rejected"
+	if status.Mode == shared.RateLimitModeWait {
+		mode = fmt.Sprintf("requests over a limit wait up to %ds", status.WaitTimeoutSeconds)
+	}
+	fmt.Println("Mode: " + mode)
+	fmt.Println()
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"", "Active Plans", "Requests (last min)", "Tokens (last min)"})
+
+	table.Append(rateLimitRow("You", status.User, status.UserLimits))
+	table.Append(rateLimitRow("Org", status.Org, status.OrgLimits))
+
+	table.Render()
+}
+
+func rateLimitRow(label string, usage shared.RateLimitUsage, limits shared.RateLimits) []string {
+	format := func(n, max int) string {
+		if max == 0 {
+			return strconv.Itoa(n)
+		}
+		return fmt.Sprintf("%d / %d", n, max)
+	}
+
+	return []string{
+		label,
+		format(usage.ActivePlans, limits.MaxConcurrentPlans),
+		format(usage.RequestsLastMinute, limits.MaxRequestsPerMinute),
+		format(usage.TokensLastMinute, limits.MaxTokensPerMinute),
+	}
+}
@@ -0,0 +1,472 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/plan_exec"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"sort"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/eiannone/keyboard"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var reviewUi bool
+var reviewComments bool
+var reviewApply bool
+
+func init() {
+	RootCmd.AddCommand(reviewCmd)
+
+	reviewCmd.Flags().BoolVar(&reviewUi, "ui", false, "Review changes in a browser UI")
+	reviewCmd.Flags().BoolVar(&reviewComments, "comments", false, "Leave line comments on changes in a browser UI and send them to the model as a new prompt")
+	reviewCmd.Flags().BoolVarP(&reviewApply, "apply", "a", false, "Apply accepted changes after the review without confirmation")
+
+	initApplyFlags(reviewCmd, false)
+	initExecScriptFlags(reviewCmd)
+}
+
+var reviewCmd = &cobra.Command{
+	Use:     "review [files...]",
+	Aliases: []string{"rv"},
+	Short:   "Accept, reject, or edit pending changes hunk by hunk",
+	Run:     review,
+}
+
+// reviewHunk is a single pending replacement, or a whole pending result when it has no replacements (full file writes and removals)
+type reviewHunk struct {
+	Path          string           `json:"path"`
+	ResultId      string           `json:"resultId"`
+	ReplacementId string           `json:"replacementId"`
+	Summary       string           `json:"summary"`
+	Old           string           `json:"old"`
+	New           string           `json:"new"`
+	RemovedFile   bool             `json:"removedFile"`
+	CanEdit       bool             `json:"canEdit"`
+	Lines         []reviewDiffLine `json:"lines"`
+
+	Rejected bool    `json:"-"`
+	Edited   *string `json:"-"`
+}
+
+type reviewDiffLine struct {
+	Type string `json:"type"` // " ", "-", or "+"
+	Text string `json:"text"`
+}
+
+func (hunk *reviewHunk) currentNew() string {
+	if hunk.Edited != nil {
+		return *hunk.Edited
+	}
+	return hunk.New
+}
+
+func review(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	mustSetPlanExecFlags(cmd, true)
+
+	if reviewComments {
+		if reviewUi {
+			term.OutputErrorAndExit("--comments can't be used with --ui")
+		}
+		reviewWithComments(args)
+		return
+	}
+
+	term.StartSpinner("")
+	currentPlanState, apiErr := api.Client.GetCurrentPlanState(lib.CurrentPlanId, lib.CurrentBranch)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting current plan state: %v", apiErr)
+	}
+
+	hunks := getReviewHunks(currentPlanState, args)
+
+	if len(hunks) == 0 {
+		fmt.Println("🤷‍♂️ No pending changes to review")
+		return
+	}
+
+	var submitted bool
+	if reviewUi {
+		submitted = reviewInBrowser(hunks)
+	} else {
+		submitted = reviewInTerminal(hunks)
+	}
+
+	if !submitted {
+		fmt.Println("🙅‍♂️ Review cancelled. No changes were made.")
+		return
+	}
+
+	req := shared.ReviewReplacementsRequest{}
+	numAccepted := 0
+	numEdited := 0
+	for _, hunk := range hunks {
+		if hunk.Rejected {
+			if hunk.ReplacementId == "" {
+				req.RejectedResults = append(req.RejectedResults, hunk.ResultId)
+			} else {
+				req.Rejected = append(req.Rejected, shared.ReplacementRef{
+					ResultId:      hunk.ResultId,
+					ReplacementId: hunk.ReplacementId,
+				})
+			}
+			continue
+		}
+
+		numAccepted++
+
+		if hunk.Edited != nil && *hunk.Edited != hunk.New {
+			numEdited++
+			req.Edited = append(req.Edited, shared.ReplacementEdit{
+				ResultId:      hunk.ResultId,
+				ReplacementId: hunk.ReplacementId,
+				New:           *hunk.Edited,
+			})
+		}
+	}
+
+	numRejected := len(hunks) - numAccepted
+
+	if numRejected > 0 || numEdited > 0 {
+		term.StartSpinner("")
+		apiErr = api.Client.ReviewReplacements(lib.CurrentPlanId, lib.CurrentBranch, req)
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error saving review: %v", apiErr)
+		}
+	}
+
+	fmt.Printf("✅ Accepted %d · 🚫 Rejected %d · ✏️  Edited %d\n", numAccepted, numRejected, numEdited)
+	fmt.Println()
+
+	if numAccepted == 0 {
+		fmt.Println("No accepted changes to apply")
+		return
+	}
+
+	if !reviewApply {
+		shouldApply, err := term.ConfirmYesNo("Apply accepted changes now?")
+		if err != nil {
+			term.OutputErrorAndExit("Error getting user input: %v", err)
+		}
+
+		if !shouldApply {
+			fmt.Println()
+			term.PrintCmds("", "apply", "diff", "review")
+			return
+		}
+	}
+
+	applyFlags := types.ApplyFlags{
+		AutoConfirm: true,
+		AutoCommit:  autoCommit,
+		NoCommit:    skipCommit,
+		AutoExec:    autoExec,
+		NoExec:      noExec,
+		AutoDebug:   autoDebug,
+	}
+
+	tellFlags := types.TellFlags{
+		TellBg:      tellBg,
+		TellStop:    tellStop,
+		TellNoBuild: tellNoBuild,
+		AutoContext: tellAutoContext,
+		ExecEnabled: !noExec,
+		AutoApply:   tellAutoApply,
+	}
+
+	lib.MustApplyPlan(lib.ApplyPlanParams{
+		PlanId:     lib.CurrentPlanId,
+		Branch:     lib.CurrentBranch,
+		ApplyFlags: applyFlags,
+		TellFlags:  tellFlags,
+		OnExecFail: plan_exec.GetOnApplyExecFail(applyFlags, tellFlags),
+	})
+}
+
+func getReviewHunks(planState *shared.CurrentPlanState, paths []string) []*reviewHunk {
+	resultsByPath := planState.PlanResult.FileResultsByPath
+
+	if len(paths) > 0 {
+		for _, path := range paths {
+			if !hasPendingResult(resultsByPath[path]) {
+				term.OutputErrorAndExit("File %s not found in plan or has no pending changes to review", path)
+			}
+		}
+	} else {
+		for path := range resultsByPath {
+			paths = append(paths, path)
+		}
+	}
+
+	sorted := append([]string{}, paths...)
+	sort.Strings(sorted)
+
+	var hunks []*reviewHunk
+	for _, path := range sorted {
+		for _, result := range resultsByPath[path] {
+			if !result.IsPending() {
+				continue
+			}
+
+			if len(result.Replacements) == 0 {
+				hunk := &reviewHunk{
+					Path:        path,
+					ResultId:    result.Id,
+					New:         result.Content,
+					RemovedFile: result.RemovedFile,
+				}
+				if !result.RemovedFile {
+					hunk.Lines = getReviewDiffLines("", result.Content)
+				}
+				hunks = append(hunks, hunk)
+				continue
+			}
+
+			for _, replacement := range result.Replacements {
+				if !replacement.IsPending() {
+					continue
+				}
+
+				old := replacement.Old
+				updated := replacement.New
+				if result.ReplaceWithLineNums {
+					old = shared.RemoveLineNums(shared.LineNumberedTextType(old))
+					updated = shared.RemoveLineNums(shared.LineNumberedTextType(updated))
+				}
+
+				hunks = append(hunks, &reviewHunk{
+					Path:          path,
+					ResultId:      result.Id,
+					ReplacementId: replacement.Id,
+					Summary:       replacement.Summary,
+					Old:           old,
+					New:           updated,
+					// line-numbered replacements are from an older format and can only be accepted or rejected
+					CanEdit: !result.ReplaceWithLineNums && !replacement.EntireFile,
+					Lines:   getReviewDiffLines(old, updated),
+				})
+			}
+		}
+	}
+
+	return hunks
+}
+
+func hasPendingResult(results []*shared.PlanFileResult) bool {
+	for _, result := range results {
+		if result.IsPending() {
+			return true
+		}
+	}
+	return false
+}
+
+// getReviewDiffLines treats lines shared at the start and end of a hunk as context and everything in between as changed
+func getReviewDiffLines(old, updated string) []reviewDiffLine {
+	var oldLines, newLines []string
+	if old != "" {
+		oldLines = strings.Split(old, "\n")
+	}
+	if updated != "" {
+		newLines = strings.Split(updated, "\n")
+	}
+
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	var lines []reviewDiffLine
+	for _, line := range oldLines[:prefix] {
+		lines = append(lines, reviewDiffLine{Type: " ", Text: line})
+	}
+	for _, line := range oldLines[prefix : len(oldLines)-suffix] {
+		lines = append(lines, reviewDiffLine{Type: "-", Text: line})
+	}
+	for _, line := range newLines[prefix : len(newLines)-suffix] {
+		lines = append(lines, reviewDiffLine{Type: "+", Text: line})
+	}
+	for _, line := range oldLines[len(oldLines)-suffix:] {
+		lines = append(lines, reviewDiffLine{Type: " ", Text: line})
+	}
+
+	return lines
+}
+
+func reviewInTerminal(hunks []*reviewHunk) bool {
+	for i := 0; i < len(hunks); i++ {
+		hunk := hunks[i]
+
+		printReviewHunk(hunk, i, len(hunks))
+
+		char, key, err := promptReviewHunk(hunk)
+		if err != nil {
+			term.OutputErrorAndExit("Error getting key: %v", err)
+		}
+		fmt.Println()
+
+		if key == keyboard.KeyCtrlC {
+			os.Exit(0)
+		}
+
+		switch char {
+		case 'y':
+			hunk.Rejected = false
+		case 'n':
+			hunk.Rejected = true
+		case 'e':
+			if !hunk.CanEdit {
+				term.OutputSimpleError("This change can't be edited")
+				i--
+				continue
+			}
+			edited, err := editReviewHunk(hunk)
+			if err != nil {
+				term.OutputErrorAndExit("Error editing change: %v", err)
+			}
+			hunk.Edited = &edited
+			hunk.Lines = getReviewDiffLines(hunk.Old, edited)
+			// show the edited hunk again so it can be accepted or edited further
+			i--
+		case 'a', 'r':
+			for j := i; j < len(hunks) && hunks[j].Path == hunk.Path; j++ {
+				hunks[j].Rejected = char == 'r'
+				i = j
+			}
+		case 'q':
+			return false
+		default:
+			term.OutputSimpleError("Invalid hotkey")
+			i--
+		}
+		fmt.Println()
+	}
+
+	return true
+}
+
+func printReviewHunk(hunk *reviewHunk, idx, total int) {
+	fmt.Println(term.GetDivisionLine())
+	fmt.Printf("%s %s\n",
+		color.New(color.Bold, term.ColorHiCyan).Sprintf("📄 %s", hunk.Path),
+		color.New(color.FgHiWhite).Sprintf("· change %d/%d", idx+1, total),
+	)
+
+	if hunk.Summary != "" {
+		fmt.Println(color.New(color.Italic).Sprint(hunk.Summary))
+	}
+
+	if hunk.Edited != nil {
+		fmt.Println(color.New(term.ColorHiYellow).Sprint("✏️  Edited"))
+	}
+
+	fmt.Println()
+
+	if hunk.RemovedFile {
+		fmt.Println(color.New(term.ColorHiRed).Sprint("🗑️  File removed"))
+	} else {
+		for _, line := range hunk.Lines {
+			switch line.Type {
+			case "-":
+				fmt.Println(color.New(term.ColorHiRed).Sprintf("- %s", line.Text))
+			case "+":
+				fmt.Println(color.New(term.ColorHiGreen).Sprintf("+ %s", line.Text))
+			default:
+				fmt.Printf("  %s\n", line.Text)
+			}
+		}
+	}
+
+	fmt.Println()
+}
+
+func promptReviewHunk(hunk *reviewHunk) (rune, keyboard.Key, error) {
+	hotkey := func(s string) string {
+		return color.New(color.Bold, term.ColorHiGreen).Sprint(s)
+	}
+
+	opts := []string{
+		hotkey("(y)") + " accept",
+		hotkey("(n)") + " reject",
+	}
+	if hunk.CanEdit {
+		opts = append(opts, hotkey("(e)")+" edit")
+	}
+	opts = append(opts,
+		hotkey("(a)")+" accept rest of file",
+		hotkey("(r)")+" reject rest of file",
+		hotkey("(q)")+" quit without saving",
+	)
+
+	fmt.Println(strings.Join(opts, " | "))
+	color.New(term.ColorHiMagenta, color.Bold).Print("Press a hotkey> ")
+
+	return term.GetUserKeyInput()
+}
+
+func editReviewHunk(hunk *reviewHunk) (string, error) {
+	current := hunk.currentNew()
+
+	tempFile, err := os.CreateTemp(os.TempDir(), "plandex_change_*"+filepath.Ext(hunk.Path))
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	filename := tempFile.Name()
+	defer os.Remove(filename)
+
+	_, err = tempFile.WriteString(current)
+	tempFile.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to write temporary file: %v", err)
+	}
+
+	editorCmd := exec.Command(defaultEditor, filename)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+	err = editorCmd.Run()
+	if err != nil {
+		return "", fmt.Errorf("error opening editor: %v", err)
+	}
+
+	bytes, err := os.ReadFile(filename)
+	if err != nil {
+		return "", fmt.Errorf("error reading temporary file: %v", err)
+	}
+
+	edited := string(bytes)
+
+	// most editors add a trailing newline on save
+	if !strings.HasSuffix(current, "\n") {
+		edited = strings.TrimSuffix(edited, "\n")
+	}
+
+	return edited, nil
+}
@@ -0,0 +1,561 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/plan_exec"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+type commentReviewFile struct {
+	Path  string              `json:"path"`
+	Lines []commentReviewLine `json:"lines"`
+}
+
+type commentReviewLine struct {
+	Type   string `json:"type"` // " ", "-", "+", or "@" for hunk headers
+	OldNum int    `json:"oldNum,omitempty"`
+	NewNum int    `json:"newNum,omitempty"`
+	Text   string `json:"text"`
+}
+
+type commentReviewComment struct {
+	Path   string `json:"path"`
+	OldNum int    `json:"oldNum"`
+	NewNum int    `json:"newNum"`
+	Line   string `json:"line"`
+	Body   string `json:"body"`
+}
+
+type commentReviewSubmission struct {
+	Cancelled bool                   `json:"cancelled"`
+	Decisions map[string]string      `json:"decisions"` // path -> "accept" or "reject"
+	Comments  []commentReviewComment `json:"comments"`
+	General   string                 `json:"general"`
+}
+
+// reviewWithComments serves a PR-style review of the pending diffs. Line comments are sent back to the model as a new prompt, and files can be accepted or rejected from the page.
+func reviewWithComments(paths []string) {
+	term.StartSpinner("")
+	diffs, apiErr := api.Client.GetPlanDiffs(lib.CurrentPlanId, lib.CurrentBranch, true)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting plan diffs: %v", apiErr)
+	}
+
+	files := parseCommentReviewDiffs(diffs)
+
+	if len(paths) > 0 {
+		byPath := map[string]*commentReviewFile{}
+		for _, file := range files {
+			byPath[file.Path] = file
+		}
+
+		files = nil
+		for _, path := range paths {
+			file, ok := byPath[path]
+			if !ok {
+				term.OutputErrorAndExit("File %s not found in plan or has no pending changes to review", path)
+			}
+			files = append(files, file)
+		}
+	}
+
+	if len(files) == 0 {
+		fmt.Println("🤷‍♂️ No pending changes to review")
+		return
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	filesJSON, err := json.Marshal(files)
+	if err != nil {
+		term.OutputErrorAndExit("Error encoding diffs: %v", err)
+	}
+
+	data := struct {
+		Files template.JS
+	}{
+		Files: template.JS(filesJSON),
+	}
+
+	submission := serveReviewPage(commentReviewHtmlTemplate, data, func(submission commentReviewSubmission) error {
+		inReview := map[string]bool{}
+		for _, file := range files {
+			inReview[file.Path] = true
+		}
+		for path, decision := range submission.Decisions {
+			if !inReview[path] {
+				return fmt.Errorf("%s isn't part of this review", path)
+			}
+			if decision != "accept" && decision != "reject" && decision != "" {
+				return fmt.Errorf("invalid decision for %s: %s", path, decision)
+			}
+		}
+		return nil
+	})
+
+	if submission.Cancelled {
+		fmt.Println("🙅‍♂️ Review cancelled. No changes were made.")
+		return
+	}
+
+	var rejected, accepted []string
+	for _, file := range files {
+		switch submission.Decisions[file.Path] {
+		case "reject":
+			rejected = append(rejected, file.Path)
+		case "accept":
+			accepted = append(accepted, file.Path)
+		}
+	}
+
+	if len(rejected) > 0 {
+		term.StartSpinner("")
+		apiErr := api.Client.RejectFiles(lib.CurrentPlanId, lib.CurrentBranch, rejected)
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error rejecting changes: %v", apiErr)
+		}
+
+		fmt.Printf("🚫 Rejected changes to %d file", len(rejected))
+		if len(rejected) > 1 {
+			fmt.Print("s")
+		}
+		fmt.Println()
+		for _, path := range rejected {
+			fmt.Printf("• 📄 %s\n", path)
+		}
+		fmt.Println()
+	}
+
+	tellFlags := types.TellFlags{
+		TellBg:          tellBg,
+		TellStop:        tellStop,
+		TellNoBuild:     tellNoBuild,
+		AutoContext:     tellAutoContext,
+		SmartContext:    tellSmartContext,
+		ExecEnabled:     !noExec,
+		AutoApply:       tellAutoApply,
+		SkipChangesMenu: tellSkipMenu,
+	}
+
+	if len(submission.Comments) > 0 || strings.TrimSpace(submission.General) != "" {
+		prompt := getReviewCommentsPrompt(submission, rejected)
+
+		fmt.Printf("💬 Sending %d review comment", len(submission.Comments))
+		if len(submission.Comments) != 1 {
+			fmt.Print("s")
+		}
+		fmt.Println(" to the model")
+		fmt.Println()
+
+		plan_exec.TellPlan(plan_exec.ExecParams{
+			CurrentPlanId: lib.CurrentPlanId,
+			CurrentBranch: lib.CurrentBranch,
+			AuthVars:      lib.MustVerifyAuthVars(auth.Current.IntegratedModelsMode),
+			CheckOutdatedContext: func(maybeContexts []*shared.Context, projectPaths *types.ProjectPaths) (bool, bool, error) {
+				auto := autoConfirm || tellAutoApply || tellAutoContext
+				return lib.CheckOutdatedContextWithOutput(auto, auto, maybeContexts, projectPaths)
+			},
+		}, prompt, tellFlags)
+		return
+	}
+
+	numUndecided := len(files) - len(accepted) - len(rejected)
+
+	if len(accepted) == 0 {
+		if numUndecided > 0 {
+			term.PrintCmds("", "review", "diff", "apply")
+		}
+		return
+	}
+
+	if numUndecided > 0 || len(paths) > 0 {
+		// apply writes every pending file, so only offer it when everything pending was accepted
+		fmt.Printf("✅ Accepted %d file", len(accepted))
+		if len(accepted) > 1 {
+			fmt.Print("s")
+		}
+		fmt.Println(". Other files still have pending changes to review.")
+		fmt.Println()
+		term.PrintCmds("", "review", "diff", "reject", "apply")
+		return
+	}
+
+	if !reviewApply {
+		shouldApply, err := term.ConfirmYesNo("Apply accepted changes now?")
+		if err != nil {
+			term.OutputErrorAndExit("Error getting user input: %v", err)
+		}
+
+		if !shouldApply {
+			fmt.Println()
+			term.PrintCmds("", "apply", "diff")
+			return
+		}
+	}
+
+	applyFlags := types.ApplyFlags{
+		AutoConfirm: true,
+		AutoCommit:  autoCommit,
+		NoCommit:    skipCommit,
+		AutoExec:    autoExec,
+		NoExec:      noExec,
+		AutoDebug:   autoDebug,
+	}
+
+	lib.MustApplyPlan(lib.ApplyPlanParams{
+		PlanId:     lib.CurrentPlanId,
+		Branch:     lib.CurrentBranch,
+		ApplyFlags: applyFlags,
+		TellFlags:  tellFlags,
+		OnExecFail: plan_exec.GetOnApplyExecFail(applyFlags, tellFlags),
+	})
+}
+
+func parseCommentReviewDiffs(diffs string) []*commentReviewFile {
+	var files []*commentReviewFile
+	var current *commentReviewFile
+	var oldNum, newNum int
+	inHunk := false
+
+	scanner := bufio.NewScanner(strings.NewReader(diffs))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			current = &commentReviewFile{}
+			files = append(files, current)
+			inHunk = false
+
+		case current == nil:
+			continue
+
+		case !inHunk && strings.HasPrefix(line, "--- "):
+			if path := strings.TrimPrefix(line, "--- "); path != "/dev/null" {
+				current.Path = strings.TrimPrefix(path, "a/")
+			}
+
+		case !inHunk && strings.HasPrefix(line, "+++ "):
+			if path := strings.TrimPrefix(line, "+++ "); path != "/dev/null" {
+				current.Path = strings.TrimPrefix(path, "b/")
+			}
+
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+			oldNum, newNum = parseHunkHeader(line)
+			current.Lines = append(current.Lines, commentReviewLine{Type: "@", Text: line})
+
+		case !inHunk:
+			continue
+
+		case strings.HasPrefix(line, "-"):
+			current.Lines = append(current.Lines, commentReviewLine{Type: "-", OldNum: oldNum, Text: line[1:]})
+			oldNum++
+
+		case strings.HasPrefix(line, "+"):
+			current.Lines = append(current.Lines, commentReviewLine{Type: "+", NewNum: newNum, Text: line[1:]})
+			newNum++
+
+		case strings.HasPrefix(line, " ") || line == "":
+			text := ""
+			if line != "" {
+				text = line[1:]
+			}
+			current.Lines = append(current.Lines, commentReviewLine{Type: " ", OldNum: oldNum, NewNum: newNum, Text: text})
+			oldNum++
+			newNum++
+		}
+	}
+
+	res := []*commentReviewFile{}
+	for _, file := range files {
+		if file.Path != "" {
+			res = append(res, file)
+		}
+	}
+
+	return res
+}
+
+// parseHunkHeader returns the starting old and new line numbers from a header like "@@ -12,7 +12,9 @@"
+func parseHunkHeader(header string) (int, int) {
+	parts := strings.Fields(header)
+	if len(parts) < 3 {
+		return 0, 0
+	}
+
+	parseStart := func(s string) int {
+		s = strings.TrimLeft(s, "-+")
+		s, _, _ = strings.Cut(s, ",")
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+
+	return parseStart(parts[1]), parseStart(parts[2])
+}
+
+func getReviewCommentsPrompt(submission commentReviewSubmission, rejected []string) string {
+	var builder strings.Builder
+
+	builder.WriteString("I reviewed the pending changes and left comments below. Update the pending changes to address them.\n\n")
+
+	if general := strings.TrimSpace(submission.General); general != "" {
+		builder.WriteString(general)
+		builder.WriteString("\n\n")
+	}
+
+	rejectedByPath := map[string]bool{}
+	for _, path := range rejected {
+		rejectedByPath[path] = true
+	}
+
+	commentsByPath := map[string][]commentReviewComment{}
+	var paths []string
+	for _, comment := range submission.Comments {
+		if _, ok := commentsByPath[comment.Path]; !ok {
+			paths = append(paths, comment.Path)
+		}
+		commentsByPath[comment.Path] = append(commentsByPath[comment.Path], comment)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		builder.WriteString(fmt.Sprintf("### %s", path))
+		if rejectedByPath[path] {
+			builder.WriteString(" (pending changes to this file were rejected)")
+		}
+		builder.WriteString("\n\n")
+
+		comments := commentsByPath[path]
+		sort.SliceStable(comments, func(i, j int) bool {
+			return commentLineNum(comments[i]) < commentLineNum(comments[j])
+		})
+
+		for _, comment := range comments {
+			if comment.NewNum > 0 {
+				builder.WriteString(fmt.Sprintf("- Line %d of the updated file: `%s`\n", comment.NewNum, comment.Line))
+			} else {
+				builder.WriteString(fmt.Sprintf("- Removed line %d of the original file: `%s`\n", comment.OldNum, comment.Line))
+			}
+
+			for _, line := range strings.Split(strings.TrimSpace(comment.Body), "\n") {
+				builder.WriteString("  " + line + "\n")
+			}
+		}
+
+		builder.WriteString("\n")
+	}
+
+	return strings.TrimSpace(builder.String())
+}
+
+func commentLineNum(comment commentReviewComment) int {
+	if comment.NewNum > 0 {
+		return comment.NewNum
+	}
+	return comment.OldNum
+}
+
+var commentReviewHtmlTemplate = `<!doctype html>
+<html lang="en-us">
+  <head>
+    <meta charset="utf-8" />
+    <title>Review pending changes</title>
+    <style>
+      body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 0; background: #f6f8fa; color: #24292f; }
+      header { position: sticky; top: 0; background: #fff; border-bottom: 1px solid #d0d7de; padding: 12px 24px; display: flex; gap: 8px; align-items: center; z-index: 1; }
+      header .counts { margin-right: auto; font-size: 14px; }
+      main { padding: 16px 24px; }
+      .general textarea { width: 100%; min-height: 60px; box-sizing: border-box; margin-bottom: 16px; font-family: inherit; }
+      .file { background: #fff; border: 1px solid #d0d7de; border-radius: 6px; margin-bottom: 16px; overflow: hidden; }
+      .file.rejected { opacity: 0.55; }
+      .file-header { display: flex; gap: 8px; align-items: center; padding: 8px 12px; border-bottom: 1px solid #d0d7de; background: #f6f8fa; }
+      .file-header .path { font-weight: 600; margin-right: auto; }
+      table { border-collapse: collapse; width: 100%; font-family: monospace; font-size: 12px; }
+      td { padding: 0 8px; white-space: pre; vertical-align: top; }
+      td.num { color: #6e7781; text-align: right; width: 1%; user-select: none; }
+      tr.line { cursor: pointer; }
+      tr.line:hover td { background: #ddf4ff; }
+      tr.del td { background: #ffebe9; }
+      tr.add td { background: #e6ffec; }
+      tr.hunk td { background: #ddf4ff; color: #57606a; }
+      tr.comment td { background: #fff8c5; white-space: normal; font-family: inherit; padding: 6px 12px; }
+      tr.comment textarea { width: 100%; min-height: 60px; box-sizing: border-box; font-family: inherit; }
+      .comment-body { white-space: pre-wrap; }
+      button { font-size: 13px; padding: 4px 10px; border-radius: 6px; border: 1px solid #d0d7de; background: #f6f8fa; cursor: pointer; }
+      button.active-accept { background: #1f883d; color: #fff; }
+      button.active-reject { background: #cf222e; color: #fff; }
+      button.primary { background: #1f883d; color: #fff; }
+    </style>
+  </head>
+  <body>
+    <header>
+      <span class="counts" id="counts"></span>
+      <button onclick="submitReview(true)">Cancel</button>
+      <button class="primary" onclick="submitReview(false)">Submit review</button>
+    </header>
+    <main>
+      <div class="general">
+        <textarea id="general" placeholder="Overall comment (optional)"></textarea>
+      </div>
+      <div id="files"></div>
+    </main>
+    <script>
+      const reviewToken = {{reviewToken}};
+      const files = {{.Files}};
+      const decisions = {};
+      const comments = [];
+      let draft = null; // { path, idx }
+
+      function el(tag, className, text) {
+        const node = document.createElement(tag);
+        if (className) node.className = className;
+        if (text !== undefined) node.textContent = text;
+        return node;
+      }
+
+      function row(className, cells) {
+        const tr = el('tr', className);
+        cells.forEach((cell) => tr.appendChild(cell));
+        return tr;
+      }
+
+      function commentRow(content) {
+        const td = el('td');
+        td.colSpan = 3;
+        td.appendChild(content);
+        return row('comment', [td]);
+      }
+
+      function render() {
+        const container = document.getElementById('files');
+        container.innerHTML = '';
+
+        files.forEach((file) => {
+          const decision = decisions[file.path];
+          const card = el('div', 'file' + (decision === 'reject' ? ' rejected' : ''));
+          const header = el('div', 'file-header');
+          header.appendChild(el('span', 'path', file.path));
+
+          const accept = el('button', decision === 'accept' ? 'active-accept' : '', 'Accept');
+          accept.onclick = () => { decisions[file.path] = decision === 'accept' ? '' : 'accept'; render(); };
+          const reject = el('button', decision === 'reject' ? 'active-reject' : '', 'Reject');
+          reject.onclick = () => { decisions[file.path] = decision === 'reject' ? '' : 'reject'; render(); };
+          header.appendChild(accept);
+          header.appendChild(reject);
+          card.appendChild(header);
+
+          const table = el('table');
+          (file.lines || []).forEach((line, idx) => {
+            if (line.type === '@') {
+              const td = el('td', '', line.text);
+              td.colSpan = 3;
+              table.appendChild(row('hunk', [td]));
+              return;
+            }
+
+            const cls = 'line' + (line.type === '-' ? ' del' : line.type === '+' ? ' add' : '');
+            const tr = row(cls, [
+              el('td', 'num', line.oldNum ? String(line.oldNum) : ''),
+              el('td', 'num', line.newNum ? String(line.newNum) : ''),
+              el('td', '', line.type + ' ' + line.text),
+            ]);
+            tr.onclick = () => { draft = { path: file.path, idx }; render(); };
+            table.appendChild(tr);
+
+            comments.forEach((comment, commentIdx) => {
+              if (comment.path !== file.path || comment.idx !== idx) return;
+              const content = el('div');
+              content.appendChild(el('div', 'comment-body', comment.body));
+              const remove = el('button', '', 'Delete');
+              remove.onclick = () => { comments.splice(commentIdx, 1); render(); };
+              content.appendChild(remove);
+              table.appendChild(commentRow(content));
+            });
+
+            if (draft && draft.path === file.path && draft.idx === idx) {
+              const content = el('div');
+              const textarea = el('textarea');
+              textarea.placeholder = 'Leave a comment on this line';
+              content.appendChild(textarea);
+              const save = el('button', 'primary', 'Comment');
+              save.onclick = () => {
+                if (textarea.value.trim()) {
+                  comments.push({
+                    path: file.path,
+                    idx,
+                    oldNum: line.type === '-' ? line.oldNum : 0,
+                    newNum: line.type === '-' ? 0 : line.newNum,
+                    line: line.text,
+                    body: textarea.value,
+                  });
+                }
+                draft = null;
+                render();
+              };
+              const cancel = el('button', '', 'Cancel');
+              cancel.onclick = () => { draft = null; render(); };
+              content.appendChild(save);
+              content.appendChild(cancel);
+              table.appendChild(commentRow(content));
+              setTimeout(() => textarea.focus(), 0);
+            }
+          });
+
+          card.appendChild(table);
+          container.appendChild(card);
+        });
+
+        const values = Object.values(decisions);
+        document.getElementById('counts').textContent =
+          files.length + ' files · ' +
+          values.filter((d) => d === 'accept').length + ' accepted · ' +
+          values.filter((d) => d === 'reject').length + ' rejected · ' +
+          comments.length + ' comments';
+      }
+
+      async function submitReview(cancelled) {
+        const body = {
+          cancelled,
+          decisions,
+          comments: comments.map(({ idx, ...comment }) => comment),
+          general: document.getElementById('general').value,
+        };
+        const res = await fetch('/submit', {
+          method: 'POST',
+          headers: { 'Content-Type': 'application/json', 'X-Review-Token': reviewToken },
+          body: JSON.stringify(body),
+        });
+        if (!res.ok) {
+          alert(await res.text());
+          return;
+        }
+        document.body.innerHTML = '<main><p>' +
+          (cancelled ? 'Review cancelled.' : 'Review submitted.') +
+          ' You can close this tab and return to your terminal.</p></main>';
+      }
+
+      render();
+    </script>
+  </body>
+</html>`
@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"mime"
+	"net"
+	"net/http"
+	"plandex-cli/term"
+	"plandex-cli/ui"
+)
+
+type reviewUiDecision struct {
+	Rejected bool    `json:"rejected"`
+	Edited   *string `json:"edited"`
+}
+
+type reviewUiSubmission struct {
+	Cancelled bool               `json:"cancelled"`
+	Decisions []reviewUiDecision `json:"decisions"`
+}
+
+// reviewInBrowser serves the hunk review page and blocks until it's submitted or cancelled from the browser
+func reviewInBrowser(hunks []*reviewHunk) bool {
+	hunksJSON, err := json.Marshal(hunks)
+	if err != nil {
+		term.OutputErrorAndExit("Error encoding changes: %v", err)
+	}
+
+	data := struct {
+		Hunks template.JS
+	}{
+		Hunks: template.JS(hunksJSON),
+	}
+
+	submission := serveReviewPage(reviewHtmlTemplate, data, func(submission reviewUiSubmission) error {
+		if !submission.Cancelled && len(submission.Decisions) != len(hunks) {
+			return fmt.Errorf("review doesn't match pending changes")
+		}
+		return nil
+	})
+
+	if submission.Cancelled {
+		return false
+	}
+
+	for i, decision := range submission.Decisions {
+		hunk := hunks[i]
+		hunk.Rejected = decision.Rejected
+		if hunk.CanEdit && decision.Edited != nil {
+			hunk.Edited = decision.Edited
+		}
+	}
+
+	return true
+}
+
+// serveReviewPage serves a review page on a local port and blocks until a valid submission is posted to /submit.
+// Any page open in the browser can send requests to a local port, so submissions must come from the served origin and include a token that's only embedded in the review page.
+func serveReviewPage[T any](page string, data any, validate func(T) error) T {
+	tokenBytes := make([]byte, 32)
+	_, err := rand.Read(tokenBytes)
+	if err != nil {
+		term.OutputErrorAndExit("Error generating review token: %v", err)
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	tmpl, err := template.New("review").Funcs(template.FuncMap{
+		"reviewToken": func() string { return token },
+	}).Parse(page)
+	if err != nil {
+		term.OutputErrorAndExit("Error parsing template: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		term.OutputErrorAndExit("Error starting server: %v", err)
+	}
+	defer listener.Close()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	host := fmt.Sprintf("localhost:%d", port)
+	origin := "http://" + host
+
+	submissionCh := make(chan T, 1)
+
+	go http.Serve(listener, newReviewMux(tmpl, data, validate, host, token, submissionCh))
+
+	ui.OpenURL("Reviewing changes in your default browser...", origin+"/review")
+	fmt.Println()
+
+	term.StartSpinner("Waiting for review to be submitted in the browser...")
+	submission := <-submissionCh
+	term.StopSpinner()
+
+	return submission
+}
+
+// newReviewMux serves the review page on /review and sends the first valid submission to /submit on submissionCh
+func newReviewMux[T any](tmpl *template.Template, data any, validate func(T) error, host, token string, submissionCh chan<- T) *http.ServeMux {
+	origin := "http://" + host
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/review", func(w http.ResponseWriter, r *http.Request) {
+		// guards against dns rebinding, where another site's hostname resolves to 127.0.0.1 and reads the token from the page
+		if r.Host != host {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err := tmpl.Execute(w, data)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	mux.HandleFunc("/submit", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if r.Host != host || r.Header.Get("Origin") != origin {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Review-Token")), []byte(token)) != 1 {
+			http.Error(w, "Invalid review token", http.StatusForbidden)
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/json" {
+			http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		var submission T
+		err = json.NewDecoder(r.Body).Decode(&submission)
+		if err != nil {
+			http.Error(w, "Error decoding review: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if validate != nil {
+			if err := validate(submission); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		select {
+		case submissionCh <- submission:
+		default:
+			http.Error(w, "Review was already submitted", http.StatusConflict)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	return mux
+}
+
+var reviewHtmlTemplate = `<!doctype html>
+<html lang="en-us">
+  <head>
+    <meta charset="utf-8" />
+    <title>Review pending changes</title>
+    <style>
+      body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 0; background: #f6f8fa; color: #24292f; }
+      header { position: sticky; top: 0; background: #fff; border-bottom: 1px solid #d0d7de; padding: 12px 24px; display: flex; gap: 8px; align-items: center; z-index: 1; }
+      header .counts { margin-right: auto; font-size: 14px; }
+      main { padding: 16px 24px; }
+      .hunk { background: #fff; border: 1px solid #d0d7de; border-radius: 6px; margin-bottom: 16px; }
+      .hunk.rejected { opacity: 0.55; }
+      .hunk-header { display: flex; gap: 8px; align-items: center; padding: 8px 12px; border-bottom: 1px solid #d0d7de; background: #f6f8fa; }
+      .hunk-header .path { font-weight: 600; margin-right: auto; }
+      .summary { padding: 8px 12px; font-style: italic; font-size: 13px; border-bottom: 1px solid #eaeef2; }
+      pre { margin: 0; padding: 8px 0; overflow-x: auto; font-size: 12px; line-height: 1.5; }
+      pre div { padding: 0 12px; white-space: pre; }
+      .del { background: #ffebe9; }
+      .add { background: #e6ffec; }
+      textarea { width: calc(100% - 24px); min-height: 160px; margin: 8px 12px; font-family: monospace; font-size: 12px; }
+      button { font-size: 13px; padding: 4px 10px; border-radius: 6px; border: 1px solid #d0d7de; background: #f6f8fa; cursor: pointer; }
+      button.active-accept { background: #1f883d; color: #fff; }
+      button.active-reject { background: #cf222e; color: #fff; }
+      button.primary { background: #1f883d; color: #fff; }
+    </style>
+  </head>
+  <body>
+    <header>
+      <span class="counts" id="counts"></span>
+      <button onclick="setAll(false)">Accept all</button>
+      <button onclick="setAll(true)">Reject all</button>
+      <button onclick="submitReview(true)">Cancel</button>
+      <button class="primary" onclick="submitReview(false)">Submit review</button>
+    </header>
+    <main id="hunks"></main>
+    <script>
+      const reviewToken = {{reviewToken}};
+      const hunks = {{.Hunks}};
+      const decisions = hunks.map(() => ({ rejected: false, edited: null }));
+
+      function el(tag, className, text) {
+        const node = document.createElement(tag);
+        if (className) node.className = className;
+        if (text !== undefined) node.textContent = text;
+        return node;
+      }
+
+      function render() {
+        const container = document.getElementById('hunks');
+        container.innerHTML = '';
+        let numRejected = 0;
+
+        hunks.forEach((hunk, i) => {
+          const decision = decisions[i];
+          if (decision.rejected) numRejected++;
+
+          const card = el('div', 'hunk' + (decision.rejected ? ' rejected' : ''));
+          const header = el('div', 'hunk-header');
+          header.appendChild(el('span', 'path', hunk.path + (decision.edited !== null ? ' (edited)' : '')));
+
+          const accept = el('button', decision.rejected ? '' : 'active-accept', 'Accept');
+          accept.onclick = () => { decision.rejected = false; render(); };
+          const reject = el('button', decision.rejected ? 'active-reject' : '', 'Reject');
+          reject.onclick = () => { decision.rejected = true; render(); };
+          header.appendChild(accept);
+          header.appendChild(reject);
+
+          if (hunk.canEdit) {
+            const edit = el('button', '', decision.editing ? 'Done' : 'Edit');
+            edit.onclick = () => { decision.editing = !decision.editing; render(); };
+            header.appendChild(edit);
+          }
+          card.appendChild(header);
+
+          if (hunk.summary) card.appendChild(el('div', 'summary', hunk.summary));
+
+          if (decision.editing) {
+            const textarea = el('textarea');
+            textarea.value = decision.edited !== null ? decision.edited : hunk.new;
+            textarea.oninput = () => { decision.edited = textarea.value === hunk.new ? null : textarea.value; };
+            card.appendChild(textarea);
+          } else if (hunk.removedFile) {
+            card.appendChild(el('pre', '', '  File removed'));
+          } else {
+            const pre = el('pre');
+            const lines = decision.edited !== null ? diffLines(hunk.old, decision.edited) : (hunk.lines || []);
+            lines.forEach((line) => {
+              const cls = line.type === '-' ? 'del' : line.type === '+' ? 'add' : '';
+              pre.appendChild(el('div', cls, line.type + ' ' + line.text));
+            });
+            card.appendChild(pre);
+          }
+
+          container.appendChild(card);
+        });
+
+        document.getElementById('counts').textContent =
+          (hunks.length - numRejected) + ' accepted · ' + numRejected + ' rejected · ' + hunks.length + ' changes';
+      }
+
+      // same prefix/suffix context split as the terminal review
+      function diffLines(oldStr, newStr) {
+        const oldLines = oldStr ? oldStr.split('\n') : [];
+        const newLines = newStr ? newStr.split('\n') : [];
+        let prefix = 0;
+        while (prefix < oldLines.length && prefix < newLines.length && oldLines[prefix] === newLines[prefix]) prefix++;
+        let suffix = 0;
+        while (suffix < oldLines.length - prefix && suffix < newLines.length - prefix &&
+          oldLines[oldLines.length - 1 - suffix] === newLines[newLines.length - 1 - suffix]) suffix++;
+
+        const lines = [];
+        oldLines.slice(0, prefix).forEach((text) => lines.push({ type: ' ', text }));
+        oldLines.slice(prefix, oldLines.length - suffix).forEach((text) => lines.push({ type: '-', text }));
+        newLines.slice(prefix, newLines.length - suffix).forEach((text) => lines.push({ type: '+', text }));
+        oldLines.slice(oldLines.length - suffix).forEach((text) => lines.push({ type: ' ', text }));
+        return lines;
+      }
+
+      function setAll(rejected) {
+        decisions.forEach((decision) => { decision.rejected = rejected; });
+        render();
+      }
+
+      async function submitReview(cancelled) {
+        const body = {
+          cancelled,
+          decisions: decisions.map((d) => ({ rejected: d.rejected, edited: d.edited })),
+        };
+        const res = await fetch('/submit', {
+          method: 'POST',
+          headers: { 'Content-Type': 'application/json', 'X-Review-Token': reviewToken },
+          body: JSON.stringify(body),
+        });
+        if (!res.ok) {
+          alert(await res.text());
+          return;
+        }
+        document.body.innerHTML = '<main><p>' +
+          (cancelled ? 'Review cancelled.' : 'Review submitted.') +
+          ' You can close this tab and return to your terminal.</p></main>';
+      }
+
+      render();
+    </script>
+  </body>
+</html>`
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReviewMux(t *testing.T) {
+	const host = "localhost:4567"
+	const origin = "http://" + host
+	const token = "test-token"
+	const body = `{"cancelled":false,"decisions":[{"rejected":true,"edited":null}]}`
+
+	tmpl := template.Must(template.New("review").Funcs(template.FuncMap{
+		"reviewToken": func() string { return token },
+	}).Parse(`<script>const reviewToken = {{reviewToken}};</script>`))
+
+	validate := func(submission reviewUiSubmission) error {
+		if len(submission.Decisions) != 1 {
+			return io.ErrUnexpectedEOF
+		}
+		return nil
+	}
+
+	headers := func(overrides map[string]string) map[string]string {
+		res := map[string]string{
+			"Origin":         origin,
+			"Content-Type":   "application/json",
+			"X-Review-Token": token,
+		}
+		for k, v := range overrides {
+			res[k] = v
+		}
+		return res
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		host       string
+		headers    map[string]string
+		body       string
+		wantStatus int
+	}{
+		{name: "valid", headers: headers(nil), wantStatus: http.StatusOK},
+		{name: "content type with charset", headers: headers(map[string]string{"Content-Type": "application/json; charset=utf-8"}), wantStatus: http.StatusOK},
+		{name: "get", method: http.MethodGet, headers: headers(nil), wantStatus: http.StatusMethodNotAllowed},
+		{name: "missing token", headers: headers(map[string]string{"X-Review-Token": ""}), wantStatus: http.StatusForbidden},
+		{name: "wrong token", headers: headers(map[string]string{"X-Review-Token": "guess"}), wantStatus: http.StatusForbidden},
+		{name: "other origin", headers: headers(map[string]string{"Origin": "https://evil.example"}), wantStatus: http.StatusForbidden},
+		{name: "missing origin", headers: headers(map[string]string{"Origin": ""}), wantStatus: http.StatusForbidden},
+		{name: "rebound host", host: "evil.example:4567", headers: headers(nil), wantStatus: http.StatusForbidden},
+		{name: "form post", headers: headers(map[string]string{"Content-Type": "text/plain"}), wantStatus: http.StatusUnsupportedMediaType},
+		{name: "invalid json", headers: headers(nil), body: "{", wantStatus: http.StatusBadRequest},
+		{name: "fails validation", headers: headers(nil), body: `{"decisions":[]}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			submissionCh := make(chan reviewUiSubmission, 1)
+			mux := newReviewMux(tmpl, nil, validate, host, token, submissionCh)
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			reqBody := tt.body
+			if reqBody == "" {
+				reqBody = body
+			}
+
+			req := httptest.NewRequest(method, "/submit", strings.NewReader(reqBody))
+			req.Host = host
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			for k, v := range tt.headers {
+				if v != "" {
+					req.Header.Set(k, v)
+				}
+			}
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+
+			select {
+			case submission := <-submissionCh:
+				if tt.wantStatus != http.StatusOK {
+					t.Errorf("rejected request was submitted: %+v", submission)
+				} else if !submission.Decisions[0].Rejected {
+					t.Errorf("unexpected submission: %+v", submission)
+				}
+			default:
+				if tt.wantStatus == http.StatusOK {
+					t.Error("valid request wasn't submitted")
+				}
+			}
+		})
+	}
+
+	t.Run("page embeds the token only for the served host", func(t *testing.T) {
+		mux := newReviewMux(tmpl, nil, validate, host, token, make(chan reviewUiSubmission, 1))
+
+		req := httptest.NewRequest(http.MethodGet, "/review", nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"test-token"`) {
+			t.Errorf("expected the page with the token, got %d: %s", rec.Code, rec.Body.String())
+		}
+
+		req = httptest.NewRequest(http.MethodGet, "/review", nil)
+		req.Host = "evil.example:4567"
+		rec = httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusForbidden || strings.Contains(rec.Body.String(), token) {
+			t.Errorf("expected a rebound host to be refused, got %d: %s", rec.Code, rec.Body.String())
+		}
+	})
+}
+
+func TestReviewTemplatesSendToken(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+	}{
+		{name: "hunk review", page: reviewHtmlTemplate},
+		{name: "comment review", page: commentReviewHtmlTemplate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := template.New("review").Funcs(template.FuncMap{
+				"reviewToken": func() string { return "test-token" },
+			}).Parse(tt.page)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			var b strings.Builder
+			err = tmpl.Execute(&b, struct {
+				Hunks template.JS
+				Files template.JS
+			}{Hunks: "[]", Files: "[]"})
+			if err != nil {
+				t.Fatalf("execute: %v", err)
+			}
+
+			for _, want := range []string{`const reviewToken = "test-token";`, `'X-Review-Token': reviewToken`, `'Content-Type': 'application/json'`} {
+				if !strings.Contains(b.String(), want) {
+					t.Errorf("rendered page is missing %s", want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,378 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/plandex-ai/survey/v2"
+	"github.com/spf13/cobra"
+)
+
+var roleLabel string
+var roleDescription string
+var rolePermissions []string
+var roleModelPacks []string
+
+var rolesCmd = &cobra.Command{
+	Use:   "roles",
+	Short: "List built-in and custom org roles",
+	Run:   listOrgRoles,
+}
+
+var createRoleCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a custom org role",
+	Run:   createOrgRole,
+	Args:  cobra.ExactArgs(1),
+}
+
+var updateRoleCmd = &cobra.Command{
+	Use:   "update <name>",
+	Short: "Update a custom org role's label, description, permissions, or approved model packs",
+	Run:   updateOrgRole,
+	Args:  cobra.ExactArgs(1),
+}
+
+var deleteRoleCmd = &cobra.Command{
+	Use:     "delete <name>",
+	Aliases: []string{"rm"},
+	Short:   "Delete a custom org role",
+	Run:     deleteOrgRole,
+	Args:    cobra.ExactArgs(1),
+}
+
+var setRoleCmd = &cobra.Command{
+	Use:   "set-role [email] [role]",
+	Short: "Change an org member's role",
+	Run:   setOrgUserRole,
+	Args:  cobra.MaximumNArgs(2),
+}
+
+func init() {
+	usersCmd.AddCommand(rolesCmd)
+	usersCmd.AddCommand(setRoleCmd)
+	rolesCmd.AddCommand(createRoleCmd)
+	rolesCmd.AddCommand(updateRoleCmd)
+	rolesCmd.AddCommand(deleteRoleCmd)
+
+	for _, cmd := range []*cobra.Command{createRoleCmd, updateRoleCmd} {
+		cmd.Flags().StringVar(&roleLabel, "label", "", "Display label for the role")
+		cmd.Flags().StringVar(&roleDescription, "desc", "", "Description of the role")
+		cmd.Flags().StringSliceVar(&rolePermissions, "permissions", nil, "Comma-separated permissions to grant (prompts if omitted)")
+		cmd.Flags().StringSliceVar(&roleModelPacks, "model-packs", nil, "Comma-separated model packs approved for the role (used with select_approved_model_pack)")
+	}
+}
+
+func listOrgRoles(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	orgRoles, apiErr := api.Client.ListOrgRoles()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching org roles: %v", apiErr.Msg)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Name", "Label", "Type", "Permissions", "Approved Model Packs"})
+
+	for _, role := range orgRoles {
+		roleType := "Custom"
+		if role.IsDefault {
+			roleType = "Built-in"
+		}
+
+		perms := make([]string, len(role.Permissions))
+		for i, p := range role.Permissions {
+			perms[i] = string(p)
+		}
+
+		table.Append([]string{role.Name, role.Label, roleType, strings.Join(perms, "\n"), strings.Join(role.ApprovedModelPacks, "\n")})
+	}
+
+	table.Render()
+
+	fmt.Println()
+	term.PrintCmds("", "users roles create", "users set-role")
+}
+
+func createOrgRole(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	name := args[0]
+
+	permissions, err := resolveRolePermissions(nil)
+	if err != nil {
+		term.OutputErrorAndExit("Error selecting permissions: %v", err)
+	}
+
+	term.StartSpinner("")
+	role, apiErr := api.Client.CreateOrgRole(shared.CreateOrgRoleRequest{
+		Name:               name,
+		Label:              roleLabel,
+		Description:        roleDescription,
+		Permissions:        permissions,
+		ApprovedModelPacks: roleModelPacks,
+	})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error creating role: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Created role %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(role.Label))
+	fmt.Println()
+	term.PrintCmds("", "users roles", "invite", "users set-role")
+}
+
+func updateOrgRole(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	role := mustGetCustomOrgRole(args[0])
+
+	req := shared.UpdateOrgRoleRequest{
+		Label:              role.Label,
+		Description:        role.Description,
+		Permissions:        role.Permissions,
+		ApprovedModelPacks: role.ApprovedModelPacks,
+	}
+
+	if cmd.Flags().Changed("label") {
+		req.Label = roleLabel
+	}
+	if cmd.Flags().Changed("desc") {
+		req.Description = roleDescription
+	}
+	if cmd.Flags().Changed("model-packs") {
+		req.ApprovedModelPacks = roleModelPacks
+	}
+
+	// only prompt for permissions if nothing else is being updated
+	if cmd.Flags().Changed("permissions") || cmd.Flags().NFlag() == 0 {
+		permissions, err := resolveRolePermissions(role.Permissions)
+		if err != nil {
+			term.OutputErrorAndExit("Error selecting permissions: %v", err)
+		}
+		req.Permissions = permissions
+	}
+
+	term.StartSpinner("")
+	apiErr := api.Client.UpdateOrgRole(role.Id, req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error updating role: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Updated role %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(req.Label))
+}
+
+func deleteOrgRole(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	role := mustGetCustomOrgRole(args[0])
+
+	term.StartSpinner("")
+	apiErr := api.Client.DeleteOrgRole(role.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error deleting role: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Deleted role %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(role.Label))
+}
+
+func setOrgUserRole(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	email, roleName := "", ""
+	if len(args) > 0 {
+		email = args[0]
+	}
+	if len(args) > 1 {
+		roleName = args[1]
+	}
+
+	var userResp *shared.ListUsersResponse
+	var orgRoles []*shared.OrgRole
+	errCh := make(chan error)
+
+	term.StartSpinner("")
+
+	go func() {
+		var err *shared.ApiError
+		userResp, err = api.Client.ListUsers()
+		if err != nil {
+			errCh <- fmt.Errorf("error fetching users: %s", err.Msg)
+			return
+		}
+		errCh <- nil
+	}()
+
+	go func() {
+		var err *shared.ApiError
+		orgRoles, err = api.Client.ListOrgRoles()
+		if err != nil {
+			errCh <- fmt.Errorf("error fetching org roles: %s", err.Msg)
+			return
+		}
+		errCh <- nil
+	}()
+
+	for i := 0; i < 2; i++ {
+		err := <-errCh
+		if err != nil {
+			term.StopSpinner()
+			term.OutputErrorAndExit("%v", err)
+		}
+	}
+
+	term.StopSpinner()
+
+	var err error
+
+	if email == "" {
+		labelToEmail := make(map[string]string)
+		var labels []string
+		for _, user := range userResp.Users {
+			label := fmt.Sprintf("%s <%s>", user.Name, user.Email)
+			labelToEmail[label] = user.Email
+			labels = append(labels, label)
+		}
+
+		selected, err := term.SelectFromList("Select a user:", labels)
+		if err != nil {
+			term.OutputErrorAndExit("Error selecting user: %v", err)
+		}
+		email = labelToEmail[selected]
+	}
+
+	var user *shared.User
+	for _, u := range userResp.Users {
+		if u.Email == email {
+			user = u
+			break
+		}
+	}
+
+	if user == nil {
+		term.OutputErrorAndExit("User '%s' not found", email)
+	}
+
+	if roleName == "" {
+		var labels []string
+		for _, role := range orgRoles {
+			labels = append(labels, role.Label)
+		}
+
+		roleName, err = term.SelectFromList("Org role:", labels)
+		if err != nil {
+			term.OutputErrorAndExit("Error selecting role: %v", err)
+		}
+	}
+
+	var role *shared.OrgRole
+	for _, r := range orgRoles {
+		if r.Label == roleName || r.Name == roleName {
+			role = r
+			break
+		}
+	}
+
+	if role == nil {
+		term.OutputErrorAndExit("Org role '%s' not found", roleName)
+	}
+
+	term.StartSpinner("")
+	apiErr := api.Client.SetOrgUserRole(user.Id, shared.SetOrgUserRoleRequest{OrgRoleId: role.Id})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error setting role: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ %s is now %s\n", email, color.New(color.Bold, term.ColorHiCyan).Sprint(role.Label))
+}
+
+func mustGetCustomOrgRole(name string) *shared.OrgRole {
+	term.StartSpinner("")
+	orgRoles, apiErr := api.Client.ListOrgRoles()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching org roles: %v", apiErr.Msg)
+	}
+
+	for _, role := range orgRoles {
+		if role.Name == name || role.Label == name {
+			if role.IsDefault {
+				term.OutputErrorAndExit("Built-in role '%s' can't be modified", name)
+			}
+			return role
+		}
+	}
+
+	term.OutputErrorAndExit("Custom role '%s' not found", name)
+	return nil
+}
+
+func resolveRolePermissions(current []shared.Permission) ([]shared.Permission, error) {
+	if len(rolePermissions) > 0 {
+		var res []shared.Permission
+		for _, p := range rolePermissions {
+			perm := shared.Permission(strings.TrimSpace(p))
+			if !shared.IsCustomRolePermission(perm) {
+				return nil, fmt.Errorf("permission '%s' can't be granted to a custom role", perm)
+			}
+			res = append(res, perm)
+		}
+		return res, nil
+	}
+
+	var options []string
+	var defaults []string
+	optionToPermission := make(map[string]shared.Permission)
+	for _, p := range shared.CustomRolePermissions {
+		option := fmt.Sprintf("%s — %s", p, shared.CustomRolePermissionDescriptions[p])
+		options = append(options, option)
+		optionToPermission[option] = p
+		for _, c := range current {
+			if c == p {
+				defaults = append(defaults, option)
+			}
+		}
+	}
+
+	var selected []string
+	prompt := &survey.MultiSelect{
+		Message: "Select permissions for the role:",
+		Options: options,
+		Default: defaults,
+	}
+
+	err := survey.AskOne(prompt, &selected)
+	if err != nil {
+		if err.Error() == "interrupt" {
+			os.Exit(0)
+		}
+		return nil, err
+	}
+
+	var res []shared.Permission
+	for _, option := range selected {
+		res = append(res, optionToPermission[option])
+	}
+
+	return res, nil
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var searchAllProjects bool
+var searchLimit int
+var searchJump int
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search conversations, summaries, and plan names across plans",
+	Long: `Search conversations, summaries, and plan names across all your plans in the current project, or across all projects in the org with --all.
+
+The query supports quoted phrases, 'or', and '-' to exclude a word. Use --jump to switch to the plan and branch of a result and show the matching message.`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  search,
+}
+
+func init() {
+	RootCmd.AddCommand(searchCmd)
+
+	searchCmd.Flags().BoolVarP(&searchAllProjects, "all", "a", false, "Search plans in all projects in the org")
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 20, "Maximum number of results")
+	searchCmd.Flags().IntVarP(&searchJump, "jump", "j", 0, "Jump to result number n")
+}
+
+var searchMatchPattern = regexp.MustCompile(`\*\*(.+?)\*\*`)
+
+func search(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	query := strings.TrimSpace(strings.Join(args, " "))
+	if query == "" {
+		term.OutputErrorAndExit("Search query is required")
+	}
+
+	var projectIds []string
+	if !searchAllProjects {
+		projectIds = []string{lib.CurrentProjectId}
+	}
+
+	term.StartSpinner("")
+	results, apiErr := api.Client.SearchConvo(query, projectIds, searchLimit)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error searching: %v", apiErr.Msg)
+	}
+
+	if len(results) == 0 {
+		fmt.Println("🤷‍♂️ No matches")
+		if !searchAllProjects {
+			fmt.Println()
+			fmt.Println("Use --all to search plans in all projects")
+		}
+		return
+	}
+
+	if searchJump > 0 {
+		if searchJump > len(results) {
+			term.OutputErrorAndExit("Result %d not found - there are %d results", searchJump, len(results))
+		}
+		jumpToSearchResult(cmd, results[searchJump-1])
+		return
+	}
+
+	for i, result := range results {
+		fmt.Printf("%s %s\n", color.New(color.Bold).Sprintf("%d.", i+1), searchResultHeader(result))
+
+		snippet := strings.Join(strings.Fields(result.Snippet), " ")
+		snippet = searchMatchPattern.ReplaceAllStringFunc(snippet, func(match string) string {
+			return color.New(color.Bold, term.ColorHiYellow).Sprint(strings.Trim(match, "*"))
+		})
+		fmt.Println("   " + snippet)
+		fmt.Println()
+	}
+
+	fmt.Printf("Jump to a result with %s\n", color.New(color.Bold, term.ColorHiCyan).Sprintf("plandex search %s --jump <n>", strconv.Quote(query)))
+}
+
+func searchResultHeader(result *shared.ConvoSearchResult) string {
+	planName := color.New(color.Bold, term.ColorHiGreen).Sprint(result.PlanName)
+	ts := result.CreatedAt.Local().Format("Jan 2, 2006 | 3:04pm")
+	if result.CreatedAt.After(time.Now().Add(-24 * time.Hour)) {
+		ts = result.CreatedAt.Local().Format("3:04pm")
+	}
+
+	var header string
+	switch result.Type {
+	case shared.ConvoSearchResultMessage:
+		author := "🤖 Plandex"
+		if result.Role == "user" {
+			author = "💬 You"
+		}
+		header = fmt.Sprintf("%s › %s › #%d %s", planName, color.New(term.ColorHiCyan).Sprint(result.Branch), result.Num, author)
+	case shared.ConvoSearchResultSummary:
+		header = fmt.Sprintf("%s › 📝 summary", planName)
+	default:
+		header = fmt.Sprintf("%s › 📋 plan name", planName)
+	}
+
+	if result.ProjectId != lib.CurrentProjectId {
+		header += color.New(color.FgHiBlack).Sprint(" (other project)")
+	}
+
+	return header + color.New(color.FgHiBlack).Sprint(" | "+ts)
+}
+
+func jumpToSearchResult(cmd *cobra.Command, result *shared.ConvoSearchResult) {
+	if result.ProjectId != lib.CurrentProjectId {
+		term.OutputErrorAndExit("Plan %s is in another project - cd into that project's directory to jump to it", result.PlanName)
+	}
+
+	if result.PlanId != lib.CurrentPlanId {
+		err := lib.WriteCurrentPlan(result.PlanId)
+		if err != nil {
+			term.OutputErrorAndExit("Error setting current plan: %v", err)
+		}
+		lib.MustLoadCurrentPlan()
+
+		// fire and forget, same as 'cd'
+		go api.Client.SetProjectPlan(lib.CurrentProjectId, shared.SetProjectPlanRequest{PlanId: result.PlanId})
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if result.Branch != "" && result.Branch != lib.CurrentBranch {
+		err := lib.WriteCurrentBranch(result.Branch)
+		if err != nil {
+			term.OutputErrorAndExit("Error setting current branch: %v", err)
+		}
+	}
+
+	fmt.Printf("✅ Current plan is %s on branch %s\n", color.New(term.ColorHiGreen, color.Bold).Sprint(result.PlanName), color.New(term.ColorHiCyan, color.Bold).Sprint(lib.CurrentBranch))
+
+	switch result.Type {
+	case shared.ConvoSearchResultMessage:
+		convo(cmd, []string{strconv.Itoa(result.Num)})
+	case shared.ConvoSearchResultSummary:
+		fmt.Println()
+		term.PrintCmds("", "summary", "convo")
+	default:
+		fmt.Println()
+		term.PrintCmds("", "current", "convo")
+	}
+}
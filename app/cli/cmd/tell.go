@@ -20,6 +20,16 @@ import (
 
 var isImplementationOfChat bool
 
+const maxTellVariants = 8
+
+var (
+	tellVariants     int
+	tellVariantPacks []string
+	tellVariantTemps []float32
+	tellVariantTest  string
+	tellVariantExec  bool
+)
+
 // tellCmd represents the prompt command
 var tellCmd = &cobra.Command{
 	Use:     "tell [prompt]",
@@ -36,6 +46,12 @@ func init() {
 	initExecFlags(tellCmd, initExecFlagsParams{})
 
 	tellCmd.Flags().BoolVar(&isImplementationOfChat, "from-chat", false, "Begin implementation based on conversation so far")
+
+	tellCmd.Flags().IntVar(&tellVariants, "variants", 0, "Run the prompt on N new branches at once and compare the results")
+	tellCmd.Flags().StringSliceVar(&tellVariantPacks, "variant-packs", nil, "Model packs to use for --variants, one per variant (cycled if fewer than N)")
+	tellCmd.Flags().Float32SliceVar(&tellVariantTemps, "variant-temps", nil, "Planner and coder temperatures to use for --variants, one per variant (cycled if fewer than N)")
+	tellCmd.Flags().StringVar(&tellVariantTest, "variant-test", "", "Command to test each variant's changes in a scratch git worktree")
+	tellCmd.Flags().BoolVar(&tellVariantExec, "variant-exec", false, "Run each variant's pending commands in a scratch git worktree and compare whether they succeed")
 }
 
 func doTell(cmd *cobra.Command, args []string) {
@@ -47,6 +63,21 @@ func doTell(cmd *cobra.Command, args []string) {
 		term.OutputErrorAndExit("Error: --from-chat cannot be used with a prompt")
 	}
 
+	if tellVariants > 0 {
+		if tellVariants < 2 || tellVariants > maxTellVariants {
+			term.OutputErrorAndExit("Error: --variants must be between 2 and %d", maxTellVariants)
+		}
+		if tellBg || tellAutoApply {
+			term.OutputErrorAndExit("Error: --variants cannot be used with --bg or --apply")
+		}
+		// noExec is set when the plan config, the user's org role, or the org policy doesn't allow executing commands
+		if tellVariantExec && noExec {
+			term.OutputErrorAndExit("Error: --variant-exec can't be used when command execution is disabled")
+		}
+	} else if len(tellVariantPacks) > 0 || len(tellVariantTemps) > 0 || tellVariantTest != "" || tellVariantExec {
+		term.OutputErrorAndExit("Error: --variant-packs, --variant-temps, --variant-test, and --variant-exec require --variants")
+	}
+
 	var prompt string
 	if !isImplementationOfChat {
 		prompt = getTellPrompt(args)
@@ -69,7 +100,7 @@ func doTell(cmd *cobra.Command, args []string) {
 		SkipChangesMenu:        tellSkipMenu,
 	}
 
-	plan_exec.TellPlan(plan_exec.ExecParams{
+	execParams := plan_exec.ExecParams{
 		CurrentPlanId: lib.CurrentPlanId,
 		CurrentBranch: lib.CurrentBranch,
 		AuthVars:      lib.MustVerifyAuthVars(auth.Current.IntegratedModelsMode),
@@ -77,7 +108,20 @@ func doTell(cmd *cobra.Command, args []string) {
 			auto := autoConfirm || tellAutoApply || tellAutoContext
 			return lib.CheckOutdatedContextWithOutput(auto, auto, maybeContexts, projectPaths)
 		},
-	}, prompt, tellFlags)
+	}
+
+	if tellVariants > 0 {
+		plan_exec.TellVariants(execParams, prompt, tellFlags, types.VariantFlags{
+			NumVariants:  tellVariants,
+			ModelPacks:   tellVariantPacks,
+			Temperatures: tellVariantTemps,
+			TestCmd:      tellVariantTest,
+			ExecApply:    tellVariantExec,
+		})
+		return
+	}
+
+	plan_exec.TellPlan(execParams, prompt, tellFlags)
 
 	if tellAutoApply {
 		applyFlags := types.ApplyFlags{
@@ -0,0 +1,415 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/fs"
+	"plandex-cli/lib"
+	"plandex-cli/plan_exec"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"plandex-cli/url"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "List plan templates from " + lib.PlanTemplatesDirName + " and your org",
+	Args:  cobra.NoArgs,
+	Run:   listPlanTemplates,
+}
+
+var showTemplateCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a plan template's vars, context, config, and steps",
+	Args:  cobra.ExactArgs(1),
+	Run:   showPlanTemplate,
+}
+
+var pushTemplateCmd = &cobra.Command{
+	Use:   "push <name-or-file>",
+	Short: "Save an in-repo template (or a template json file) to your org",
+	Args:  cobra.ExactArgs(1),
+	Run:   pushPlanTemplate,
+}
+
+var rmTemplateCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Remove a plan template from your org",
+	Args:  cobra.ExactArgs(1),
+	Run:   rmPlanTemplate,
+}
+
+func init() {
+	RootCmd.AddCommand(templatesCmd)
+	templatesCmd.AddCommand(showTemplateCmd)
+	templatesCmd.AddCommand(pushTemplateCmd)
+	templatesCmd.AddCommand(rmTemplateCmd)
+}
+
+func listPlanTemplates(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	templates, err := lib.ListPlanTemplates()
+	term.StopSpinner()
+
+	if err != nil {
+		term.OutputErrorAndExit("Error listing templates: %v", err)
+	}
+
+	if len(templates) == 0 {
+		fmt.Println("🤷‍♂️ No plan templates")
+		fmt.Println()
+		fmt.Printf("Add a template json file to %s or save one to your org with %s\n", lib.PlanTemplatesDirName, color.New(color.Bold, term.ColorHiCyan).Sprint("plandex templates push"))
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(true)
+	table.SetRowLine(true)
+	table.SetHeader([]string{"Name", "Source", "Vars", "Steps", "Description"})
+	for _, template := range templates {
+		var vars []string
+		for _, v := range template.Vars {
+			vars = append(vars, v.Name)
+		}
+		table.Append([]string{
+			template.Name,
+			template.Source,
+			strings.Join(vars, ", "),
+			fmt.Sprintf("%d", len(template.Steps)),
+			template.Description,
+		})
+	}
+	table.Render()
+	fmt.Println()
+
+	term.PrintCmds("", "templates show", "new --template")
+}
+
+func showPlanTemplate(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	template, err := lib.GetPlanTemplate(args[0])
+	term.StopSpinner()
+
+	if err != nil {
+		term.OutputErrorAndExit("Error: %v", err)
+	}
+
+	bold := color.New(color.Bold, term.ColorHiCyan)
+
+	bold.Println(template.Name)
+	if template.Description != "" {
+		fmt.Println(template.Description)
+	}
+	source := template.Source
+	if template.Path != "" {
+		source = template.Path
+	}
+	fmt.Println("Source:", source)
+	fmt.Println()
+
+	if len(template.Vars) > 0 {
+		bold.Println("Vars")
+		for _, v := range template.Vars {
+			line := "  " + v.Name
+			if v.Default != "" {
+				line += fmt.Sprintf(" (default: %s)", v.Default)
+			} else {
+				line += " (required)"
+			}
+			if v.Description != "" {
+				line += " — " + v.Description
+			}
+			fmt.Println(line)
+		}
+		fmt.Println()
+	}
+
+	if len(template.Context) > 0 {
+		bold.Println("Context")
+		for _, pattern := range template.Context {
+			fmt.Println("  " + pattern)
+		}
+		fmt.Println()
+	}
+
+	if template.ModelPack != "" {
+		bold.Println("Model pack")
+		fmt.Println("  " + template.ModelPack)
+		fmt.Println()
+	}
+
+	if len(template.PlanConfig) > 0 {
+		bold.Println("Config")
+		fmt.Println("  " + string(template.PlanConfig))
+		fmt.Println()
+	}
+
+	bold.Println("Steps")
+	for i, step := range template.Steps {
+		title := step.Title
+		if title == "" {
+			title = fmt.Sprintf("Step %d", i+1)
+		}
+		fmt.Printf("  %d. %s\n", i+1, color.New(color.Bold).Sprint(title))
+		for _, line := range strings.Split(strings.TrimSpace(step.Prompt), "\n") {
+			fmt.Println("     " + line)
+		}
+	}
+	fmt.Println()
+
+	term.PrintCmds("", "new --template")
+}
+
+func pushPlanTemplate(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	path := args[0]
+	exists, err := fs.FileExists(path)
+	if err != nil {
+		term.OutputErrorAndExit("Error checking template file: %v", err)
+	}
+	if !exists {
+		path = filepath.Join(lib.GetPlanTemplatesDir(), args[0]+".json")
+	}
+
+	template, err := lib.ReadPlanTemplateFile(path)
+	if err != nil {
+		term.OutputErrorAndExit("Error: %v", err)
+	}
+
+	term.StartSpinner("")
+	apiErr := api.Client.UpsertPlanTemplate(shared.UpsertPlanTemplateRequest{Template: template})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error saving template: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Saved template %s to your org\n", color.New(color.Bold, term.ColorHiCyan).Sprint(template.Name))
+}
+
+func rmPlanTemplate(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	apiErr := api.Client.DeletePlanTemplate(args[0])
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error removing template: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("✅ Removed template %s from your org\n", color.New(color.Bold, term.ColorHiCyan).Sprint(args[0]))
+}
+
+// parseTemplateVars parses --var k=v flags
+func parseTemplateVars(vars []string) (map[string]string, error) {
+	res := map[string]string{}
+	for _, v := range vars {
+		key, value, ok := strings.Cut(v, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --var '%s' - use --var name=value", v)
+		}
+		res[key] = value
+	}
+	return res, nil
+}
+
+// applyPlanTemplateSettings applies the template's partial config and model pack to the current plan. It runs before the auto mode and model pack flags are resolved so that flags passed to 'new' take precedence.
+func applyPlanTemplateSettings(template *shared.PlanTemplate, config *shared.PlanConfig) *shared.PlanConfig {
+	if len(template.PlanConfig) > 0 {
+		updatedConfig, err := template.ApplyPlanConfig(config)
+		if err != nil {
+			term.OutputErrorAndExit("Error applying template config: %v", err)
+		}
+
+		term.StartSpinner("")
+		apiErr := api.Client.UpdatePlanConfig(lib.CurrentPlanId, shared.UpdatePlanConfigRequest{
+			Config: updatedConfig,
+		})
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error applying template config: %v", apiErr.Msg)
+		}
+
+		lib.SetCachedPlanConfig(updatedConfig)
+		config = updatedConfig
+	}
+
+	if template.ModelPack != "" {
+		term.StartSpinner("")
+		settings, apiErr := api.Client.GetSettings(lib.CurrentPlanId, lib.CurrentBranch)
+		if apiErr != nil {
+			term.StopSpinner()
+			term.OutputErrorAndExit("Error getting current settings: %v", apiErr.Msg)
+		}
+
+		updatedSettings := updateModelSettings([]string{template.ModelPack}, settings, "")
+		_, apiErr = api.Client.UpdateSettings(lib.CurrentPlanId, lib.CurrentBranch, shared.UpdateSettingsRequest{
+			ModelPackName: updatedSettings.ModelPackName,
+			ModelPack:     updatedSettings.ModelPack,
+		})
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error setting template model pack: %v", apiErr.Msg)
+		}
+	}
+
+	return config
+}
+
+// loadPlanTemplateContext loads the template's context patterns relative to the project root, expanding any globs.
+// Templates can be committed by anyone with access to the repo, so patterns and matches that point outside the project are refused rather than uploaded as context.
+func loadPlanTemplateContext(template *shared.PlanTemplate) {
+	var resources []string
+	for _, pattern := range template.Context {
+		if url.IsValidURL(pattern) {
+			resources = append(resources, pattern)
+			continue
+		}
+
+		if !shared.IsProjectRelativePath(pattern) {
+			term.OutputErrorAndExit("Template context pattern '%s' must be a relative path inside the project", pattern)
+		}
+
+		matches := []string{filepath.Join(fs.ProjectRoot, pattern)}
+		if strings.ContainsAny(pattern, "*?[") {
+			var err error
+			matches, err = filepath.Glob(matches[0])
+			if err != nil {
+				term.OutputErrorAndExit("Invalid context pattern '%s': %v", pattern, err)
+			}
+			if len(matches) == 0 {
+				fmt.Printf("⚠️  No files match context pattern %s\n", pattern)
+			}
+		}
+
+		for _, match := range matches {
+			rel, err := templateContextPath(fs.ProjectRoot, match)
+			if err != nil {
+				term.OutputErrorAndExit("Error loading template context pattern '%s': %v", pattern, err)
+			}
+			resources = append(resources, rel)
+		}
+	}
+
+	if len(resources) == 0 {
+		return
+	}
+
+	lib.MustLoadContext(resources, &types.LoadContextParams{
+		Recursive:         true,
+		SkipIgnoreWarning: true,
+	})
+}
+
+// runPlanTemplateSteps sends each step as its own prompt through the tell pipeline, waiting for each to finish before sending the next, so each step is planned into subtasks on top of what earlier steps did
+func runPlanTemplateSteps(cmd *cobra.Command, template *shared.PlanTemplate) {
+	mustSetPlanExecFlags(cmd, false)
+
+	execParams := plan_exec.ExecParams{
+		CurrentPlanId: lib.CurrentPlanId,
+		CurrentBranch: lib.CurrentBranch,
+		AuthVars:      lib.MustVerifyAuthVars(auth.Current.IntegratedModelsMode),
+		CheckOutdatedContext: func(maybeContexts []*shared.Context, projectPaths *types.ProjectPaths) (bool, bool, error) {
+			auto := autoConfirm || tellAutoApply || tellAutoContext
+			return lib.CheckOutdatedContextWithOutput(auto, auto, maybeContexts, projectPaths)
+		},
+	}
+
+	tellFlags := types.TellFlags{
+		TellStop:     tellStop,
+		TellNoBuild:  tellNoBuild,
+		AutoContext:  tellAutoContext,
+		SmartContext: tellSmartContext,
+		ExecEnabled:  !noExec,
+	}
+
+	for i, step := range template.Steps {
+		isLast := i == len(template.Steps)-1
+
+		title := step.Title
+		if title == "" {
+			title = fmt.Sprintf("Step %d", i+1)
+		}
+		fmt.Println()
+		color.New(color.Bold, term.ColorHiCyan).Printf("📋 %s (%d/%d)\n", title, i+1, len(template.Steps))
+
+		stepFlags := tellFlags
+		if isLast {
+			stepFlags.AutoApply = tellAutoApply
+			stepFlags.SkipChangesMenu = tellSkipMenu
+		} else {
+			// changes from earlier steps stay pending and are reviewed after the last step
+			stepFlags.SkipChangesMenu = true
+		}
+
+		plan_exec.TellPlan(execParams, step.Prompt, stepFlags)
+	}
+
+	if tellAutoApply {
+		applyFlags := types.ApplyFlags{
+			AutoConfirm: true,
+			AutoCommit:  autoCommit,
+			NoCommit:    !autoCommit,
+			NoExec:      noExec,
+			AutoExec:    autoExec || autoDebug > 0,
+			AutoDebug:   autoDebug,
+		}
+
+		lib.MustApplyPlan(lib.ApplyPlanParams{
+			PlanId:     lib.CurrentPlanId,
+			Branch:     lib.CurrentBranch,
+			ApplyFlags: applyFlags,
+			TellFlags:  tellFlags,
+			OnExecFail: plan_exec.GetOnApplyExecFail(applyFlags, tellFlags),
+		})
+	}
+}
+
+// templateContextPath returns match relative to root, or an error if it's outside root. Symlinks are resolved first so a link inside the project can't point the load at a file outside it.
+func templateContextPath(root, match string) (string, error) {
+	rel, err := filepath.Rel(root, match)
+	if err != nil {
+		return "", fmt.Errorf("error resolving %s: %v", match, err)
+	}
+	if !shared.IsProjectRelativePath(rel) {
+		return "", fmt.Errorf("%s is outside the project", match)
+	}
+
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", fmt.Errorf("error resolving project root: %v", err)
+	}
+	resolved, err := filepath.EvalSymlinks(match)
+	if os.IsNotExist(err) {
+		// missing files are reported by the context load
+		return rel, nil
+	} else if err != nil {
+		return "", fmt.Errorf("error resolving %s: %v", match, err)
+	}
+
+	resolvedRel, err := filepath.Rel(resolvedRoot, resolved)
+	if err != nil || !shared.IsProjectRelativePath(resolvedRel) {
+		return "", fmt.Errorf("%s links outside the project", rel)
+	}
+
+	return rel, nil
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTemplateContextPath(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	for _, dir := range []string{"src", "docs"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "src", "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "id_rsa"), []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "id_rsa"), filepath.Join(root, "docs", "key")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, "src", "main.go"), filepath.Join(root, "docs", "main.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		match   string
+		want    string
+		wantErr bool
+	}{
+		{name: "file", match: filepath.Join(root, "src", "main.go"), want: filepath.Join("src", "main.go")},
+		{name: "dir", match: filepath.Join(root, "src"), want: "src"},
+		{name: "missing file", match: filepath.Join(root, "src", "missing.go"), want: filepath.Join("src", "missing.go")},
+		{name: "symlink inside the project", match: filepath.Join(root, "docs", "main.go"), want: filepath.Join("docs", "main.go")},
+		{name: "symlink outside the project", match: filepath.Join(root, "docs", "key"), wantErr: true},
+		{name: "parent dir", match: filepath.Join(root, "..", "etc", "passwd"), wantErr: true},
+		{name: "absolute path elsewhere", match: filepath.Join(outside, "id_rsa"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := templateContextPath(root, tt.match)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
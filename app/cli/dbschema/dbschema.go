@@ -0,0 +1,301 @@
+package dbschema
+
+import (
+	"context"
+	"fmt"
+	neturl "net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	DialectPostgres = "postgres"
+	DialectMySQL    = "mysql"
+	DialectSQLite   = "sqlite"
+)
+
+const introspectTimeout = 30 * time.Second
+
+type Schema struct {
+	Dialect string
+	Tables  []*Table
+	Enums   []*Enum
+}
+
+type Table struct {
+	// empty for the default schema ('public' in postgres)
+	Schema      string
+	Name        string
+	Columns     []*Column
+	PrimaryKey  []string
+	Indexes     []*Index
+	ForeignKeys []*ForeignKey
+}
+
+type Column struct {
+	Name     string
+	Type     string
+	NotNull  bool
+	Default  string
+	Identity bool
+}
+
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+	// only set for index methods other than btree
+	Method string
+	// the predicate of a partial index
+	Where string
+}
+
+type ForeignKey struct {
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	OnDelete   string
+	OnUpdate   string
+}
+
+type Enum struct {
+	Schema string
+	Name   string
+	Values []string
+}
+
+var sqliteExtensions = []string{".db", ".sqlite", ".sqlite3", ".db3"}
+
+var passwordParamRegex = regexp.MustCompile(`(?i)(^|\s)(password|sslpassword)\s*=\s*('(?:[^'\\]|\\.)*'|\S*)`)
+
+// Dialect determines the database type from a dsn. Postgres and MySQL dsns are urls ('postgres://...', 'mysql://...'), or in the driver's native format ('host=... dbname=...' for postgres, 'user:pass@tcp(host)/db' for mysql). SQLite dsns are 'sqlite://path' or a path to a database file.
+func Dialect(dsn string) (string, error) {
+	lower := strings.ToLower(dsn)
+	switch {
+	case strings.HasPrefix(lower, "postgres://"), strings.HasPrefix(lower, "postgresql://"):
+		return DialectPostgres, nil
+	case strings.HasPrefix(lower, "mysql://"):
+		return DialectMySQL, nil
+	case strings.HasPrefix(lower, "sqlite://"), strings.HasPrefix(lower, "sqlite3://"), strings.HasPrefix(lower, "file:"):
+		return DialectSQLite, nil
+	case strings.Contains(dsn, "@tcp(") || strings.Contains(dsn, "@unix("):
+		return DialectMySQL, nil
+	case strings.Contains(lower, "dbname=") || strings.Contains(lower, "host="):
+		return DialectPostgres, nil
+	}
+
+	for _, ext := range sqliteExtensions {
+		if strings.HasSuffix(lower, ext) {
+			return DialectSQLite, nil
+		}
+	}
+	if _, err := os.Stat(dsn); err == nil {
+		return DialectSQLite, nil
+	}
+
+	return "", fmt.Errorf("unrecognized database dsn - use postgres://, mysql://, or sqlite:// (or a path to a sqlite file)")
+}
+
+// Redact removes passwords from a dsn so it can be shown and stored with the plan. It returns the dsn unchanged if it has no credentials.
+func Redact(dsn string) string {
+	if u, err := neturl.Parse(dsn); err == nil && u.Scheme != "" && u.Host != "" {
+		if u.User != nil {
+			u.User = neturl.User(u.User.Username())
+		}
+		q := u.Query()
+		for key := range q {
+			if isSecretParam(key) {
+				q.Del(key)
+			}
+		}
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+
+	// mysql native format: user:pass@tcp(host)/db. passwords can contain '@', so like the driver's parser, the credentials end at the last '@' before the last '/'
+	if strings.Contains(dsn, "@tcp(") || strings.Contains(dsn, "@unix(") {
+		slash := strings.LastIndex(dsn, "/")
+		if slash < 0 {
+			slash = len(dsn)
+		}
+		if at := strings.LastIndex(dsn[:slash], "@"); at >= 0 {
+			userInfo := dsn[:at]
+			if colon := strings.Index(userInfo, ":"); colon >= 0 {
+				userInfo = userInfo[:colon]
+			}
+			return userInfo + dsn[at:]
+		}
+	}
+
+	// postgres key=value format
+	return strings.TrimSpace(passwordParamRegex.ReplaceAllString(dsn, ""))
+}
+
+func isSecretParam(key string) bool {
+	key = strings.ToLower(key)
+	return strings.Contains(key, "pass") || strings.Contains(key, "secret") || strings.Contains(key, "token")
+}
+
+// Introspect connects to the database and reads its tables, columns, indexes, foreign keys, and enum types
+func Introspect(dsn string) (*Schema, error) {
+	dialect, err := Dialect(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), introspectTimeout)
+	defer cancel()
+
+	var schema *Schema
+	switch dialect {
+	case DialectPostgres:
+		schema, err = introspectPostgres(ctx, dsn)
+	case DialectMySQL:
+		schema, err = introspectMySQL(ctx, dsn)
+	case DialectSQLite:
+		schema, err = introspectSQLite(SQLitePath(dsn))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema from %s: %v", Redact(dsn), err)
+	}
+
+	schema.Dialect = dialect
+	return schema, nil
+}
+
+// SQLitePath returns the database file path from a sqlite dsn
+func SQLitePath(dsn string) string {
+	for _, prefix := range []string{"sqlite://", "sqlite3://", "file:"} {
+		if len(dsn) >= len(prefix) && strings.EqualFold(dsn[:len(prefix)], prefix) {
+			dsn = dsn[len(prefix):]
+			break
+		}
+	}
+	// drop sqlite uri params like ?mode=ro
+	if i := strings.Index(dsn, "?"); i >= 0 {
+		dsn = dsn[:i]
+	}
+	return dsn
+}
+
+// Render formats the schema as a compact map, one table per block
+func Render(schema *Schema) string {
+	var sb strings.Builder
+
+	enumLabel := "enum"
+	tableLabel := "table"
+	if len(schema.Enums) != 1 {
+		enumLabel = "enums"
+	}
+	if len(schema.Tables) != 1 {
+		tableLabel = "tables"
+	}
+	fmt.Fprintf(&sb, "%s schema | %d %s", schema.Dialect, len(schema.Tables), tableLabel)
+	if len(schema.Enums) > 0 {
+		fmt.Fprintf(&sb, ", %d %s", len(schema.Enums), enumLabel)
+	}
+	sb.WriteString("\n")
+
+	for _, enum := range schema.Enums {
+		quoted := make([]string, len(enum.Values))
+		for i, v := range enum.Values {
+			quoted[i] = "'" + v + "'"
+		}
+		fmt.Fprintf(&sb, "\nenum %s: %s\n", qualifiedName(enum.Schema, enum.Name), strings.Join(quoted, ", "))
+	}
+
+	for _, table := range schema.Tables {
+		fmt.Fprintf(&sb, "\ntable %s\n", qualifiedName(table.Schema, table.Name))
+
+		singlePk := ""
+		if len(table.PrimaryKey) == 1 {
+			singlePk = table.PrimaryKey[0]
+		}
+
+		// single-column foreign keys are shown on their column
+		inlineFks := map[string]*ForeignKey{}
+		for _, fk := range table.ForeignKeys {
+			if len(fk.Columns) == 1 {
+				inlineFks[fk.Columns[0]] = fk
+			}
+		}
+
+		for _, col := range table.Columns {
+			line := "  " + col.Name
+			if col.Type != "" {
+				line += " " + col.Type
+			}
+			if col.Name == singlePk {
+				line += " pk"
+			} else if col.NotNull {
+				line += " not null"
+			}
+			if col.Identity {
+				line += " identity"
+			}
+			if col.Default != "" {
+				line += " default " + col.Default
+			}
+			if fk, ok := inlineFks[col.Name]; ok {
+				line += " -> " + fk.RefTable
+				if len(fk.RefColumns) == 1 {
+					line += "." + fk.RefColumns[0]
+				}
+				line += fkActions(fk)
+			}
+			sb.WriteString(line + "\n")
+		}
+
+		if len(table.PrimaryKey) > 1 {
+			fmt.Fprintf(&sb, "  primary key (%s)\n", strings.Join(table.PrimaryKey, ", "))
+		}
+
+		for _, fk := range table.ForeignKeys {
+			if len(fk.Columns) == 1 {
+				continue
+			}
+			fmt.Fprintf(&sb, "  foreign key (%s) -> %s (%s)%s\n", strings.Join(fk.Columns, ", "), fk.RefTable, strings.Join(fk.RefColumns, ", "), fkActions(fk))
+		}
+
+		for _, index := range table.Indexes {
+			kind := "index"
+			if index.Unique {
+				kind = "unique"
+			}
+			line := "  " + kind
+			if index.Name != "" {
+				line += " " + index.Name
+			}
+			if index.Method != "" {
+				line += " using " + index.Method
+			}
+			line += " (" + strings.Join(index.Columns, ", ") + ")"
+			if index.Where != "" {
+				line += " where " + index.Where
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+
+	return sb.String()
+}
+
+func fkActions(fk *ForeignKey) string {
+	var s string
+	if fk.OnDelete != "" && !strings.EqualFold(fk.OnDelete, "no action") {
+		s += " on delete " + strings.ToLower(fk.OnDelete)
+	}
+	if fk.OnUpdate != "" && !strings.EqualFold(fk.OnUpdate, "no action") {
+		s += " on update " + strings.ToLower(fk.OnUpdate)
+	}
+	return s
+}
+
+func qualifiedName(schema, name string) string {
+	if schema == "" {
+		return name
+	}
+	return schema + "." + name
+}
@@ -0,0 +1,222 @@
+package dbschema
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+
+	_ "github.com/lib/pq"
+)
+
+const pgSystemSchemas = `n.nspname NOT IN ('pg_catalog', 'information_schema') AND n.nspname NOT LIKE 'pg_toast%' AND n.nspname NOT LIKE 'pg_temp%'`
+
+var pgIndexDefRegex = regexp.MustCompile(`(?s)USING (\w+) \((.*)\)(?: WHERE \((.*)\))?$`)
+var pgForeignKeyRegex = regexp.MustCompile(`(?s)^FOREIGN KEY \((.+?)\) REFERENCES (.+?)\((.+?)\)(.*)$`)
+var fkActionRegex = regexp.MustCompile(`(?i)ON (DELETE|UPDATE) (SET NULL|SET DEFAULT|CASCADE|RESTRICT|NO ACTION)`)
+
+func introspectPostgres(ctx context.Context, dsn string) (*Schema, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	if err := db.PingContext(ctx); err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{}
+	tables := map[string]*Table{}
+
+	getTable := func(nsp, name string) *Table {
+		key := nsp + "." + name
+		table, ok := tables[key]
+		if !ok {
+			if nsp == "public" {
+				nsp = ""
+			}
+			table = &Table{Schema: nsp, Name: name}
+			tables[key] = table
+			schema.Tables = append(schema.Tables, table)
+		}
+		return table
+	}
+
+	// partitions are skipped - the partitioned parent table has the same columns
+	rows, err := db.QueryContext(ctx, `
+		SELECT n.nspname, c.relname, a.attname, format_type(a.atttypid, a.atttypmod), a.attnotnull,
+			COALESCE(pg_get_expr(d.adbin, d.adrelid), ''), a.attidentity <> ''
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE c.relkind IN ('r', 'p') AND NOT c.relispartition AND a.attnum > 0 AND NOT a.attisdropped AND `+pgSystemSchemas+`
+		ORDER BY n.nspname, c.relname, a.attnum`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var nsp, tableName string
+		col := &Column{}
+		if err := rows.Scan(&nsp, &tableName, &col.Name, &col.Type, &col.NotNull, &col.Default, &col.Identity); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		table := getTable(nsp, tableName)
+		table.Columns = append(table.Columns, col)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, `
+		SELECT n.nspname, t.relname, i.relname, ix.indisunique, ix.indisprimary, pg_get_indexdef(ix.indexrelid)
+		FROM pg_index ix
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE t.relkind IN ('r', 'p') AND NOT t.relispartition AND `+pgSystemSchemas+`
+		ORDER BY n.nspname, t.relname, ix.indisprimary DESC, i.relname`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var nsp, tableName, indexName, def string
+		var unique, primary bool
+		if err := rows.Scan(&nsp, &tableName, &indexName, &unique, &primary, &def); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if _, ok := tables[nsp+"."+tableName]; !ok {
+			continue
+		}
+		table := getTable(nsp, tableName)
+
+		index := &Index{Name: indexName, Unique: unique}
+		if m := pgIndexDefRegex.FindStringSubmatch(def); m != nil {
+			if m[1] != "btree" {
+				index.Method = m[1]
+			}
+			index.Columns = splitTopLevel(m[2])
+			index.Where = m[3]
+		}
+
+		if primary {
+			table.PrimaryKey = index.Columns
+			continue
+		}
+		table.Indexes = append(table.Indexes, index)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, `
+		SELECT n.nspname, c.relname, pg_get_constraintdef(con.oid)
+		FROM pg_constraint con
+		JOIN pg_class c ON c.oid = con.conrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE con.contype = 'f' AND NOT c.relispartition AND `+pgSystemSchemas+`
+		ORDER BY n.nspname, c.relname, con.conname`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var nsp, tableName, def string
+		if err := rows.Scan(&nsp, &tableName, &def); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if _, ok := tables[nsp+"."+tableName]; !ok {
+			continue
+		}
+		m := pgForeignKeyRegex.FindStringSubmatch(def)
+		if m == nil {
+			continue
+		}
+		fk := &ForeignKey{
+			Columns:    splitTopLevel(m[1]),
+			RefTable:   strings.TrimPrefix(strings.TrimSpace(m[2]), "public."),
+			RefColumns: splitTopLevel(m[3]),
+		}
+		for _, action := range fkActionRegex.FindAllStringSubmatch(m[4], -1) {
+			if strings.EqualFold(action[1], "delete") {
+				fk.OnDelete = action[2]
+			} else {
+				fk.OnUpdate = action[2]
+			}
+		}
+		table := getTable(nsp, tableName)
+		table.ForeignKeys = append(table.ForeignKeys, fk)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, `
+		SELECT n.nspname, t.typname, e.enumlabel
+		FROM pg_type t
+		JOIN pg_enum e ON e.enumtypid = t.oid
+		JOIN pg_namespace n ON n.oid = t.typnamespace
+		WHERE `+pgSystemSchemas+`
+		ORDER BY n.nspname, t.typname, e.enumsortorder`)
+	if err != nil {
+		return nil, err
+	}
+	var enum *Enum
+	for rows.Next() {
+		var nsp, name, value string
+		if err := rows.Scan(&nsp, &name, &value); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if nsp == "public" {
+			nsp = ""
+		}
+		if enum == nil || enum.Schema != nsp || enum.Name != name {
+			enum = &Enum{Schema: nsp, Name: name}
+			schema.Enums = append(schema.Enums, enum)
+		}
+		enum.Values = append(enum.Values, value)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// splitTopLevel splits a comma-separated list, ignoring commas inside parentheses and quotes
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth := 0
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if rest := strings.TrimSpace(s[start:]); rest != "" {
+		parts = append(parts, rest)
+	}
+	return parts
+}
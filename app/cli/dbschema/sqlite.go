@@ -0,0 +1,636 @@
+package dbschema
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"unicode/utf16"
+)
+
+// SQLite schemas are read straight from the database file rather than through a driver, so the cli doesn't need cgo. The schema table (sqlite_schema) is a table b-tree rooted at page 1 that stores each object's CREATE statement, which is then parsed for columns, keys, and indexes. Changes still in the write-ahead log aren't seen until they're checkpointed.
+
+const sqliteHeader = "SQLite format 3\x00"
+
+// limits for corrupt or hostile files
+const (
+	sqliteMaxSchemaPages = 100000
+	sqliteMaxPayload     = 64 * 1024 * 1024
+)
+
+type sqliteFile struct {
+	data       []byte
+	pageSize   int
+	usableSize int
+	encoding   uint32
+}
+
+type sqliteSchemaRow struct {
+	objType string
+	name    string
+	tblName string
+	sql     string
+}
+
+func introspectSQLite(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := openSqliteFile(data)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := f.readSchemaTable()
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{}
+	tables := map[string]*Table{}
+
+	for _, row := range rows {
+		if row.objType != "table" || row.sql == "" || strings.HasPrefix(row.name, "sqlite_") {
+			continue
+		}
+		table := parseSqliteCreateTable(row.name, row.sql)
+		tables[strings.ToLower(row.name)] = table
+		schema.Tables = append(schema.Tables, table)
+	}
+
+	// indexes created by UNIQUE and PRIMARY KEY constraints have no sql and are already covered by the table definition
+	for _, row := range rows {
+		if row.objType != "index" || row.sql == "" {
+			continue
+		}
+		table, ok := tables[strings.ToLower(row.tblName)]
+		if !ok {
+			continue
+		}
+		if index := parseSqliteCreateIndex(row.sql); index != nil {
+			table.Indexes = append(table.Indexes, index)
+		}
+	}
+
+	return schema, nil
+}
+
+func openSqliteFile(data []byte) (*sqliteFile, error) {
+	if len(data) < 100 || string(data[:16]) != sqliteHeader {
+		return nil, fmt.Errorf("not a sqlite database")
+	}
+
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, fmt.Errorf("invalid sqlite page size %d", pageSize)
+	}
+
+	f := &sqliteFile{
+		data:       data,
+		pageSize:   pageSize,
+		usableSize: pageSize - int(data[20]),
+		encoding:   binary.BigEndian.Uint32(data[56:60]),
+	}
+	if f.usableSize < 480 {
+		return nil, fmt.Errorf("invalid sqlite reserved space")
+	}
+
+	return f, nil
+}
+
+func (f *sqliteFile) page(n uint32) ([]byte, error) {
+	start := int(n-1) * f.pageSize
+	if n == 0 || start+f.pageSize > len(f.data) {
+		return nil, fmt.Errorf("sqlite page %d is out of range", n)
+	}
+	return f.data[start : start+f.pageSize], nil
+}
+
+func (f *sqliteFile) readSchemaTable() ([]*sqliteSchemaRow, error) {
+	var rows []*sqliteSchemaRow
+	visited := map[uint32]bool{}
+
+	var walk func(pageNum uint32) error
+	walk = func(pageNum uint32) error {
+		if visited[pageNum] || len(visited) > sqliteMaxSchemaPages {
+			return fmt.Errorf("corrupt sqlite schema b-tree")
+		}
+		visited[pageNum] = true
+
+		page, err := f.page(pageNum)
+		if err != nil {
+			return err
+		}
+
+		// page 1 starts with the 100 byte file header
+		headerStart := 0
+		if pageNum == 1 {
+			headerStart = 100
+		}
+		if headerStart+8 > len(page) {
+			return fmt.Errorf("corrupt sqlite page %d", pageNum)
+		}
+
+		pageType := page[headerStart]
+		numCells := int(binary.BigEndian.Uint16(page[headerStart+3 : headerStart+5]))
+
+		switch pageType {
+		case 0x05: // interior table page
+			cellPtrs := headerStart + 12
+			if cellPtrs+numCells*2 > len(page) {
+				return fmt.Errorf("corrupt sqlite page %d", pageNum)
+			}
+			for i := 0; i < numCells; i++ {
+				offset := int(binary.BigEndian.Uint16(page[cellPtrs+i*2:]))
+				if offset+4 > len(page) {
+					return fmt.Errorf("corrupt sqlite page %d", pageNum)
+				}
+				if err := walk(binary.BigEndian.Uint32(page[offset:])); err != nil {
+					return err
+				}
+			}
+			return walk(binary.BigEndian.Uint32(page[headerStart+8:]))
+
+		case 0x0d: // leaf table page
+			cellPtrs := headerStart + 8
+			if cellPtrs+numCells*2 > len(page) {
+				return fmt.Errorf("corrupt sqlite page %d", pageNum)
+			}
+			for i := 0; i < numCells; i++ {
+				offset := int(binary.BigEndian.Uint16(page[cellPtrs+i*2:]))
+				payload, err := f.leafPayload(page, offset)
+				if err != nil {
+					return err
+				}
+				values, err := f.decodeRecord(payload)
+				if err != nil {
+					return err
+				}
+				// type, name, tbl_name, rootpage, sql
+				if len(values) < 5 {
+					continue
+				}
+				rows = append(rows, &sqliteSchemaRow{
+					objType: values[0],
+					name:    values[1],
+					tblName: values[2],
+					sql:     values[4],
+				})
+			}
+			return nil
+		}
+
+		return fmt.Errorf("unexpected sqlite page type %d on page %d", pageType, pageNum)
+	}
+
+	if err := walk(1); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// leafPayload reads a table leaf cell's payload, following overflow pages when it doesn't fit on the page
+func (f *sqliteFile) leafPayload(page []byte, offset int) ([]byte, error) {
+	if offset >= len(page) {
+		return nil, fmt.Errorf("corrupt sqlite cell")
+	}
+	size, n := readVarint(page[offset:])
+	offset += n
+	_, n = readVarint(page[offset:]) // rowid
+	offset += n
+
+	if size > sqliteMaxPayload {
+		return nil, fmt.Errorf("sqlite schema record is too large")
+	}
+	payloadSize := int(size)
+
+	u := f.usableSize
+	maxLocal := u - 35
+	local := payloadSize
+	if payloadSize > maxLocal {
+		minLocal := (u-12)*32/255 - 23
+		local = minLocal + (payloadSize-minLocal)%(u-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+	}
+
+	if offset+local > len(page) {
+		return nil, fmt.Errorf("corrupt sqlite cell")
+	}
+	payload := make([]byte, 0, payloadSize)
+	payload = append(payload, page[offset:offset+local]...)
+	if local == payloadSize {
+		return payload, nil
+	}
+
+	if offset+local+4 > len(page) {
+		return nil, fmt.Errorf("corrupt sqlite cell")
+	}
+	next := binary.BigEndian.Uint32(page[offset+local:])
+	for len(payload) < payloadSize {
+		overflow, err := f.page(next)
+		if err != nil {
+			return nil, err
+		}
+		next = binary.BigEndian.Uint32(overflow)
+		chunk := overflow[4:u]
+		if remaining := payloadSize - len(payload); len(chunk) > remaining {
+			chunk = chunk[:remaining]
+		}
+		payload = append(payload, chunk...)
+		if next == 0 && len(payload) < payloadSize {
+			return nil, fmt.Errorf("truncated sqlite overflow chain")
+		}
+	}
+
+	return payload, nil
+}
+
+// decodeRecord returns a record's values as strings - the schema table only holds text and integers
+func (f *sqliteFile) decodeRecord(payload []byte) ([]string, error) {
+	headerSize, n := readVarint(payload)
+	if headerSize > uint64(len(payload)) || n == 0 {
+		return nil, fmt.Errorf("corrupt sqlite record")
+	}
+
+	var serialTypes []uint64
+	for pos := n; pos < int(headerSize); {
+		t, n := readVarint(payload[pos:])
+		if n == 0 {
+			return nil, fmt.Errorf("corrupt sqlite record")
+		}
+		serialTypes = append(serialTypes, t)
+		pos += n
+	}
+
+	values := make([]string, len(serialTypes))
+	pos := int(headerSize)
+	for i, t := range serialTypes {
+		var size int
+		switch {
+		case t == 0, t == 8, t == 9:
+			size = 0
+		case t >= 1 && t <= 4:
+			size = int(t)
+		case t == 5:
+			size = 6
+		case t == 6, t == 7:
+			size = 8
+		case t >= 12:
+			size = int((t - 12) / 2)
+		default:
+			return nil, fmt.Errorf("corrupt sqlite record")
+		}
+		if pos+size > len(payload) {
+			return nil, fmt.Errorf("corrupt sqlite record")
+		}
+		value := payload[pos : pos+size]
+		pos += size
+
+		switch {
+		case t >= 13 && t%2 == 1:
+			values[i] = f.decodeText(value)
+		case t >= 1 && t <= 6:
+			var v int64
+			for _, b := range value {
+				v = v<<8 | int64(b)
+			}
+			// sign-extend
+			shift := 64 - 8*uint(size)
+			values[i] = fmt.Sprint(v << shift >> shift)
+		case t == 7:
+			values[i] = fmt.Sprint(math.Float64frombits(binary.BigEndian.Uint64(value)))
+		case t == 9:
+			values[i] = "1"
+		case t == 8:
+			values[i] = "0"
+		}
+	}
+
+	return values, nil
+}
+
+func (f *sqliteFile) decodeText(b []byte) string {
+	if f.encoding != 2 && f.encoding != 3 {
+		return string(b)
+	}
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		if f.encoding == 2 {
+			u[i] = binary.LittleEndian.Uint16(b[i*2:])
+		} else {
+			u[i] = binary.BigEndian.Uint16(b[i*2:])
+		}
+	}
+	return string(utf16.Decode(u))
+}
+
+// readVarint decodes a sqlite varint - big-endian, up to 9 bytes, with all 8 bits of the 9th byte used. It returns 0 bytes read if b is too short.
+func readVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9; i++ {
+		if i >= len(b) {
+			return 0, 0
+		}
+		if i == 8 {
+			return v<<8 | uint64(b[i]), 9
+		}
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return v, 9
+}
+
+// column constraint keywords that end a column's type
+var sqliteConstraintKeywords = map[string]bool{
+	"CONSTRAINT": true, "PRIMARY": true, "NOT": true, "NULL": true, "UNIQUE": true, "CHECK": true,
+	"DEFAULT": true, "COLLATE": true, "REFERENCES": true, "GENERATED": true, "AS": true,
+}
+
+func parseSqliteCreateTable(name, sql string) *Table {
+	table := &Table{Name: name}
+
+	tokens := tokenizeSQL(sql)
+	// the column list is the first parenthesized group - 'CREATE TABLE ... AS SELECT' has none
+	var body string
+	for _, tok := range tokens {
+		if strings.HasPrefix(tok, "(") {
+			body = tok[1 : len(tok)-1]
+			break
+		}
+		if strings.EqualFold(tok, "AS") {
+			return table
+		}
+	}
+
+	for _, def := range splitTopLevel(body) {
+		toks := tokenizeSQL(def)
+		if len(toks) == 0 {
+			continue
+		}
+
+		// a named table constraint
+		if strings.EqualFold(toks[0], "CONSTRAINT") && len(toks) > 2 {
+			toks = toks[2:]
+		}
+
+		switch strings.ToUpper(toks[0]) {
+		case "PRIMARY":
+			if cols := firstGroup(toks); cols != nil {
+				table.PrimaryKey = cols
+			}
+			continue
+		case "UNIQUE":
+			if cols := firstGroup(toks); cols != nil {
+				table.Indexes = append(table.Indexes, &Index{Columns: cols, Unique: true})
+			}
+			continue
+		case "FOREIGN":
+			if fk := parseSqliteReferences(toks); fk != nil {
+				fk.Columns = firstGroup(toks)
+				table.ForeignKeys = append(table.ForeignKeys, fk)
+			}
+			continue
+		case "CHECK":
+			continue
+		}
+
+		col := &Column{Name: unquoteIdent(toks[0])}
+		i := 1
+		var typeToks []string
+		for ; i < len(toks) && !sqliteConstraintKeywords[strings.ToUpper(toks[i])]; i++ {
+			typeToks = append(typeToks, toks[i])
+		}
+		col.Type = strings.ToLower(strings.Join(typeToks, " "))
+		col.Type = strings.ReplaceAll(col.Type, " (", "(")
+
+		for ; i < len(toks); i++ {
+			switch strings.ToUpper(toks[i]) {
+			case "NOT":
+				if i+1 < len(toks) && strings.EqualFold(toks[i+1], "NULL") {
+					col.NotNull = true
+					i++
+				}
+			case "PRIMARY":
+				table.PrimaryKey = []string{col.Name}
+			case "AUTOINCREMENT":
+				col.Identity = true
+			case "UNIQUE":
+				table.Indexes = append(table.Indexes, &Index{Columns: []string{col.Name}, Unique: true})
+			case "DEFAULT":
+				if i+1 < len(toks) {
+					col.Default = toks[i+1]
+					i++
+				}
+			case "REFERENCES":
+				if fk := parseSqliteReferences(toks[i:]); fk != nil {
+					fk.Columns = []string{col.Name}
+					table.ForeignKeys = append(table.ForeignKeys, fk)
+				}
+				// skip the referenced table, columns, and ON clauses so 'SET DEFAULT' or 'SET NULL' aren't read as column constraints
+				i++
+				if i+1 < len(toks) && strings.HasPrefix(toks[i+1], "(") {
+					i++
+				}
+				for i+3 < len(toks) && strings.EqualFold(toks[i+1], "ON") {
+					i += 3
+					if up := strings.ToUpper(toks[i]); (up == "SET" || up == "NO") && i+1 < len(toks) {
+						i++
+					}
+				}
+			}
+		}
+
+		table.Columns = append(table.Columns, col)
+	}
+
+	return table
+}
+
+// parseSqliteReferences parses 'REFERENCES table (cols) ON DELETE action' from a list of tokens
+func parseSqliteReferences(toks []string) *ForeignKey {
+	for i, tok := range toks {
+		if !strings.EqualFold(tok, "REFERENCES") || i+1 >= len(toks) {
+			continue
+		}
+		fk := &ForeignKey{RefTable: unquoteIdent(toks[i+1])}
+		rest := toks[i+2:]
+		if len(rest) > 0 && strings.HasPrefix(rest[0], "(") {
+			fk.RefColumns = groupIdents(rest[0])
+			rest = rest[1:]
+		}
+		for j := 0; j+2 < len(rest); j++ {
+			if !strings.EqualFold(rest[j], "ON") {
+				continue
+			}
+			action := strings.ToUpper(rest[j+2])
+			if (action == "SET" || action == "NO") && j+3 < len(rest) {
+				action += " " + strings.ToUpper(rest[j+3])
+			}
+			switch strings.ToUpper(rest[j+1]) {
+			case "DELETE":
+				fk.OnDelete = action
+			case "UPDATE":
+				fk.OnUpdate = action
+			}
+		}
+		return fk
+	}
+	return nil
+}
+
+func parseSqliteCreateIndex(sql string) *Index {
+	toks := tokenizeSQL(sql)
+	index := &Index{}
+
+	i := 0
+	for ; i < len(toks); i++ {
+		upper := strings.ToUpper(toks[i])
+		if upper == "UNIQUE" {
+			index.Unique = true
+		}
+		if upper == "INDEX" {
+			i++
+			break
+		}
+	}
+	if i+2 < len(toks) && strings.EqualFold(toks[i], "IF") {
+		i += 3 // IF NOT EXISTS
+	}
+	if i >= len(toks) {
+		return nil
+	}
+	index.Name = unquoteIdent(toks[i])
+
+	for ; i < len(toks); i++ {
+		if strings.HasPrefix(toks[i], "(") {
+			index.Columns = splitTopLevel(toks[i][1 : len(toks[i])-1])
+			if i+1 < len(toks) && strings.EqualFold(toks[i+1], "WHERE") {
+				index.Where = strings.Join(toks[i+2:], " ")
+			}
+			return index
+		}
+	}
+	return nil
+}
+
+func firstGroup(toks []string) []string {
+	for _, tok := range toks {
+		if strings.HasPrefix(tok, "(") {
+			return groupIdents(tok)
+		}
+	}
+	return nil
+}
+
+func groupIdents(group string) []string {
+	parts := splitTopLevel(group[1 : len(group)-1])
+	for i, part := range parts {
+		// drop a trailing ASC/DESC or COLLATE clause
+		fields := tokenizeSQL(part)
+		if len(fields) > 0 {
+			parts[i] = unquoteIdent(fields[0])
+		}
+	}
+	return parts
+}
+
+func unquoteIdent(s string) string {
+	if len(s) >= 2 {
+		first, last := s[0], s[len(s)-1]
+		if (first == '"' && last == '"') || (first == '`' && last == '`') || (first == '[' && last == ']') || (first == '\'' && last == '\'') {
+			return s[1 : len(s)-1]
+		}
+	}
+	return s
+}
+
+// tokenizeSQL splits sql into words, quoted strings and identifiers, and parenthesized groups (kept whole), skipping comments
+func tokenizeSQL(sql string) []string {
+	var tokens []string
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' || c == ';':
+			i++
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				return tokens
+			}
+			i += end + 1
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 4
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			j := i + 1
+			for j < len(sql) {
+				if sql[j] == closing {
+					// doubled quotes are escapes
+					if closing != ']' && j+1 < len(sql) && sql[j+1] == closing {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			end := min(j+1, len(sql))
+			tokens = append(tokens, sql[i:end])
+			i = end
+		case c == '(':
+			depth := 0
+			var quote byte
+			j := i
+			for ; j < len(sql); j++ {
+				ch := sql[j]
+				if quote != 0 {
+					if ch == quote {
+						quote = 0
+					}
+					continue
+				}
+				if ch == '\'' || ch == '"' || ch == '`' {
+					quote = ch
+				} else if ch == '(' {
+					depth++
+				} else if ch == ')' {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			end := min(j+1, len(sql))
+			tokens = append(tokens, sql[i:end])
+			i = end
+		default:
+			j := i
+			for j < len(sql) && !strings.ContainsRune(" \t\n\r,;()'\"`[", rune(sql[j])) {
+				j++
+			}
+			if j == i {
+				j++
+			}
+			tokens = append(tokens, sql[i:j])
+			i = j
+		}
+	}
+	return tokens
+}
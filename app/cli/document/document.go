@@ -0,0 +1,153 @@
+package document
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+// documents longer than this are split into multiple context parts at page or section boundaries
+const MaxPartTokens = 25000
+
+// cap on the decompressed size of a single pdf stream or archive entry, so a decompression bomb can't exhaust memory
+const maxDecodedSize = 64 * 1024 * 1024 // 64MB
+
+type Section struct {
+	Title string
+	Text  string
+	// 1-based page number for pdfs, 0 for documents without pages
+	Page int
+}
+
+// Part is a contiguous run of sections. Start and End are 1-based and inclusive.
+type Part struct {
+	Start int
+	End   int
+}
+
+func IsDocumentFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".pdf", ".docx", ".odt":
+		return true
+	}
+	return false
+}
+
+// Extract returns the text of a pdf, docx, or odt document, split into pages for pdfs and heading-delimited sections otherwise
+func Extract(path string, content []byte) (sections []Section, err error) {
+	// the parsers are hand-written and documents come from anywhere, so a malformed file is an error rather than a crash
+	defer func() {
+		if r := recover(); r != nil {
+			sections = nil
+			err = fmt.Errorf("failed to extract text from %s: malformed document (%v)", path, r)
+		}
+	}()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".pdf":
+		sections, err = extractPdf(content)
+	case ".docx":
+		sections, err = extractDocx(content)
+	case ".odt":
+		sections, err = extractOdt(content)
+	default:
+		return nil, fmt.Errorf("unsupported document type: %s", path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract text from %s: %v", path, err)
+	}
+
+	hasText := false
+	for _, section := range sections {
+		if section.Text != "" {
+			hasText = true
+			break
+		}
+	}
+	if !hasText {
+		return nil, fmt.Errorf("no text found in %s - scanned documents and images aren't supported", path)
+	}
+
+	return sections, nil
+}
+
+// Chunk packs sections into parts of up to maxTokens each. A single section larger than maxTokens gets a part to itself.
+func Chunk(sections []Section, maxTokens int) []Part {
+	var parts []Part
+	start := 0
+	tokens := 0
+
+	for i, section := range sections {
+		n := shared.GetNumTokensEstimate(section.Text)
+		if i > start && tokens+n > maxTokens {
+			parts = append(parts, Part{Start: start + 1, End: i})
+			start = i
+			tokens = 0
+		}
+		tokens += n
+	}
+
+	if len(sections) > 0 {
+		parts = append(parts, Part{Start: start + 1, End: len(sections)})
+	}
+
+	return parts
+}
+
+// RenderPart renders sections start to end (1-based, inclusive) with a header locating them in the document
+func RenderPart(sections []Section, start, end int) string {
+	start = max(start, 1)
+	end = min(end, len(sections))
+	if start > end {
+		return ""
+	}
+
+	isPaged := sections[0].Page > 0
+	unit := "Sections"
+	if isPaged {
+		unit = "Pages"
+	}
+
+	var sb strings.Builder
+	if start == 1 && end == len(sections) {
+		sb.WriteString(fmt.Sprintf("%s: %d\n\n", unit, len(sections)))
+	} else {
+		sb.WriteString(fmt.Sprintf("%s %d-%d of %d\n\n", unit, start, end, len(sections)))
+	}
+
+	for _, section := range sections[start-1 : end] {
+		if isPaged {
+			sb.WriteString(fmt.Sprintf("--- Page %d ---\n", section.Page))
+		}
+		if section.Text != "" {
+			sb.WriteString(section.Text)
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+// cleanText trims trailing whitespace from each line and collapses runs of blank lines
+func cleanText(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	var res []string
+	blank := false
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if strings.TrimSpace(line) == "" {
+			if !blank && len(res) > 0 {
+				res = append(res, "")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+		res = append(res, line)
+	}
+	return strings.TrimSpace(strings.Join(res, "\n"))
+}
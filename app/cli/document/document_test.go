@@ -0,0 +1,259 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func buildPdf(objects ...string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.7\n")
+	for i, obj := range objects {
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	b.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return b.Bytes()
+}
+
+func pdfStreamObj(dict string, data []byte) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func flate(s string) []byte {
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	w.Write([]byte(s))
+	w.Close()
+	return b.Bytes()
+}
+
+func onePagePdf(contentObj string, extra ...string) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+		contentObj,
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+	return buildPdf(append(objects, extra...)...)
+}
+
+func TestExtractPdf(t *testing.T) {
+	content := "BT /F1 12 Tf 72 720 Td (Hello PDF) Tj ET"
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "plain content stream",
+			data: onePagePdf(pdfStreamObj("", []byte(content))),
+			want: "Hello PDF",
+		},
+		{
+			name: "flate content stream",
+			data: onePagePdf(pdfStreamObj("/Filter /FlateDecode", flate(content))),
+			want: "Hello PDF",
+		},
+		{
+			name: "length past end of file falls back to endstream",
+			data: onePagePdf(fmt.Sprintf("<< /Length 999999 >>\nstream\n%s\nendstream", content)),
+			want: "Hello PDF",
+		},
+		{
+			name: "negative length falls back to endstream",
+			data: onePagePdf(fmt.Sprintf("<< /Length -500 >>\nstream\n%s\nendstream", content)),
+			want: "Hello PDF",
+		},
+		{
+			name: "object stream with negative first",
+			data: onePagePdf(
+				pdfStreamObj("", []byte(content)),
+				pdfStreamObj("/Type /ObjStm /N 1 /First -20 /Filter /FlateDecode", flate("7 0 << /A 1 >>")),
+			),
+			want: "Hello PDF",
+		},
+		{
+			name: "object stream with negative offset",
+			data: onePagePdf(
+				pdfStreamObj("", []byte(content)),
+				pdfStreamObj("/Type /ObjStm /N 1 /First 6 /Filter /FlateDecode", flate("7 -50 << /A 1 >>")),
+			),
+			want: "Hello PDF",
+		},
+		{
+			name: "object stream with first past end",
+			data: onePagePdf(
+				pdfStreamObj("", []byte(content)),
+				pdfStreamObj("/Type /ObjStm /N 1 /First 99999 /Filter /FlateDecode", flate("7 0 << /A 1 >>")),
+			),
+			want: "Hello PDF",
+		},
+		{
+			name:    "truncated stream",
+			data:    []byte("%PDF-1.7\n1 0 obj\n<< /Length -1 >>\nstream\nBT (x"),
+			wantErr: true,
+		},
+		{
+			name:    "not a pdf",
+			data:    []byte("hello"),
+			wantErr: true,
+		},
+		{
+			name:    "encrypted",
+			data:    []byte("%PDF-1.7\n1 0 obj\n<< >>\nendobj\ntrailer\n<< /Encrypt 2 0 R >>\n"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sections, err := Extract("test.pdf", tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", sections)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(sections) != 1 || sections[0].Page != 1 {
+				t.Fatalf("expected a single page, got %+v", sections)
+			}
+			if !strings.Contains(sections[0].Text, tt.want) {
+				t.Errorf("got %q, want it to contain %q", sections[0].Text, tt.want)
+			}
+		})
+	}
+}
+
+func buildZip(t *testing.T, files map[string]string) []byte {
+	var b bytes.Buffer
+	w := zip.NewWriter(&b)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestExtractOffice(t *testing.T) {
+	docx := `<?xml version="1.0"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Intro</w:t></w:r></w:p>
+<w:p><w:r><w:t>Hello docx</w:t></w:r></w:p>
+</w:body></w:document>`
+
+	odt := `<?xml version="1.0"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"><office:body><office:text>
+<text:h text:outline-level="1">Intro</text:h>
+<text:p>Hello odt</text:p>
+</office:text></office:body></office:document-content>`
+
+	tests := []struct {
+		name    string
+		path    string
+		data    []byte
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "docx",
+			path: "test.docx",
+			data: buildZip(t, map[string]string{"word/document.xml": docx}),
+			want: "Hello docx",
+		},
+		{
+			name: "odt",
+			path: "test.odt",
+			data: buildZip(t, map[string]string{"content.xml": odt}),
+			want: "Hello odt",
+		},
+		{
+			name:    "docx without document.xml",
+			path:    "test.docx",
+			data:    buildZip(t, map[string]string{"word/other.xml": docx}),
+			wantErr: true,
+		},
+		{
+			name:    "docx with malformed xml",
+			path:    "test.docx",
+			data:    buildZip(t, map[string]string{"word/document.xml": "<w:document><w:body><w:p>"}),
+			wantErr: true,
+		},
+		{
+			name:    "not a zip",
+			path:    "test.odt",
+			data:    []byte("hello"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sections, err := Extract(tt.path, tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", sections)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var text strings.Builder
+			for _, section := range sections {
+				text.WriteString(section.Text)
+			}
+			if !strings.Contains(text.String(), tt.want) {
+				t.Errorf("got %+v, want text containing %q", sections, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadObjectStreamBounds(t *testing.T) {
+	// these only check that malformed object streams are skipped rather than panicking
+	tests := []struct {
+		name  string
+		dict  string
+		data  string
+		want  int
+		found bool
+	}{
+		{name: "valid", dict: "/N 1 /First 4", data: "7 0 << /A 1 >>", want: 7, found: true},
+		{name: "negative first", dict: "/N 1 /First -4", data: "7 0 << /A 1 >>", want: 7},
+		{name: "negative offset", dict: "/N 1 /First 4", data: "7 -9 << /A 1 >>", want: 7},
+		{name: "offset past end", dict: "/N 1 /First 4", data: "7 900 << /A 1 >>", want: 7},
+		{name: "huge offset", dict: "/N 1 /First 4", data: "7 1e300 << /A 1 >>", want: 7},
+		{name: "n larger than header", dict: "/N 50 /First 4", data: "7 0 << /A 1 >>", want: 7, found: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &pdfFile{objects: map[int]any{}}
+			lex := &pdfLexer{data: []byte("<< /Type /ObjStm " + tt.dict + " >>")}
+			val, err := lex.parseValue()
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.loadObjectStream(&pdfStream{dict: val.(pdfDict), raw: []byte(tt.data)})
+			if _, ok := f.objects[tt.want]; ok != tt.found {
+				t.Errorf("object %d loaded = %v, want %v", tt.want, ok, tt.found)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var docxHeadingStyleRegex = regexp.MustCompile(`(?i)^heading\s*(\d)$`)
+
+// sectionBuilder collects paragraphs into sections, starting a new one at each heading
+type sectionBuilder struct {
+	sections []Section
+	current  *Section
+	body     strings.Builder
+}
+
+func (b *sectionBuilder) heading(level int, text string) {
+	b.flush()
+	level = min(max(level, 1), 6)
+	b.current = &Section{Title: text}
+	b.body.WriteString(strings.Repeat("#", level) + " " + text + "\n\n")
+}
+
+func (b *sectionBuilder) paragraph(text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	if b.current == nil {
+		b.current = &Section{}
+	}
+	b.body.WriteString(text + "\n\n")
+}
+
+func (b *sectionBuilder) flush() {
+	if b.current == nil {
+		return
+	}
+	b.current.Text = cleanText(b.body.String())
+	b.sections = append(b.sections, *b.current)
+	b.current = nil
+	b.body.Reset()
+}
+
+func (b *sectionBuilder) result() []Section {
+	b.flush()
+	return b.sections
+}
+
+func readZipFile(data []byte, name string) ([]byte, error) {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %v", err)
+	}
+	for _, file := range r.File {
+		if file.Name == name {
+			rc, err := file.Open()
+			if err != nil {
+				return nil, fmt.Errorf("failed to open %s: %v", name, err)
+			}
+			defer rc.Close()
+			b, err := io.ReadAll(io.LimitReader(rc, maxDecodedSize+1))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %v", name, err)
+			}
+			if len(b) > maxDecodedSize {
+				return nil, fmt.Errorf("%s is too large to extract (over %d MB)", name, maxDecodedSize/1024/1024)
+			}
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("%s not found in archive", name)
+}
+
+func attr(el xml.StartElement, local string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func extractDocx(data []byte) ([]Section, error) {
+	doc, err := readZipFile(data, "word/document.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	b := &sectionBuilder{}
+	dec := xml.NewDecoder(bytes.NewReader(doc))
+
+	var para strings.Builder
+	var style string
+	var inText, isListItem bool
+	tableDepth := 0
+	var row []string
+	var cell strings.Builder
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse document: %v", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				para.Reset()
+				style = ""
+				isListItem = false
+			case "pStyle":
+				style = attr(t, "val")
+			case "numPr":
+				isListItem = true
+			case "t":
+				inText = true
+			case "tab":
+				para.WriteString("\t")
+			case "br", "cr":
+				para.WriteString("\n")
+			case "tbl":
+				tableDepth++
+			case "tr":
+				row = nil
+			case "tc":
+				cell.Reset()
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				text := strings.TrimSpace(para.String())
+				if tableDepth > 0 {
+					if cell.Len() > 0 && text != "" {
+						cell.WriteString(" ")
+					}
+					cell.WriteString(text)
+					continue
+				}
+				if m := docxHeadingStyleRegex.FindStringSubmatch(style); m != nil && text != "" {
+					level, _ := strconv.Atoi(m[1])
+					b.heading(level, text)
+				} else if strings.EqualFold(style, "Title") && text != "" {
+					b.heading(1, text)
+				} else if isListItem {
+					b.paragraph("- " + text)
+				} else {
+					b.paragraph(text)
+				}
+			case "tc":
+				row = append(row, cell.String())
+			case "tr":
+				if tableDepth == 1 {
+					b.paragraph("| " + strings.Join(row, " | ") + " |")
+				}
+			case "tbl":
+				tableDepth--
+			}
+		case xml.CharData:
+			if inText {
+				para.Write(t)
+			}
+		}
+	}
+
+	return b.result(), nil
+}
+
+func extractOdt(data []byte) ([]Section, error) {
+	content, err := readZipFile(data, "content.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	b := &sectionBuilder{}
+	dec := xml.NewDecoder(bytes.NewReader(content))
+
+	var para strings.Builder
+	// paragraphs and headings can nest (e.g. notes), so only the outermost one is emitted
+	paraDepth := 0
+	headingLevel := 0
+	listDepth := 0
+	tableDepth := 0
+	var row []string
+	var cell strings.Builder
+	inBody := false
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse document: %v", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "body" {
+				inBody = true
+			}
+			if !inBody {
+				continue
+			}
+			switch t.Name.Local {
+			case "p", "h":
+				if paraDepth == 0 {
+					para.Reset()
+					headingLevel = 0
+					if t.Name.Local == "h" {
+						headingLevel, _ = strconv.Atoi(attr(t, "outline-level"))
+						headingLevel = max(headingLevel, 1)
+					}
+				}
+				paraDepth++
+			case "s":
+				n, err := strconv.Atoi(attr(t, "c"))
+				if err != nil || n < 1 {
+					n = 1
+				}
+				para.WriteString(strings.Repeat(" ", n))
+			case "tab":
+				para.WriteString("\t")
+			case "line-break":
+				para.WriteString("\n")
+			case "list":
+				listDepth++
+			case "table":
+				tableDepth++
+			case "table-row":
+				row = nil
+			case "table-cell":
+				cell.Reset()
+			}
+		case xml.EndElement:
+			if !inBody {
+				continue
+			}
+			switch t.Name.Local {
+			case "p", "h":
+				paraDepth--
+				if paraDepth > 0 {
+					continue
+				}
+				text := strings.TrimSpace(para.String())
+				if tableDepth > 0 {
+					if cell.Len() > 0 && text != "" {
+						cell.WriteString(" ")
+					}
+					cell.WriteString(text)
+				} else if headingLevel > 0 && text != "" {
+					b.heading(headingLevel, text)
+				} else if listDepth > 0 {
+					b.paragraph(strings.Repeat("  ", listDepth-1) + "- " + text)
+				} else {
+					b.paragraph(text)
+				}
+			case "list":
+				listDepth--
+			case "table-cell":
+				row = append(row, cell.String())
+			case "table-row":
+				if tableDepth == 1 {
+					b.paragraph("| " + strings.Join(row, " | ") + " |")
+				}
+			case "table":
+				tableDepth--
+			case "body":
+				inBody = false
+			}
+		case xml.CharData:
+			if inBody && paraDepth > 0 {
+				para.Write(t)
+			}
+		}
+	}
+
+	return b.result(), nil
+}
@@ -0,0 +1,518 @@
+package document
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// A minimal PDF object parser - just enough to find pages, fonts, and content streams for text extraction. Objects are found by scanning for 'N G obj' rather than reading the xref table, which also copes with files that have a damaged xref.
+
+type pdfName string
+
+type pdfRef struct {
+	num int
+	gen int
+}
+
+type pdfKeyword string
+
+type pdfDict map[pdfName]any
+
+type pdfStream struct {
+	dict pdfDict
+	raw  []byte
+}
+
+var pdfObjRegex = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+type pdfFile struct {
+	objects map[int]any
+}
+
+func parsePdf(data []byte) (*pdfFile, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("%PDF")) {
+		return nil, fmt.Errorf("not a PDF file")
+	}
+
+	f := &pdfFile{objects: map[int]any{}}
+
+	// stream data can contain bytes that look like object headers, so matches inside a parsed stream are skipped
+	skipUntil := 0
+
+	for _, loc := range pdfObjRegex.FindAllSubmatchIndex(data, -1) {
+		// the match must start a token, not be the tail of another number
+		if loc[0] < skipUntil || (loc[0] > 0 && isPdfRegular(data[loc[0]-1])) {
+			continue
+		}
+		num, _ := strconv.Atoi(string(data[loc[2]:loc[3]]))
+
+		lex := &pdfLexer{data: data, pos: loc[1]}
+		val, err := lex.parseValue()
+		if err != nil {
+			continue
+		}
+
+		if dict, ok := val.(pdfDict); ok {
+			lex.skipSpace()
+			if bytes.HasPrefix(data[lex.pos:], []byte("stream")) {
+				val = lex.readStream(dict)
+				skipUntil = lex.pos
+			}
+		}
+
+		// later definitions win, as with incremental updates
+		f.objects[num] = val
+	}
+
+	if f.isEncrypted(data) {
+		return nil, fmt.Errorf("encrypted PDFs aren't supported")
+	}
+
+	// objects packed into object streams
+	var objStreams []*pdfStream
+	for _, obj := range f.objects {
+		if stream, ok := obj.(*pdfStream); ok && stream.dict["Type"] == pdfName("ObjStm") {
+			objStreams = append(objStreams, stream)
+		}
+	}
+	for _, stream := range objStreams {
+		f.loadObjectStream(stream)
+	}
+
+	return f, nil
+}
+
+func (f *pdfFile) isEncrypted(data []byte) bool {
+	idx := bytes.LastIndex(data, []byte("trailer"))
+	if idx >= 0 && bytes.Contains(data[idx:], []byte("/Encrypt")) {
+		return true
+	}
+	// cross-reference streams carry the trailer keys in their dict
+	for _, obj := range f.objects {
+		if stream, ok := obj.(*pdfStream); ok && stream.dict["Type"] == pdfName("XRef") && stream.dict["Encrypt"] != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *pdfFile) loadObjectStream(stream *pdfStream) {
+	data, err := f.decodeStream(stream)
+	if err != nil {
+		return
+	}
+	n, _ := f.resolve(stream.dict["N"]).(float64)
+	first, _ := f.resolve(stream.dict["First"]).(float64)
+	if first < 0 || int(first) > len(data) {
+		return
+	}
+
+	header := &pdfLexer{data: data[:int(first)]}
+	for i := 0; i < int(n); i++ {
+		numVal, err1 := header.parseValue()
+		offsetVal, err2 := header.parseValue()
+		if err1 != nil || err2 != nil {
+			return
+		}
+		num, ok1 := numVal.(float64)
+		offset, ok2 := offsetVal.(float64)
+		if !ok1 || !ok2 {
+			return
+		}
+		if _, exists := f.objects[int(num)]; exists {
+			continue
+		}
+		// offsets are relative to /First and come straight from the file
+		if offset < 0 || first+offset >= float64(len(data)) {
+			continue
+		}
+		start := int(first + offset)
+		lex := &pdfLexer{data: data, pos: start}
+		val, err := lex.parseValue()
+		if err != nil {
+			continue
+		}
+		f.objects[int(num)] = val
+	}
+}
+
+func (f *pdfFile) resolve(v any) any {
+	for i := 0; i < 32; i++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		v = f.objects[ref.num]
+	}
+	return nil
+}
+
+func (f *pdfFile) dict(v any) pdfDict {
+	switch t := f.resolve(v).(type) {
+	case pdfDict:
+		return t
+	case *pdfStream:
+		return t.dict
+	}
+	return nil
+}
+
+func (f *pdfFile) decodeStream(stream *pdfStream) ([]byte, error) {
+	data := stream.raw
+
+	var filters []any
+	switch t := f.resolve(stream.dict["Filter"]).(type) {
+	case pdfName:
+		filters = []any{t}
+	case []any:
+		filters = t
+	}
+
+	for _, filter := range filters {
+		name, _ := f.resolve(filter).(pdfName)
+		switch name {
+		case "FlateDecode", "Fl":
+			r, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("error decoding stream: %v", err)
+			}
+			// streams are often slightly truncated or padded - keep whatever decoded
+			out, err := io.ReadAll(io.LimitReader(r, maxDecodedSize))
+			if err != nil && len(out) == 0 {
+				return nil, fmt.Errorf("error decoding stream: %v", err)
+			}
+			data = out
+		case "ASCIIHexDecode", "AHx":
+			cleaned := bytes.Map(func(r rune) rune {
+				if isPdfSpace(byte(r)) || r == '>' {
+					return -1
+				}
+				return r
+			}, data)
+			if len(cleaned)%2 == 1 {
+				cleaned = append(cleaned, '0')
+			}
+			out := make([]byte, len(cleaned)/2)
+			if _, err := hex.Decode(out, cleaned); err != nil {
+				return nil, fmt.Errorf("error decoding stream: %v", err)
+			}
+			data = out
+		case "ASCII85Decode", "A85":
+			trimmed := bytes.TrimSpace(data)
+			trimmed = bytes.TrimPrefix(trimmed, []byte("<~"))
+			trimmed = bytes.TrimSuffix(trimmed, []byte("~>"))
+			out := make([]byte, len(trimmed)*4/5+4)
+			n, _, err := ascii85.Decode(out, trimmed, true)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding stream: %v", err)
+			}
+			data = out[:n]
+		default:
+			return nil, fmt.Errorf("unsupported stream filter: %s", name)
+		}
+	}
+
+	return data, nil
+}
+
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func isPdfSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func isPdfDelim(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func isPdfRegular(c byte) bool {
+	return !isPdfSpace(c) && !isPdfDelim(c)
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isPdfSpace(c) {
+			l.pos++
+		} else if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		} else {
+			return
+		}
+	}
+}
+
+func (l *pdfLexer) atEnd() bool {
+	l.skipSpace()
+	return l.pos >= len(l.data)
+}
+
+// parseValue reads one object. Bare keywords (content stream operators, 'endobj', etc.) come back as pdfKeyword.
+func (l *pdfLexer) parseValue() (any, error) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, io.EOF
+	}
+
+	c := l.data[l.pos]
+	switch {
+	case c == '/':
+		l.pos++
+		start := l.pos
+		for l.pos < len(l.data) && isPdfRegular(l.data[l.pos]) {
+			l.pos++
+		}
+		return pdfName(decodePdfName(l.data[start:l.pos])), nil
+	case c == '(':
+		return l.readLiteralString(), nil
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		return l.readDict()
+	case c == '<':
+		return l.readHexString(), nil
+	case c == '[':
+		l.pos++
+		var arr []any
+		for {
+			l.skipSpace()
+			if l.pos >= len(l.data) {
+				return arr, nil
+			}
+			if l.data[l.pos] == ']' {
+				l.pos++
+				return arr, nil
+			}
+			v, err := l.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+	case c == ']' || c == '>' || c == ')' || c == '{' || c == '}':
+		// stray delimiter - skip it so callers make progress
+		l.pos++
+		return pdfKeyword(string(c)), nil
+	}
+
+	start := l.pos
+	for l.pos < len(l.data) && isPdfRegular(l.data[l.pos]) {
+		l.pos++
+	}
+	tok := string(l.data[start:l.pos])
+
+	if num, err := strconv.ParseFloat(tok, 64); err == nil {
+		// 'num gen R' is a reference
+		if isPdfInt(tok) {
+			save := l.pos
+			l.skipSpace()
+			genStart := l.pos
+			for l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '9' {
+				l.pos++
+			}
+			if l.pos > genStart {
+				gen, _ := strconv.Atoi(string(l.data[genStart:l.pos]))
+				l.skipSpace()
+				if l.pos < len(l.data) && l.data[l.pos] == 'R' && (l.pos+1 == len(l.data) || !isPdfRegular(l.data[l.pos+1])) {
+					l.pos++
+					return pdfRef{num: int(num), gen: gen}, nil
+				}
+			}
+			l.pos = save
+		}
+		return num, nil
+	}
+
+	switch tok {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+
+	return pdfKeyword(tok), nil
+}
+
+func isPdfInt(tok string) bool {
+	for i := 0; i < len(tok); i++ {
+		if tok[i] < '0' || tok[i] > '9' {
+			return false
+		}
+	}
+	return len(tok) > 0
+}
+
+func decodePdfName(b []byte) string {
+	if !bytes.Contains(b, []byte("#")) {
+		return string(b)
+	}
+	var out []byte
+	for i := 0; i < len(b); i++ {
+		if b[i] == '#' && i+2 < len(b) {
+			if v, err := strconv.ParseUint(string(b[i+1:i+3]), 16, 8); err == nil {
+				out = append(out, byte(v))
+				i += 2
+				continue
+			}
+		}
+		out = append(out, b[i])
+	}
+	return string(out)
+}
+
+func (l *pdfLexer) readDict() (pdfDict, error) {
+	dict := pdfDict{}
+	for {
+		l.skipSpace()
+		if l.pos >= len(l.data) {
+			return dict, nil
+		}
+		if l.data[l.pos] == '>' {
+			l.pos++
+			if l.pos < len(l.data) && l.data[l.pos] == '>' {
+				l.pos++
+			}
+			return dict, nil
+		}
+		key, err := l.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(pdfName)
+		if !ok {
+			continue
+		}
+		val, err := l.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		dict[name] = val
+	}
+}
+
+func (l *pdfLexer) readLiteralString() []byte {
+	l.pos++ // (
+	var out []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+			out = append(out, c)
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+			out = append(out, c)
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r':
+				// line continuation
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					out = append(out, byte(v))
+				} else {
+					out = append(out, e)
+				}
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (l *pdfLexer) readHexString() []byte {
+	l.pos++ // <
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		c := l.data[l.pos]
+		if !isPdfSpace(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++ // >
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	n, _ := hex.Decode(out, digits)
+	return out[:n]
+}
+
+func (l *pdfLexer) readStream(dict pdfDict) *pdfStream {
+	l.pos += len("stream")
+	if l.pos < len(l.data) && l.data[l.pos] == '\r' {
+		l.pos++
+	}
+	if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+		l.pos++
+	}
+	start := l.pos
+
+	// a direct /Length is used when it lines up with 'endstream' - indirect lengths may not be parsed yet, so fall back to searching
+	if length, ok := dict["Length"].(float64); ok && length >= 0 && length <= float64(len(l.data)) {
+		end := start + int(length)
+		if end <= len(l.data) {
+			rest := bytes.TrimLeft(l.data[end:min(end+20, len(l.data))], " \t\r\n")
+			if bytes.HasPrefix(rest, []byte("endstream")) {
+				l.pos = end
+				return &pdfStream{dict: dict, raw: l.data[start:end]}
+			}
+		}
+	}
+
+	idx := bytes.Index(l.data[start:], []byte("endstream"))
+	if idx < 0 {
+		l.pos = len(l.data)
+		return &pdfStream{dict: dict, raw: l.data[start:]}
+	}
+	end := start + idx
+	raw := bytes.TrimRight(l.data[start:end], "\r\n")
+	l.pos = end
+	return &pdfStream{dict: dict, raw: raw}
+}
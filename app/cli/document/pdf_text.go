@@ -0,0 +1,424 @@
+package document
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf16"
+)
+
+type pdfFont struct {
+	// maps character codes to text, from the font's ToUnicode cmap
+	toUnicode map[uint32]string
+	codeLen   int
+	// composite (Type0) fonts use multi-byte glyph codes that can't be read without a cmap
+	composite bool
+}
+
+func extractPdf(data []byte) ([]Section, error) {
+	f, err := parsePdf(data)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := f.pages()
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("no pages found")
+	}
+
+	fontCache := map[any]*pdfFont{}
+
+	var sections []Section
+	for i, page := range pages {
+		fonts := map[pdfName]*pdfFont{}
+		resources := f.dict(f.inherited(page, "Resources"))
+		for name, ref := range f.dict(resources["Font"]) {
+			key := ref
+			if _, ok := ref.(pdfRef); !ok {
+				key = fmt.Sprintf("%s-%d", name, i)
+			}
+			font, ok := fontCache[key]
+			if !ok {
+				font = f.loadFont(ref)
+				fontCache[key] = font
+			}
+			fonts[name] = font
+		}
+
+		var content []byte
+		switch c := f.resolve(page["Contents"]).(type) {
+		case *pdfStream:
+			content, _ = f.decodeStream(c)
+		case []any:
+			for _, part := range c {
+				if stream, ok := f.resolve(part).(*pdfStream); ok {
+					decoded, err := f.decodeStream(stream)
+					if err == nil {
+						content = append(content, decoded...)
+						content = append(content, '\n')
+					}
+				}
+			}
+		}
+
+		sections = append(sections, Section{
+			Title: fmt.Sprintf("Page %d", i+1),
+			Page:  i + 1,
+			Text:  cleanText(pdfContentText(content, fonts)),
+		})
+	}
+
+	return sections, nil
+}
+
+// pages returns page dicts in document order by walking the page tree from the catalog, falling back to object order if the tree can't be read
+func (f *pdfFile) pages() []pdfDict {
+	var pages []pdfDict
+	seen := map[int]bool{}
+
+	var walk func(node any, depth int)
+	walk = func(node any, depth int) {
+		if depth > 64 {
+			return
+		}
+		if ref, ok := node.(pdfRef); ok {
+			if seen[ref.num] {
+				return
+			}
+			seen[ref.num] = true
+		}
+		dict := f.dict(node)
+		if dict == nil {
+			return
+		}
+		switch dict["Type"] {
+		case pdfName("Pages"):
+			kids, _ := f.resolve(dict["Kids"]).([]any)
+			for _, kid := range kids {
+				walk(kid, depth+1)
+			}
+		case pdfName("Page"):
+			pages = append(pages, dict)
+		}
+	}
+
+	for num := 0; num <= f.maxObjectNum(); num++ {
+		dict := f.dict(f.objects[num])
+		if dict != nil && dict["Type"] == pdfName("Catalog") {
+			walk(dict["Pages"], 0)
+			if len(pages) > 0 {
+				return pages
+			}
+		}
+	}
+
+	for num := 0; num <= f.maxObjectNum(); num++ {
+		dict := f.dict(f.objects[num])
+		if dict != nil && dict["Type"] == pdfName("Page") {
+			pages = append(pages, dict)
+		}
+	}
+	return pages
+}
+
+func (f *pdfFile) maxObjectNum() int {
+	max := 0
+	for num := range f.objects {
+		if num > max {
+			max = num
+		}
+	}
+	return max
+}
+
+// inherited looks up a page attribute that may be set on an ancestor in the page tree
+func (f *pdfFile) inherited(page pdfDict, key pdfName) any {
+	node := page
+	for i := 0; i < 64 && node != nil; i++ {
+		if v, ok := node[key]; ok {
+			return v
+		}
+		node = f.dict(node["Parent"])
+	}
+	return nil
+}
+
+func (f *pdfFile) loadFont(ref any) *pdfFont {
+	dict := f.dict(ref)
+	font := &pdfFont{codeLen: 1}
+	if dict == nil {
+		return font
+	}
+
+	font.composite = dict["Subtype"] == pdfName("Type0")
+	if font.composite {
+		font.codeLen = 2
+	}
+
+	if stream, ok := f.resolve(dict["ToUnicode"]).(*pdfStream); ok {
+		data, err := f.decodeStream(stream)
+		if err == nil {
+			font.toUnicode, font.codeLen = parseToUnicode(data, font.codeLen)
+		}
+	}
+
+	return font
+}
+
+func parseToUnicode(data []byte, defaultCodeLen int) (map[uint32]string, int) {
+	res := map[uint32]string{}
+	codeLen := 0
+
+	lex := &pdfLexer{data: data}
+	var operands []any
+
+	for !lex.atEnd() {
+		v, err := lex.parseValue()
+		if err != nil {
+			break
+		}
+		kw, ok := v.(pdfKeyword)
+		if !ok {
+			operands = append(operands, v)
+			continue
+		}
+
+		switch kw {
+		case "endcodespacerange":
+			if len(operands) > 0 {
+				if lo, ok := operands[0].([]byte); ok {
+					codeLen = len(lo)
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].([]byte)
+				dst, ok2 := operands[i+1].([]byte)
+				if ok1 && ok2 {
+					if codeLen == 0 {
+						codeLen = len(src)
+					}
+					res[bytesToCode(src)] = decodeUTF16BE(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].([]byte)
+				hi, ok2 := operands[i+1].([]byte)
+				if !ok1 || !ok2 {
+					continue
+				}
+				if codeLen == 0 {
+					codeLen = len(lo)
+				}
+				start, end := bytesToCode(lo), bytesToCode(hi)
+				if end < start || end-start > 0xFFFF {
+					continue
+				}
+				switch dst := operands[i+2].(type) {
+				case []byte:
+					// the last byte of the destination increments through the range
+					runes := []rune(decodeUTF16BE(dst))
+					for code := start; code <= end && len(runes) > 0; code++ {
+						r := append([]rune(nil), runes...)
+						r[len(r)-1] += rune(code - start)
+						res[code] = string(r)
+					}
+				case []any:
+					for j, item := range dst {
+						if b, ok := item.([]byte); ok && start+uint32(j) <= end {
+							res[start+uint32(j)] = decodeUTF16BE(b)
+						}
+					}
+				}
+			}
+		}
+
+		operands = nil
+	}
+
+	if codeLen == 0 {
+		codeLen = defaultCodeLen
+	}
+	return res, codeLen
+}
+
+func bytesToCode(b []byte) uint32 {
+	var code uint32
+	for _, c := range b {
+		code = code<<8 | uint32(c)
+	}
+	return code
+}
+
+func decodeUTF16BE(b []byte) string {
+	if len(b) == 1 {
+		return string(rune(b[0]))
+	}
+	u := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		u = append(u, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(u))
+}
+
+func (font *pdfFont) decode(b []byte) string {
+	if font == nil {
+		return latin1(b)
+	}
+
+	if font.toUnicode == nil {
+		if font.composite {
+			return ""
+		}
+		return latin1(b)
+	}
+
+	var sb strings.Builder
+	step := max(font.codeLen, 1)
+	for i := 0; i+step <= len(b); i += step {
+		code := bytesToCode(b[i : i+step])
+		if s, ok := font.toUnicode[code]; ok {
+			sb.WriteString(s)
+		} else if step == 1 {
+			sb.WriteString(latin1(b[i : i+1]))
+		}
+	}
+	return sb.String()
+}
+
+func latin1(b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		if c >= 0x20 || c == '\n' || c == '\t' {
+			sb.WriteRune(rune(c))
+		}
+	}
+	return sb.String()
+}
+
+// pdfContentText interprets the text operators in a page content stream. Line breaks are inferred from text positioning, and large gaps in TJ arrays become spaces.
+func pdfContentText(content []byte, fonts map[pdfName]*pdfFont) string {
+	var sb strings.Builder
+	var operands []any
+	var font *pdfFont
+	lastY := math.NaN()
+
+	newline := func() {
+		s := sb.String()
+		if len(s) > 0 && !strings.HasSuffix(s, "\n") {
+			sb.WriteByte('\n')
+		}
+	}
+	space := func() {
+		s := sb.String()
+		if len(s) > 0 && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+			sb.WriteByte(' ')
+		}
+	}
+	show := func(v any) {
+		if b, ok := v.([]byte); ok {
+			sb.WriteString(font.decode(b))
+		}
+	}
+	num := func(i int) float64 {
+		if i < 0 || i >= len(operands) {
+			return 0
+		}
+		n, _ := operands[i].(float64)
+		return n
+	}
+
+	lex := &pdfLexer{data: content}
+	for !lex.atEnd() {
+		v, err := lex.parseValue()
+		if err != nil {
+			break
+		}
+		kw, ok := v.(pdfKeyword)
+		if !ok {
+			operands = append(operands, v)
+			continue
+		}
+
+		switch kw {
+		case "BT":
+			lastY = math.NaN()
+		case "ET":
+			newline()
+		case "Tf":
+			if len(operands) >= 2 {
+				if name, ok := operands[0].(pdfName); ok {
+					font = fonts[name]
+				}
+			}
+		case "Td", "TD":
+			if num(1) != 0 {
+				newline()
+			} else if num(0) != 0 {
+				space()
+			}
+		case "Tm":
+			y := num(5)
+			if !math.IsNaN(lastY) && y != lastY {
+				newline()
+			} else {
+				space()
+			}
+			lastY = y
+		case "T*":
+			newline()
+		case "Tj":
+			if len(operands) > 0 {
+				show(operands[len(operands)-1])
+			}
+		case "'":
+			newline()
+			if len(operands) > 0 {
+				show(operands[len(operands)-1])
+			}
+		case "\"":
+			newline()
+			if len(operands) > 0 {
+				show(operands[len(operands)-1])
+			}
+		case "TJ":
+			if len(operands) > 0 {
+				arr, _ := operands[len(operands)-1].([]any)
+				for _, item := range arr {
+					if n, ok := item.(float64); ok {
+						// offsets are in thousandths of a text space unit - large negative ones separate words
+						if n < -200 {
+							space()
+						}
+						continue
+					}
+					show(item)
+				}
+			}
+		case "ID":
+			// skip inline image data
+			idx := strings.Index(string(content[lex.pos:]), "EI")
+			for idx >= 0 {
+				end := lex.pos + idx + 2
+				if end >= len(content) || isPdfSpace(content[end]) {
+					lex.pos = end
+					break
+				}
+				next := strings.Index(string(content[end:]), "EI")
+				if next < 0 {
+					idx = -1
+					break
+				}
+				idx = end - lex.pos + next
+			}
+			if idx < 0 {
+				lex.pos = len(content)
+			}
+		}
+
+		operands = nil
+	}
+
+	return sb.String()
+}
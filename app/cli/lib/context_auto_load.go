@@ -27,6 +27,18 @@ func AutoLoadContextFiles(ctx context.Context, files []string) (string, error) {
 		totalSize += context.BodySize
 	}
 
+	// api spec items and other file ranges are referenced as 'path::Symbol' or 'path#L120-240'
+	var rangeInputs []*fileRangeInput
+	var paths []string
+	for _, file := range files {
+		if input, ok := parseFileRangeResource(file); ok {
+			rangeInputs = append(rangeInputs, input)
+		} else {
+			paths = append(paths, file)
+		}
+	}
+	files = paths
+
 	loadContextReqsByIndex := make(map[int]*shared.LoadContextParams)
 	filesSkippedTooLarge := []filePathWithSize{}
 	filesSkippedAfterSizeLimit := []string{}
@@ -130,6 +142,14 @@ func AutoLoadContextFiles(ctx context.Context, files []string) (string, error) {
 		}
 	}
 
+	if len(rangeInputs) > 0 {
+		rangeReqs, err := loadFileRanges(rangeInputs, true)
+		if err != nil {
+			return "", fmt.Errorf("failed to load context: %v", err)
+		}
+		loadContextReqs = append(loadContextReqs, rangeReqs...)
+	}
+
 	// even if there are no files to load, we still need to hit the API endpoint because the stream is waiting on a channel for the autoload to finish
 	res, apiErr := api.Client.AutoLoadContext(ctx, CurrentPlanId, CurrentBranch, loadContextReqs)
 	if apiErr != nil {
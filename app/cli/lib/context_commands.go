@@ -0,0 +1,226 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"plandex-cli/fs"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	shared "plandex-shared"
+)
+
+const DefaultCommandContextTimeout = 60
+
+// only the tail of long output is kept - for test runs and builds, that's where the failures and summaries are
+const maxCommandOutputBytes = 100 * 1024
+
+const approvedCommandsFileName = "approved-commands.json"
+
+// RunContextCommand runs a shell command in the project root and returns a context body with its combined stdout and stderr, followed by the exit code. A non-zero exit isn't an error since failing output is usually what's wanted in context.
+func RunContextCommand(command string, timeoutSeconds int) (string, error) {
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = DefaultCommandContextTimeout
+	}
+
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/bash" // fallback
+	}
+
+	execCmd := exec.Command(shell, "-c", command)
+	execCmd.Dir = fs.ProjectRoot
+	execCmd.Env = os.Environ()
+
+	output := &tailBuffer{limit: maxCommandOutputBytes}
+	execCmd.Stdout = output
+	execCmd.Stderr = output
+
+	// don't hang on background processes that keep the output open after the command exits
+	execCmd.WaitDelay = time.Second
+
+	// run in its own process group so a timeout kills everything the command started
+	SetPlatformSpecificAttrs(execCmd)
+
+	if err := execCmd.Start(); err != nil {
+		return "", fmt.Errorf("failed to start command '%s': %v", command, err)
+	}
+
+	var timedOut bool
+	var timedOutMu sync.Mutex
+	timer := time.AfterFunc(time.Duration(timeoutSeconds)*time.Second, func() {
+		timedOutMu.Lock()
+		timedOut = true
+		timedOutMu.Unlock()
+		KillProcessGroup(execCmd, syscall.SIGKILL)
+	})
+	err := execCmd.Wait()
+	timer.Stop()
+
+	timedOutMu.Lock()
+	defer timedOutMu.Unlock()
+
+	var status string
+	var exitErr *exec.ExitError
+	if timedOut {
+		status = fmt.Sprintf("[timed out after %ds]", timeoutSeconds)
+	} else if err == nil || errors.Is(err, exec.ErrWaitDelay) {
+		status = "[exit code 0]"
+	} else if errors.As(err, &exitErr) {
+		status = fmt.Sprintf("[exit code %d]", exitErr.ExitCode())
+	} else {
+		return "", fmt.Errorf("failed to run command '%s': %v", command, err)
+	}
+
+	// a cut can land mid-character, so invalid utf-8 is replaced
+	out := strings.ToValidUTF8(output.String(), "�")
+
+	var sb strings.Builder
+	sb.WriteString("$ " + command + "\n\n")
+	if output.truncated {
+		sb.WriteString(fmt.Sprintf("[output truncated to the last %dKB]\n", maxCommandOutputBytes/1024))
+	}
+	out = strings.TrimRight(out, "\n")
+	if out == "" {
+		out = "(no output)"
+	}
+	sb.WriteString(out + "\n\n" + status)
+
+	return sb.String(), nil
+}
+
+// tailBuffer keeps the last limit bytes written to it
+type tailBuffer struct {
+	mu        sync.Mutex
+	buf       []byte
+	limit     int
+	truncated bool
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf = append(b.buf, p...)
+	// trim in chunks rather than on every write
+	if len(b.buf) > b.limit*2 {
+		b.buf = append([]byte(nil), b.buf[len(b.buf)-b.limit:]...)
+		b.truncated = true
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.buf) > b.limit {
+		b.truncated = true
+		return string(b.buf[len(b.buf)-b.limit:])
+	}
+	return string(b.buf)
+}
+
+// loadCommandParams runs each command and approves it on this machine so it can be re-run when context is checked
+func loadCommandParams(commands []string, timeoutSeconds int, autoLoaded bool) ([]*shared.LoadContextParams, error) {
+	var res []*shared.LoadContextParams
+	for _, command := range commands {
+		command = strings.TrimSpace(command)
+		if command == "" {
+			continue
+		}
+
+		body, err := RunContextCommand(command, timeoutSeconds)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, &shared.LoadContextParams{
+			ContextType:    shared.ContextCommandType,
+			Name:           command,
+			Body:           body,
+			Command:        command,
+			CommandTimeout: timeoutSeconds,
+			AutoLoaded:     autoLoaded,
+		})
+	}
+
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no command to run")
+	}
+
+	var approved []string
+	for _, params := range res {
+		approved = append(approved, params.Command)
+	}
+	err := approveCommands(approved)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Commands are stored with the plan, so anyone with access to it could add one. They're only re-run on machines where they were loaded (or re-loaded) with 'plandex load --cmd', tracked per project in the home plandex dir.
+func getApprovedCommandsPath() string {
+	return filepath.Join(HomeCurrentProjectDir, approvedCommandsFileName)
+}
+
+func loadApprovedCommands() (map[string]bool, error) {
+	approved := map[string]bool{}
+
+	bytes, err := os.ReadFile(getApprovedCommandsPath())
+	if os.IsNotExist(err) {
+		return approved, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading approved commands: %v", err)
+	}
+
+	var commands []string
+	err = json.Unmarshal(bytes, &commands)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling approved commands: %v", err)
+	}
+
+	for _, command := range commands {
+		approved[command] = true
+	}
+
+	return approved, nil
+}
+
+func approveCommands(commands []string) error {
+	approved, err := loadApprovedCommands()
+	if err != nil {
+		return err
+	}
+
+	var all []string
+	for command := range approved {
+		all = append(all, command)
+	}
+	for _, command := range commands {
+		if !approved[command] {
+			approved[command] = true
+			all = append(all, command)
+		}
+	}
+
+	bytes, err := json.Marshal(all)
+	if err != nil {
+		return fmt.Errorf("error marshalling approved commands: %v", err)
+	}
+
+	err = os.WriteFile(getApprovedCommandsPath(), bytes, 0600)
+	if err != nil {
+		return fmt.Errorf("error writing approved commands: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"plandex-cli/dbschema"
+	"plandex-cli/fs"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+const dbConnectionsFileName = "db-connections.json"
+const approvedDbsFileName = "approved-dbs.json"
+
+// loadDbSchemaParams introspects a database and renders its schema as context. Only the connection string with any password removed is stored with the plan - the full connection string is kept in the home plandex dir so the schema can be re-checked on this machine.
+func loadDbSchemaParams(dsn string, autoLoaded bool) (*shared.LoadContextParams, error) {
+	dsn = strings.TrimSpace(dsn)
+
+	dialect, err := dbschema.Dialect(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if dialect == dbschema.DialectSQLite {
+		dsn, err = projectSQLiteDsn(dsn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	source := dbschema.Redact(dsn)
+
+	body, err := getDbSchemaBody(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if source != dsn {
+		err = saveDbConnection(source, dsn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = approveDb(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return &shared.LoadContextParams{
+		ContextType: shared.ContextDbSchemaType,
+		Name:        source,
+		Body:        body,
+		DbSource:    source,
+		AutoLoaded:  autoLoaded,
+	}, nil
+}
+
+func getDbSchemaBody(dsn string) (string, error) {
+	dialect, err := dbschema.Dialect(dsn)
+	if err != nil {
+		return "", err
+	}
+
+	// sqlite paths are stored relative to the project root
+	if dialect == dbschema.DialectSQLite {
+		path := dbschema.SQLitePath(dsn)
+		if !filepath.IsAbs(path) {
+			dsn = filepath.Join(fs.ProjectRoot, path)
+		}
+	}
+
+	schema, err := dbschema.Introspect(dsn)
+	if err != nil {
+		return "", err
+	}
+
+	return dbschema.Render(schema), nil
+}
+
+// projectSQLiteDsn resolves a sqlite path against the current directory and makes it relative to the project root when it's inside the project, so the context works from any directory and on other machines
+func projectSQLiteDsn(dsn string) (string, error) {
+	path := dbschema.SQLitePath(dsn)
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("error resolving sqlite path: %v", err)
+	}
+
+	if _, err := os.Stat(absPath); err != nil {
+		return "", fmt.Errorf("sqlite database %s not found", path)
+	}
+
+	if rel, err := filepath.Rel(fs.ProjectRoot, absPath); err == nil && !strings.HasPrefix(rel, "..") {
+		return "sqlite://" + filepath.ToSlash(rel), nil
+	}
+
+	return "sqlite://" + absPath, nil
+}
+
+// getDbDsn returns the connection string to use for a db schema context. Sources without a saved connection are used as-is, which works for databases that don't need a password (or get it from the environment, like ~/.pgpass).
+func getDbDsn(source string, connections map[string]string) string {
+	if dsn, ok := connections[source]; ok {
+		return dsn
+	}
+	return source
+}
+
+func getDbConnectionsPath() string {
+	return filepath.Join(HomeCurrentProjectDir, dbConnectionsFileName)
+}
+
+func loadDbConnections() (map[string]string, error) {
+	connections := map[string]string{}
+
+	bytes, err := os.ReadFile(getDbConnectionsPath())
+	if os.IsNotExist(err) {
+		return connections, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading db connections: %v", err)
+	}
+
+	err = json.Unmarshal(bytes, &connections)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling db connections: %v", err)
+	}
+
+	return connections, nil
+}
+
+func saveDbConnection(source, dsn string) error {
+	connections, err := loadDbConnections()
+	if err != nil {
+		return err
+	}
+
+	connections[source] = dsn
+
+	bytes, err := json.Marshal(connections)
+	if err != nil {
+		return fmt.Errorf("error marshalling db connections: %v", err)
+	}
+
+	err = os.WriteFile(getDbConnectionsPath(), bytes, 0600)
+	if err != nil {
+		return fmt.Errorf("error writing db connections: %v", err)
+	}
+
+	return nil
+}
+
+// Db sources are stored with the plan, so anyone with access to it could point one at a host of their choosing. Like commands, they're only re-checked on machines where they were loaded with 'plandex load --db', tracked per project in the home plandex dir.
+func getApprovedDbsPath() string {
+	return filepath.Join(HomeCurrentProjectDir, approvedDbsFileName)
+}
+
+func loadApprovedDbs() (map[string]bool, error) {
+	approved := map[string]bool{}
+
+	bytes, err := os.ReadFile(getApprovedDbsPath())
+	if os.IsNotExist(err) {
+		return approved, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading approved dbs: %v", err)
+	}
+
+	var sources []string
+	err = json.Unmarshal(bytes, &sources)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling approved dbs: %v", err)
+	}
+
+	for _, source := range sources {
+		approved[source] = true
+	}
+
+	return approved, nil
+}
+
+func approveDb(source string) error {
+	approved, err := loadApprovedDbs()
+	if err != nil {
+		return err
+	}
+
+	if approved[source] {
+		return nil
+	}
+	approved[source] = true
+
+	var all []string
+	for s := range approved {
+		all = append(all, s)
+	}
+
+	bytes, err := json.Marshal(all)
+	if err != nil {
+		return fmt.Errorf("error marshalling approved dbs: %v", err)
+	}
+
+	err = os.WriteFile(getApprovedDbsPath(), bytes, 0600)
+	if err != nil {
+		return fmt.Errorf("error writing approved dbs: %v", err)
+	}
+
+	return nil
+}
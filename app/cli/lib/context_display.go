@@ -27,6 +27,24 @@ func GetContextLabelAndIcon(contextType shared.ContextType) (string, string) {
 	case shared.ContextMapType:
 		icon = "🗺️ "
 		lbl = "map"
+	case shared.ContextFileRangeType:
+		icon = "✂️ "
+		lbl = "range"
+	case shared.ContextGitDiffType:
+		icon = "🔀"
+		lbl = "git"
+	case shared.ContextDocumentType:
+		icon = "📑"
+		lbl = "doc"
+	case shared.ContextDocsType:
+		icon = "📚"
+		lbl = "docs"
+	case shared.ContextCommandType:
+		icon = "⚡️"
+		lbl = "cmd"
+	case shared.ContextDbSchemaType:
+		icon = "🗄️ "
+		lbl = "db"
 	}
 
 	return lbl, icon
@@ -0,0 +1,83 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/document"
+	"sync"
+
+	shared "plandex-shared"
+)
+
+// loadDocumentParams extracts the text of a pdf, docx, or odt file and splits it into context parts at page or section boundaries
+func loadDocumentParams(path string, content []byte, autoLoaded bool) ([]*shared.LoadContextParams, error) {
+	sections, err := document.Extract(path, content)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := document.Chunk(sections, document.MaxPartTokens)
+
+	var res []*shared.LoadContextParams
+	for i, part := range parts {
+		res = append(res, &shared.LoadContextParams{
+			ContextType: shared.ContextDocumentType,
+			Name:        documentPartName(path, i, len(parts)),
+			Body:        document.RenderPart(sections, part.Start, part.End),
+			FilePath:    path,
+			StartLine:   part.Start,
+			EndLine:     part.End,
+			AutoLoaded:  autoLoaded,
+		})
+	}
+
+	return res, nil
+}
+
+func documentPartName(path string, i, numParts int) string {
+	if numParts == 1 {
+		return path
+	}
+	return fmt.Sprintf("%s (part %d)", path, i+1)
+}
+
+// documentExtractions re-extracts each document once when checking context, since a long document is loaded as several parts
+type documentExtractions struct {
+	mu     sync.Mutex
+	byPath map[string]*documentExtraction
+}
+
+type documentExtraction struct {
+	once     sync.Once
+	sections []document.Section
+	exists   bool
+	err      error
+}
+
+func (d *documentExtractions) get(path string) ([]document.Section, bool, error) {
+	d.mu.Lock()
+	if d.byPath == nil {
+		d.byPath = map[string]*documentExtraction{}
+	}
+	extraction, ok := d.byPath[path]
+	if !ok {
+		extraction = &documentExtraction{}
+		d.byPath[path] = extraction
+	}
+	d.mu.Unlock()
+
+	extraction.once.Do(func() {
+		content, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			return
+		}
+		if err != nil {
+			extraction.err = fmt.Errorf("failed to read the file %s: %v", path, err)
+			return
+		}
+		extraction.exists = true
+		extraction.sections, extraction.err = document.Extract(path, content)
+	})
+
+	return extraction.sections, extraction.exists, extraction.err
+}
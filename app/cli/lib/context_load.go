@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"plandex-cli/api"
 	"plandex-cli/auth"
+	"plandex-cli/document"
 	"plandex-cli/fs"
 	"plandex-cli/term"
 	"plandex-cli/types"
@@ -90,10 +91,11 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 
 	var inputUrls []string
 	var inputFilePaths []string
+	var inputRanges []*fileRangeInput
 
 	if len(resources) > 0 {
 		for _, resource := range resources {
-			// so far resources are either files or urls
+			// resources are files, urls, or file ranges ('path#L10-20' or 'path::Symbol')
 			if url.IsValidURL(resource) {
 				inputUrls = append(inputUrls, resource)
 			} else {
@@ -101,11 +103,122 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 					resource = resource[2:]
 				}
 
+				// a file that happens to match the range syntax is still loaded as a file
+				if rangeInput, ok := parseFileRangeResource(resource); ok {
+					if _, err := os.Stat(resource); os.IsNotExist(err) {
+						if params.DefsOnly || params.NamesOnly {
+							onErr(fmt.Errorf("can't load %s as a map or tree - only whole files and directories are supported", resource))
+						}
+						inputRanges = append(inputRanges, rangeInput)
+						continue
+					}
+				}
+
 				inputFilePaths = append(inputFilePaths, resource)
 			}
 		}
 	}
 
+	var gitDiffReq []*shared.LoadContextParams
+	if params.GitDiff || params.GitCommit != "" || params.GitStaged {
+		if params.DefsOnly || params.NamesOnly {
+			onErr(fmt.Errorf("git diffs can't be loaded as maps or trees"))
+		}
+
+		type gitDiffSource struct {
+			mode shared.GitDiffMode
+			ref  string
+		}
+		var sources []gitDiffSource
+		if params.GitDiff {
+			sources = append(sources, gitDiffSource{shared.GitDiffModeDiff, params.GitDiffBase})
+		}
+		if params.GitCommit != "" {
+			sha, err := GitResolveCommit(params.GitCommit)
+			if err != nil {
+				onErr(err)
+			}
+			sources = append(sources, gitDiffSource{shared.GitDiffModeCommit, sha})
+		}
+		if params.GitStaged {
+			sources = append(sources, gitDiffSource{shared.GitDiffModeStaged, ""})
+		}
+
+		for _, source := range sources {
+			name := GitDiffContextName(source.mode, source.ref)
+
+			diff, touchedPaths, err := GitDiffForContext(source.mode, source.ref)
+			if err != nil {
+				onErr(err)
+			}
+			if diff == "" {
+				onErr(fmt.Errorf("no changes found for %s", name))
+			}
+			if int64(len(diff)) > shared.MaxContextBodySize {
+				onErr(fmt.Errorf("%s is too large to load (%d bytes)", name, len(diff)))
+			}
+
+			gitDiffReq = append(gitDiffReq, &shared.LoadContextParams{
+				ContextType: shared.ContextGitDiffType,
+				Name:        name,
+				Body:        diff,
+				GitDiffMode: source.mode,
+				GitRef:      source.ref,
+				AutoLoaded:  params.AutoLoaded,
+			})
+
+			// the files touched by the diff are loaded alongside it so the model sees them in full
+			inputFilePaths = append(inputFilePaths, touchedPaths...)
+		}
+	}
+
+	var docsReq []*shared.LoadContextParams
+	if params.Crawl != "" || params.Docs != "" {
+		if params.DefsOnly || params.NamesOnly {
+			onErr(fmt.Errorf("docs can't be loaded as maps or trees"))
+		}
+
+		bundle, err := resolveDocsBundle(params)
+		if err != nil {
+			onErr(err)
+		}
+		docsReq = bundle.loadParams(params.AutoLoaded)
+		term.StartSpinner("📥 Loading context...")
+	}
+
+	var commandReq []*shared.LoadContextParams
+	if len(params.Cmds) > 0 {
+		if params.DefsOnly || params.NamesOnly {
+			onErr(fmt.Errorf("commands can't be loaded as maps or trees"))
+		}
+		if !MustGetCurrentPlanConfig().CommandContext {
+			onErr(fmt.Errorf("command context is disabled for this plan - enable it with 'plandex set-config command-context true'"))
+		}
+
+		term.StartSpinner("⚡️ Running commands...")
+		var err error
+		commandReq, err = loadCommandParams(params.Cmds, params.CmdTimeout, params.AutoLoaded)
+		if err != nil {
+			onErr(err)
+		}
+		term.StartSpinner("📥 Loading context...")
+	}
+
+	var dbReq *shared.LoadContextParams
+	if params.Db != "" {
+		if params.DefsOnly || params.NamesOnly {
+			onErr(fmt.Errorf("database schemas can't be loaded as maps or trees"))
+		}
+
+		term.StartSpinner("🗄️  Reading database schema...")
+		var err error
+		dbReq, err = loadDbSchemaParams(params.Db, params.AutoLoaded)
+		if err != nil {
+			onErr(err)
+		}
+		term.StartSpinner("📥 Loading context...")
+	}
+
 	var contextMu sync.Mutex
 
 	errCh := make(chan error)
@@ -132,11 +245,55 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 	existsByComposite := make(map[string]*shared.Context)
 	for _, context := range existingContexts {
 		switch context.ContextType {
-		case shared.ContextFileType, shared.ContextDirectoryTreeType, shared.ContextMapType, shared.ContextImageType:
+		case shared.ContextFileType, shared.ContextDirectoryTreeType, shared.ContextMapType, shared.ContextImageType, shared.ContextDocumentType:
 			existsByComposite[strings.Join([]string{string(context.ContextType), context.FilePath}, "|")] = context
 		case shared.ContextURLType:
 			existsByComposite[strings.Join([]string{string(context.ContextType), context.Url}, "|")] = context
+		case shared.ContextFileRangeType, shared.ContextGitDiffType:
+			existsByComposite[strings.Join([]string{string(context.ContextType), context.Name}, "|")] = context
+		case shared.ContextDocsType:
+			existsByComposite[strings.Join([]string{string(context.ContextType), context.DocsBundle}, "|")] = context
+		case shared.ContextCommandType:
+			existsByComposite[strings.Join([]string{string(context.ContextType), context.Command}, "|")] = context
+		case shared.ContextDbSchemaType:
+			existsByComposite[strings.Join([]string{string(context.ContextType), context.DbSource}, "|")] = context
+		}
+	}
+
+	if len(docsReq) > 0 {
+		composite := strings.Join([]string{string(shared.ContextDocsType), docsReq[0].DocsBundle}, "|")
+		if existsByComposite[composite] != nil {
+			alreadyLoadedByComposite[composite] = existsByComposite[composite]
+		} else {
+			loadContextReq = append(loadContextReq, docsReq...)
+		}
+	}
+
+	for _, commandParams := range commandReq {
+		composite := strings.Join([]string{string(shared.ContextCommandType), commandParams.Command}, "|")
+		if existsByComposite[composite] != nil {
+			alreadyLoadedByComposite[composite] = existsByComposite[composite]
+			continue
+		}
+		loadContextReq = append(loadContextReq, commandParams)
+	}
+
+	if dbReq != nil {
+		composite := strings.Join([]string{string(shared.ContextDbSchemaType), dbReq.DbSource}, "|")
+		if existsByComposite[composite] != nil {
+			alreadyLoadedByComposite[composite] = existsByComposite[composite]
+		} else {
+			loadContextReq = append(loadContextReq, dbReq)
+		}
+	}
+
+	for _, gitParams := range gitDiffReq {
+		composite := strings.Join([]string{string(shared.ContextGitDiffType), gitParams.Name}, "|")
+		if existsByComposite[composite] != nil {
+			alreadyLoadedByComposite[composite] = existsByComposite[composite]
+			continue
 		}
+		loadContextReq = append(loadContextReq, gitParams)
 	}
 
 	var cachedMapPaths map[string]bool
@@ -358,7 +515,7 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 				if params.DefsOnly {
 					filtered := []string{}
 					for _, path := range flattenedPaths {
-						if shared.HasFileMapSupport(path) {
+						if hasFileMapSupport(path) {
 							numPaths++
 
 							if numPaths > shared.MaxContextMapPaths {
@@ -428,8 +585,11 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 
 					var contextType shared.ContextType
 					isImage := shared.IsImageFile(path)
+					isDocument := !params.DefsOnly && document.IsDocumentFile(path)
 					if isImage {
 						contextType = shared.ContextImageType
+					} else if isDocument {
+						contextType = shared.ContextDocumentType
 					} else if params.DefsOnly {
 						contextType = shared.ContextMapType
 					} else {
@@ -526,6 +686,23 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 								ImageDetail: params.ImageDetail,
 								AutoLoaded:  params.AutoLoaded,
 							})
+						} else if isDocument {
+							fileContent, err := os.ReadFile(path)
+							if err != nil {
+								errCh <- fmt.Errorf("failed to read the file %s: %v", path, err)
+								return
+							}
+
+							documentParams, err := loadDocumentParams(path, fileContent, params.AutoLoaded)
+							if err != nil {
+								errCh <- err
+								return
+							}
+
+							contextMu.Lock()
+							defer contextMu.Unlock()
+
+							loadContextReq = append(loadContextReq, documentParams...)
 						} else {
 							fileContent, err := os.ReadFile(path)
 							if err != nil {
@@ -616,6 +793,51 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 		}
 	}
 
+	if len(inputRanges) > 0 {
+		if !params.ForceSkipIgnore {
+			var rangePaths []string
+			for _, input := range inputRanges {
+				rangePaths = append(rangePaths, input.Path)
+			}
+			baseDir := fs.GetBaseDirForFilePaths(rangePaths)
+			paths, err := fs.GetProjectPaths(baseDir)
+			if err != nil {
+				onErr(fmt.Errorf("failed to get project paths: %v", err))
+			}
+
+			var filteredRanges []*fileRangeInput
+			for _, input := range inputRanges {
+				if _, ok := paths.ActivePaths[input.Path]; !ok {
+					ignored, reason, err := fs.IsIgnored(paths, input.Path, baseDir)
+					if err != nil {
+						onErr(fmt.Errorf("failed to check if %s is ignored: %v", input.Path, err))
+					}
+					if ignored {
+						ignoredPaths[input.Path] = reason
+						continue
+					}
+				}
+				filteredRanges = append(filteredRanges, input)
+			}
+			inputRanges = filteredRanges
+		}
+
+		rangeParams, err := loadFileRanges(inputRanges, params.AutoLoaded)
+		if err != nil {
+			onErr(err)
+		}
+
+		for _, rangeParam := range rangeParams {
+			composite := strings.Join([]string{string(shared.ContextFileRangeType), rangeParam.Name}, "|")
+			if existsByComposite[composite] != nil {
+				alreadyLoadedByComposite[composite] = existsByComposite[composite]
+				continue
+			}
+			rangeParam.ForceSkipIgnore = params.ForceSkipIgnore
+			loadContextReq = append(loadContextReq, rangeParam)
+		}
+	}
+
 	if params.DefsOnly {
 		allMapBodies, err := processMapBatches(mapInputBatches)
 		if err != nil {
@@ -664,7 +886,7 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 
 	filesToLoad := map[string]string{}
 	for _, context := range loadContextReq {
-		if context.ContextType == shared.ContextFileType {
+		if context.ContextType == shared.ContextFileType || context.ContextType == shared.ContextFileRangeType {
 			filesToLoad[context.FilePath] = context.Body
 		}
 	}
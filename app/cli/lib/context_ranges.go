@@ -0,0 +1,312 @@
+package lib
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"plandex-cli/api"
+	"regexp"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+// past this many changed lines, range tracking gives up on a line diff and keeps the range's position relative to the unchanged start and end of the file
+const maxRangeDiffEdits = 2000
+
+var lineRangeResourceRegex = regexp.MustCompile(`^(.+)#L(\d+)(?:-L?(\d+))?$`)
+var symbolResourceRegex = regexp.MustCompile(`^(.+)::([A-Za-z_$][\w$-]*(?:\.[A-Za-z_$][\w$-]*)*)$`)
+
+type fileRangeInput struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	Symbol    string
+}
+
+// parseFileRangeResource parses 'path#L120-240', 'path#L120', and 'path::Symbol' load arguments
+func parseFileRangeResource(resource string) (*fileRangeInput, bool) {
+	if m := lineRangeResourceRegex.FindStringSubmatch(resource); m != nil {
+		start, _ := strconv.Atoi(m[2])
+		end := start
+		if m[3] != "" {
+			end, _ = strconv.Atoi(m[3])
+		}
+		return &fileRangeInput{Path: m[1], StartLine: start, EndLine: end}, true
+	}
+
+	if m := symbolResourceRegex.FindStringSubmatch(resource); m != nil {
+		return &fileRangeInput{Path: m[1], Symbol: m[2]}, true
+	}
+
+	return nil, false
+}
+
+// loadFileRanges reads the files for range inputs and resolves symbols to line ranges. The full file is sent as the body so builds can apply changes anywhere in it. Auto-loaded ranges come from the model's response, so symbols that aren't found are skipped instead of failing the load.
+func loadFileRanges(inputs []*fileRangeInput, autoLoaded bool) ([]*shared.LoadContextParams, error) {
+	bodies := map[string]string{}
+	var lookups []*shared.FileSymbolLookup
+
+	for _, input := range inputs {
+		if _, ok := bodies[input.Path]; ok {
+			continue
+		}
+
+		fileInfo, err := os.Stat(input.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %v", input.Path, err)
+		}
+		if fileInfo.IsDir() {
+			return nil, fmt.Errorf("%s is a directory - ranges can only be loaded from files", input.Path)
+		}
+		if fileInfo.Size() > shared.MaxContextBodySize {
+			return nil, fmt.Errorf("%s is too large to load (%d bytes)", input.Path, fileInfo.Size())
+		}
+
+		content, err := os.ReadFile(input.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", input.Path, err)
+		}
+		bodies[input.Path] = string(shared.NormalizeEOL(content))
+	}
+
+	for _, input := range inputs {
+		if input.Symbol != "" {
+			lookups = append(lookups, &shared.FileSymbolLookup{Path: input.Path, Symbol: input.Symbol})
+		}
+	}
+
+	var ranges []*shared.FileChunk
+	if len(lookups) > 0 {
+		var err error
+		ranges, err = resolveSymbolRanges(lookups, bodies)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var res []*shared.LoadContextParams
+	i := 0
+	for _, input := range inputs {
+		body := bodies[input.Path]
+		numLines := strings.Count(body, "\n") + 1
+
+		start, end := input.StartLine, input.EndLine
+		if input.Symbol != "" {
+			r := ranges[i]
+			i++
+			if r == nil {
+				if autoLoaded {
+					log.Printf("Skipping auto-loaded symbol %s - not found in %s", input.Symbol, input.Path)
+					continue
+				}
+				return nil, fmt.Errorf("symbol %s not found in %s", input.Symbol, input.Path)
+			}
+			start, end = r.StartLine, r.EndLine
+		} else {
+			if start < 1 || end < start {
+				return nil, fmt.Errorf("invalid line range %d-%d for %s", start, end, input.Path)
+			}
+			if start > numLines {
+				return nil, fmt.Errorf("line range %d-%d is past the end of %s (%d lines)", start, end, input.Path, numLines)
+			}
+			end = min(end, numLines)
+		}
+
+		res = append(res, &shared.LoadContextParams{
+			ContextType: shared.ContextFileRangeType,
+			Name:        shared.FileRangeContextName(input.Path, start, end, input.Symbol),
+			FilePath:    input.Path,
+			Body:        body,
+			StartLine:   start,
+			EndLine:     end,
+			Symbol:      input.Symbol,
+			AutoLoaded:  autoLoaded,
+		})
+	}
+
+	return res, nil
+}
+
+func resolveSymbolRanges(lookups []*shared.FileSymbolLookup, bodies map[string]string) ([]*shared.FileChunk, error) {
+	var ranges []*shared.FileChunk
+
+	for i := 0; i < len(lookups); i += shared.ContextMapMaxBatchSize {
+		batch := lookups[i:min(i+shared.ContextMapMaxBatchSize, len(lookups))]
+
+		inputs := shared.FileMapInputs{}
+		for _, lookup := range batch {
+			inputs[lookup.Path] = bodies[lookup.Path]
+		}
+
+		res, apiErr := api.Client.GetFileSymbols(shared.GetFileSymbolsRequest{
+			SymbolInputs: inputs,
+			Lookups:      batch,
+		})
+		if apiErr != nil {
+			return nil, fmt.Errorf("failed to look up symbols: %v", apiErr.Msg)
+		}
+		if len(res.Ranges) != len(batch) {
+			return nil, fmt.Errorf("unexpected number of symbol ranges: got %d, expected %d", len(res.Ranges), len(batch))
+		}
+
+		ranges = append(ranges, res.Ranges...)
+	}
+
+	return ranges, nil
+}
+
+// trackFileRange finds where a range context's lines are in the file's new content. Symbols are looked up again, falling back to following the old range through a line diff if the symbol is gone. Returns removed if every line in the range was deleted.
+func trackFileRange(ctx *shared.Context, newContent string) (start, end int, removed bool, err error) {
+	if ctx.Symbol != "" {
+		ranges, err := resolveSymbolRanges([]*shared.FileSymbolLookup{{Path: ctx.FilePath, Symbol: ctx.Symbol}}, map[string]string{ctx.FilePath: newContent})
+		if err != nil {
+			return 0, 0, false, err
+		}
+		if ranges[0] != nil {
+			return ranges[0].StartLine, ranges[0].EndLine, false, nil
+		}
+	}
+
+	// list results don't include bodies, so get the previous version of the file from the server
+	res, apiErr := api.Client.GetContextBody(CurrentPlanId, CurrentBranch, ctx.Id)
+	if apiErr != nil {
+		return 0, 0, false, fmt.Errorf("failed to get context body for %s: %v", ctx.Name, apiErr.Msg)
+	}
+
+	start, end, ok := shiftLineRange(strings.Split(res.Body, "\n"), strings.Split(newContent, "\n"), ctx.StartLine, ctx.EndLine)
+	return start, end, !ok, nil
+}
+
+// shiftLineRange maps a 1-based inclusive line range from old to new. Lines that are unchanged keep their place in the range, lines inserted or changed inside the range are included, and lines inserted directly above or below it are left out.
+func shiftLineRange(oldLines, newLines []string, start, end int) (int, int, bool) {
+	matches := matchLines(oldLines, newLines)
+
+	var newStart, newEnd int
+
+	if n, ok := matches[start-1]; ok {
+		newStart = n + 1
+	} else {
+		// line after the closest unchanged line above the range
+		newStart = 1
+		for i := start - 2; i >= 0; i-- {
+			if n, ok := matches[i]; ok {
+				newStart = n + 2
+				break
+			}
+		}
+	}
+
+	if n, ok := matches[end-1]; ok {
+		newEnd = n + 1
+	} else {
+		// line before the closest unchanged line below the range
+		newEnd = len(newLines)
+		for i := end; i < len(oldLines); i++ {
+			if n, ok := matches[i]; ok {
+				newEnd = n
+				break
+			}
+		}
+	}
+
+	if newEnd < newStart {
+		return 0, 0, false
+	}
+	return newStart, newEnd, true
+}
+
+// matchLines maps the 0-based index of each unchanged line in a to its index in b, using a Myers diff between the common prefix and suffix
+func matchLines(a, b []string) map[int]int {
+	matches := map[int]int{}
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		matches[prefix] = prefix
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		matches[len(a)-1-suffix] = len(b) - 1 - suffix
+		suffix++
+	}
+
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+
+	for i, j := range myersMatches(midA, midB) {
+		matches[prefix+i] = prefix + j
+	}
+
+	return matches
+}
+
+func myersMatches(a, b []string) map[int]int {
+	matches := map[int]int{}
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return matches
+	}
+
+	maxD := min(n+m, maxRangeDiffEdits)
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	var trace [][]int
+
+	x, y := 0, 0
+	found := false
+
+	for d := 0; d <= maxD && !found; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y = x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	if !found {
+		return matches
+	}
+
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && prev[offset+k-1] < prev[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := prev[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			matches[x] = y
+		}
+		x, y = prevX, prevY
+	}
+
+	for x > 0 && y > 0 {
+		x--
+		y--
+		matches[x] = y
+	}
+
+	return matches
+}
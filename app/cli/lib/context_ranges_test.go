@@ -0,0 +1,156 @@
+package lib
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatchLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want map[int]int
+	}{
+		{
+			name: "unchanged",
+			a:    "a b c",
+			b:    "a b c",
+			want: map[int]int{0: 0, 1: 1, 2: 2},
+		},
+		{
+			name: "insert",
+			a:    "a b c",
+			b:    "a x y b c",
+			want: map[int]int{0: 0, 1: 3, 2: 4},
+		},
+		{
+			name: "delete",
+			a:    "a b c d",
+			b:    "a d",
+			want: map[int]int{0: 0, 3: 1},
+		},
+		{
+			name: "replace",
+			a:    "a b c d",
+			b:    "a x y d",
+			want: map[int]int{0: 0, 3: 3},
+		},
+		{
+			name: "insert and delete in the middle",
+			a:    "a b c d e f",
+			b:    "a c d x e g f",
+			want: map[int]int{0: 0, 2: 1, 3: 2, 4: 4, 5: 6},
+		},
+		{
+			name: "everything replaced",
+			a:    "a b",
+			b:    "x y z",
+			want: map[int]int{},
+		},
+		{
+			name: "empty old",
+			a:    "",
+			b:    "a b",
+			want: map[int]int{},
+		},
+		{
+			name: "repeated lines",
+			a:    "} } }",
+			b:    "} x } }",
+			want: map[int]int{0: 0, 1: 2, 2: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchLines(strings.Fields(tt.a), strings.Fields(tt.b))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matchLines(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShiftLineRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		old       string
+		new       string
+		start     int
+		end       int
+		wantStart int
+		wantEnd   int
+		wantOk    bool
+	}{
+		{
+			name:  "unchanged",
+			old:   "a b c d",
+			new:   "a b c d",
+			start: 2, end: 3,
+			wantStart: 2, wantEnd: 3, wantOk: true,
+		},
+		{
+			name:  "lines inserted above",
+			old:   "a b c d",
+			new:   "x y a b c d",
+			start: 2, end: 3,
+			wantStart: 4, wantEnd: 5, wantOk: true,
+		},
+		{
+			name:  "lines inserted inside",
+			old:   "a b c d",
+			new:   "a b x c d",
+			start: 2, end: 3,
+			wantStart: 2, wantEnd: 4, wantOk: true,
+		},
+		{
+			name:  "lines inserted directly below are left out",
+			old:   "a b c d",
+			new:   "a b c x d",
+			start: 2, end: 3,
+			wantStart: 2, wantEnd: 3, wantOk: true,
+		},
+		{
+			name:  "lines deleted above",
+			old:   "a b c d e",
+			new:   "a d e",
+			start: 4, end: 5,
+			wantStart: 2, wantEnd: 3, wantOk: true,
+		},
+		{
+			name:  "first line of range replaced",
+			old:   "a b c d",
+			new:   "a x c d",
+			start: 2, end: 3,
+			wantStart: 2, wantEnd: 3, wantOk: true,
+		},
+		{
+			name:  "whole range replaced",
+			old:   "a b c d",
+			new:   "a x y z d",
+			start: 2, end: 3,
+			wantStart: 2, wantEnd: 4, wantOk: true,
+		},
+		{
+			name:  "whole range deleted",
+			old:   "a b c d",
+			new:   "a d",
+			start: 2, end: 3,
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok := shiftLineRange(strings.Fields(tt.old), strings.Fields(tt.new), tt.start, tt.end)
+			if ok != tt.wantOk {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOk)
+			}
+			if ok && (start != tt.wantStart || end != tt.wantEnd) {
+				t.Errorf("got %d-%d, want %d-%d", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
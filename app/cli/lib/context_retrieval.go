@@ -0,0 +1,475 @@
+package lib
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"plandex-cli/api"
+	"plandex-cli/fs"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	shared "plandex-shared"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	retrievalIndexDirName     = "retrieval-index"
+	legacyRetrievalIndexFile  = "retrieval-index.json"
+	retrievalMaxResults       = 20
+	retrievalEmbedBatchSize   = 64
+	retrievalMaxChunkChars    = 6000
+	retrievalEmbedTimeout     = 2 * time.Minute
+	retrievalMaxChunksPerFile = 200
+)
+
+// the index is a dir in the project's .plandex dir with one json entry per indexed file, named by a hash of the file's content (plus the path and embeddings model, since both affect the vectors). unchanged files map to existing entries, so an update only writes entries for files that were added or changed.
+type retrievalIndex struct {
+	Files map[string]*retrievalIndexedFile
+}
+
+type retrievalIndexedFile struct {
+	Path   string                   `json:"path"`
+	Chunks []*retrievalIndexedChunk `json:"chunks"`
+}
+
+type retrievalIndexedChunk struct {
+	shared.FileChunk
+	Vector []float32 `json:"vector"`
+}
+
+type RetrievalIndexUpdateResult struct {
+	NumFiles   int
+	NumChunks  int
+	NumUpdated int
+	NumRemoved int
+}
+
+func getRetrievalIndexDir() string {
+	return filepath.Join(fs.PlandexDir, retrievalIndexDirName)
+}
+
+func getRetrievalEntryPath(key string) string {
+	return filepath.Join(getRetrievalIndexDir(), key+".json")
+}
+
+// the OpenAI key is only sent to OpenAI. any other endpoint gets PLANDEX_EMBEDDINGS_API_KEY or no key at all.
+func getEmbeddingsApiKey(baseUrl string) string {
+	if key := os.Getenv("PLANDEX_EMBEDDINGS_API_KEY"); key != "" {
+		return key
+	}
+	if baseUrl == shared.DefaultEmbeddingsBaseUrl {
+		return os.Getenv(shared.OpenAIEnvVar)
+	}
+	return ""
+}
+
+// vectors from a different model or endpoint can't be compared, so those are part of the key and a change re-embeds everything
+func getRetrievalEntryKey(config *shared.PlanConfig, path string, content []byte) string {
+	h := sha256.New()
+	for _, s := range []string{config.GetEmbeddingsBaseUrl(), config.GetEmbeddingsModel(), path} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// loadRetrievalEntry returns nil if there's no entry for key. a corrupt entry is treated as missing so the file gets re-embedded.
+func loadRetrievalEntry(key string) *retrievalIndexedFile {
+	bytes, err := os.ReadFile(getRetrievalEntryPath(key))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("error reading retrieval index entry %s: %v", key, err)
+		}
+		return nil
+	}
+
+	var file retrievalIndexedFile
+	err = json.Unmarshal(bytes, &file)
+	if err != nil {
+		log.Printf("error unmarshalling retrieval index entry %s, rebuilding: %v", key, err)
+		return nil
+	}
+
+	return &file
+}
+
+func (file *retrievalIndexedFile) save(key string) error {
+	bytes, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("error marshalling retrieval index entry: %v", err)
+	}
+
+	// written to a temp file and renamed so an interrupted write can't leave a truncated entry behind
+	path := getRetrievalEntryPath(key)
+	tmpPath := path + ".tmp"
+	err = os.WriteFile(tmpPath, bytes, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing retrieval index entry: %v", err)
+	}
+
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		return fmt.Errorf("error writing retrieval index entry: %v", err)
+	}
+
+	return nil
+}
+
+// pruneRetrievalEntries removes entries that aren't in keep (files that changed, were removed, or were indexed with a different model) and returns how many were removed
+func pruneRetrievalEntries(keep map[string]bool) (int, error) {
+	entries, err := os.ReadDir(getRetrievalIndexDir())
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("error reading retrieval index: %v", err)
+	}
+
+	var numRemoved int
+	for _, entry := range entries {
+		name := entry.Name()
+		key := strings.TrimSuffix(name, ".json")
+		if entry.IsDir() || keep[key] {
+			continue
+		}
+		err := os.Remove(filepath.Join(getRetrievalIndexDir(), name))
+		if err != nil && !os.IsNotExist(err) {
+			return numRemoved, fmt.Errorf("error removing retrieval index entry: %v", err)
+		}
+		if strings.HasSuffix(name, ".json") {
+			numRemoved++
+		}
+	}
+
+	return numRemoved, nil
+}
+
+// ClearRetrievalIndex removes the project's retrieval index so the next update rebuilds it
+func ClearRetrievalIndex() error {
+	err := os.RemoveAll(getRetrievalIndexDir())
+	if err != nil {
+		return fmt.Errorf("error removing retrieval index: %v", err)
+	}
+
+	err = os.Remove(filepath.Join(fs.PlandexDir, legacyRetrievalIndexFile))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing retrieval index: %v", err)
+	}
+
+	return nil
+}
+
+// UpdateRetrievalIndex brings the project's retrieval index up to date with paths. Only files that were added or changed since the last update are chunked, embedded, and written, and entries for files that are no longer in paths are dropped.
+func UpdateRetrievalIndex(paths map[string]bool) (*RetrievalIndexUpdateResult, error) {
+	_, res, err := updateRetrievalIndex(MustGetCurrentPlanConfig(), paths)
+	return res, err
+}
+
+func updateRetrievalIndex(config *shared.PlanConfig, paths map[string]bool) (*retrievalIndex, *RetrievalIndexUpdateResult, error) {
+	err := os.MkdirAll(getRetrievalIndexDir(), 0755)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating retrieval index dir: %v", err)
+	}
+
+	// the single-file index used before entries were split out is never read, so drop it
+	os.Remove(filepath.Join(fs.PlandexDir, legacyRetrievalIndexFile))
+
+	index := &retrievalIndex{Files: map[string]*retrievalIndexedFile{}}
+	res := &RetrievalIndexUpdateResult{}
+
+	keysByPath := map[string]string{}
+	contentsByPath := map[string]string{}
+	for path := range paths {
+		if shared.IsImageFile(path) {
+			continue
+		}
+
+		b, err := os.ReadFile(filepath.Join(fs.ProjectRoot, path))
+		if err != nil {
+			log.Printf("error reading %s for retrieval index: %v", path, err)
+			continue
+		}
+
+		if len(b) == 0 || len(b) > shared.MaxContextMapSingleInputSize || !utf8.Valid(b) {
+			continue
+		}
+
+		key := getRetrievalEntryKey(config, path, b)
+		keysByPath[path] = key
+
+		if existing := loadRetrievalEntry(key); existing != nil {
+			index.Files[path] = existing
+			continue
+		}
+
+		contentsByPath[path] = string(b)
+	}
+
+	if len(contentsByPath) > 0 {
+		chunksByPath, err := getRetrievalChunks(contentsByPath)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var toEmbed []*retrievalIndexedChunk
+		var texts []string
+		updated := map[string]*retrievalIndexedFile{}
+		for path := range contentsByPath {
+			// files the server couldn't chunk are saved without chunks so they aren't retried until they change
+			indexed := &retrievalIndexedFile{Path: path}
+
+			chunks := chunksByPath[path]
+			if len(chunks) > retrievalMaxChunksPerFile {
+				chunks = chunks[:retrievalMaxChunksPerFile]
+			}
+
+			lines := strings.Split(contentsByPath[path], "\n")
+			for _, chunk := range chunks {
+				indexedChunk := &retrievalIndexedChunk{FileChunk: *chunk}
+				indexed.Chunks = append(indexed.Chunks, indexedChunk)
+				toEmbed = append(toEmbed, indexedChunk)
+				texts = append(texts, getRetrievalChunkText(path, chunk, lines))
+			}
+			updated[path] = indexed
+		}
+
+		saveUpdated := func() error {
+			for path, file := range updated {
+				if !file.isEmbedded() {
+					continue
+				}
+				err := file.save(keysByPath[path])
+				if err != nil {
+					return err
+				}
+				index.Files[path] = file
+				res.NumUpdated++
+				delete(updated, path)
+			}
+			return nil
+		}
+
+		for i := 0; i < len(texts); i += retrievalEmbedBatchSize {
+			end := min(i+retrievalEmbedBatchSize, len(texts))
+			vectors, err := embedTexts(config, texts[i:end])
+			if err != nil {
+				// save what's been embedded so far so a retry picks up where this left off
+				saveErr := saveUpdated()
+				if saveErr != nil {
+					log.Printf("error saving partial retrieval index: %v", saveErr)
+				}
+				return nil, nil, err
+			}
+			for j, vector := range vectors {
+				toEmbed[i+j].Vector = vector
+			}
+		}
+
+		err = saveUpdated()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	keep := map[string]bool{}
+	for _, key := range keysByPath {
+		keep[key] = true
+	}
+	res.NumRemoved, err = pruneRetrievalEntries(keep)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res.NumFiles = len(index.Files)
+	for _, file := range index.Files {
+		res.NumChunks += len(file.Chunks)
+	}
+
+	return index, res, nil
+}
+
+func (file *retrievalIndexedFile) isEmbedded() bool {
+	for _, chunk := range file.Chunks {
+		if chunk.Vector == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// RetrieveChunks updates the retrieval index, then returns the indexed chunks most similar to the prompt
+func RetrieveChunks(prompt string, paths map[string]bool) ([]*shared.RetrievedChunk, error) {
+	config := MustGetCurrentPlanConfig()
+
+	index, _, err := updateRetrievalIndex(config, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(index.Files) == 0 {
+		return nil, nil
+	}
+
+	vectors, err := embedTexts(config, []string{truncateRetrievalText(prompt)})
+	if err != nil {
+		return nil, err
+	}
+	query := vectors[0]
+
+	var res []*shared.RetrievedChunk
+	for path, file := range index.Files {
+		for _, chunk := range file.Chunks {
+			if len(chunk.Vector) != len(query) {
+				continue
+			}
+			res = append(res, &shared.RetrievedChunk{
+				Path:      path,
+				StartLine: chunk.StartLine,
+				EndLine:   chunk.EndLine,
+				Symbol:    chunk.Symbol,
+				Score:     cosineSimilarity(query, chunk.Vector),
+			})
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Score > res[j].Score
+	})
+
+	if len(res) > retrievalMaxResults {
+		res = res[:retrievalMaxResults]
+	}
+
+	return res, nil
+}
+
+func getRetrievalChunks(contentsByPath map[string]string) (map[string][]*shared.FileChunk, error) {
+	res := map[string][]*shared.FileChunk{}
+
+	batch := shared.FileMapInputs{}
+	var batchSize int64
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		chunksRes, apiErr := api.Client.GetFileChunks(shared.GetFileChunksRequest{ChunkInputs: batch})
+		if apiErr != nil {
+			return fmt.Errorf("error chunking files: %v", apiErr.Msg)
+		}
+		for path, chunks := range chunksRes.ChunksByPath {
+			res[path] = chunks
+		}
+		batch = shared.FileMapInputs{}
+		batchSize = 0
+		return nil
+	}
+
+	for path, content := range contentsByPath {
+		if len(batch) >= shared.ContextMapMaxBatchSize || batchSize+int64(len(content)) > shared.ContextMapMaxBatchBytes {
+			err := flush()
+			if err != nil {
+				return nil, err
+			}
+		}
+		batch[path] = content
+		batchSize += int64(len(content))
+	}
+
+	err := flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// the path and symbol are embedded with the code so file and function names count towards similarity
+func getRetrievalChunkText(path string, chunk *shared.FileChunk, lines []string) string {
+	start := max(chunk.StartLine-1, 0)
+	end := min(chunk.EndLine, len(lines))
+
+	var b strings.Builder
+	b.WriteString(path + "\n")
+	if chunk.Symbol != "" {
+		b.WriteString(chunk.Symbol + "\n")
+	}
+	if start < end {
+		b.WriteString(strings.Join(lines[start:end], "\n"))
+	}
+
+	return truncateRetrievalText(b.String())
+}
+
+func truncateRetrievalText(s string) string {
+	if len(s) <= retrievalMaxChunkChars {
+		return s
+	}
+	s = s[:retrievalMaxChunkChars]
+	// don't cut a multi-byte character in half
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+func embedTexts(config *shared.PlanConfig, texts []string) ([][]float32, error) {
+	baseUrl := config.GetEmbeddingsBaseUrl()
+	apiKey := getEmbeddingsApiKey(baseUrl)
+
+	if apiKey == "" && baseUrl == shared.DefaultEmbeddingsBaseUrl {
+		return nil, fmt.Errorf("retrieval requires PLANDEX_EMBEDDINGS_API_KEY or OPENAI_API_KEY to be set")
+	}
+
+	clientConfig := openai.DefaultConfig(apiKey)
+	clientConfig.BaseURL = baseUrl
+	client := openai.NewClientWithConfig(clientConfig)
+
+	ctx, cancel := context.WithTimeout(context.Background(), retrievalEmbedTimeout)
+	defer cancel()
+
+	resp, err := client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
+		Input: texts,
+		Model: openai.EmbeddingModel(config.GetEmbeddingsModel()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting embeddings from %s: %v", baseUrl, err)
+	}
+
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings from %s, got %d", len(texts), baseUrl, len(resp.Data))
+	}
+
+	res := make([][]float32, len(texts))
+	for _, data := range resp.Data {
+		if data.Index < 0 || data.Index >= len(texts) {
+			return nil, fmt.Errorf("invalid embedding index %d from %s", data.Index, baseUrl)
+		}
+		res[data.Index] = data.Embedding
+	}
+
+	return res, nil
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
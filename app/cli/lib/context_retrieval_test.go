@@ -0,0 +1,138 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"plandex-cli/fs"
+	"reflect"
+	"sort"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestGetRetrievalEntryKey(t *testing.T) {
+	config := &shared.PlanConfig{}
+	otherModel := &shared.PlanConfig{EmbeddingsModel: "other-embedding-model"}
+	base := getRetrievalEntryKey(config, "main.go", []byte("package main"))
+
+	tests := []struct {
+		name     string
+		config   *shared.PlanConfig
+		path     string
+		content  string
+		wantSame bool
+	}{
+		{name: "unchanged", config: config, path: "main.go", content: "package main", wantSame: true},
+		{name: "content changed", config: config, path: "main.go", content: "package main\n", wantSame: false},
+		{name: "path changed", config: config, path: "cmd/main.go", content: "package main", wantSame: false},
+		{name: "model changed", config: otherModel, path: "main.go", content: "package main", wantSame: false},
+		{name: "path and content aren't ambiguous", config: config, path: "main.gopackage", content: " main", wantSame: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := getRetrievalEntryKey(tt.config, tt.path, []byte(tt.content))
+			if (key == base) != tt.wantSame {
+				t.Errorf("key == base is %v, want %v", key == base, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestGetEmbeddingsApiKey(t *testing.T) {
+	const localUrl = "http://localhost:11434/v1"
+
+	tests := []struct {
+		name          string
+		baseUrl       string
+		embeddingsKey string
+		openAIKey     string
+		want          string
+	}{
+		{name: "openai falls back to the openai key", baseUrl: shared.DefaultEmbeddingsBaseUrl, openAIKey: "sk-openai", want: "sk-openai"},
+		{name: "embeddings key wins for openai", baseUrl: shared.DefaultEmbeddingsBaseUrl, embeddingsKey: "sk-embed", openAIKey: "sk-openai", want: "sk-embed"},
+		{name: "other endpoint doesn't get the openai key", baseUrl: localUrl, openAIKey: "sk-openai", want: ""},
+		{name: "other endpoint uses the embeddings key", baseUrl: localUrl, embeddingsKey: "sk-embed", openAIKey: "sk-openai", want: "sk-embed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PLANDEX_EMBEDDINGS_API_KEY", tt.embeddingsKey)
+			t.Setenv(shared.OpenAIEnvVar, tt.openAIKey)
+
+			if got := getEmbeddingsApiKey(tt.baseUrl); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetrievalEntries(t *testing.T) {
+	origPlandexDir := fs.PlandexDir
+	fs.PlandexDir = t.TempDir()
+	defer func() { fs.PlandexDir = origPlandexDir }()
+
+	dir := getRetrievalIndexDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &retrievalIndexedFile{
+		Path: "main.go",
+		Chunks: []*retrievalIndexedChunk{
+			{FileChunk: shared.FileChunk{StartLine: 1, EndLine: 3, Symbol: "main"}, Vector: []float32{0.5, -1}},
+		},
+	}
+
+	for _, key := range []string{"keep", "changed", "removed"} {
+		if err := file.save(key); err != nil {
+			t.Fatalf("save %s: %v", key, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "corrupt.json"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// left behind by an interrupted save
+	if err := os.WriteFile(filepath.Join(dir, "partial.json.tmp"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key  string
+		want *retrievalIndexedFile
+	}{
+		{key: "keep", want: file},
+		{key: "missing", want: nil},
+		{key: "corrupt", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := loadRetrievalEntry(tt.key); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	numRemoved, err := pruneRetrievalEntries(map[string]bool{"keep": true})
+	if err != nil {
+		t.Fatalf("pruneRetrievalEntries: %v", err)
+	}
+	if numRemoved != 3 {
+		t.Errorf("expected 3 entries removed, got %d", numRemoved)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	if !reflect.DeepEqual(names, []string{"keep.json"}) {
+		t.Errorf("remaining entries = %v, want [keep.json]", names)
+	}
+}
@@ -0,0 +1,329 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plandex-cli/api"
+	"plandex-cli/fs"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"regexp"
+	"sort"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+func ListContextSets() ([]*shared.ContextSet, error) {
+	sets, apiErr := api.Client.ListContextSets(CurrentProjectId)
+	if apiErr != nil {
+		return nil, fmt.Errorf("error listing context sets: %v", apiErr.Msg)
+	}
+	return sets, nil
+}
+
+func GetContextSet(name string) (*shared.ContextSet, error) {
+	sets, err := ListContextSets()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, set := range sets {
+		if set.Name == name {
+			return set, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no context set named '%s' in this project", name)
+}
+
+// ContextSetFromPlan builds a set from the current plan's files, maps, notes, and urls. Other kinds of context (ranges, diffs, docs, etc.) aren't saved - the number skipped is returned.
+func ContextSetFromPlan(name string) (*shared.ContextSet, int, error) {
+	set := &shared.ContextSet{Name: name}
+
+	contexts, apiErr := api.Client.ListContext(CurrentPlanId, CurrentBranch)
+	if apiErr != nil {
+		return nil, 0, fmt.Errorf("error listing context: %v", apiErr.Msg)
+	}
+
+	numSkipped := 0
+	for _, context := range contexts {
+		switch context.ContextType {
+		case shared.ContextFileType:
+			path, err := toProjectPath(context.FilePath)
+			if err != nil {
+				return nil, 0, err
+			}
+			set.Paths = append(set.Paths, path)
+		case shared.ContextMapType:
+			path, err := toProjectPath(context.FilePath)
+			if err != nil {
+				return nil, 0, err
+			}
+			set.Maps = append(set.Maps, path)
+		case shared.ContextURLType:
+			set.Urls = append(set.Urls, context.Url)
+		case shared.ContextNoteType:
+			// the list doesn't include bodies
+			res, apiErr := api.Client.GetContextBody(CurrentPlanId, CurrentBranch, context.Id)
+			if apiErr != nil {
+				return nil, 0, fmt.Errorf("error getting note body: %v", apiErr.Msg)
+			}
+			set.Notes = append(set.Notes, res.Body)
+		default:
+			numSkipped++
+		}
+	}
+
+	return set, numSkipped, nil
+}
+
+// AddContextSetPaths adds files, directories, or globs given relative to the current directory, storing them relative to the project root
+func AddContextSetPaths(set *shared.ContextSet, patterns []string) error {
+	for _, pattern := range patterns {
+		path, err := toProjectPath(pattern)
+		if err != nil {
+			return err
+		}
+		if !contextSetHasPath(set, path) {
+			set.Paths = append(set.Paths, path)
+		}
+	}
+	return nil
+}
+
+func contextSetHasPath(set *shared.ContextSet, path string) bool {
+	for _, p := range set.Paths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+func SaveContextSet(set *shared.ContextSet) error {
+	err := set.Validate()
+	if err != nil {
+		return err
+	}
+
+	apiErr := api.Client.UpsertContextSet(CurrentProjectId, shared.UpsertContextSetRequest{ContextSet: set})
+	if apiErr != nil {
+		return fmt.Errorf("error saving context set: %v", apiErr.Msg)
+	}
+
+	return nil
+}
+
+func DeleteContextSet(name string) error {
+	apiErr := api.Client.DeleteContextSet(CurrentProjectId, name)
+	if apiErr != nil {
+		return fmt.Errorf("error removing context set: %v", apiErr.Msg)
+	}
+	return nil
+}
+
+type ResolvedContextSet struct {
+	// files and directories relative to the current directory
+	Paths []string
+	Maps  []string
+	// patterns that don't match anything in the current tree
+	Unmatched []string
+}
+
+// ResolveContextSet matches a set's paths and globs against the current tree. Globs only match files that aren't ignored by .gitignore or .plandexignore.
+func ResolveContextSet(set *shared.ContextSet) (*ResolvedContextSet, error) {
+	// sets are shared with the org, so they're checked again before anything is read
+	if err := set.Validate(); err != nil {
+		return nil, err
+	}
+
+	res := &ResolvedContextSet{}
+
+	var projectPaths *types.ProjectPaths
+	seen := map[string]bool{}
+
+	for _, pattern := range set.Paths {
+		if !strings.ContainsAny(pattern, "*?[") {
+			path, err := fromProjectPath(pattern)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := os.Stat(path); err != nil {
+				res.Unmatched = append(res.Unmatched, pattern)
+				continue
+			}
+			if !seen[path] {
+				seen[path] = true
+				res.Paths = append(res.Paths, path)
+			}
+			continue
+		}
+
+		re, err := contextSetGlobToRegex(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob %s: %v", pattern, err)
+		}
+
+		if projectPaths == nil {
+			projectPaths, err = fs.GetProjectPaths(fs.ProjectRoot)
+			if err != nil {
+				return nil, fmt.Errorf("error getting project paths: %v", err)
+			}
+		}
+
+		var matches []string
+		for p := range projectPaths.ActivePaths {
+			if re.MatchString(filepath.ToSlash(p)) {
+				matches = append(matches, p)
+			}
+		}
+		if len(matches) == 0 {
+			res.Unmatched = append(res.Unmatched, pattern)
+			continue
+		}
+		sort.Strings(matches)
+
+		for _, match := range matches {
+			path, err := fromProjectPath(match)
+			if err != nil {
+				return nil, err
+			}
+			if !seen[path] {
+				seen[path] = true
+				res.Paths = append(res.Paths, path)
+			}
+		}
+	}
+
+	for _, mapPath := range set.Maps {
+		path, err := fromProjectPath(mapPath)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(path); err != nil {
+			res.Unmatched = append(res.Unmatched, mapPath)
+			continue
+		}
+		res.Maps = append(res.Maps, path)
+	}
+
+	return res, nil
+}
+
+// MustApplyContextSet loads a resolved set into the current plan. Paths and urls already in context are skipped by MustLoadContext, and notes are skipped when the plan already has a note with the same text.
+func MustApplyContextSet(set *shared.ContextSet, resolved *ResolvedContextSet) {
+	resources := append(append([]string{}, resolved.Paths...), set.Urls...)
+	if len(resources) > 0 {
+		MustLoadContext(resources, &types.LoadContextParams{
+			Recursive:         true,
+			SkipIgnoreWarning: true,
+		})
+	}
+
+	// maps are loaded one directory at a time
+	for _, path := range resolved.Maps {
+		MustLoadContext([]string{path}, &types.LoadContextParams{
+			DefsOnly:          true,
+			SkipIgnoreWarning: true,
+		})
+	}
+
+	if len(set.Notes) == 0 {
+		return
+	}
+
+	existingNotes := map[string]bool{}
+	contexts, apiErr := api.Client.ListContext(CurrentPlanId, CurrentBranch)
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing context: %v", apiErr.Msg)
+	}
+	for _, context := range contexts {
+		if context.ContextType != shared.ContextNoteType {
+			continue
+		}
+		res, apiErr := api.Client.GetContextBody(CurrentPlanId, CurrentBranch, context.Id)
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error getting note body: %v", apiErr.Msg)
+		}
+		existingNotes[res.Body] = true
+	}
+
+	for _, note := range set.Notes {
+		if existingNotes[note] {
+			continue
+		}
+		MustLoadContext(nil, &types.LoadContextParams{
+			Note:              note,
+			SkipIgnoreWarning: true,
+		})
+	}
+}
+
+func toProjectPath(path string) (string, error) {
+	abs := path
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(fs.Cwd, path)
+	}
+	rel, err := filepath.Rel(fs.ProjectRoot, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return "", fmt.Errorf("%s is outside the project", path)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+func fromProjectPath(path string) (string, error) {
+	if !shared.IsProjectRelativePath(path) {
+		return "", fmt.Errorf("%s is outside the project", path)
+	}
+	abs := filepath.Join(fs.ProjectRoot, filepath.FromSlash(path))
+	inProject, err := filepath.Rel(fs.ProjectRoot, abs)
+	if err != nil || inProject == ".." || strings.HasPrefix(inProject, ".."+string(os.PathSeparator)) {
+		return "", fmt.Errorf("%s is outside the project", path)
+	}
+
+	rel, err := filepath.Rel(fs.Cwd, abs)
+	if err != nil {
+		return "", fmt.Errorf("error getting relative path for %s: %v", path, err)
+	}
+	return rel, nil
+}
+
+// contextSetGlobToRegex converts a glob to a regex matched against slash-separated paths. '**/' matches zero or more directories, '*' and '?' match within a path segment, and '[...]' is a character class.
+func contextSetGlobToRegex(glob string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				sb.WriteString(regexp.QuoteMeta(string(c)))
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+
+	return regexp.Compile(sb.String())
+}
@@ -41,7 +41,10 @@ func getMapFileDetails(path string, size, mapSize int64) (mapFileDetails, error)
 		mapFilesTruncatedTooLarge:     []filePathWithSize{},
 	}
 
-	if !shared.HasFileMapSupport(path) {
+	mapSupported := hasFileMapSupport(path)
+	maxInputSize := int64(shared.MaxFileMapInputSize(path))
+
+	if !mapSupported {
 		if shared.IsImageFile(path) {
 			isImage = true
 
@@ -55,8 +58,8 @@ func getMapFileDetails(path string, size, mapSize int64) (mapFileDetails, error)
 		}
 	} else {
 		var truncated bool
-		if size > shared.MaxContextMapSingleInputSize {
-			size = shared.MaxContextMapSingleInputSize
+		if size > maxInputSize {
+			size = maxInputSize
 			truncated = true
 			res.tokens = shared.GetBytesToTokensEstimate(size)
 		}
@@ -71,7 +74,7 @@ func getMapFileDetails(path string, size, mapSize int64) (mapFileDetails, error)
 		}
 	}
 
-	if totalMapSizeExceeded || !shared.HasFileMapSupport(path) || isImage {
+	if totalMapSizeExceeded || !mapSupported || isImage {
 		shaVal := sha256.Sum256([]byte(fmt.Sprintf("%d", res.tokens)))
 		res.shaVal = hex.EncodeToString(shaVal[:])
 
@@ -88,9 +91,9 @@ func getMapFileDetails(path string, size, mapSize int64) (mapFileDetails, error)
 		res.shaVal = contentRes.shaVal
 
 		if contentRes.truncated {
-			res.mapFilesTruncatedTooLarge = append(res.mapFilesTruncatedTooLarge, filePathWithSize{Path: path, Size: shared.MaxContextMapSingleInputSize})
-			res.size = shared.MaxContextMapSingleInputSize
-			res.tokens = shared.GetBytesToTokensEstimate(shared.MaxContextMapSingleInputSize)
+			res.mapFilesTruncatedTooLarge = append(res.mapFilesTruncatedTooLarge, filePathWithSize{Path: path, Size: maxInputSize})
+			res.size = maxInputSize
+			res.tokens = shared.GetBytesToTokensEstimate(maxInputSize)
 		} else {
 			// do the actual token count if we didn't truncate
 			res.tokens = shared.GetNumTokensEstimate(res.mapContent)
@@ -107,6 +110,26 @@ type mapFileContent struct {
 	truncated bool
 }
 
+// hasFileMapSupport is like shared.HasFileMapSupport, but also checks the start of json and yaml files, which are mapped when they're OpenAPI specs
+func hasFileMapSupport(path string) bool {
+	if shared.HasFileMapSupport(path) {
+		return true
+	}
+	if !shared.MayBeApiSpec(path) {
+		return false
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	head := make([]byte, shared.ApiSpecSniffSize)
+	n, _ := io.ReadFull(f, head)
+	return shared.GetApiSpecKind(path, head[:n]) != ""
+}
+
 func getMapFileContent(path string) (mapFileContent, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -120,7 +143,7 @@ func getMapFileContent(path string) (mapFileContent, error) {
 	}
 	size := info.Size()
 
-	limit := int64(shared.MaxContextMapSingleInputSize)
+	limit := int64(shared.MaxFileMapInputSize(path))
 	truncated := size > limit
 
 	limitReader := io.LimitReader(f, limit)
@@ -230,7 +253,7 @@ func getSkippedFilesMsg(
 			}
 		}
 		if len(mapFilesTruncatedTooLarge) > 0 {
-			fmt.Fprintf(&builder, "They will still be included in the map, but only the first %d KB will be mapped.\n", shared.MaxContextMapSingleInputSize/1024)
+			fmt.Fprintf(&builder, "They will still be included in the map, but only the first %d KB (%d MB for API specs) will be mapped.\n", shared.MaxContextMapSingleInputSize/1024, shared.MaxApiSpecMapInputSize/1024/1024)
 		}
 	}
 	if len(filesSkippedAfterSizeLimit) > 0 {
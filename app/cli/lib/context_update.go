@@ -5,8 +5,10 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"log"
 	"os"
 	"plandex-cli/api"
+	"plandex-cli/document"
 	"plandex-cli/fs"
 	"plandex-cli/term"
 	"plandex-cli/types"
@@ -89,6 +91,30 @@ func CheckOutdatedContextWithOutput(quiet, autoConfirm bool, maybeContexts []*sh
 			lbl = strconv.Itoa(outdatedRes.NumMaps) + " " + lbl
 			types = append(types, lbl)
 		}
+		if outdatedRes.NumGitDiffs > 0 {
+			lbl := "git diff"
+			if outdatedRes.NumGitDiffs > 1 {
+				lbl = "git diffs"
+			}
+			lbl = strconv.Itoa(outdatedRes.NumGitDiffs) + " " + lbl
+			types = append(types, lbl)
+		}
+		if outdatedRes.NumCommands > 0 {
+			lbl := "command output"
+			if outdatedRes.NumCommands > 1 {
+				lbl = "command outputs"
+			}
+			lbl = strconv.Itoa(outdatedRes.NumCommands) + " " + lbl
+			types = append(types, lbl)
+		}
+		if outdatedRes.NumDbSchemas > 0 {
+			lbl := "db schema"
+			if outdatedRes.NumDbSchemas > 1 {
+				lbl = "db schemas"
+			}
+			lbl = strconv.Itoa(outdatedRes.NumDbSchemas) + " " + lbl
+			types = append(types, lbl)
+		}
 
 		var msg string
 		if len(types) <= 2 {
@@ -246,7 +272,7 @@ func UpdateContext(params UpdateContextParams) (UpdateContextResult, error) {
 	filesToLoad := map[string]string{}
 	for id := range req {
 		context := contextsById[id]
-		if context.ContextType == shared.ContextFileType {
+		if context.ContextType == shared.ContextFileType || context.ContextType == shared.ContextFileRangeType {
 			filesToLoad[context.FilePath] = context.Body
 		}
 	}
@@ -256,6 +282,9 @@ func UpdateContext(params UpdateContextParams) (UpdateContextResult, error) {
 			filesToLoad[context.FilePath] = ""
 		}
 	}
+	for _, file := range params.OutdatedRes.NewFiles {
+		filesToLoad[file.FilePath] = file.Body
+	}
 
 	hasConflicts, err = checkContextConflicts(filesToLoad)
 	if err != nil {
@@ -270,6 +299,14 @@ func UpdateContext(params UpdateContextParams) (UpdateContextResult, error) {
 		msg = res.Msg
 	}
 
+	if len(params.OutdatedRes.NewFiles) > 0 {
+		res, apiErr := api.Client.LoadContext(CurrentPlanId, CurrentBranch, params.OutdatedRes.NewFiles)
+		if apiErr != nil {
+			return UpdateContextResult{}, fmt.Errorf("failed to load files added to git diffs: %v", apiErr)
+		}
+		msg += " " + res.Msg
+	}
+
 	if len(deleteIds) > 0 {
 		res, apiErr := api.Client.DeleteContext(CurrentPlanId, CurrentBranch, shared.DeleteContextRequest{
 			Ids: deleteIds,
@@ -330,6 +367,9 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 	var numUrls int
 	var numTrees int
 	var numMaps int
+	var numGitDiffs int
+	var numCommands int
+	var numDbSchemas int
 	var numFilesRemoved int
 	var numTreesRemoved int
 	var mu sync.Mutex
@@ -358,8 +398,76 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 
 	sem := make(chan struct{}, ContextMapMaxClientConcurrency)
 
+	// the last part of a document picks up any pages or sections added to the end
+	lastDocumentPartStart := map[string]int{}
+	var extractions documentExtractions
+
+	// docs bundles are re-rendered from the project's cache - crawling again only happens on request
+	lastDocsPartStart := map[string]int{}
+	docsBundles := map[string]*DocsBundle{}
+
+	// commands are only re-run when command context is enabled for the plan and the command was loaded on this machine
+	var commandsEnabled bool
+	var approvedCommands map[string]bool
+	var unapprovedCommands []string
+	// one command runs at a time, since builds and test runs often can't share the working tree
+	var commandMu sync.Mutex
+
+	// databases that can't be reached are left as-is rather than failing the whole update
+	var dbConnections map[string]string
+	var approvedDbs map[string]bool
+	var unapprovedDbs []string
+	var unreachableDbs []string
+
+	for _, c := range contexts {
+		if c.ContextType == shared.ContextDbSchemaType {
+			var err error
+			dbConnections, err = loadDbConnections()
+			if err != nil {
+				return nil, err
+			}
+			approvedDbs, err = loadApprovedDbs()
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	for _, c := range contexts {
+		if c.ContextType == shared.ContextCommandType {
+			commandsEnabled = MustGetCurrentPlanConfig().CommandContext
+			var err error
+			approvedCommands, err = loadApprovedCommands()
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	// files that are new to a refreshed git diff are loaded alongside it, like when the diff was first loaded
+	loadedFilePaths := map[string]bool{}
+	var newGitDiffFiles []*shared.LoadContextParams
+
 	for _, c := range contexts {
 		contextsById[c.Id] = c
+		if c.ContextType == shared.ContextFileType {
+			loadedFilePaths[c.FilePath] = true
+		}
+		if c.ContextType == shared.ContextDocumentType {
+			lastDocumentPartStart[c.FilePath] = max(lastDocumentPartStart[c.FilePath], c.StartLine)
+		}
+		if c.ContextType == shared.ContextDocsType {
+			lastDocsPartStart[c.DocsBundle] = max(lastDocsPartStart[c.DocsBundle], c.StartLine)
+			if _, ok := docsBundles[c.DocsBundle]; !ok {
+				bundle, err := LoadDocsBundle(c.DocsBundle)
+				if err != nil {
+					return nil, err
+				}
+				docsBundles[c.DocsBundle] = bundle
+			}
+		}
 	}
 
 	for _, context := range contexts {
@@ -474,6 +582,395 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 				}
 			}(context)
 
+		case shared.ContextFileRangeType:
+			wg.Add(1)
+			go func(ctx *shared.Context) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				if _, err := os.Stat(ctx.FilePath); os.IsNotExist(err) {
+					mu.Lock()
+					defer mu.Unlock()
+
+					deleteIds[ctx.Id] = true
+					numFilesRemoved++
+					tokenDiffsById[ctx.Id] = -ctx.NumTokens
+					return
+				}
+
+				fileContent, err := os.ReadFile(ctx.FilePath)
+				if err != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					errs = append(errs, fmt.Errorf("failed to read the file %s: %v", ctx.FilePath, err))
+					return
+				}
+				fileContent = shared.NormalizeEOL(fileContent)
+
+				size := int64(len(fileContent))
+				if size > shared.MaxContextBodySize {
+					mu.Lock()
+					defer mu.Unlock()
+
+					filesSkippedTooLarge = append(filesSkippedTooLarge, filePathWithSize{Path: ctx.FilePath, Size: size})
+					return
+				}
+
+				hash := sha256.Sum256(fileContent)
+				sha := hex.EncodeToString(hash[:])
+
+				if sha == ctx.Sha {
+					return
+				}
+
+				// follow the range through the edits so it keeps pointing at the same code
+				startLine, endLine, removed, err := trackFileRange(ctx, string(fileContent))
+				if err != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					errs = append(errs, fmt.Errorf("failed to update range %s: %v", ctx.Name, err))
+					return
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				if removed {
+					deleteIds[ctx.Id] = true
+					numFilesRemoved++
+					tokenDiffsById[ctx.Id] = -ctx.NumTokens
+					return
+				}
+
+				if totalContextCount >= shared.MaxContextCount || totalSize+size > shared.MaxContextBodySize {
+					filesSkippedAfterSizeLimit = append(filesSkippedAfterSizeLimit, ctx.FilePath)
+					return
+				}
+				totalSize += size
+				totalContextCount++
+
+				numTokens := shared.GetNumTokensEstimate(shared.GetFileRangeBody(string(fileContent), startLine, endLine))
+
+				tokenDiffsById[ctx.Id] = numTokens - ctx.NumTokens
+				numFiles++
+				updatedContexts = append(updatedContexts, ctx)
+
+				reqFns[ctx.Id] = func() (*shared.UpdateContextParams, error) {
+					return &shared.UpdateContextParams{
+						Body:      string(fileContent),
+						StartLine: startLine,
+						EndLine:   endLine,
+					}, nil
+				}
+			}(context)
+
+		case shared.ContextGitDiffType:
+			wg.Add(1)
+			go func(ctx *shared.Context) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				// touched files are separate file contexts that are refreshed on their own, so only files that weren't in the diff before are loaded here
+				diff, touchedPaths, err := GitDiffForContext(ctx.GitDiffMode, ctx.GitRef)
+				if err != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					errs = append(errs, fmt.Errorf("failed to refresh %s: %v", ctx.Name, err))
+					return
+				}
+
+				hash := sha256.Sum256([]byte(diff))
+				sha := hex.EncodeToString(hash[:])
+
+				if sha == ctx.Sha {
+					return
+				}
+
+				size := int64(len(diff))
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				if size > shared.MaxContextBodySize {
+					filesSkippedTooLarge = append(filesSkippedTooLarge, filePathWithSize{Path: ctx.Name, Size: size})
+					return
+				}
+				if totalContextCount >= shared.MaxContextCount || totalSize+size > shared.MaxContextBodySize {
+					filesSkippedAfterSizeLimit = append(filesSkippedAfterSizeLimit, ctx.Name)
+					return
+				}
+				totalSize += size
+				totalContextCount++
+
+				numTokens := shared.GetNumTokensEstimate(diff)
+				tokenDiffsById[ctx.Id] = numTokens - ctx.NumTokens
+				numGitDiffs++
+				updatedContexts = append(updatedContexts, ctx)
+
+				// a file the user removed from context after the diff was loaded is still in the old diff, so it isn't loaded again
+				prevPaths := gitDiffPaths(ctx.Body)
+				for _, path := range touchedPaths {
+					if prevPaths[path] || loadedFilePaths[path] || shared.IsImageFile(path) || document.IsDocumentFile(path) {
+						continue
+					}
+
+					fileContent, err := os.ReadFile(path)
+					if err != nil {
+						log.Printf("failed to read %s for %s: %v", path, ctx.Name, err)
+						continue
+					}
+					fileContent = shared.NormalizeEOL(fileContent)
+
+					fileSize := int64(len(fileContent))
+					if fileSize > shared.MaxContextBodySize {
+						filesSkippedTooLarge = append(filesSkippedTooLarge, filePathWithSize{Path: path, Size: fileSize})
+						continue
+					}
+					if totalContextCount >= shared.MaxContextCount || totalSize+fileSize > shared.MaxContextBodySize {
+						filesSkippedAfterSizeLimit = append(filesSkippedAfterSizeLimit, path)
+						continue
+					}
+					totalSize += fileSize
+					totalContextCount++
+
+					loadedFilePaths[path] = true
+					newGitDiffFiles = append(newGitDiffFiles, &shared.LoadContextParams{
+						ContextType: shared.ContextFileType,
+						Name:        path,
+						Body:        string(fileContent),
+						FilePath:    path,
+						AutoLoaded:  ctx.AutoLoaded,
+					})
+				}
+
+				reqFns[ctx.Id] = func() (*shared.UpdateContextParams, error) {
+					return &shared.UpdateContextParams{
+						Body: diff,
+					}, nil
+				}
+			}(context)
+
+		case shared.ContextDocumentType:
+			wg.Add(1)
+			go func(ctx *shared.Context) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				sections, exists, err := extractions.get(ctx.FilePath)
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				if err != nil {
+					errs = append(errs, err)
+					return
+				}
+
+				// parts past the end of a shortened document are removed along with deleted documents
+				if !exists || ctx.StartLine > len(sections) {
+					deleteIds[ctx.Id] = true
+					numFilesRemoved++
+					tokenDiffsById[ctx.Id] = -ctx.NumTokens
+					return
+				}
+
+				startLine, endLine := ctx.StartLine, ctx.EndLine
+				if startLine == lastDocumentPartStart[ctx.FilePath] {
+					endLine = len(sections)
+				}
+
+				body := document.RenderPart(sections, startLine, endLine)
+
+				hash := sha256.Sum256([]byte(body))
+				sha := hex.EncodeToString(hash[:])
+
+				if sha == ctx.Sha {
+					return
+				}
+
+				size := int64(len(body))
+				if totalContextCount >= shared.MaxContextCount || totalSize+size > shared.MaxContextBodySize {
+					filesSkippedAfterSizeLimit = append(filesSkippedAfterSizeLimit, ctx.Name)
+					return
+				}
+				totalSize += size
+				totalContextCount++
+
+				numTokens := shared.GetNumTokensEstimate(body)
+				tokenDiffsById[ctx.Id] = numTokens - ctx.NumTokens
+				numFiles++
+				updatedContexts = append(updatedContexts, ctx)
+
+				reqFns[ctx.Id] = func() (*shared.UpdateContextParams, error) {
+					return &shared.UpdateContextParams{
+						Body:      body,
+						StartLine: startLine,
+						EndLine:   min(endLine, len(sections)),
+					}, nil
+				}
+			}(context)
+
+		case shared.ContextCommandType:
+			if !commandsEnabled {
+				continue
+			}
+			if !approvedCommands[context.Command] {
+				unapprovedCommands = append(unapprovedCommands, context.Command)
+				continue
+			}
+
+			wg.Add(1)
+			go func(ctx *shared.Context) {
+				defer wg.Done()
+
+				commandMu.Lock()
+				body, err := RunContextCommand(ctx.Command, ctx.CommandTimeout)
+				commandMu.Unlock()
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				if err != nil {
+					errs = append(errs, err)
+					return
+				}
+
+				hash := sha256.Sum256([]byte(body))
+				sha := hex.EncodeToString(hash[:])
+
+				if sha == ctx.Sha {
+					return
+				}
+
+				size := int64(len(body))
+				if totalContextCount >= shared.MaxContextCount || totalSize+size > shared.MaxContextBodySize {
+					filesSkippedAfterSizeLimit = append(filesSkippedAfterSizeLimit, ctx.Name)
+					return
+				}
+				totalSize += size
+				totalContextCount++
+
+				numTokens := shared.GetNumTokensEstimate(body)
+				tokenDiffsById[ctx.Id] = numTokens - ctx.NumTokens
+				numCommands++
+				updatedContexts = append(updatedContexts, ctx)
+
+				reqFns[ctx.Id] = func() (*shared.UpdateContextParams, error) {
+					return &shared.UpdateContextParams{
+						Body: body,
+					}, nil
+				}
+			}(context)
+
+		case shared.ContextDbSchemaType:
+			// a saved connection also means the db was loaded on this machine, before approvals were tracked
+			_, hasConnection := dbConnections[context.DbSource]
+			if !approvedDbs[context.DbSource] && !hasConnection {
+				unapprovedDbs = append(unapprovedDbs, context.DbSource)
+				continue
+			}
+
+			wg.Add(1)
+			go func(ctx *shared.Context) {
+				defer wg.Done()
+
+				sem <- struct{}{}
+				body, err := getDbSchemaBody(getDbDsn(ctx.DbSource, dbConnections))
+				<-sem
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				if err != nil {
+					unreachableDbs = append(unreachableDbs, ctx.DbSource)
+					return
+				}
+
+				hash := sha256.Sum256([]byte(body))
+				sha := hex.EncodeToString(hash[:])
+
+				if sha == ctx.Sha {
+					return
+				}
+
+				size := int64(len(body))
+				if totalContextCount >= shared.MaxContextCount || totalSize+size > shared.MaxContextBodySize {
+					filesSkippedAfterSizeLimit = append(filesSkippedAfterSizeLimit, ctx.Name)
+					return
+				}
+				totalSize += size
+				totalContextCount++
+
+				numTokens := shared.GetNumTokensEstimate(body)
+				tokenDiffsById[ctx.Id] = numTokens - ctx.NumTokens
+				numDbSchemas++
+				updatedContexts = append(updatedContexts, ctx)
+
+				reqFns[ctx.Id] = func() (*shared.UpdateContextParams, error) {
+					return &shared.UpdateContextParams{
+						Body: body,
+					}, nil
+				}
+			}(context)
+
+		case shared.ContextDocsType:
+			wg.Add(1)
+			go func(ctx *shared.Context) {
+				defer wg.Done()
+
+				bundle := docsBundles[ctx.DocsBundle]
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				// removed along with the bundle, or if a re-crawl found fewer pages
+				if bundle == nil || ctx.StartLine > len(bundle.Pages) {
+					deleteIds[ctx.Id] = true
+					numFilesRemoved++
+					tokenDiffsById[ctx.Id] = -ctx.NumTokens
+					return
+				}
+
+				sections := bundle.sections()
+				startLine, endLine := ctx.StartLine, min(ctx.EndLine, len(sections))
+				if startLine == lastDocsPartStart[ctx.DocsBundle] {
+					endLine = len(sections)
+				}
+
+				body := document.RenderPart(sections, startLine, endLine)
+
+				hash := sha256.Sum256([]byte(body))
+				sha := hex.EncodeToString(hash[:])
+
+				if sha == ctx.Sha {
+					return
+				}
+
+				size := int64(len(body))
+				if totalContextCount >= shared.MaxContextCount || totalSize+size > shared.MaxContextBodySize {
+					filesSkippedAfterSizeLimit = append(filesSkippedAfterSizeLimit, ctx.Name)
+					return
+				}
+				totalSize += size
+				totalContextCount++
+
+				numTokens := shared.GetNumTokensEstimate(body)
+				tokenDiffsById[ctx.Id] = numTokens - ctx.NumTokens
+				numUrls++
+				updatedContexts = append(updatedContexts, ctx)
+
+				reqFns[ctx.Id] = func() (*shared.UpdateContextParams, error) {
+					return &shared.UpdateContextParams{
+						Body:      body,
+						StartLine: startLine,
+						EndLine:   endLine,
+					}, nil
+				}
+			}(context)
+
 		case shared.ContextDirectoryTreeType:
 			wg.Add(1)
 			go func(ctx *shared.Context) {
@@ -920,6 +1417,39 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 		return nil, fmt.Errorf("failed to check context outdated: %v", errs)
 	}
 
+	if len(unapprovedCommands) > 0 {
+		term.StopSpinner()
+		fmt.Println()
+		color.New(term.ColorHiYellow, color.Bold).Println("⚡️ These commands weren't re-run because they haven't been loaded on this machine:")
+		for _, command := range unapprovedCommands {
+			fmt.Println("  • " + command)
+		}
+		fmt.Println("Load a command with 'plandex load --cmd' to allow it to run here.")
+		fmt.Println()
+	}
+
+	if len(unapprovedDbs) > 0 {
+		term.StopSpinner()
+		fmt.Println()
+		color.New(term.ColorHiYellow, color.Bold).Println("🗄️  These database schemas weren't checked because they haven't been loaded on this machine:")
+		for _, source := range unapprovedDbs {
+			fmt.Println("  • " + source)
+		}
+		fmt.Println("Load a database with 'plandex load --db' to allow it to be checked here.")
+		fmt.Println()
+	}
+
+	if len(unreachableDbs) > 0 {
+		term.StopSpinner()
+		fmt.Println()
+		color.New(term.ColorHiYellow, color.Bold).Println("🗄️  These database schemas couldn't be checked because the database couldn't be reached:")
+		for _, source := range unreachableDbs {
+			fmt.Println("  • " + source)
+		}
+		fmt.Println("Load a database with 'plandex load --db' to save its connection on this machine.")
+		fmt.Println()
+	}
+
 	// Identify contexts to remove
 	var removedContexts []*shared.Context
 	for id := range deleteIds {
@@ -969,8 +1499,12 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 		NumUrls:         numUrls,
 		NumTrees:        numTrees,
 		NumMaps:         numMaps,
+		NumGitDiffs:     numGitDiffs,
+		NumCommands:     numCommands,
+		NumDbSchemas:    numDbSchemas,
 		NumFilesRemoved: numFilesRemoved,
 		NumTreesRemoved: numTreesRemoved,
+		NewFiles:        newGitDiffFiles,
 		ReqFn:           reqFn,
 	}
 
@@ -995,12 +1529,15 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 		}
 		newTotal := totalTokens + tokensDiff
 		outdatedRes.Msg = shared.SummaryForUpdateContext(shared.SummaryForUpdateContextParams{
-			NumFiles:    numFiles,
-			NumTrees:    numTrees,
-			NumUrls:     numUrls,
-			NumMaps:     numMaps,
-			TokensDiff:  tokensDiff,
-			TotalTokens: newTotal,
+			NumFiles:     numFiles,
+			NumTrees:     numTrees,
+			NumUrls:      numUrls,
+			NumMaps:      numMaps,
+			NumGitDiffs:  numGitDiffs,
+			NumCommands:  numCommands,
+			NumDbSchemas: numDbSchemas,
+			TokensDiff:   tokensDiff,
+			TotalTokens:  newTotal,
 		})
 	}
 
@@ -1061,3 +1598,14 @@ func tableForContextOutdated(updatedContexts []*shared.Context, tokenDiffsById m
 	table.Render()
 	return tableString.String()
 }
+
+// gitDiffPaths returns the paths of the files in a unified diff, taken from its '+++ b/' headers
+func gitDiffPaths(diff string) map[string]bool {
+	paths := map[string]bool{}
+	for _, line := range strings.Split(diff, "\n") {
+		if path, ok := strings.CutPrefix(line, "+++ b/"); ok {
+			paths[strings.TrimSuffix(path, "\t")] = true
+		}
+	}
+	return paths
+}
@@ -0,0 +1,257 @@
+package lib
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"plandex-cli/fs"
+	shared "plandex-shared"
+	"sort"
+	"sync"
+)
+
+// The keyring is a local fallback for machines without an OS keychain.
+// Values are encrypted with AES-GCM and the file is only readable by the current user.
+// By default the key lives alongside the keyring in the Plandex home dir—set PLANDEX_KEYRING_KEY (32 bytes, base64-encoded)
+// to keep it somewhere else, like a password manager or a CI secret.
+
+const keyringKeyEnvVar = "PLANDEX_KEYRING_KEY"
+
+var keyringMu sync.Mutex
+var cachedKeyring map[string]string
+
+type keyringCredentialSource struct{}
+
+func (s keyringCredentialSource) Type() shared.CredentialSourceType {
+	return shared.CredentialSourceKeyring
+}
+
+func (s keyringCredentialSource) Get(name string) (string, error) {
+	keyringMu.Lock()
+	defer keyringMu.Unlock()
+
+	keyring, err := loadKeyring()
+	if err != nil {
+		return "", err
+	}
+
+	return keyring[name], nil
+}
+
+func SetKeyringCredential(name, value string) error {
+	keyringMu.Lock()
+	defer keyringMu.Unlock()
+
+	keyring, err := loadKeyring()
+	if err != nil {
+		return err
+	}
+
+	keyring[name] = value
+
+	return writeKeyring(keyring)
+}
+
+func DeleteKeyringCredential(name string) (bool, error) {
+	keyringMu.Lock()
+	defer keyringMu.Unlock()
+
+	keyring, err := loadKeyring()
+	if err != nil {
+		return false, err
+	}
+
+	if _, ok := keyring[name]; !ok {
+		return false, nil
+	}
+
+	delete(keyring, name)
+
+	return true, writeKeyring(keyring)
+}
+
+func ListKeyringCredentialNames() ([]string, error) {
+	keyringMu.Lock()
+	defer keyringMu.Unlock()
+
+	keyring, err := loadKeyring()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(keyring))
+	for name := range keyring {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func keyringDir() string {
+	return filepath.Join(fs.HomePlandexDir, "keyring")
+}
+
+func keyringPath() string {
+	return filepath.Join(keyringDir(), "keyring.json")
+}
+
+func keyringKeyPath() string {
+	return filepath.Join(keyringDir(), "keyring.key")
+}
+
+func loadKeyring() (map[string]string, error) {
+	if cachedKeyring != nil {
+		return cachedKeyring, nil
+	}
+
+	bytes, err := os.ReadFile(keyringPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			cachedKeyring = map[string]string{}
+			return cachedKeyring, nil
+		}
+		return nil, fmt.Errorf("error reading keyring: %v", err)
+	}
+
+	var encrypted map[string]string
+	err = json.Unmarshal(bytes, &encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling keyring: %v", err)
+	}
+
+	key, err := getKeyringKey(false)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newKeyringGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	keyring := map[string]string{}
+	for name, encoded := range encrypted {
+		sealed, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding keyring value for %s: %v", name, err)
+		}
+
+		if len(sealed) < gcm.NonceSize() {
+			return nil, fmt.Errorf("keyring value for %s is corrupted", name)
+		}
+
+		nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+		plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error decrypting keyring value for %s—was the keyring key changed? %v", name, err)
+		}
+
+		keyring[name] = string(plaintext)
+	}
+
+	cachedKeyring = keyring
+	return keyring, nil
+}
+
+func writeKeyring(keyring map[string]string) error {
+	err := os.MkdirAll(keyringDir(), 0700)
+	if err != nil {
+		return fmt.Errorf("error creating keyring directory: %v", err)
+	}
+
+	key, err := getKeyringKey(true)
+	if err != nil {
+		return err
+	}
+
+	gcm, err := newKeyringGCM(key)
+	if err != nil {
+		return err
+	}
+
+	encrypted := map[string]string{}
+	for name, value := range keyring {
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return fmt.Errorf("error generating nonce: %v", err)
+		}
+		sealed := gcm.Seal(nonce, nonce, []byte(value), nil)
+		encrypted[name] = base64.StdEncoding.EncodeToString(sealed)
+	}
+
+	bytes, err := json.MarshalIndent(encrypted, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling keyring: %v", err)
+	}
+
+	err = os.WriteFile(keyringPath(), bytes, 0600)
+	if err != nil {
+		return fmt.Errorf("error writing keyring: %v", err)
+	}
+
+	cachedKeyring = keyring
+
+	return nil
+}
+
+func getKeyringKey(create bool) ([]byte, error) {
+	if encoded := os.Getenv(keyringKeyEnvVar); encoded != "" {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding %s: %v", keyringKeyEnvVar, err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("%s must be 32 bytes (base64-encoded), got %d bytes", keyringKeyEnvVar, len(key))
+		}
+		return key, nil
+	}
+
+	key, err := os.ReadFile(keyringKeyPath())
+	if err == nil {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("keyring key at %s is corrupted", keyringKeyPath())
+		}
+		return key, nil
+	}
+
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading keyring key: %v", err)
+	}
+
+	if !create {
+		return nil, errors.New("keyring key not found—set " + keyringKeyEnvVar + " or remove the keyring to start over")
+	}
+
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("error generating keyring key: %v", err)
+	}
+
+	err = os.WriteFile(keyringKeyPath(), key, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("error writing keyring key: %v", err)
+	}
+
+	return key, nil
+}
+
+func newKeyringGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating keyring cipher: %v", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("error creating keyring cipher: %v", err)
+	}
+
+	return gcm, nil
+}
@@ -0,0 +1,115 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	shared "plandex-shared"
+)
+
+// CredentialSource is a backend that provider credentials (API keys and extra auth vars) can be resolved from.
+// Get returns an empty string with no error when the source doesn't have a value for the name.
+type CredentialSource interface {
+	Type() shared.CredentialSourceType
+	Get(name string) (string, error)
+}
+
+type envCredentialSource struct{}
+
+func (s envCredentialSource) Type() shared.CredentialSourceType {
+	return shared.CredentialSourceEnv
+}
+
+func (s envCredentialSource) Get(name string) (string, error) {
+	return os.Getenv(name), nil
+}
+
+var cachedCredentialSources []CredentialSource
+
+// credentialSources returns the local credential sources in priority order:
+// env vars first so they can always override, then the keyring file, then a Vault server if one is configured.
+// The org vault isn't included since its values are resolved server-side—see getOrgSecretNames.
+func credentialSources() []CredentialSource {
+	if cachedCredentialSources != nil {
+		return cachedCredentialSources
+	}
+
+	sources := []CredentialSource{
+		envCredentialSource{},
+		keyringCredentialSource{},
+	}
+
+	vaultSource := newVaultCredentialSourceFromEnv()
+	if vaultSource != nil {
+		sources = append(sources, vaultSource)
+	}
+
+	cachedCredentialSources = sources
+	return sources
+}
+
+func lookupCredential(name string) (string, error) {
+	for _, source := range credentialSources() {
+		val, err := source.Get(name)
+		if err != nil {
+			return "", fmt.Errorf("error reading %s from %s credential source: %v", name, source.Type(), err)
+		}
+		if val != "" {
+			return val, nil
+		}
+	}
+	return "", nil
+}
+
+var cachedOrgSecretNames map[string]bool
+
+// getOrgSecretNames returns the names of credentials stored in the org vault.
+// The values stay on the server, which merges them into the auth vars for each request.
+func getOrgSecretNames() (map[string]bool, error) {
+	if cachedOrgSecretNames != nil {
+		return cachedOrgSecretNames, nil
+	}
+
+	names := map[string]bool{}
+
+	if auth.Current == nil || auth.Current.IsCloud {
+		cachedOrgSecretNames = names
+		return names, nil
+	}
+
+	secrets, apiErr := api.Client.ListOrgSecrets()
+	if apiErr != nil {
+		return nil, fmt.Errorf("error listing org secrets: %v", apiErr.Msg)
+	}
+
+	for _, secret := range secrets {
+		names[secret.Name] = true
+	}
+
+	cachedOrgSecretNames = names
+	return names, nil
+}
+
+// ResolveCredentialSource reports which source a credential would be resolved from, or an empty string if none has it
+func ResolveCredentialSource(name string) (shared.CredentialSourceType, error) {
+	for _, source := range credentialSources() {
+		val, err := source.Get(name)
+		if err != nil {
+			return "", fmt.Errorf("error reading %s from %s credential source: %v", name, source.Type(), err)
+		}
+		if val != "" {
+			return source.Type(), nil
+		}
+	}
+
+	orgSecretNames, err := getOrgSecretNames()
+	if err != nil {
+		return "", err
+	}
+	if orgSecretNames[name] {
+		return shared.CredentialSourceOrgVault, nil
+	}
+
+	return "", nil
+}
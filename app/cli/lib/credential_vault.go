@@ -0,0 +1,159 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	shared "plandex-shared"
+	"strings"
+	"sync"
+	"time"
+)
+
+// vaultCredentialSource reads credentials from a single secret in a HashiCorp Vault-compatible KV store.
+// Both KV v1 and v2 response shapes are supported—each key in the secret is treated as an auth var name.
+//
+// Configured with:
+//   PLANDEX_VAULT_ADDR       e.g. https://vault.example.com:8200 (required to enable)
+//   PLANDEX_VAULT_TOKEN      falls back to VAULT_TOKEN
+//   PLANDEX_VAULT_PATH       API path of the secret, defaults to secret/data/plandex (KV v2 mount 'secret')
+//   PLANDEX_VAULT_NAMESPACE  falls back to VAULT_NAMESPACE (Vault Enterprise only)
+
+const defaultVaultSecretPath = "secret/data/plandex"
+
+type vaultCredentialSource struct {
+	addr      string
+	token     string
+	path      string
+	namespace string
+	client    *http.Client
+
+	once   sync.Once
+	values map[string]string
+	err    error
+}
+
+func newVaultCredentialSource(addr, token, path, namespace string) *vaultCredentialSource {
+	if path == "" {
+		path = defaultVaultSecretPath
+	}
+
+	return &vaultCredentialSource{
+		addr:      strings.TrimRight(addr, "/"),
+		token:     token,
+		path:      strings.Trim(path, "/"),
+		namespace: namespace,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// returns nil unless PLANDEX_VAULT_ADDR is set so that Vault lookups are opt-in
+func newVaultCredentialSourceFromEnv() *vaultCredentialSource {
+	addr := os.Getenv("PLANDEX_VAULT_ADDR")
+	if addr == "" {
+		return nil
+	}
+
+	token := os.Getenv("PLANDEX_VAULT_TOKEN")
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+
+	namespace := os.Getenv("PLANDEX_VAULT_NAMESPACE")
+	if namespace == "" {
+		namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+
+	return newVaultCredentialSource(addr, token, os.Getenv("PLANDEX_VAULT_PATH"), namespace)
+}
+
+func (s *vaultCredentialSource) Type() shared.CredentialSourceType {
+	return shared.CredentialSourceVault
+}
+
+func (s *vaultCredentialSource) Get(name string) (string, error) {
+	// the whole secret is fetched once per process
+	s.once.Do(func() {
+		s.values, s.err = s.fetch()
+	})
+
+	if s.err != nil {
+		return "", s.err
+	}
+
+	return s.values[name], nil
+}
+
+func (s *vaultCredentialSource) fetch() (map[string]string, error) {
+	url := fmt.Sprintf("%s/v1/%s", s.addr, s.path)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating vault request: %v", err)
+	}
+
+	if s.token != "" {
+		req.Header.Set("X-Vault-Token", s.token)
+	}
+	if s.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.namespace)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending vault request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// secret doesn't exist yet—not an error, there just aren't any credentials in it
+	if resp.StatusCode == http.StatusNotFound {
+		return map[string]string{}, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading vault response: %v", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		var errResp struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(body, &errResp) == nil && len(errResp.Errors) > 0 {
+			return nil, fmt.Errorf("vault returned %d: %s", resp.StatusCode, strings.Join(errResp.Errors, "; "))
+		}
+		return nil, fmt.Errorf("vault returned %d", resp.StatusCode)
+	}
+
+	return parseVaultSecretResponse(body)
+}
+
+func parseVaultSecretResponse(body []byte) (map[string]string, error) {
+	var parsed struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	err := json.Unmarshal(body, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing vault response: %v", err)
+	}
+
+	data := parsed.Data
+
+	// KV v2 nests the secret under data.data alongside data.metadata
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = nested
+		}
+	}
+
+	values := map[string]string{}
+	for k, v := range data {
+		if s, ok := v.(string); ok {
+			values[k] = s
+		}
+	}
+
+	return values, nil
+}
@@ -0,0 +1,83 @@
+package lib
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVaultCredentialSource(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{
+			name: "kv v2",
+			path: "/v1/secret/data/plandex",
+			body: `{"data":{"data":{"OPENAI_API_KEY":"sk-test","ANTHROPIC_API_KEY":"sk-ant"},"metadata":{"version":3}}}`,
+		},
+		{
+			name: "kv v1",
+			path: "/v1/kv/plandex",
+			body: `{"data":{"OPENAI_API_KEY":"sk-test","ANTHROPIC_API_KEY":"sk-ant"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			numRequests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				numRequests++
+				if r.Header.Get("X-Vault-Token") != "test-token" {
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte(`{"errors":["permission denied"]}`))
+					return
+				}
+				if r.URL.Path != tt.path {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			source := newVaultCredentialSource(server.URL, "test-token", tt.path[len("/v1/"):], "")
+
+			val, err := source.Get("OPENAI_API_KEY")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if val != "sk-test" {
+				t.Fatalf("expected sk-test, got %q", val)
+			}
+
+			val, err = source.Get("MISSING_KEY")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if val != "" {
+				t.Fatalf("expected empty value, got %q", val)
+			}
+
+			if numRequests != 1 {
+				t.Fatalf("expected secret to be fetched once, got %d requests", numRequests)
+			}
+		})
+	}
+}
+
+func TestVaultCredentialSourceErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"errors":["permission denied"]}`))
+	}))
+	defer server.Close()
+
+	source := newVaultCredentialSource(server.URL, "bad-token", "", "")
+
+	_, err := source.Get("OPENAI_API_KEY")
+	if err == nil {
+		t.Fatalf("expected error for forbidden response")
+	}
+}
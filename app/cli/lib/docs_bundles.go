@@ -0,0 +1,247 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"plandex-cli/document"
+	"plandex-cli/fs"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"plandex-cli/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+)
+
+const docsBundlesDirName = "docs"
+
+var docsBundleNameRegex = regexp.MustCompile(`^[\w.@+-]+$`)
+
+// DocsBundle is a crawled documentation site, cached in the project's plandex directory so it can be loaded into any plan without crawling again
+type DocsBundle struct {
+	Name      string             `json:"name"`
+	StartUrl  string             `json:"startUrl"`
+	Depth     int                `json:"depth"`
+	Include   []string           `json:"include"`
+	MaxPages  int                `json:"maxPages"`
+	Version   int                `json:"version"`
+	Sha       string             `json:"sha"`
+	CrawledAt time.Time          `json:"crawledAt"`
+	Pages     []*url.CrawledPage `json:"pages"`
+}
+
+func getDocsBundlePath(name string) string {
+	return filepath.Join(fs.PlandexDir, docsBundlesDirName, name+".json")
+}
+
+// DocsBundleNameForUrl is the default bundle name for a crawl, e.g. 'react.dev_reference' for https://react.dev/reference/
+func DocsBundleNameForUrl(u string) string {
+	return strings.Trim(url.SanitizeURL(u), "_")
+}
+
+func ValidateDocsBundleName(name string) error {
+	if !docsBundleNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid docs bundle name '%s' - use letters, numbers, and . _ - @ +", name)
+	}
+	return nil
+}
+
+// LoadDocsBundle returns the cached bundle with the given name, or nil if there isn't one
+func LoadDocsBundle(name string) (*DocsBundle, error) {
+	bytes, err := os.ReadFile(getDocsBundlePath(name))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading docs bundle %s: %v", name, err)
+	}
+
+	var bundle DocsBundle
+	err = json.Unmarshal(bytes, &bundle)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling docs bundle %s: %v", name, err)
+	}
+
+	return &bundle, nil
+}
+
+func ListDocsBundles() ([]*DocsBundle, error) {
+	entries, err := os.ReadDir(filepath.Join(fs.PlandexDir, docsBundlesDirName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading docs bundles: %v", err)
+	}
+
+	var bundles []*DocsBundle
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		bundle, err := LoadDocsBundle(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		if bundle != nil {
+			bundles = append(bundles, bundle)
+		}
+	}
+
+	sort.Slice(bundles, func(i, j int) bool {
+		return bundles[i].Name < bundles[j].Name
+	})
+
+	return bundles, nil
+}
+
+func DeleteDocsBundle(name string) error {
+	err := os.Remove(getDocsBundlePath(name))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no docs bundle named %s", name)
+	} else if err != nil {
+		return fmt.Errorf("error removing docs bundle %s: %v", name, err)
+	}
+	return nil
+}
+
+// resolveDocsBundle gets the bundle for a load, crawling it if it isn't cached yet or a refresh was requested
+func resolveDocsBundle(params *types.LoadContextParams) (*DocsBundle, error) {
+	name := params.Docs
+	if name == "" {
+		name = DocsBundleNameForUrl(params.Crawl)
+	}
+	err := ValidateDocsBundleName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle, err := LoadDocsBundle(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if bundle == nil && params.Crawl == "" {
+		return nil, fmt.Errorf("no docs bundle named %s - crawl one with 'plandex load --crawl <url> --docs %s'", name, name)
+	}
+
+	if bundle != nil && params.Crawl != "" && params.Crawl != bundle.StartUrl && !params.RefreshDocs {
+		return nil, fmt.Errorf("docs bundle %s was crawled from %s - use --refresh to re-crawl it from %s, or --docs to pick another name", name, bundle.StartUrl, params.Crawl)
+	}
+
+	if bundle != nil && !params.RefreshDocs {
+		return bundle, nil
+	}
+
+	if bundle == nil {
+		bundle = &DocsBundle{Name: name}
+	}
+
+	// crawl settings from flags replace the cached ones, so a refresh without flags re-crawls the same way
+	if params.Crawl != "" {
+		bundle.StartUrl = params.Crawl
+		bundle.Depth = params.CrawlDepth
+		bundle.Include = params.CrawlInclude
+		bundle.MaxPages = params.CrawlMaxPages
+	}
+
+	term.StartSpinner(fmt.Sprintf("🕸️  Crawling %s...", bundle.Name))
+	err = bundle.Crawl()
+	if err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+func (bundle *DocsBundle) save() error {
+	err := os.MkdirAll(filepath.Join(fs.PlandexDir, docsBundlesDirName), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("error creating docs bundles dir: %v", err)
+	}
+
+	bytes, err := json.Marshal(bundle)
+	if err != nil {
+		return fmt.Errorf("error marshalling docs bundle: %v", err)
+	}
+
+	err = os.WriteFile(getDocsBundlePath(bundle.Name), bytes, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing docs bundle: %v", err)
+	}
+
+	return nil
+}
+
+// Crawl fetches the bundle's pages with its current settings and saves it. The version is bumped when the content changes.
+func (bundle *DocsBundle) Crawl() error {
+	pages, err := url.Crawl(url.CrawlParams{
+		StartUrl: bundle.StartUrl,
+		Depth:    bundle.Depth,
+		Include:  bundle.Include,
+		MaxPages: bundle.MaxPages,
+		OnPage: func(numPages int, pageUrl string) {
+			term.StartSpinner(fmt.Sprintf("🕸️  Crawling %s... %d pages", bundle.Name, numPages))
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to crawl %s: %v", bundle.StartUrl, err)
+	}
+
+	hash := sha256.New()
+	for _, page := range pages {
+		hash.Write([]byte(page.Url + "\n" + page.Markdown + "\n"))
+	}
+	sha := hex.EncodeToString(hash.Sum(nil))
+
+	if sha != bundle.Sha {
+		bundle.Version++
+		bundle.Sha = sha
+	}
+	bundle.Pages = pages
+	bundle.CrawledAt = time.Now()
+
+	return bundle.save()
+}
+
+func (bundle *DocsBundle) sections() []document.Section {
+	sections := make([]document.Section, len(bundle.Pages))
+	for i, page := range bundle.Pages {
+		title := page.Title
+		if title == "" {
+			title = page.Url
+		}
+		sections[i] = document.Section{
+			Title: title,
+			Page:  i + 1,
+			Text:  fmt.Sprintf("# %s\nSource: %s\n\n%s", title, page.Url, page.Markdown),
+		}
+	}
+	return sections
+}
+
+// loadParams splits the bundle's pages into context parts, the same way long documents are split
+func (bundle *DocsBundle) loadParams(autoLoaded bool) []*shared.LoadContextParams {
+	sections := bundle.sections()
+	parts := document.Chunk(sections, document.MaxPartTokens)
+
+	var res []*shared.LoadContextParams
+	for i, part := range parts {
+		res = append(res, &shared.LoadContextParams{
+			ContextType: shared.ContextDocsType,
+			Name:        documentPartName(bundle.Name, i, len(parts)),
+			Url:         bundle.StartUrl,
+			Body:        document.RenderPart(sections, part.Start, part.End),
+			DocsBundle:  bundle.Name,
+			StartLine:   part.Start,
+			EndLine:     part.End,
+			AutoLoaded:  autoLoaded,
+		})
+	}
+	return res
+}
@@ -3,11 +3,15 @@ package lib
 import (
 	"fmt"
 	"log"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"sync"
 	"time"
+
+	shared "plandex-shared"
 )
 
 var gitMutex sync.Mutex
@@ -248,3 +252,219 @@ func parseConflictFiles(gitOutput string) []string {
 	}
 	return conflictFiles
 }
+
+// GitAddScratchWorktree checks out HEAD of the repo containing dir into a new temporary worktree. It returns the path within the worktree that corresponds to dir, along with a cleanup func that removes the worktree.
+func GitAddScratchWorktree(dir string) (string, func(), error) {
+	prefix, err := exec.Command("git", "-C", dir, "rev-parse", "--show-prefix").Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("error getting git prefix for dir: %s, err: %v", dir, err)
+	}
+
+	worktreeDir, err := os.MkdirTemp("", "plandex-worktree-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("error creating temp dir: %v", err)
+	}
+
+	gitMutex.Lock()
+	res, err := exec.Command("git", "-C", dir, "worktree", "add", "--detach", worktreeDir, "HEAD").CombinedOutput()
+	gitMutex.Unlock()
+	if err != nil {
+		os.RemoveAll(worktreeDir)
+		return "", nil, fmt.Errorf("error adding git worktree for dir: %s, err: %v, output: %s", dir, err, string(res))
+	}
+
+	cleanup := func() {
+		gitMutex.Lock()
+		defer gitMutex.Unlock()
+		res, err := exec.Command("git", "-C", dir, "worktree", "remove", "--force", worktreeDir).CombinedOutput()
+		if err != nil {
+			log.Printf("Error removing git worktree %s: %v, output: %s", worktreeDir, err, string(res))
+		}
+		os.RemoveAll(worktreeDir)
+	}
+
+	return filepath.Join(worktreeDir, strings.TrimSpace(string(prefix))), cleanup, nil
+}
+
+func GitBranchExists(dir, branch string) bool {
+	gitMutex.Lock()
+	defer gitMutex.Unlock()
+
+	err := exec.Command("git", "-C", dir, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch).Run()
+	return err == nil
+}
+
+func GitCheckoutNewBranch(dir, branch string) error {
+	gitMutex.Lock()
+	defer gitMutex.Unlock()
+
+	res, err := exec.Command("git", "-C", dir, "checkout", "-b", branch).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error creating branch %s | err: %v, output: %s", branch, err, string(res))
+	}
+
+	return nil
+}
+
+// GitHeadCommit returns the HEAD commit's message along with the paths it changed, relative to dir
+func GitHeadCommit(dir string) (string, []string, error) {
+	gitMutex.Lock()
+	defer gitMutex.Unlock()
+
+	msg, err := exec.Command("git", "-C", dir, "log", "-1", "--format=%B").Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("error getting HEAD commit message for dir: %s, err: %v", dir, err)
+	}
+
+	res, err := exec.Command("git", "-C", dir, "diff-tree", "--no-commit-id", "--name-only", "--relative", "-r", "HEAD").Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("error getting HEAD commit paths for dir: %s, err: %v", dir, err)
+	}
+
+	var paths []string
+	for _, line := range strings.Split(string(res), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			paths = append(paths, line)
+		}
+	}
+
+	return strings.TrimSpace(string(msg)), paths, nil
+}
+
+// GitUndoHeadCommit removes the HEAD commit while leaving its changes staged
+func GitUndoHeadCommit(dir string) error {
+	gitMutex.Lock()
+	defer gitMutex.Unlock()
+
+	res, err := exec.Command("git", "-C", dir, "reset", "--soft", "HEAD~1").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error undoing HEAD commit for dir: %s, err: %v, output: %s", dir, err, string(res))
+	}
+
+	return nil
+}
+
+func GitPushBranch(dir, remote, branch string) error {
+	gitMutex.Lock()
+	defer gitMutex.Unlock()
+
+	cmd := exec.Command("git", "-C", dir, "push", "-u", remote, branch)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("error pushing branch %s to %s: %v", branch, remote, err)
+	}
+
+	return nil
+}
+
+func GitRefExists(ref string) bool {
+	gitMutex.Lock()
+	defer gitMutex.Unlock()
+
+	_, err := resolveGitCommit(ref)
+	return err == nil
+}
+
+// GitResolveCommit resolves a ref to a full commit sha so a commit context stays on the same commit
+func GitResolveCommit(ref string) (string, error) {
+	gitMutex.Lock()
+	defer gitMutex.Unlock()
+
+	return resolveGitCommit(ref)
+}
+
+// resolveGitCommit resolves ref to a full commit sha. Refs can come from plan data that anyone with access to the plan can write, so a ref that git could parse as an option is refused. gitMutex must be held.
+func resolveGitCommit(ref string) (string, error) {
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return "", fmt.Errorf("invalid git ref %q", ref)
+	}
+
+	res, err := exec.Command("git", "rev-parse", "--verify", "--quiet", "--end-of-options", ref+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("commit %s not found", ref)
+	}
+
+	return strings.TrimSpace(string(res)), nil
+}
+
+// GitDiffForContext returns the unified diff for a git diff context along with the paths it touches, relative to the current directory. Deleted files are left out of the paths.
+func GitDiffForContext(mode shared.GitDiffMode, ref string) (string, []string, error) {
+	gitMutex.Lock()
+	defer gitMutex.Unlock()
+
+	var diffArgs []string
+	var pathArgs []string
+
+	switch mode {
+	case shared.GitDiffModeDiff:
+		base := "HEAD"
+		if ref != "" && ref != "HEAD" {
+			sha, err := resolveGitCommit(ref)
+			if err != nil {
+				return "", nil, err
+			}
+			base = sha
+
+			// compare against where the branch diverged so changes made on the base since then aren't included
+			res, err := exec.Command("git", "merge-base", "--end-of-options", sha, "HEAD").Output()
+			if err == nil {
+				base = strings.TrimSpace(string(res))
+			}
+		}
+		diffArgs = []string{"diff", "--no-color", "--no-ext-diff", "--relative", "--end-of-options", base}
+		pathArgs = []string{"diff", "--name-only", "--diff-filter=d", "--relative", "--end-of-options", base}
+	case shared.GitDiffModeCommit:
+		sha, err := resolveGitCommit(ref)
+		if err != nil {
+			return "", nil, err
+		}
+		diffArgs = []string{"show", "--no-color", "--no-ext-diff", "--relative", "--format=commit %H%nAuthor: %an <%ae>%nDate: %ad%n%n%B", "--end-of-options", sha}
+		pathArgs = []string{"diff-tree", "--root", "--no-commit-id", "--name-only", "--diff-filter=d", "--relative", "-r", "--end-of-options", sha}
+	case shared.GitDiffModeStaged:
+		diffArgs = []string{"diff", "--cached", "--no-color", "--no-ext-diff", "--relative"}
+		pathArgs = []string{"diff", "--cached", "--name-only", "--diff-filter=d", "--relative"}
+	default:
+		return "", nil, fmt.Errorf("unknown git diff mode: %s", mode)
+	}
+
+	diff, err := exec.Command("git", diffArgs...).CombinedOutput()
+	if err != nil {
+		return "", nil, fmt.Errorf("error getting git diff: %v, output: %s", err, string(diff))
+	}
+
+	res, err := exec.Command("git", pathArgs...).CombinedOutput()
+	if err != nil {
+		return "", nil, fmt.Errorf("error getting git diff paths: %v, output: %s", err, string(res))
+	}
+
+	var paths []string
+	for _, line := range strings.Split(string(res), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			paths = append(paths, line)
+		}
+	}
+
+	return strings.TrimRight(string(diff), "\n"), paths, nil
+}
+
+// GitDiffContextName is the display name for a git diff context
+func GitDiffContextName(mode shared.GitDiffMode, ref string) string {
+	switch mode {
+	case shared.GitDiffModeCommit:
+		if len(ref) > 7 {
+			ref = ref[:7]
+		}
+		return "git commit " + ref
+	case shared.GitDiffModeStaged:
+		return "git staged"
+	}
+	if ref == "" || ref == "HEAD" {
+		return "git diff"
+	}
+	return "git diff " + ref
+}
@@ -0,0 +1,117 @@
+package lib
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestGitDiffForContext(t *testing.T) {
+	dir := t.TempDir()
+	outputPath := filepath.Join(t.TempDir(), "overwritten")
+
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(origDir)
+
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q", "-b", "main")
+	write("a.go", "package a\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "first")
+	git("checkout", "-q", "-b", "feature")
+	write("b.go", "package b\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "second")
+	write("a.go", "package a\n\nfunc A() {}\n")
+
+	tests := []struct {
+		name      string
+		mode      shared.GitDiffMode
+		ref       string
+		wantPaths []string
+		wantErr   bool
+	}{
+		{name: "working tree", mode: shared.GitDiffModeDiff, wantPaths: []string{"a.go"}},
+		{name: "against a branch", mode: shared.GitDiffModeDiff, ref: "main", wantPaths: []string{"a.go", "b.go"}},
+		{name: "commit", mode: shared.GitDiffModeCommit, ref: "HEAD", wantPaths: []string{"b.go"}},
+		{name: "option as diff base", mode: shared.GitDiffModeDiff, ref: "--output=" + outputPath, wantErr: true},
+		{name: "option as commit", mode: shared.GitDiffModeCommit, ref: "--output=" + outputPath, wantErr: true},
+		{name: "empty commit", mode: shared.GitDiffModeCommit, ref: "", wantErr: true},
+		{name: "unknown ref", mode: shared.GitDiffModeDiff, ref: "missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, paths, err := GitDiffForContext(tt.mode, tt.ref)
+
+			if _, statErr := os.Stat(outputPath); statErr == nil {
+				t.Fatalf("ref %q was passed to git as an option", tt.ref)
+			}
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got diff:\n%s", diff)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GitDiffForContext: %v", err)
+			}
+			if !reflect.DeepEqual(paths, tt.wantPaths) {
+				t.Errorf("paths = %v, want %v", paths, tt.wantPaths)
+			}
+			for _, path := range tt.wantPaths {
+				if !strings.Contains(diff, "+++ b/"+path) {
+					t.Errorf("diff is missing %s:\n%s", path, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestGitDiffPaths(t *testing.T) {
+	diff := strings.Join([]string{
+		"diff --git a/a.go b/a.go",
+		"--- a/a.go",
+		"+++ b/a.go",
+		"@@ -1 +1 @@",
+		"-+++ b/not-a-header.go",
+		"diff --git a/old.go b/new.go",
+		"--- a/old.go",
+		"+++ b/new.go",
+		"diff --git a/gone.go b/gone.go",
+		"--- a/gone.go",
+		"+++ /dev/null",
+	}, "\n")
+
+	want := map[string]bool{"a.go": true, "new.go": true}
+	if got := gitDiffPaths(diff); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
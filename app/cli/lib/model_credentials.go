@@ -49,6 +49,11 @@ type CredentialCheckResult struct {
 func CheckCredentialStatus(opts shared.ModelProviderOptions, claudeMaxEnabled bool) (CredentialCheckResult, error) {
 	publishersToProviders := groupProvidersByPublisher(opts)
 
+	orgSecretNames, err := getOrgSecretNames()
+	if err != nil {
+		return CredentialCheckResult{}, fmt.Errorf("error checking API keys/credentials: %v", err)
+	}
+
 	selectedAuthVars := map[string]string{}
 	var publisherStatuses []PublisherCredentialStatus
 	allSatisfied := true
@@ -66,7 +71,7 @@ func CheckCredentialStatus(opts shared.ModelProviderOptions, claudeMaxEnabled bo
 			if err != nil {
 				return CredentialCheckResult{}, fmt.Errorf("error checking API keys/credentials: %v", err)
 			}
-			status, missingVars, err := checkProviderCredentialStatus(provider.Config, authVars)
+			status, missingVars, err := checkProviderCredentialStatus(provider.Config, authVars, orgSecretNames)
 			if err != nil {
 				return CredentialCheckResult{}, fmt.Errorf("error checking API keys/credentials: %v", err)
 			}
@@ -121,7 +126,8 @@ func groupProvidersByPublisher(opts shared.ModelProviderOptions) map[shared.Mode
 	return grouped
 }
 
-func checkProviderCredentialStatus(cfg *shared.ModelProviderConfigSchema, authVars map[string]string) (ProviderAuthStatus, []string, error) {
+// orgSecretNames are credentials stored in the org vault—the server fills these in, so they count as set
+func checkProviderCredentialStatus(cfg *shared.ModelProviderConfigSchema, authVars map[string]string, orgSecretNames map[string]bool) (ProviderAuthStatus, []string, error) {
 	var missing []string
 
 	if cfg.SkipAuth {
@@ -138,12 +144,12 @@ func checkProviderCredentialStatus(cfg *shared.ModelProviderConfigSchema, authVa
 		}
 	}
 
-	if cfg.ApiKeyEnvVar != "" && authVars[cfg.ApiKeyEnvVar] == "" {
+	if cfg.ApiKeyEnvVar != "" && authVars[cfg.ApiKeyEnvVar] == "" && !orgSecretNames[cfg.ApiKeyEnvVar] {
 		missing = append(missing, cfg.ApiKeyEnvVar)
 	}
 
 	for _, extra := range cfg.ExtraAuthVars {
-		if extra.Required && authVars[extra.Var] == "" {
+		if extra.Required && authVars[extra.Var] == "" && !orgSecretNames[extra.Var] {
 			missing = append(missing, extra.Var)
 		}
 	}
@@ -268,15 +274,22 @@ func ResolveProviderAuthVars(cfg *shared.ModelProviderConfigSchema) (map[string]
 		}
 	}
 
+	// env vars, then the local keyring, then Vault (if configured)
 	if cfg.ApiKeyEnvVar != "" {
-		val := os.Getenv(cfg.ApiKeyEnvVar)
+		val, err := lookupCredential(cfg.ApiKeyEnvVar)
+		if err != nil {
+			return nil, err
+		}
 		if val != "" {
 			authVars[cfg.ApiKeyEnvVar] = val
 		}
 	}
 
 	for _, extra := range cfg.ExtraAuthVars {
-		val := os.Getenv(extra.Var)
+		val, err := lookupCredential(extra.Var)
+		if err != nil {
+			return nil, err
+		}
 		if val == "" && extra.Default != "" {
 			val = extra.Default
 		}
@@ -499,6 +512,11 @@ func showCredentialErrorMessage(res CredentialCheckResult, opts shared.ModelProv
 		fmt.Println("For details on the API key/credentials required for each provider, go to:\n" + color.New(color.Bold).Sprint("https://docs.plandex.ai/models/model-providers"))
 	}
 
+	fmt.Println()
+	fmt.Println(color.New(term.ColorHiCyan, color.Bold).Sprint("🔐 Storing credentials"))
+	fmt.Println("Instead of exporting env vars, you can store credentials in the local keyring, your org's vault, or a Vault server (set PLANDEX_VAULT_ADDR and PLANDEX_VAULT_TOKEN)")
+	term.PrintCmds("", "credentials set", "credentials set --org")
+
 	fmt.Println()
 }
 
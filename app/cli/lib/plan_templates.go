@@ -0,0 +1,132 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"plandex-cli/api"
+	"plandex-cli/fs"
+	"sort"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+// in-repo templates live alongside .plandexignore so they can be committed and shared with the project
+const PlanTemplatesDirName = ".plandex-templates"
+
+const (
+	PlanTemplateSourceRepo = "repo"
+	PlanTemplateSourceOrg  = "org"
+)
+
+type PlanTemplateWithSource struct {
+	*shared.PlanTemplate
+	Source string
+	Path   string
+}
+
+func GetPlanTemplatesDir() string {
+	root := fs.ProjectRoot
+	if root == "" {
+		root = fs.Cwd
+	}
+	return filepath.Join(root, PlanTemplatesDirName)
+}
+
+// ReadPlanTemplateFile parses and validates a template json file. The file name (without extension) is used as the template name if the file doesn't set one.
+func ReadPlanTemplateFile(path string) (*shared.PlanTemplate, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading template file: %v", err)
+	}
+
+	var template shared.PlanTemplate
+	err = json.Unmarshal(bytes, &template)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template file %s: %v", path, err)
+	}
+
+	if template.Name == "" {
+		template.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	err = template.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid template file %s: %v", path, err)
+	}
+
+	return &template, nil
+}
+
+func ListRepoPlanTemplates() ([]*PlanTemplateWithSource, error) {
+	dir := GetPlanTemplatesDir()
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("error listing templates in %s: %v", dir, err)
+	}
+
+	sort.Strings(paths)
+
+	var templates []*PlanTemplateWithSource
+	for _, path := range paths {
+		template, err := ReadPlanTemplateFile(path)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, &PlanTemplateWithSource{
+			PlanTemplate: template,
+			Source:       PlanTemplateSourceRepo,
+			Path:         path,
+		})
+	}
+
+	return templates, nil
+}
+
+// ListPlanTemplates returns in-repo templates followed by org templates. An in-repo template shadows an org template with the same name.
+func ListPlanTemplates() ([]*PlanTemplateWithSource, error) {
+	templates, err := ListRepoPlanTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	orgTemplates, apiErr := api.Client.ListPlanTemplates()
+	if apiErr != nil {
+		return nil, fmt.Errorf("error listing org templates: %v", apiErr.Msg)
+	}
+
+	inRepo := map[string]bool{}
+	for _, template := range templates {
+		inRepo[template.Name] = true
+	}
+
+	for _, template := range orgTemplates {
+		if inRepo[template.Name] {
+			continue
+		}
+		templates = append(templates, &PlanTemplateWithSource{
+			PlanTemplate: template,
+			Source:       PlanTemplateSourceOrg,
+		})
+	}
+
+	return templates, nil
+}
+
+func GetPlanTemplate(name string) (*PlanTemplateWithSource, error) {
+	templates, err := ListPlanTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, template := range templates {
+		if template.Name == name {
+			return template, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no template named '%s' in %s or in your org", name, PlanTemplatesDirName)
+}
@@ -117,13 +117,13 @@ func AnalyzeRewind(targetState, currentState *shared.CurrentPlanState) (*RewindA
 
 	// Add paths from both states
 	for path, context := range targetState.ContextsByPath {
-		if context.ContextType != shared.ContextFileType {
+		if !hasFullFileBody(context) {
 			continue
 		}
 		allPaths[path] = true
 	}
 	for path, context := range currentState.ContextsByPath {
-		if context.ContextType != shared.ContextFileType {
+		if !hasFullFileBody(context) {
 			continue
 		}
 		allPaths[path] = true
@@ -213,6 +213,11 @@ func AnalyzeRewind(targetState, currentState *shared.CurrentPlanState) (*RewindA
 	}, nil
 }
 
+// hasFullFileBody is true for contexts whose body is the whole file - range contexts store the full file too, with the range marked by StartLine and EndLine
+func hasFullFileBody(context *shared.Context) bool {
+	return context.ContextType == shared.ContextFileType || context.ContextType == shared.ContextFileRangeType
+}
+
 // RemoveEmptyDirs recursively removes empty directories starting from the given path
 func RemoveEmptyDirs(path string, baseDir string) error {
 	// Check if the path is a directory
@@ -0,0 +1,98 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"plandex-cli/fs"
+	"reflect"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestAnalyzeRewind(t *testing.T) {
+	origProjectRoot := fs.ProjectRoot
+	fs.ProjectRoot = t.TempDir()
+	defer func() { fs.ProjectRoot = origProjectRoot }()
+
+	fileCtx := func(path, body string) *shared.Context {
+		return &shared.Context{ContextType: shared.ContextFileType, FilePath: path, Body: body}
+	}
+	rangeCtx := func(path, body string, start, end int) *shared.Context {
+		return &shared.Context{ContextType: shared.ContextFileRangeType, FilePath: path, Body: body, StartLine: start, EndLine: end}
+	}
+
+	tests := []struct {
+		name          string
+		disk          map[string]string
+		current       map[string]*shared.Context
+		target        map[string]*shared.Context
+		wantChanges   map[string]string
+		wantConflicts map[string]bool
+	}{
+		{
+			name:          "file reverted",
+			disk:          map[string]string{"a.go": "new"},
+			current:       map[string]*shared.Context{"a.go": fileCtx("a.go", "new")},
+			target:        map[string]*shared.Context{"a.go": fileCtx("a.go", "old")},
+			wantChanges:   map[string]string{"a.go": "old"},
+			wantConflicts: map[string]bool{},
+		},
+		{
+			name:          "range context reverted to the full file",
+			disk:          map[string]string{"b.go": "one\ntwo changed\nthree"},
+			current:       map[string]*shared.Context{"b.go": rangeCtx("b.go", "one\ntwo changed\nthree", 2, 2)},
+			target:        map[string]*shared.Context{"b.go": rangeCtx("b.go", "one\ntwo\nthree", 2, 2)},
+			wantChanges:   map[string]string{"b.go": "one\ntwo\nthree"},
+			wantConflicts: map[string]bool{},
+		},
+		{
+			name:          "range context modified on disk",
+			disk:          map[string]string{"c.go": "edited locally"},
+			current:       map[string]*shared.Context{"c.go": rangeCtx("c.go", "one\ntwo changed", 1, 1)},
+			target:        map[string]*shared.Context{"c.go": rangeCtx("c.go", "one\ntwo", 1, 1)},
+			wantChanges:   map[string]string{"c.go": "one\ntwo"},
+			wantConflicts: map[string]bool{"c.go": true},
+		},
+		{
+			name:          "unchanged range context",
+			disk:          map[string]string{"d.go": "same"},
+			current:       map[string]*shared.Context{"d.go": rangeCtx("d.go", "same", 1, 1)},
+			target:        map[string]*shared.Context{"d.go": rangeCtx("d.go", "same", 1, 1)},
+			wantChanges:   map[string]string{},
+			wantConflicts: map[string]bool{},
+		},
+		{
+			name:          "non-file contexts are ignored",
+			current:       map[string]*shared.Context{"notes": {ContextType: shared.ContextNoteType, FilePath: "notes", Body: "x"}},
+			target:        map[string]*shared.Context{},
+			wantChanges:   map[string]string{},
+			wantConflicts: map[string]bool{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for path, content := range tt.disk {
+				if err := os.WriteFile(filepath.Join(fs.ProjectRoot, path), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			analysis, err := AnalyzeRewind(
+				&shared.CurrentPlanState{ContextsByPath: tt.target},
+				&shared.CurrentPlanState{ContextsByPath: tt.current},
+			)
+			if err != nil {
+				t.Fatalf("AnalyzeRewind: %v", err)
+			}
+
+			if !reflect.DeepEqual(analysis.RequiredChanges, tt.wantChanges) {
+				t.Errorf("required changes = %v, want %v", analysis.RequiredChanges, tt.wantChanges)
+			}
+			if !reflect.DeepEqual(analysis.Conflicts, tt.wantConflicts) {
+				t.Errorf("conflicts = %v, want %v", analysis.Conflicts, tt.wantConflicts)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package plan_exec
+
+import (
+	"fmt"
+	"log"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"plandex-cli/types"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+)
+
+// getRetrievedChunks searches the project's embeddings index for code related to the prompt when retrieval is enabled. Retrieval only supplements auto context, so errors are shown as a warning and the prompt is sent without it.
+func getRetrievedChunks(prompt string, autoContext bool, paths *types.ProjectPaths) []*shared.RetrievedChunk {
+	if prompt == "" || !autoContext || !lib.MustGetCurrentPlanConfig().Retrieval {
+		return nil
+	}
+
+	term.StartSpinner("🔎 Searching project index...")
+	chunks, err := lib.RetrieveChunks(prompt, paths.ActivePaths)
+	term.StopSpinner()
+
+	if err != nil {
+		log.Printf("error retrieving chunks: %v", err)
+		fmt.Println(color.New(term.ColorHiYellow).Sprintf("⚠️  Retrieval skipped: %v", err))
+		return nil
+	}
+
+	log.Printf("retrieved %d chunks", len(chunks))
+
+	return chunks
+}
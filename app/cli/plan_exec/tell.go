@@ -106,6 +106,11 @@ func TellPlan(
 		os.Exit(0)
 	}
 
+	var retrievedChunks []*shared.RetrievedChunk
+	if !isUserContinue {
+		retrievedChunks = getRetrievedChunks(prompt, autoContext, paths)
+	}
+
 	var fn func() bool
 	fn = func() bool {
 
@@ -136,6 +141,7 @@ func TellPlan(
 			ConnectStream:          !tellBg,
 			AutoContinue:           !tellStop,
 			ProjectPaths:           paths.ActivePaths,
+			RetrievedChunks:        retrievedChunks,
 			BuildMode:              buildMode,
 			IsUserContinue:         isUserContinue,
 			IsUserDebug:            isDebugCmd,
@@ -0,0 +1,549 @@
+package plan_exec
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"plandex-cli/api"
+	"plandex-cli/fs"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+)
+
+const variantCheckTimeout = 5 * time.Minute
+
+type variant struct {
+	branch string
+	label  string
+
+	status   string
+	errMsg   string
+	numFiles int
+	added    int
+	removed  int
+	failed   int
+	checked  bool
+	passed   bool
+	checkMsg string
+}
+
+// TellVariants sends the same prompt on several new branches at once, each with its own model pack or temperature, then compares the results so one can be kept
+func TellVariants(params ExecParams, prompt string, flags types.TellFlags, variantFlags types.VariantFlags) {
+	term.StartSpinner("")
+
+	err := PromptSyncModelsIfNeeded()
+	if err != nil {
+		term.OutputErrorAndExit("Error syncing models: %v", err)
+	}
+
+	term.StartSpinner("")
+
+	contexts, apiErr := api.Client.ListContext(params.CurrentPlanId, params.CurrentBranch)
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting context: %v", apiErr)
+	}
+
+	paths, err := fs.GetProjectPaths(fs.GetBaseDirForContexts(contexts))
+	if err != nil {
+		term.OutputErrorAndExit("Error getting project paths: %v", err)
+	}
+
+	anyOutdated, didUpdate, err := params.CheckOutdatedContext(contexts, paths)
+	if err != nil {
+		term.OutputErrorAndExit("Error checking outdated context: %v", err)
+	}
+
+	if anyOutdated && !didUpdate {
+		term.StopSpinner()
+		color.New(term.ColorHiRed, color.Bold).Println("🛑 Variants won't run due to outdated context")
+		os.Exit(0)
+	}
+
+	retrievedChunks := getRetrievedChunks(prompt, flags.AutoContext, paths)
+
+	term.StartSpinner("🌱 Creating variant branches...")
+
+	originalSettings, apiErr := api.Client.GetSettings(params.CurrentPlanId, params.CurrentBranch)
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting settings: %v", apiErr)
+	}
+
+	variants, err := createVariantBranches(params, originalSettings, variantFlags)
+	if err != nil {
+		term.OutputErrorAndExit("Error creating variant branches: %v", err)
+	}
+
+	var osDetails string
+	if flags.ExecEnabled {
+		osDetails = term.GetOsDetails()
+	}
+	isGitRepo := fs.ProjectRootIsGitRepo()
+
+	buildMode := shared.BuildModeAuto
+	if flags.TellNoBuild {
+		buildMode = shared.BuildModeNone
+	}
+
+	req := shared.TellPlanRequest{
+		Prompt:                 prompt,
+		ConnectStream:          true,
+		AutoContinue:           !flags.TellStop,
+		ProjectPaths:           paths.ActivePaths,
+		RetrievedChunks:        retrievedChunks,
+		BuildMode:              buildMode,
+		AutoContext:            flags.AutoContext,
+		SmartContext:           flags.SmartContext,
+		ExecEnabled:            flags.ExecEnabled,
+		OsDetails:              osDetails,
+		AuthVars:               params.AuthVars,
+		IsImplementationOfChat: flags.IsImplementationOfChat,
+		IsGitRepo:              isGitRepo,
+		SessionId:              os.Getenv("PLANDEX_REPL_SESSION_ID"),
+	}
+
+	doneCh := make(chan *variant, len(variants))
+	for _, v := range variants {
+		go runVariant(params.CurrentPlanId, v, req, doneCh)
+	}
+
+	for i := range variants {
+		term.StartSpinner(fmt.Sprintf("⚡️ Running %d variants... %d/%d finished", len(variants), i, len(variants)))
+		<-doneCh
+	}
+
+	term.StartSpinner("🔬 Comparing variants...")
+
+	for _, v := range variants {
+		if v.status == "error" {
+			continue
+		}
+		compareVariant(params.CurrentPlanId, v, variantFlags)
+	}
+
+	term.StopSpinner()
+
+	printVariants(variants)
+
+	keepVariant(params, originalSettings, variants, len(variantFlags.ModelPacks) > 0 || len(variantFlags.Temperatures) > 0)
+}
+
+func createVariantBranches(params ExecParams, originalSettings *shared.PlanSettings, variantFlags types.VariantFlags) ([]*variant, error) {
+	branches, apiErr := api.Client.ListBranches(params.CurrentPlanId)
+	if apiErr != nil {
+		return nil, fmt.Errorf("error getting branches: %v", apiErr.Msg)
+	}
+
+	existing := map[string]bool{}
+	for _, b := range branches {
+		existing[b.Name] = true
+	}
+
+	// pick a run number that doesn't collide with branches from an earlier run
+	var names []string
+	for run := 1; ; run++ {
+		names = nil
+		collides := false
+		for i := 1; i <= variantFlags.NumVariants; i++ {
+			name := fmt.Sprintf("%s-v%d", params.CurrentBranch, i)
+			if run > 1 {
+				name = fmt.Sprintf("%s-r%d-v%d", params.CurrentBranch, run, i)
+			}
+			if existing[name] {
+				collides = true
+				break
+			}
+			names = append(names, name)
+		}
+		if !collides {
+			break
+		}
+	}
+
+	var variants []*variant
+
+	for i, name := range names {
+		apiErr := api.Client.CreateBranch(params.CurrentPlanId, params.CurrentBranch, shared.CreateBranchRequest{Name: name})
+		if apiErr != nil {
+			return nil, fmt.Errorf("error creating branch %s: %v", name, apiErr.Msg)
+		}
+
+		v := &variant{branch: name}
+		var labelParts []string
+
+		packName := originalSettings.ModelPackName
+		if len(variantFlags.ModelPacks) > 0 {
+			packName = variantFlags.ModelPacks[i%len(variantFlags.ModelPacks)]
+
+			_, apiErr := api.Client.UpdateSettings(params.CurrentPlanId, name, shared.UpdateSettingsRequest{ModelPackName: packName})
+			if apiErr != nil {
+				return nil, fmt.Errorf("error setting model pack for %s: %v", name, apiErr.Msg)
+			}
+		}
+		labelParts = append(labelParts, packName)
+
+		if len(variantFlags.Temperatures) > 0 {
+			temperature := variantFlags.Temperatures[i%len(variantFlags.Temperatures)]
+
+			settings, apiErr := api.Client.GetSettings(params.CurrentPlanId, name)
+			if apiErr != nil {
+				return nil, fmt.Errorf("error getting settings for %s: %v", name, apiErr.Msg)
+			}
+
+			pack := withVariantTemperature(settings, temperature)
+
+			_, apiErr = api.Client.UpdateSettings(params.CurrentPlanId, name, shared.UpdateSettingsRequest{ModelPack: pack})
+			if apiErr != nil {
+				return nil, fmt.Errorf("error setting temperature for %s: %v", name, apiErr.Msg)
+			}
+
+			labelParts = append(labelParts, "temp "+strconv.FormatFloat(float64(temperature), 'f', -1, 32))
+		}
+
+		v.label = strings.Join(labelParts, " · ")
+		variants = append(variants, v)
+	}
+
+	return variants, nil
+}
+
+// withVariantTemperature returns a copy of the branch's model pack with the planner and coder temperatures overridden. settings.ModelPack is only set for custom packs, so the pack is resolved by name otherwise.
+func withVariantTemperature(settings *shared.PlanSettings, temperature float32) *shared.ModelPack {
+	pack := *settings.GetModelPack()
+	pack.Planner.Temperature = temperature
+	if pack.Coder != nil {
+		coder := *pack.Coder
+		coder.Temperature = temperature
+		pack.Coder = &coder
+	}
+	return &pack
+}
+
+func runVariant(planId string, v *variant, req shared.TellPlanRequest, doneCh chan<- *variant) {
+	var once sync.Once
+	finish := func(status, errMsg string) {
+		once.Do(func() {
+			v.status = status
+			v.errMsg = errMsg
+			doneCh <- v
+		})
+	}
+
+	var handle func(msg shared.StreamMessage)
+	handle = func(msg shared.StreamMessage) {
+		switch msg.Type {
+		case shared.StreamMessageMulti:
+			for _, m := range msg.StreamMessages {
+				handle(m)
+			}
+		case shared.StreamMessagePromptMissingFile:
+			// nobody is watching a variant's stream, so skip files that weren't loaded rather than waiting on a prompt
+			apiErr := api.Client.RespondMissingFile(planId, v.branch, shared.RespondMissingFileRequest{
+				Choice:   shared.RespondMissingFileChoiceSkip,
+				FilePath: msg.MissingFilePath,
+			})
+			if apiErr != nil {
+				log.Printf("Error responding to missing file for variant %s: %v", v.branch, apiErr.Msg)
+				// the stream would wait on the response forever
+				finish("error", "error skipping missing file: "+apiErr.Msg)
+			}
+		case shared.StreamMessageFinished:
+			finish("finished", "")
+		case shared.StreamMessageAborted:
+			finish("stopped", "")
+		case shared.StreamMessageError:
+			errMsg := "stream error"
+			if msg.Error != nil {
+				errMsg = msg.Error.Msg
+			}
+			finish("error", errMsg)
+		}
+	}
+
+	apiErr := api.Client.TellPlan(planId, v.branch, req, func(params types.OnStreamPlanParams) {
+		if params.Err != nil {
+			finish("error", params.Err.Error())
+			return
+		}
+		if params.Msg == nil {
+			finish("error", "stream closed before the variant finished")
+			return
+		}
+		handle(*params.Msg)
+	})
+
+	if apiErr != nil {
+		finish("error", apiErr.Msg)
+	}
+}
+
+func compareVariant(planId string, v *variant, variantFlags types.VariantFlags) {
+	state, apiErr := api.Client.GetCurrentPlanState(planId, v.branch)
+	if apiErr != nil {
+		v.errMsg = "error getting plan state: " + apiErr.Msg
+		return
+	}
+
+	for _, path := range state.PlanResult.SortedPaths {
+		if path != "_apply.sh" {
+			v.numFiles++
+		}
+	}
+
+	for _, result := range state.PlanResult.Results {
+		if !result.IsPending() {
+			continue
+		}
+		if result.AnyFailed {
+			v.failed++
+			continue
+		}
+		for _, replacement := range result.Replacements {
+			if replacement.Failed {
+				v.failed++
+				break
+			}
+		}
+	}
+
+	diffs, apiErr := api.Client.GetPlanDiffs(planId, v.branch, true)
+	if apiErr != nil {
+		v.errMsg = "error getting diffs: " + apiErr.Msg
+		return
+	}
+
+	for _, line := range strings.Split(diffs, "\n") {
+		if strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") {
+			continue
+		}
+		if strings.HasPrefix(line, "+") {
+			v.added++
+		} else if strings.HasPrefix(line, "-") {
+			v.removed++
+		}
+	}
+
+	if variantFlags.TestCmd != "" || variantFlags.ExecApply {
+		checkVariant(v, state.CurrentPlanFiles, variantFlags)
+	}
+}
+
+// checkVariant applies a variant's files to a scratch git worktree and runs its _apply.sh and/or the test command there, so the project itself isn't touched
+func checkVariant(v *variant, files *shared.CurrentPlanFiles, variantFlags types.VariantFlags) {
+	v.checked = true
+
+	if !fs.ProjectRootIsGitRepo() {
+		v.checkMsg = "skipped — project isn't a git repo"
+		return
+	}
+
+	root, cleanup, err := lib.GitAddScratchWorktree(fs.ProjectRoot)
+	if err != nil {
+		v.checkMsg = err.Error()
+		return
+	}
+	defer cleanup()
+
+	for path, content := range files.Files {
+		if path == "_apply.sh" {
+			continue
+		}
+		dstPath := filepath.Join(root, path)
+		err := os.MkdirAll(filepath.Dir(dstPath), 0755)
+		if err == nil {
+			err = os.WriteFile(dstPath, []byte(strings.ReplaceAll(content, "\\`\\`\\`", "```")), 0644)
+		}
+		if err != nil {
+			v.checkMsg = fmt.Sprintf("error writing %s: %v", path, err)
+			return
+		}
+	}
+
+	for path := range files.Removed {
+		os.Remove(filepath.Join(root, path))
+	}
+
+	var cmds []string
+	if variantFlags.ExecApply && files.Files["_apply.sh"] != "" {
+		cmds = append(cmds, files.Files["_apply.sh"])
+	}
+	if variantFlags.TestCmd != "" {
+		cmds = append(cmds, variantFlags.TestCmd)
+	}
+
+	if len(cmds) == 0 {
+		v.checked = false
+		return
+	}
+
+	for _, cmdStr := range cmds {
+		ctx, cancel := context.WithTimeout(context.Background(), variantCheckTimeout)
+		cmd := exec.CommandContext(ctx, "bash", "-c", cmdStr)
+		cmd.Dir = root
+		out, err := cmd.CombinedOutput()
+		cancel()
+
+		if err != nil {
+			log.Printf("Variant %s check failed: %v\n%s", v.branch, err, string(out))
+			v.checkMsg = lastLine(string(out))
+			if ctx.Err() == context.DeadlineExceeded {
+				v.checkMsg = "timed out"
+			}
+			return
+		}
+	}
+
+	v.passed = true
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+func printVariants(variants []*variant) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"#", "Branch", "Settings", "Status", "Files", "+/-", "Build", "Test"})
+
+	for i, v := range variants {
+		status := v.status
+		switch v.status {
+		case "finished":
+			status = "✅ finished"
+		case "stopped":
+			status = "⏹️ stopped"
+		case "error":
+			status = "🚨 error"
+		}
+
+		build := "—"
+		test := "—"
+		files := "—"
+		diffStat := "—"
+
+		if v.status != "error" && v.errMsg == "" {
+			files = strconv.Itoa(v.numFiles)
+			diffStat = fmt.Sprintf("+%d / -%d", v.added, v.removed)
+
+			if v.failed > 0 {
+				build = fmt.Sprintf("❌ %d failed", v.failed)
+			} else {
+				build = "✅"
+			}
+
+			if v.checked {
+				if v.passed {
+					test = "✅ passed"
+				} else {
+					test = "❌ failed"
+				}
+			}
+		}
+
+		table.Append([]string{
+			strconv.Itoa(i + 1),
+			v.branch,
+			v.label,
+			status,
+			files,
+			diffStat,
+			build,
+			test,
+		})
+	}
+
+	fmt.Println()
+	table.Render()
+	fmt.Println()
+
+	for _, v := range variants {
+		if v.errMsg != "" {
+			fmt.Printf("🚨 %s: %s\n", color.New(color.Bold).Sprint(v.branch), v.errMsg)
+		}
+		if v.checked && !v.passed && v.checkMsg != "" {
+			fmt.Printf("❌ %s test: %s\n", color.New(color.Bold).Sprint(v.branch), v.checkMsg)
+		}
+	}
+}
+
+// keepVariant merges the selected variant into the original branch and deletes all the variant branches
+func keepVariant(params ExecParams, originalSettings *shared.PlanSettings, variants []*variant, changedSettings bool) {
+	const keepAllOpt = "Keep all variant branches (decide later)"
+
+	var opts []string
+	for i, v := range variants {
+		opts = append(opts, fmt.Sprintf("%d. %s (%s)", i+1, v.branch, v.label))
+	}
+	opts = append(opts, keepAllOpt)
+
+	sel, err := term.SelectFromList(fmt.Sprintf("Which variant do you want to keep on %s?", params.CurrentBranch), opts)
+	if err != nil {
+		term.OutputErrorAndExit("Error selecting variant: %v", err)
+	}
+
+	if sel == keepAllOpt {
+		fmt.Println("✅ Kept all variant branches")
+		fmt.Println()
+		term.PrintCmds("", "branches", "checkout", "merge", "delete-branch")
+		return
+	}
+
+	var winner *variant
+	for i, opt := range opts {
+		if opt == sel {
+			winner = variants[i]
+			break
+		}
+	}
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.MergeBranch(params.CurrentPlanId, params.CurrentBranch, shared.MergeBranchRequest{SourceBranch: winner.branch})
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error merging %s: %v", winner.branch, apiErr.Msg)
+	}
+
+	if len(res.Conflicts) > 0 {
+		term.StopSpinner()
+		fmt.Printf("🚨 Couldn't merge %s into %s. Variant branches were kept.\n", winner.branch, params.CurrentBranch)
+		for _, conflict := range res.Conflicts {
+			fmt.Printf("• %s → %s\n", conflict.Path, conflict.Reason)
+		}
+		os.Exit(1)
+	}
+
+	if changedSettings {
+		// the winner's model settings came along with the merge, so put the original settings back
+		_, apiErr = api.Client.UpdateSettings(params.CurrentPlanId, params.CurrentBranch, shared.UpdateSettingsRequest{
+			ModelPackName: originalSettings.ModelPackName,
+			ModelPack:     originalSettings.ModelPack,
+		})
+		if apiErr != nil {
+			log.Printf("Error restoring settings on %s: %v", params.CurrentBranch, apiErr.Msg)
+		}
+	}
+
+	for _, v := range variants {
+		apiErr := api.Client.DeleteBranch(params.CurrentPlanId, v.branch)
+		if apiErr != nil {
+			log.Printf("Error deleting variant branch %s: %v", v.branch, apiErr.Msg)
+		}
+	}
+	term.StopSpinner()
+
+	fmt.Printf("✅ Kept %s on %s and deleted the variant branches\n", color.New(color.Bold, term.ColorHiCyan).Sprint(winner.branch), color.New(color.Bold, term.ColorHiCyan).Sprint(params.CurrentBranch))
+	fmt.Println()
+	term.PrintCmds("", "diff", "apply", "reject")
+}
@@ -0,0 +1,63 @@
+package plan_exec
+
+import (
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestWithVariantTemperature(t *testing.T) {
+	coder := shared.ModelRoleConfig{Role: shared.ModelRoleCoder, Temperature: 0.2}
+	customPack := &shared.ModelPack{
+		Name:    "custom",
+		Planner: shared.PlannerRoleConfig{ModelRoleConfig: shared.ModelRoleConfig{Role: shared.ModelRolePlanner, Temperature: 0.3}},
+		Coder:   &coder,
+	}
+
+	named := &shared.PlanSettings{}
+	named.SetModelPackByName(shared.ReasoningModelPack.Name)
+	named.Configure(nil, nil, nil, false)
+
+	custom := &shared.PlanSettings{}
+	custom.SetCustomModelPack(customPack)
+	custom.Configure(nil, nil, nil, false)
+
+	tests := []struct {
+		name     string
+		settings *shared.PlanSettings
+		original *shared.ModelPack
+	}{
+		{name: "named pack", settings: named, original: &shared.ReasoningModelPack},
+		{name: "custom pack", settings: custom, original: customPack},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			origPlannerTemp := tt.original.Planner.Temperature
+			var origCoderTemp float32
+			if tt.original.Coder != nil {
+				origCoderTemp = tt.original.Coder.Temperature
+			}
+
+			pack := withVariantTemperature(tt.settings, 0.9)
+
+			if pack.Name != tt.original.Name {
+				t.Errorf("pack name = %q, want %q", pack.Name, tt.original.Name)
+			}
+			if pack.Planner.Temperature != 0.9 {
+				t.Errorf("planner temperature = %v, want 0.9", pack.Planner.Temperature)
+			}
+			if pack.Coder != nil && pack.Coder.Temperature != 0.9 {
+				t.Errorf("coder temperature = %v, want 0.9", pack.Coder.Temperature)
+			}
+
+			// the pack is shared (a built-in or the settings' own pack), so it must not be modified
+			if tt.original.Planner.Temperature != origPlannerTemp {
+				t.Errorf("original planner temperature changed to %v", tt.original.Planner.Temperature)
+			}
+			if tt.original.Coder != nil && tt.original.Coder.Temperature != origCoderTemp {
+				t.Errorf("original coder temperature changed to %v", tt.original.Coder.Temperature)
+			}
+		})
+	}
+}
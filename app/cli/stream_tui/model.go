@@ -72,6 +72,9 @@ type streamUIModel struct {
 
 	prompt string
 
+	// context left out or sent as maps to fit the token limit on the latest request
+	prunedContext []*shared.PrunedContext
+
 	stopped    bool
 	background bool
 	finished   bool
@@ -85,6 +88,16 @@ type streamUIModel struct {
 
 	buildViewCollapsed bool
 	userToggledBuild   bool
+
+	splitView      bool
+	splitFocusDiff bool
+	selectedPath   string
+	diffsByPath    map[string]string
+	rejectedByPath map[string]bool
+	diffViewport   viewport.Model
+	loadingDiffs   bool
+	diffsStale     bool
+	splitStatus    string
 }
 
 type keymap = struct {
@@ -99,7 +112,12 @@ type keymap = struct {
 	down,
 	quit,
 	background,
-	enter bubbleKey.Binding
+	enter,
+	toggleSplit,
+	switchFocus,
+	prevFile,
+	nextFile,
+	rejectFile bubbleKey.Binding
 }
 
 func (m streamUIModel) Init() tea.Cmd {
@@ -119,7 +137,7 @@ func (m streamUIModel) pollBuildStatus() tea.Cmd {
 	})
 }
 
-func initialModel(prestartReply, prompt string, buildOnly bool, canSendToBg bool) *streamUIModel {
+func initialModel(prestartReply, prompt string, buildOnly, canSendToBg, splitView bool) *streamUIModel {
 	sharedTicker := time.NewTicker(100 * time.Millisecond)
 
 	s := spinner.New()
@@ -133,6 +151,7 @@ func initialModel(prestartReply, prompt string, buildOnly bool, canSendToBg bool
 		buildOnly:          buildOnly,
 		canSendToBg:        canSendToBg,
 		buildViewCollapsed: false,
+		splitView:          splitView,
 		prompt:             prompt,
 		reply:              prestartReply,
 		keymap: keymap{
@@ -195,11 +214,39 @@ func initialModel(prestartReply, prompt string, buildOnly bool, canSendToBg bool
 				bubbleKey.WithKeys("G", "end"),
 				bubbleKey.WithHelp("G", "end"),
 			),
+
+			toggleSplit: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("v"),
+				bubbleKey.WithHelp("v", "toggle split view"),
+			),
+
+			switchFocus: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("tab"),
+				bubbleKey.WithHelp("tab", "focus reply/diff"),
+			),
+
+			prevFile: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("["),
+				bubbleKey.WithHelp("[", "prev file"),
+			),
+
+			nextFile: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("]"),
+				bubbleKey.WithHelp("]", "next file"),
+			),
+
+			rejectFile: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("r"),
+				bubbleKey.WithHelp("r", "reject file"),
+			),
 		},
 
 		tokensByPath:    make(map[string]int),
 		finishedByPath:  make(map[string]bool),
 		removedByPath:   make(map[string]bool),
+		diffsByPath:     make(map[string]string),
+		rejectedByPath:  make(map[string]bool),
+		diffViewport:    viewport.New(0, 0),
 		spinner:         s,
 		buildSpinner:    buildSpinner,
 		sharedTicker:    sharedTicker,
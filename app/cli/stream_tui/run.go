@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"plandex-cli/lib"
 	"plandex-cli/term"
 	"sync"
 
@@ -34,7 +35,9 @@ func StartStreamUI(prompt string, buildOnly, canSendToBg bool) error {
 
 	log.Println("Starting stream UI")
 
-	initial := initialModel(prestartReply, prompt, buildOnly, canSendToBg)
+	splitView := lib.MustGetCurrentPlanConfig().StreamSplitView
+
+	initial := initialModel(prestartReply, prompt, buildOnly, canSendToBg, splitView)
 
 	mu.Lock()
 	ui = tea.NewProgram(initial, tea.WithAltScreen())
@@ -0,0 +1,453 @@
+package streamtui
+
+import (
+	"fmt"
+	"log"
+	"plandex-cli/api"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"sort"
+	"strings"
+
+	shared "plandex-shared"
+
+	bubbleKey "github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+)
+
+// share of the width given to the reply pane in split view
+const splitReplyWidthPct = 55
+
+type diffsLoadedMsg struct {
+	diffsByPath map[string]string
+	apiErr      *shared.ApiError
+}
+
+type rejectFileDoneMsg struct {
+	path   string
+	apiErr *shared.ApiError
+}
+
+// buildPaths returns the paths in the build, sorted, with _apply.sh last
+func (m streamUIModel) buildPaths() []string {
+	paths := make([]string, 0, len(m.tokensByPath))
+	for path := range m.tokensByPath {
+		if path == "_apply.sh" {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	if _, ok := m.tokensByPath["_apply.sh"]; ok {
+		paths = append(paths, "_apply.sh")
+	}
+	return paths
+}
+
+func (m streamUIModel) getSplitDimensions() (replyWidth, paneHeight, listHeight int) {
+	helpHeight := lipgloss.Height(m.renderHelp())
+
+	var processingHeight int
+	if m.starting || m.processing {
+		processingHeight = lipgloss.Height(m.renderProcessing())
+	}
+
+	paneHeight = max(m.height-(helpHeight+processingHeight), 0)
+
+	if !m.buildOnly {
+		replyWidth = m.width * splitReplyWidthPct / 100
+	}
+
+	// the file list gets up to a third of the pane, plus its header
+	listHeight = min(len(m.tokensByPath)+1, max(paneHeight/3, 2))
+
+	return replyWidth, paneHeight, listHeight
+}
+
+func (m streamUIModel) getDiffPaneWidth() int {
+	replyWidth, _, _ := m.getSplitDimensions()
+	if replyWidth == 0 {
+		return m.width
+	}
+	// 1 for the border between the panes
+	return max(m.width-replyWidth-1, 0)
+}
+
+func (m streamUIModel) renderSplitView() string {
+	replyWidth, paneHeight, listHeight := m.getSplitDimensions()
+	diffWidth := m.getDiffPaneWidth()
+
+	right := lipgloss.JoinVertical(lipgloss.Left,
+		m.renderFileList(diffWidth, listHeight),
+		m.diffViewport.View(),
+	)
+	right = lipgloss.NewStyle().Width(diffWidth).Height(paneHeight).MaxHeight(paneHeight).Render(right)
+
+	body := right
+	if !m.buildOnly {
+		left := lipgloss.NewStyle().
+			Width(replyWidth).
+			Height(paneHeight).
+			MaxHeight(paneHeight).
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderRight(true).
+			BorderForeground(lipgloss.Color(borderColor)).
+			Render(m.mainViewport.View())
+		body = lipgloss.JoinHorizontal(lipgloss.Top, left, right)
+	}
+
+	views := []string{body}
+	if m.processing || m.starting {
+		views = append(views, m.renderProcessing())
+	}
+	views = append(views, m.renderHelp())
+
+	return lipgloss.JoinVertical(lipgloss.Left, views...)
+}
+
+func (m streamUIModel) renderFileList(width, height int) string {
+	paths := m.buildPaths()
+
+	head := color.New(color.BgGreen, color.FgHiWhite, color.Bold).Sprint(" 🏗  ") + color.New(color.BgGreen, color.FgHiWhite).Sprint("Building plan ")
+	if m.splitStatus != "" {
+		head += " " + m.splitStatus
+	}
+	if len(paths) == 0 {
+		return lipgloss.JoinVertical(lipgloss.Left, truncateToWidth(head, width), " Waiting for file edits "+m.buildSpinner.View())
+	}
+
+	selectedIdx := 0
+	for i, path := range paths {
+		if path == m.selectedPath {
+			selectedIdx = i
+			break
+		}
+	}
+
+	// keep the selected file in view if the list doesn't fit
+	numRows := max(height-1, 1)
+	start := 0
+	if selectedIdx >= numRows {
+		start = selectedIdx - numRows + 1
+	}
+	end := min(start+numRows, len(paths))
+
+	rows := []string{truncateToWidth(head, width)}
+	for i := start; i < end; i++ {
+		path := paths[i]
+
+		icon := "📄"
+		label := path
+		if path == "_apply.sh" {
+			icon = "🚀"
+			label = "commands"
+		}
+
+		var status string
+		switch {
+		case m.rejectedByPath[path]:
+			status = "🚫"
+		case m.removedByPath[path]:
+			status = "❌"
+		case m.finished || m.finishedByPath[path]:
+			status = "✅"
+		case m.tokensByPath[path] > 0:
+			status = fmt.Sprintf("%d 🪙", m.tokensByPath[path])
+		default:
+			status = m.buildSpinner.View()
+		}
+
+		row := fmt.Sprintf("   %s %s %s", icon, label, status)
+		if path == m.selectedPath {
+			row = color.New(color.Bold, color.FgHiCyan).Sprintf(" › %s %s", icon, label) + " " + status
+		}
+		rows = append(rows, truncateToWidth(row, width))
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+func truncateToWidth(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	return lipgloss.NewStyle().MaxWidth(width-1).Render(s) + "⋯"
+}
+
+// updateDiffDisplay sets the diff pane's content for the selected file
+func (m *streamUIModel) updateDiffDisplay() {
+	state := m.readState()
+
+	path := state.selectedPath
+	var content string
+	switch {
+	case path == "":
+		content = ""
+	case state.rejectedByPath[path]:
+		content = color.New(color.FgHiBlack).Sprintf("🚫 Rejected %s", path)
+	case state.diffsByPath[path] != "":
+		content = colorizeDiff(state.diffsByPath[path])
+	case state.removedByPath[path]:
+		content = color.New(color.FgHiBlack).Sprintf("❌ %s will be removed", path)
+	case state.finished || state.finishedByPath[path]:
+		if state.loadingDiffs {
+			content = color.New(color.FgHiBlack).Sprint("Loading diff…")
+		} else {
+			content = color.New(color.FgHiBlack).Sprint("No changes")
+		}
+	default:
+		content = color.New(color.FgHiBlack).Sprint("⏳ The diff will show here when this file is built")
+	}
+
+	m.updateState(func() {
+		m.diffViewport.SetContent(content)
+	})
+}
+
+func colorizeDiff(diff string) string {
+	lines := strings.Split(strings.TrimRight(diff, "\n"), "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---"):
+			lines[i] = color.New(color.Bold).Sprint(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = color.New(color.FgCyan).Sprint(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = color.New(color.FgGreen).Sprint(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = color.New(color.FgRed).Sprint(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// splitDiffsByPath splits 'git diff' output into a diff per file, dropping the 'diff --git' and index headers
+func splitDiffsByPath(diffs string) map[string]string {
+	res := map[string]string{}
+
+	var path string
+	var current []string
+	inHunk := false
+	flush := func() {
+		if path != "" {
+			res[path] = strings.Join(current, "\n")
+		}
+		path = ""
+		current = nil
+		inHunk = false
+	}
+
+	for _, line := range strings.Split(diffs, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			continue
+		case inHunk:
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case strings.HasPrefix(line, "index ") || strings.HasPrefix(line, "new file mode") || strings.HasPrefix(line, "deleted file mode"):
+			continue
+		case strings.HasPrefix(line, "--- "):
+			if p := strings.TrimPrefix(line, "--- "); p != "/dev/null" {
+				path = strings.TrimPrefix(p, "a/")
+			}
+		case strings.HasPrefix(line, "+++ "):
+			if p := strings.TrimPrefix(line, "+++ "); p != "/dev/null" {
+				path = strings.TrimPrefix(p, "b/")
+			}
+		}
+		current = append(current, line)
+	}
+	flush()
+
+	return res
+}
+
+func loadDiffsCmd() tea.Cmd {
+	return func() tea.Msg {
+		diffs, apiErr := api.Client.GetPlanDiffs(lib.CurrentPlanId, lib.CurrentBranch, true)
+		if apiErr != nil {
+			return diffsLoadedMsg{apiErr: apiErr}
+		}
+		return diffsLoadedMsg{diffsByPath: splitDiffsByPath(diffs)}
+	}
+}
+
+// refreshDiffs reloads pending diffs. If a load is already in flight, another one runs when it finishes so the latest builds are always included.
+func (m *streamUIModel) refreshDiffs() tea.Cmd {
+	state := m.readState()
+	if !state.splitView {
+		return nil
+	}
+
+	if state.loadingDiffs {
+		m.updateState(func() {
+			m.diffsStale = true
+		})
+		return nil
+	}
+
+	m.updateState(func() {
+		m.loadingDiffs = true
+	})
+	return loadDiffsCmd()
+}
+
+func (m *streamUIModel) diffsLoaded(msg diffsLoadedMsg) tea.Cmd {
+	if msg.apiErr != nil {
+		log.Println("stream UI - error loading diffs:", msg.apiErr.Msg)
+	}
+
+	m.updateState(func() {
+		m.loadingDiffs = false
+		if msg.apiErr == nil {
+			m.diffsByPath = msg.diffsByPath
+		} else {
+			m.splitStatus = color.New(color.FgHiRed).Sprint("error loading diffs")
+		}
+	})
+
+	state := m.readState()
+	if state.diffsStale {
+		m.updateState(func() {
+			m.diffsStale = false
+		})
+		m.updateDiffDisplay()
+		return m.refreshDiffs()
+	}
+
+	m.updateDiffDisplay()
+	return nil
+}
+
+func (m *streamUIModel) toggleSplitView() tea.Cmd {
+	m.updateState(func() {
+		m.splitView = !m.splitView
+		m.splitFocusDiff = false
+	})
+
+	m.ensureSelectedPath()
+	m.updateViewportDimensions()
+	m.updateReplyDisplay()
+	m.updateDiffDisplay()
+
+	return m.refreshDiffs()
+}
+
+func (m *streamUIModel) ensureSelectedPath() {
+	state := m.readState()
+	if _, ok := state.tokensByPath[state.selectedPath]; ok {
+		return
+	}
+	paths := state.buildPaths()
+	if len(paths) > 0 {
+		m.updateState(func() {
+			m.selectedPath = paths[0]
+		})
+	}
+}
+
+func (m *streamUIModel) selectFile(delta int) {
+	state := m.readState()
+	paths := state.buildPaths()
+	if len(paths) == 0 {
+		return
+	}
+
+	idx := 0
+	for i, path := range paths {
+		if path == state.selectedPath {
+			idx = i
+			break
+		}
+	}
+	idx = (idx + delta + len(paths)) % len(paths)
+
+	m.updateState(func() {
+		m.selectedPath = paths[idx]
+		m.splitStatus = ""
+		m.diffViewport.GotoTop()
+	})
+	m.updateDiffDisplay()
+}
+
+// rejectSelectedFile rejects the selected file's pending changes. Only files that have finished building can be rejected, since a build in progress would store its result after the reject.
+func (m *streamUIModel) rejectSelectedFile() tea.Cmd {
+	state := m.readState()
+	path := state.selectedPath
+	if path == "" || state.rejectedByPath[path] {
+		return nil
+	}
+
+	if !(state.finished || state.finishedByPath[path]) {
+		m.updateState(func() {
+			m.splitStatus = color.New(term.ColorHiYellow).Sprint("wait for the file to finish building to reject it")
+		})
+		return nil
+	}
+
+	m.updateState(func() {
+		m.splitStatus = fmt.Sprintf("rejecting %s…", path)
+	})
+
+	return func() tea.Msg {
+		apiErr := api.Client.RejectFile(lib.CurrentPlanId, lib.CurrentBranch, path)
+		return rejectFileDoneMsg{path: path, apiErr: apiErr}
+	}
+}
+
+func (m *streamUIModel) rejectFileDone(msg rejectFileDoneMsg) {
+	m.updateState(func() {
+		if msg.apiErr != nil {
+			log.Println("stream UI - error rejecting file:", msg.apiErr.Msg)
+			m.splitStatus = color.New(color.FgHiRed).Sprintf("error rejecting %s", msg.path)
+			return
+		}
+		m.rejectedByPath[msg.path] = true
+		delete(m.diffsByPath, msg.path)
+		m.splitStatus = fmt.Sprintf("rejected %s", msg.path)
+	})
+	m.updateDiffDisplay()
+}
+
+// splitKeyUpdate handles split view keys, returning false if the key isn't one of them
+func (m *streamUIModel) splitKeyUpdate(msg tea.KeyMsg) (bool, tea.Cmd) {
+	state := m.readState()
+	if !state.splitView || state.promptingMissingFile {
+		return false, nil
+	}
+
+	switch {
+	case bubbleKey.Matches(msg, m.keymap.switchFocus):
+		m.updateState(func() {
+			m.splitFocusDiff = !m.splitFocusDiff && !m.buildOnly
+		})
+	case bubbleKey.Matches(msg, m.keymap.prevFile) || bubbleKey.Matches(msg, m.keymap.up):
+		m.selectFile(-1)
+	case bubbleKey.Matches(msg, m.keymap.nextFile) || bubbleKey.Matches(msg, m.keymap.down):
+		m.selectFile(1)
+	case bubbleKey.Matches(msg, m.keymap.rejectFile):
+		return true, m.rejectSelectedFile()
+	case (state.splitFocusDiff || state.buildOnly) && bubbleKey.Matches(msg, m.keymap.scrollDown):
+		m.updateState(func() { m.diffViewport.LineDown(1) })
+	case (state.splitFocusDiff || state.buildOnly) && bubbleKey.Matches(msg, m.keymap.scrollUp):
+		m.updateState(func() { m.diffViewport.LineUp(1) })
+	case (state.splitFocusDiff || state.buildOnly) && bubbleKey.Matches(msg, m.keymap.pageDown):
+		m.updateState(func() { m.diffViewport.ViewDown() })
+	case (state.splitFocusDiff || state.buildOnly) && bubbleKey.Matches(msg, m.keymap.pageUp):
+		m.updateState(func() { m.diffViewport.ViewUp() })
+	case (state.splitFocusDiff || state.buildOnly) && bubbleKey.Matches(msg, m.keymap.start):
+		m.updateState(func() { m.diffViewport.GotoTop() })
+	case (state.splitFocusDiff || state.buildOnly) && bubbleKey.Matches(msg, m.keymap.end):
+		m.updateState(func() { m.diffViewport.GotoBottom() })
+	default:
+		return false, nil
+	}
+
+	return true, nil
+}
@@ -76,6 +76,12 @@ func (m streamUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.finishedByPath[msg.path] = false
 		})
 
+	case diffsLoadedMsg:
+		return m, m.diffsLoaded(msg)
+
+	case rejectFileDoneMsg:
+		m.rejectFileDone(msg)
+
 	// Scroll wheel doesn't seem to work--not sure why
 	// case tea.MouseMsg:
 	// 	if !m.promptingMissingFile {
@@ -87,6 +93,13 @@ func (m streamUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// 	}
 
 	case tea.KeyMsg:
+		if bubbleKey.Matches(msg, m.keymap.toggleSplit) && !m.promptingMissingFile {
+			return m, m.toggleSplitView()
+		}
+		if handled, cmd := m.splitKeyUpdate(msg); handled {
+			return m, cmd
+		}
+
 		switch {
 		case bubbleKey.Matches(msg, m.keymap.stop) || bubbleKey.Matches(msg, m.keymap.quit):
 			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
@@ -153,14 +166,22 @@ func (m streamUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, m.pollBuildStatus()
 			}
 
+			newlyFinished := false
 			m.updateState(func() {
 				for path, isBuilt := range status.BuiltFiles {
 					isBuilding := status.IsBuildingByPath[path]
 					if isBuilt && !isBuilding {
+						if !m.finishedByPath[path] {
+							newlyFinished = true
+						}
 						m.finishedByPath[path] = true
 					}
 				}
 			})
+
+			if newlyFinished {
+				return m, tea.Batch(m.pollBuildStatus(), m.refreshDiffs())
+			}
 		}
 		return m, m.pollBuildStatus()
 	}
@@ -210,6 +231,21 @@ func (m *streamUIModel) updateReplyDisplay() {
 		s += "\n\n" + strings.TrimSpace(promptTxt) + "\n"
 	}
 
+	if len(state.prunedContext) > 0 {
+		tokensSaved := 0
+		for _, pruned := range state.prunedContext {
+			tokensSaved += pruned.TokensSaved
+		}
+		s += "\n" + color.New(color.FgHiYellow, color.Bold).Sprintf("✂️  Pruned context to fit the token limit | -%d 🪙", tokensSaved) + "\n"
+		for _, pruned := range state.prunedContext {
+			if pruned.Downgraded {
+				s += fmt.Sprintf("  • %s → map\n", pruned.Name)
+			} else {
+				s += fmt.Sprintf("  • %s (left out)\n", pruned.Name)
+			}
+		}
+	}
+
 	if state.reply != "" {
 		replyMd, _ := term.GetMarkdown(state.reply)
 		s += "\n" + color.New(color.BgBlue, color.Bold, color.FgHiWhite).Sprintf(" 🤖 Plandex reply 👇 ")
@@ -218,6 +254,12 @@ func (m *streamUIModel) updateReplyDisplay() {
 		s += "\n"
 	}
 
+	if state.splitView {
+		// leave room for the viewport's horizontal padding
+		replyWidth, _, _ := state.getSplitDimensions()
+		s = lipgloss.NewStyle().Width(max(replyWidth-2, 1)).Render(s)
+	}
+
 	m.updateState(func() {
 		m.mainDisplay = s
 		m.mainViewport.SetContent(s)
@@ -240,9 +282,22 @@ func (m *streamUIModel) updateViewportDimensions() {
 		m.mainViewport.Width = w
 		m.mainViewport.Height = h
 	})
+
+	if state.splitView {
+		_, paneHeight, listHeight := state.getSplitDimensions()
+		m.updateState(func() {
+			m.diffViewport.Width = state.getDiffPaneWidth()
+			m.diffViewport.Height = max(paneHeight-listHeight, 0)
+		})
+	}
 }
 
 func (m *streamUIModel) getViewportDimensions() (int, int) {
+	if m.splitView {
+		replyWidth, paneHeight, _ := m.getSplitDimensions()
+		return replyWidth, paneHeight
+	}
+
 	w := m.width
 	h := m.height
 
@@ -458,6 +513,11 @@ func (m *streamUIModel) streamUpdate(msg *shared.StreamMessage, deferUIUpdate bo
 				m.finishedByPath[msg.BuildInfo.Path] = true
 			})
 		} else {
+			// a new build of a rejected file replaces the reject
+			m.updateState(func() {
+				delete(m.rejectedByPath, msg.BuildInfo.Path)
+			})
+
 			if wasFinished && !nowFinished {
 				// delay for a second before marking not finished again (so check flashes green prior to restarting build)
 				log.Println("Stream message build info - delaying for 1 second before marking not finished again")
@@ -486,6 +546,15 @@ func (m *streamUIModel) streamUpdate(msg *shared.StreamMessage, deferUIUpdate bo
 			m.updateViewportDimensions()
 		}
 
+		state = m.readState()
+		if state.splitView {
+			m.ensureSelectedPath()
+			m.updateDiffDisplay()
+			if nowFinished && !wasFinished {
+				return m, tea.Batch(m.Tick(), m.refreshDiffs())
+			}
+		}
+
 		return m, m.Tick()
 
 	case shared.StreamMessageDescribing:
@@ -495,6 +564,14 @@ func (m *streamUIModel) streamUpdate(msg *shared.StreamMessage, deferUIUpdate bo
 		})
 		return m, m.Tick()
 
+	case shared.StreamMessagePrunedContext:
+		m.updateState(func() {
+			m.prunedContext = msg.PrunedContext
+		})
+		if !deferUIUpdate {
+			m.updateReplyDisplay()
+		}
+
 		// Instead of blocking here, we'll spawn a command
 	case shared.StreamMessageLoadContext:
 		m.updateState(func() {
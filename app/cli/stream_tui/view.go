@@ -2,7 +2,6 @@ package streamtui
 
 import (
 	"fmt"
-	"sort"
 	"strings"
 
 	"plandex-cli/term"
@@ -20,6 +19,10 @@ func (m streamUIModel) View() string {
 		return m.renderMissingFilePrompt()
 	}
 
+	if m.splitView {
+		return m.renderSplitView()
+	}
+
 	views := []string{}
 	if !m.buildOnly {
 		views = append(views, m.renderMainView())
@@ -42,18 +45,30 @@ func (m streamUIModel) renderMainView() string {
 func (m streamUIModel) renderHelp() string {
 	style := lipgloss.NewStyle().Width(m.width).Foreground(lipgloss.Color(helpTextColor)).BorderStyle(lipgloss.NormalBorder()).BorderTop(true).BorderForeground(lipgloss.Color(borderColor))
 
-	if m.buildOnly {
+	if m.splitView {
 		s := " (s)top"
 		if m.canSendToBg {
 			s += " • (b)ackground"
 		}
+		s += " • (v) single view"
+		if !m.buildOnly {
+			s += " • (tab) focus"
+		}
+		s += " • ([/]) file • (r)eject • (j/k) scroll"
+		return style.Render(s)
+	} else if m.buildOnly {
+		s := " (s)top"
+		if m.canSendToBg {
+			s += " • (b)ackground"
+		}
+		s += " • (v) split view"
 		return style.Render(s)
 	} else {
 		s := " (s)top"
 		if m.canSendToBg {
 			s += " • (b)ackground"
 		}
-		s += " • (j/k) scroll • (d/u) page • (g/G) start/end"
+		s += " • (j/k) scroll • (d/u) page • (g/G) start/end • (v) split view"
 		return style.Render(s)
 	}
 }
@@ -178,18 +193,7 @@ func (m streamUIModel) getRows(static bool) []string {
 	built := m.didBuild() && static
 	head := m.getBuildHeader(static)
 
-	// Gather file paths, _apply.sh last
-	filePaths := make([]string, 0, len(m.tokensByPath))
-	for filePath := range m.tokensByPath {
-		if filePath == "_apply.sh" {
-			continue
-		}
-		filePaths = append(filePaths, filePath)
-	}
-	sort.Strings(filePaths)
-	if _, ok := m.tokensByPath["_apply.sh"]; ok {
-		filePaths = append(filePaths, "_apply.sh")
-	}
+	filePaths := m.buildPaths()
 
 	var rows [][]string
 	lineWidth := 0
@@ -212,6 +216,8 @@ func (m streamUIModel) getRows(static bool) []string {
 
 		// Mark removed/finished/tokens
 		switch {
+		case m.rejectedByPath[filePath]:
+			block += " 🚫"
 		case removed:
 			block += " ❌"
 		case finished:
@@ -211,6 +211,22 @@ func HandleApiError(apiError *shared.ApiError) {
 		}
 	}
 
+	if apiError.Type == shared.ApiErrorTypeOrgPolicyViolation {
+		StopSpinner()
+		OutputSimpleError("%s", apiError.Msg)
+		fmt.Println()
+		PrintCmds("", "org-policy")
+		os.Exit(1)
+	}
+
+	if apiError.Type == shared.ApiErrorTypeRateLimited {
+		StopSpinner()
+		OutputSimpleError("%s", apiError.Msg)
+		fmt.Println()
+		PrintCmds("", "rate-limits")
+		os.Exit(1)
+	}
+
 	StopSpinner()
 	OutputErrorAndExit(apiError.Msg)
 }
@@ -32,6 +32,8 @@ var CliCommands = []CmdConfig{
 
 	{"new", "", "start a new plan", true},
 
+	{"new --template", "", "start a new plan from a template and run its steps", true},
+
 	{"new --full", "", fmt.Sprintf("start a new plan with auto-mode %s", "'full'"), true},
 	{"new --semi", "", fmt.Sprintf("start a new plan with auto-mode %s", "'semi'"), true},
 	{"new --plus", "", fmt.Sprintf("start a new plan with auto-mode %s", "'plus'"), true},
@@ -78,6 +80,12 @@ var CliCommands = []CmdConfig{
 	{"clear", "", "remove all context", true},
 	{"update", "u", "update outdated context", true},
 	{"show", "", "show current context by name or index", true},
+	{"index", "", "build or update the project's embeddings index for retrieval", true},
+	{"docs", "", "list, refresh, or remove the project's crawled docs bundles", true},
+	{"context", "", "list the project's named context sets", true},
+	{"context save", "", "save the current plan's context as a named set", true},
+	{"context use", "", "load a named context set into the current plan", true},
+	{"context rm", "", "remove a named context set", true},
 
 	{"diff --ui", "", "review pending changes in a browser UI", true},
 	{"diff", "", "review pending changes in 'git diff' format", true},
@@ -86,8 +94,13 @@ var CliCommands = []CmdConfig{
 
 	{"apply", "ap", "apply pending changes to project files", true},
 	{"reject", "rj", "reject pending changes to one or more project files", true},
+	{"review", "rv", "accept, reject, or edit pending changes hunk by hunk", true},
+	{"review --ui", "", "review pending changes hunk by hunk in a browser UI", true},
+	{"review --comments", "", "comment on pending changes in a browser UI and send the comments to the model", true},
+	{"pr", "", "create a git branch with a commit per subtask and a PR description from the latest apply", true},
 
 	{"log", "", "show log of plan updates", true},
+	{"log diff", "", "show changes between two points in plan history", true},
 	{"rewind", "rw", "rewind to a previous state", true},
 
 	{"continue", "c", "continue the plan", true},
@@ -98,15 +111,26 @@ var CliCommands = []CmdConfig{
 	{"convo 1", "", "show a specific message in the conversation", false},
 	{"convo 2-5", "", "show a range of messages in the conversation", false},
 	{"convo --plain", "", "show conversation in plain text", false},
+	{"search", "", "search conversations, summaries, and plan names across plans", true},
 
 	{"branches", "br", "list plan branches", true},
 	{"checkout", "co", "checkout or create a branch", true},
 	{"delete-branch", "dlb", "delete a branch by name or index", true},
+	{"merge", "", "merge another branch into the current branch", true},
+	{"cherry-pick", "", "bring a convo message or update from another branch", true},
 
 	{"plans --archived", "", "list archived plans", true},
 	{"archive", "arc", "archive a plan", true},
 	{"unarchive", "unarc", "unarchive a plan", true},
 
+	{"export", "", "export a plan with its history and branches to an archive", true},
+	{"import", "", "import a plan from an archive", true},
+
+	{"templates", "", "list plan templates from .plandex-templates and your org", true},
+	{"templates show", "", "show a plan template's vars, context, config, and steps", true},
+	{"templates push", "", "save an in-repo template to your org", true},
+	{"templates rm", "", "remove a plan template from your org", true},
+
 	{"models", "", "show current plan model settings", true},
 	{"models default", "", "show the default model settings for new plans", true},
 
@@ -145,11 +169,25 @@ var CliCommands = []CmdConfig{
 	{"invite", "", "invite a user to join your org", true},
 	{"revoke", "", "revoke an invite or remove a user from your org", true},
 	{"users", "", "list users and pending invites in your org", true},
+	{"users set-role", "", "change an org member's role", true},
+	{"users roles", "", "list built-in and custom org roles", true},
+	{"users roles create", "", "create a custom org role", true},
+	{"users roles update", "", "update a custom org role", true},
+	{"users roles delete", "", "delete a custom org role", true},
+	{"org-policy", "", "show the org policy for providers, model packs, auto mode, exec, and context size", true},
+	{"org-policy set", "", "update the org policy", true},
+	{"org-policy clear", "", "remove all org policy restrictions", true},
+	{"rate-limits", "", "show the server's rate limits and your current usage", true},
 
 	{"connect-claude", "", "connect your Claude Pro or Max subscription", true},
 	{"disconnect-claude", "", "disconnect your Claude Pro or Max subscription", true},
 	{"claude-status", "", "status of your Claude Pro or Max subscription connection", true},
 
+	{"credentials", "creds", "list model provider credentials in the local keyring and org vault", true},
+	{"credentials set", "", "store a model provider credential in the local keyring", true},
+	{"credentials set --org", "", "store a model provider credential in the org vault", true},
+	{"credentials rm", "", "remove a stored model provider credential", true},
+
 	{"usage", "", "show Plandex Cloud current balance and usage report", true},
 	{"usage --today", "", "show Plandex Cloud usage for the day so far", true},
 	{"usage --month", "", "show Plandex Cloud usage for the current billing month", true},
@@ -301,23 +339,23 @@ func PrintHelpAllCommands() {
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Plans ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "new", "plans", "cd", "current", "delete-plan", "rename", "archive", "plans --archived", "unarchive")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "new", "plans", "cd", "current", "delete-plan", "rename", "archive", "plans --archived", "unarchive", "export", "import", "templates", "new --template")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Changes ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "diff", "diff --ui", "diff --plain", "apply", "reject")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "diff", "diff --ui", "diff --plain", "review", "review --ui", "review --comments", "apply", "reject", "pr")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Context ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "load", "ls", "rm", "update", "clear")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "load", "ls", "rm", "update", "clear", "index", "context save", "context use")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Branches ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "branches", "checkout", "delete-branch")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "branches", "checkout", "delete-branch", "merge", "cherry-pick")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " History ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "log", "rewind", "convo", "convo 1", "convo 2-5", "convo --plain", "summary")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "log", "log diff", "rewind", "convo", "convo 1", "convo 2-5", "convo --plain", "summary", "search")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Control ")
@@ -346,11 +384,11 @@ func PrintHelpAllCommands() {
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Accounts ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "sign-in", "invite", "revoke", "users")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "sign-in", "invite", "revoke", "users", "users set-role", "users roles", "users roles create", "users roles update", "users roles delete", "org-policy", "org-policy set", "org-policy clear", "rate-limits")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Integrations ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "connect-claude", "disconnect-claude", "claude-status")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "connect-claude", "disconnect-claude", "claude-status", "credentials", "credentials set", "credentials set --org", "credentials rm")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Cloud ")
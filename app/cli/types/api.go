@@ -34,10 +34,31 @@ type ApiClient interface {
 	GetOrgUserConfig() (*shared.OrgUserConfig, *shared.ApiError)
 	UpdateOrgUserConfig(req shared.OrgUserConfig) *shared.ApiError
 
+	ListOrgSecrets() ([]*shared.OrgSecret, *shared.ApiError)
+	SetOrgSecret(req shared.SetOrgSecretRequest) *shared.ApiError
+	DeleteOrgSecret(name string) *shared.ApiError
+
 	ListUsers() (*shared.ListUsersResponse, *shared.ApiError)
 	DeleteUser(userId string) *shared.ApiError
 
 	ListOrgRoles() ([]*shared.OrgRole, *shared.ApiError)
+	CreateOrgRole(req shared.CreateOrgRoleRequest) (*shared.OrgRole, *shared.ApiError)
+	UpdateOrgRole(roleId string, req shared.UpdateOrgRoleRequest) *shared.ApiError
+	DeleteOrgRole(roleId string) *shared.ApiError
+	SetOrgUserRole(userId string, req shared.SetOrgUserRoleRequest) *shared.ApiError
+
+	GetOrgPolicy() (*shared.OrgPolicy, *shared.ApiError)
+	UpdateOrgPolicy(req shared.UpdateOrgPolicyRequest) *shared.ApiError
+
+	ListPlanTemplates() ([]*shared.PlanTemplate, *shared.ApiError)
+	UpsertPlanTemplate(req shared.UpsertPlanTemplateRequest) *shared.ApiError
+	DeletePlanTemplate(name string) *shared.ApiError
+
+	ListContextSets(projectId string) ([]*shared.ContextSet, *shared.ApiError)
+	UpsertContextSet(projectId string, req shared.UpsertContextSetRequest) *shared.ApiError
+	DeleteContextSet(projectId, name string) *shared.ApiError
+
+	GetRateLimitStatus() (*shared.RateLimitStatusResponse, *shared.ApiError)
 
 	InviteUser(req shared.InviteRequest) *shared.ApiError
 	ListPendingInvites() ([]*shared.Invite, *shared.ApiError)
@@ -58,6 +79,8 @@ type ApiClient interface {
 
 	GetPlan(planId string) (*shared.Plan, *shared.ApiError)
 	CreatePlan(projectId string, req shared.CreatePlanRequest) (*shared.CreatePlanResponse, *shared.ApiError)
+	ExportPlan(planId string) ([]byte, *shared.ApiError)
+	ImportPlan(projectId, name string, archive []byte) (*shared.CreatePlanResponse, *shared.ApiError)
 
 	TellPlan(planId, branch string, req shared.TellPlanRequest, onStreamPlan OnStreamPlan) *shared.ApiError
 	BuildPlan(planId, branch string, req shared.BuildPlanRequest, onStreamPlan OnStreamPlan) *shared.ApiError
@@ -78,7 +101,9 @@ type ApiClient interface {
 	RejectAllChanges(planId, branch string) *shared.ApiError
 	RejectFile(planId, branch, filePath string) *shared.ApiError
 	RejectFiles(planId, branch string, paths []string) *shared.ApiError
+	ReviewReplacements(planId, branch string, req shared.ReviewReplacementsRequest) *shared.ApiError
 	GetPlanDiffs(planId, branch string, plain bool) (string, *shared.ApiError)
+	GetPlanPR(planId, branch string) (*shared.PlanPRResponse, *shared.ApiError)
 
 	LoadContext(planId, branch string, req shared.LoadContextRequest) (*shared.LoadContextResponse, *shared.ApiError)
 	UpdateContext(planId, branch string, req shared.UpdateContextRequest) (*shared.UpdateContextResponse, *shared.ApiError)
@@ -87,13 +112,17 @@ type ApiClient interface {
 	LoadCachedFileMap(planId, branch string, req shared.LoadCachedFileMapRequest) (*shared.LoadCachedFileMapResponse, *shared.ApiError)
 
 	ListConvo(planId, branch string) ([]*shared.ConvoMessage, *shared.ApiError)
+	SearchConvo(query string, projectIds []string, limit int) ([]*shared.ConvoSearchResult, *shared.ApiError)
 	GetPlanStatus(planId, branch string) (string, *shared.ApiError)
 	ListLogs(planId, branch string) (*shared.LogResponse, *shared.ApiError)
+	GetLogDiff(planId, branch, from, to string, plain bool) (*shared.PlanLogDiffResponse, *shared.ApiError)
 	RewindPlan(planId, branch string, req shared.RewindPlanRequest) (*shared.RewindPlanResponse, *shared.ApiError)
 
 	ListBranches(planId string) ([]*shared.Branch, *shared.ApiError)
 	DeleteBranch(planId, branch string) *shared.ApiError
 	CreateBranch(planId, branch string, req shared.CreateBranchRequest) *shared.ApiError
+	MergeBranch(planId, branch string, req shared.MergeBranchRequest) (*shared.BranchMergeResponse, *shared.ApiError)
+	CherryPick(planId, branch string, req shared.CherryPickRequest) (*shared.BranchMergeResponse, *shared.ApiError)
 
 	GetSettings(planId, branch string) (*shared.PlanSettings, *shared.ApiError)
 	UpdateSettings(planId, branch string, req shared.UpdateSettingsRequest) (*shared.UpdateSettingsResponse, *shared.ApiError)
@@ -118,6 +147,8 @@ type ApiClient interface {
 	GetBalance() (decimal.Decimal, *shared.ApiError)
 
 	GetFileMap(req shared.GetFileMapRequest) (*shared.GetFileMapResponse, *shared.ApiError)
+	GetFileChunks(req shared.GetFileChunksRequest) (*shared.GetFileChunksResponse, *shared.ApiError)
+	GetFileSymbols(req shared.GetFileSymbolsRequest) (*shared.GetFileSymbolsResponse, *shared.ApiError)
 	GetContextBody(planId, branch, contextId string) (*shared.GetContextBodyResponse, *shared.ApiError)
 	AutoLoadContext(ctx context.Context, planId, branch string, req shared.LoadContextRequest) (*shared.LoadContextResponse, *shared.ApiError)
 	GetBuildStatus(planId, branch string) (*shared.GetBuildStatusResponse, *shared.ApiError)
@@ -20,3 +20,12 @@ type BuildFlags struct {
 	BuildBg   bool
 	AutoApply bool
 }
+
+// VariantFlags configures running a prompt on several branches at once to compare results
+type VariantFlags struct {
+	NumVariants  int
+	ModelPacks   []string
+	Temperatures []float32
+	TestCmd      string
+	ExecApply    bool
+}
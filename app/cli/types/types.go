@@ -17,6 +17,19 @@ type LoadContextParams struct {
 	SkipIgnoreWarning bool
 	AutoLoaded        bool
 	SessionId         string
+	GitDiff           bool
+	GitDiffBase       string
+	GitCommit         string
+	GitStaged         bool
+	Crawl             string
+	CrawlDepth        int
+	CrawlInclude      []string
+	CrawlMaxPages     int
+	Docs              string
+	RefreshDocs       bool
+	Cmds              []string
+	CmdTimeout        int
+	Db                string
 }
 
 type ContextOutdatedResult struct {
@@ -28,8 +41,12 @@ type ContextOutdatedResult struct {
 	NumUrls         int
 	NumTrees        int
 	NumMaps         int
+	NumGitDiffs     int
+	NumCommands     int
+	NumDbSchemas    int
 	NumFilesRemoved int
 	NumTreesRemoved int
+	NewFiles        []*shared.LoadContextParams
 	ReqFn           func() (map[string]*shared.UpdateContextParams, error)
 }
 
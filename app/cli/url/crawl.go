@@ -0,0 +1,364 @@
+package url
+
+import (
+	"crypto/sha256"
+	"fmt"
+	neturl "net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	DefaultCrawlDepth    = 2
+	DefaultCrawlMaxPages = 100
+	crawlConcurrency     = 4
+
+	// a block of markdown that appears on at least this share of crawled pages is treated as boilerplate
+	boilerplateMinShare = 0.5
+	boilerplateMinPages = 3
+)
+
+// links to files that aren't html pages
+var nonPageExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".webp": true, ".ico": true,
+	".css": true, ".js": true, ".mjs": true, ".json": true, ".xml": true, ".rss": true, ".txt": true,
+	".pdf": true, ".zip": true, ".gz": true, ".tgz": true, ".tar": true, ".dmg": true, ".exe": true,
+	".mp4": true, ".webm": true, ".mp3": true, ".woff": true, ".woff2": true, ".ttf": true,
+}
+
+type CrawlParams struct {
+	StartUrl string
+	// link hops to follow from the start page
+	Depth int
+	// globs matched against url paths (e.g. '/docs/v5/**') - when empty, pages must be under the start url's directory
+	Include  []string
+	MaxPages int
+	// called after each page is fetched
+	OnPage func(numPages int, pageUrl string)
+}
+
+type CrawledPage struct {
+	Url      string `json:"url"`
+	Title    string `json:"title"`
+	Markdown string `json:"markdown"`
+}
+
+// Crawl fetches pages breadth-first from the start url, following links on the same host that are in scope. Pages are converted to markdown, duplicate pages are dropped, and blocks repeated across most pages (menus, banners, footers) are removed.
+func Crawl(params CrawlParams) ([]*CrawledPage, error) {
+	start, err := neturl.Parse(params.StartUrl)
+	if err != nil || start.Scheme == "" || start.Host == "" {
+		return nil, fmt.Errorf("invalid url: %s", params.StartUrl)
+	}
+	start = normalizeCrawlUrl(start)
+
+	var includeRegexes []*regexp.Regexp
+	for _, glob := range params.Include {
+		re, err := globToRegex(glob)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include glob %s: %v", glob, err)
+		}
+		includeRegexes = append(includeRegexes, re)
+	}
+
+	scopePrefix := crawlScopePrefix(start)
+
+	inScope := func(u *neturl.URL) bool {
+		if u.Host != start.Host || (u.Scheme != "http" && u.Scheme != "https") {
+			return false
+		}
+		if nonPageExtensions[strings.ToLower(path.Ext(u.Path))] {
+			return false
+		}
+		if len(includeRegexes) == 0 {
+			return strings.HasPrefix(u.Path, scopePrefix) || u.Path+"/" == scopePrefix
+		}
+		for _, re := range includeRegexes {
+			if re.MatchString(u.Path) {
+				return true
+			}
+		}
+		return false
+	}
+
+	maxPages := params.MaxPages
+	if maxPages <= 0 {
+		maxPages = DefaultCrawlMaxPages
+	}
+
+	seen := map[string]bool{start.String(): true}
+	seenContent := map[[32]byte]bool{}
+	level := []*neturl.URL{start}
+
+	var pages []*CrawledPage
+	var firstErr error
+
+	for depth := 0; depth <= params.Depth && len(level) > 0 && len(pages) < maxPages; depth++ {
+		type result struct {
+			page  *CrawledPage
+			links []*neturl.URL
+			err   error
+		}
+		results := make([]result, len(level))
+
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, crawlConcurrency)
+		for i, u := range level {
+			wg.Add(1)
+			go func(i int, u *neturl.URL) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				page, links, err := crawlPage(u)
+				results[i] = result{page, links, err}
+			}(i, u)
+		}
+		wg.Wait()
+
+		var next []*neturl.URL
+		for i, res := range results {
+			if res.err != nil {
+				// the start page has to load, but broken links further in are skipped
+				if depth == 0 {
+					return nil, fmt.Errorf("failed to fetch %s: %v", level[i], res.err)
+				}
+				if firstErr == nil {
+					firstErr = res.err
+				}
+				continue
+			}
+			if res.page == nil {
+				continue
+			}
+
+			// links on the start page resolve against where it redirected to, so scope follows it too
+			if depth == 0 {
+				if finalUrl, err := neturl.Parse(res.page.Url); err == nil {
+					start = normalizeCrawlUrl(finalUrl)
+					scopePrefix = crawlScopePrefix(start)
+					seen[start.String()] = true
+				}
+			}
+
+			hash := sha256.Sum256([]byte(res.page.Markdown))
+			if res.page.Markdown == "" || seenContent[hash] {
+				continue
+			}
+			seenContent[hash] = true
+
+			if len(pages) >= maxPages {
+				break
+			}
+			pages = append(pages, res.page)
+			if params.OnPage != nil {
+				params.OnPage(len(pages), res.page.Url)
+			}
+
+			for _, link := range res.links {
+				link = normalizeCrawlUrl(link)
+				key := link.String()
+				if seen[key] || !inScope(link) {
+					continue
+				}
+				seen[key] = true
+				next = append(next, link)
+			}
+		}
+
+		// don't fetch more pages than could still be kept
+		if remaining := maxPages - len(pages); len(next) > remaining {
+			next = next[:remaining]
+		}
+		level = next
+	}
+
+	if len(pages) == 0 {
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		return nil, fmt.Errorf("no pages with text content found at %s", params.StartUrl)
+	}
+
+	removeBoilerplate(pages)
+
+	return pages, nil
+}
+
+func crawlPage(u *neturl.URL) (*CrawledPage, []*neturl.URL, error) {
+	content, contentType, finalUrl, err := fetch(u.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	if !strings.Contains(contentType, "text/html") {
+		return nil, nil, nil
+	}
+	if finalUrl == nil {
+		finalUrl = u
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(content)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %v", u, err)
+	}
+
+	base := finalUrl
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+		if b, err := neturl.Parse(href); err == nil {
+			base = finalUrl.ResolveReference(b)
+		}
+	}
+
+	var links []*neturl.URL
+	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		link, err := neturl.Parse(strings.TrimSpace(href))
+		if err != nil {
+			return
+		}
+		links = append(links, base.ResolveReference(link))
+	})
+
+	title, markdown, err := HTMLToMarkdown(string(content), base)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert %s: %v", u, err)
+	}
+
+	return &CrawledPage{
+		Url:      finalUrl.String(),
+		Title:    title,
+		Markdown: markdown,
+	}, links, nil
+}
+
+// crawlScopePrefix is the directory of u's path - pages under it are in scope when no include globs are set
+func crawlScopePrefix(u *neturl.URL) string {
+	prefix := u.Path
+	if !strings.HasSuffix(prefix, "/") {
+		prefix = path.Dir(prefix)
+		if !strings.HasSuffix(prefix, "/") {
+			prefix += "/"
+		}
+	}
+	return prefix
+}
+
+// normalizeCrawlUrl drops fragments and query strings so the same page isn't fetched twice
+func normalizeCrawlUrl(u *neturl.URL) *neturl.URL {
+	res := *u
+	res.Fragment = ""
+	res.RawFragment = ""
+	res.RawQuery = ""
+	res.Host = strings.ToLower(res.Host)
+	if res.Path == "" {
+		res.Path = "/"
+	}
+	return &res
+}
+
+// globToRegex converts a path glob to a regex. '**' matches across path segments, '*' and '?' match within one, and '[...]' is a character class.
+func globToRegex(glob string) (*regexp.Regexp, error) {
+	// a full url is matched on its path
+	if i := strings.Index(glob, "://"); i >= 0 {
+		rest := glob[i+3:]
+		if j := strings.Index(rest, "/"); j >= 0 {
+			glob = rest[j:]
+		} else {
+			glob = "/**"
+		}
+	}
+	if !strings.HasPrefix(glob, "/") && !strings.HasPrefix(glob, "*") {
+		glob = "/" + glob
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case c == '*' && i+1 < len(glob) && glob[i+1] == '*':
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				sb.WriteString(regexp.QuoteMeta(string(c)))
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+
+	return regexp.Compile(sb.String())
+}
+
+func removeBoilerplate(pages []*CrawledPage) {
+	if len(pages) < boilerplateMinPages {
+		return
+	}
+
+	counts := map[string]int{}
+	for _, page := range pages {
+		seen := map[string]bool{}
+		for _, block := range splitMarkdownBlocks(page.Markdown) {
+			if !seen[block] {
+				seen[block] = true
+				counts[block]++
+			}
+		}
+	}
+
+	threshold := max(boilerplateMinPages, int(float64(len(pages))*boilerplateMinShare))
+
+	for _, page := range pages {
+		var kept []string
+		for _, block := range splitMarkdownBlocks(page.Markdown) {
+			// headings and code are kept since they often repeat legitimately (e.g. 'Parameters', or an install command)
+			if counts[block] >= threshold && !strings.HasPrefix(block, "#") && !strings.HasPrefix(block, "```") {
+				continue
+			}
+			kept = append(kept, block)
+		}
+		page.Markdown = strings.Join(kept, "\n\n")
+	}
+}
+
+// splitMarkdownBlocks splits markdown on blank lines, keeping fenced code blocks whole
+func splitMarkdownBlocks(markdown string) []string {
+	var blocks []string
+	var current []string
+	inCode := false
+
+	for _, line := range strings.Split(markdown, "\n") {
+		if strings.HasPrefix(line, "```") {
+			inCode = !inCode
+		}
+		if !inCode && strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				blocks = append(blocks, strings.Join(current, "\n"))
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, strings.Join(current, "\n"))
+	}
+
+	return blocks
+}
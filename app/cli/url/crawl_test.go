@@ -0,0 +1,56 @@
+package url
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestCrawlFollowsStartRedirect(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the start url is on 127.0.0.1 and redirects to the same server under localhost and a different directory
+		if strings.HasPrefix(r.Host, "127.0.0.1") {
+			http.Redirect(w, r, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)+"/docs/", http.StatusFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch r.URL.Path {
+		case "/docs/":
+			fmt.Fprint(w, `<html><body><h1>Docs</h1><p>Start page.</p><a href="guide">Guide</a><a href="/blog/">Blog</a></body></html>`)
+		case "/docs/guide":
+			fmt.Fprint(w, `<html><body><h1>Guide</h1><p>Guide page.</p></body></html>`)
+		case "/blog/":
+			fmt.Fprint(w, `<html><body><h1>Blog</h1><p>Out of scope.</p></body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	pages, err := Crawl(CrawlParams{StartUrl: server.URL + "/old/", Depth: 1})
+	if err != nil {
+		t.Fatalf("Crawl: %v", err)
+	}
+
+	var paths []string
+	for _, page := range pages {
+		u, err := neturl.Parse(page.Url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, u.Path)
+	}
+	sort.Strings(paths)
+
+	want := []string{"/docs/", "/docs/guide"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("crawled %v, want %v", paths, want)
+	}
+}
@@ -0,0 +1,324 @@
+package url
+
+import (
+	neturl "net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// elements that never hold page content
+var skipElements = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"noscript": true,
+	"template": true,
+	"svg":      true,
+	"iframe":   true,
+	"form":     true,
+	"button":   true,
+	"select":   true,
+	"nav":      true,
+	"header":   true,
+	"footer":   true,
+	"aside":    true,
+}
+
+// selectors tried in order to find a page's main content, skipping site navigation
+var mainContentSelectors = []string{
+	"main",
+	"article",
+	"[role=main]",
+	".markdown-body",
+	"#content",
+	".content",
+	"body",
+}
+
+var blankLinesRegex = regexp.MustCompile(`\n{3,}`)
+var whitespaceRegex = regexp.MustCompile(`\s+`)
+var codeLangRegex = regexp.MustCompile(`(?:^|\s)(?:language|lang)-([\w+#-]+)`)
+
+// HTMLToMarkdown converts the main content of an html page to markdown, returning the page title and the markdown. Relative links are resolved against pageUrl.
+func HTMLToMarkdown(htmlContent string, pageUrl *neturl.URL) (string, string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+	if err != nil {
+		return "", "", err
+	}
+
+	title := strings.TrimSpace(doc.Find("title").First().Text())
+	if title == "" {
+		title = strings.TrimSpace(doc.Find("h1").First().Text())
+	}
+
+	var main *goquery.Selection
+	for _, selector := range mainContentSelectors {
+		sel := doc.Find(selector).First()
+		if sel.Length() > 0 && strings.TrimSpace(sel.Text()) != "" {
+			main = sel
+			break
+		}
+	}
+	if main == nil {
+		return title, "", nil
+	}
+
+	c := &markdownConverter{pageUrl: pageUrl}
+	var sb strings.Builder
+	for _, node := range main.Nodes {
+		sb.WriteString(c.convert(node))
+	}
+
+	return title, cleanMarkdown(sb.String()), nil
+}
+
+type markdownConverter struct {
+	pageUrl *neturl.URL
+}
+
+func (c *markdownConverter) children(n *html.Node) string {
+	var sb strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		sb.WriteString(c.convert(child))
+	}
+	return sb.String()
+}
+
+func (c *markdownConverter) convert(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return whitespaceRegex.ReplaceAllString(n.Data, " ")
+	case html.ElementNode:
+	default:
+		return c.children(n)
+	}
+
+	tag := n.Data
+	if skipElements[tag] {
+		return ""
+	}
+	if hasAttr(n, "hidden") || attr(n, "aria-hidden") == "true" {
+		return ""
+	}
+
+	switch tag {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		text := strings.TrimSpace(c.children(n))
+		if text == "" {
+			return ""
+		}
+		level := int(tag[1] - '0')
+		return "\n\n" + strings.Repeat("#", level) + " " + text + "\n\n"
+
+	case "p", "div", "section", "article", "main", "dl", "figure", "details", "summary":
+		return "\n\n" + c.children(n) + "\n\n"
+
+	case "dt":
+		return "\n\n**" + strings.TrimSpace(c.children(n)) + "**\n"
+
+	case "dd":
+		return "\n" + strings.TrimSpace(c.children(n)) + "\n"
+
+	case "br":
+		return "\n"
+
+	case "hr":
+		return "\n\n---\n\n"
+
+	case "pre":
+		lang := ""
+		if m := codeLangRegex.FindStringSubmatch(attr(n, "class")); m != nil {
+			lang = m[1]
+		} else if code := firstChildElement(n, "code"); code != nil {
+			if m := codeLangRegex.FindStringSubmatch(attr(code, "class")); m != nil {
+				lang = m[1]
+			}
+		}
+		code := strings.Trim(rawText(n), "\n")
+		return "\n\n```" + lang + "\n" + code + "\n```\n\n"
+
+	case "code", "kbd", "samp":
+		text := rawText(n)
+		if text == "" {
+			return ""
+		}
+		if strings.Contains(text, "`") {
+			return "`` " + text + " ``"
+		}
+		return "`" + text + "`"
+
+	case "strong", "b":
+		return wrapInline(c.children(n), "**")
+
+	case "em", "i":
+		return wrapInline(c.children(n), "*")
+
+	case "a":
+		text := c.children(n)
+		href := strings.TrimSpace(attr(n, "href"))
+		if strings.TrimSpace(text) == "" || href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
+			return text
+		}
+		if u, err := neturl.Parse(href); err == nil && c.pageUrl != nil {
+			href = c.pageUrl.ResolveReference(u).String()
+		}
+		return "[" + strings.TrimSpace(text) + "](" + href + ")"
+
+	case "img":
+		return ""
+
+	case "ul", "ol":
+		var sb strings.Builder
+		i := 0
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode || child.Data != "li" {
+				continue
+			}
+			i++
+			marker := "- "
+			if tag == "ol" {
+				marker = strconv.Itoa(i) + ". "
+			}
+			item := strings.TrimSpace(cleanMarkdown(c.children(child)))
+			// continuation lines and nested lists are indented under the item
+			item = strings.ReplaceAll(item, "\n", "\n"+strings.Repeat(" ", len(marker)))
+			sb.WriteString(marker + item + "\n")
+		}
+		return "\n\n" + sb.String() + "\n\n"
+
+	case "blockquote":
+		text := strings.TrimSpace(cleanMarkdown(c.children(n)))
+		return "\n\n> " + strings.ReplaceAll(text, "\n", "\n> ") + "\n\n"
+
+	case "table":
+		return "\n\n" + c.table(n) + "\n\n"
+	}
+
+	return c.children(n)
+}
+
+func (c *markdownConverter) table(n *html.Node) string {
+	var rows [][]string
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			if child.Data == "tr" {
+				var row []string
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.Data == "td" || cell.Data == "th") {
+						text := strings.TrimSpace(whitespaceRegex.ReplaceAllString(c.children(cell), " "))
+						row = append(row, strings.ReplaceAll(text, "|", "\\|"))
+					}
+				}
+				rows = append(rows, row)
+			} else if child.Data != "table" {
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+
+	if len(rows) == 0 {
+		return ""
+	}
+
+	numCols := 0
+	for _, row := range rows {
+		numCols = max(numCols, len(row))
+	}
+
+	var sb strings.Builder
+	for i, row := range rows {
+		for len(row) < numCols {
+			row = append(row, "")
+		}
+		sb.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			sb.WriteString(strings.Repeat("| --- ", numCols) + "|\n")
+		}
+	}
+	return sb.String()
+}
+
+func wrapInline(text, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	// keep surrounding spaces outside the markers
+	leading := text[:len(text)-len(strings.TrimLeft(text, " "))]
+	trailing := text[len(strings.TrimRight(text, " ")):]
+	return leading + marker + trimmed + marker + trailing
+}
+
+func rawText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.TextNode {
+			sb.WriteString(node.Data)
+		} else if node.Type == html.ElementNode && node.Data == "br" {
+			sb.WriteString("\n")
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return sb.String()
+}
+
+func firstChildElement(n *html.Node, tag string) *html.Node {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.Data == tag {
+			return child
+		}
+	}
+	return nil
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// cleanMarkdown trims each line outside of code blocks and collapses runs of blank lines
+func cleanMarkdown(s string) string {
+	lines := strings.Split(s, "\n")
+	inCode := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			lines[i] = strings.TrimSpace(line)
+			continue
+		}
+		if !inCode {
+			lines[i] = strings.TrimRight(line, " \t")
+			if strings.TrimSpace(lines[i]) == "" {
+				lines[i] = ""
+			} else if !strings.HasPrefix(lines[i], "  ") {
+				lines[i] = strings.TrimLeft(lines[i], " ")
+			}
+		}
+	}
+	return strings.TrimSpace(blankLinesRegex.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
@@ -4,7 +4,7 @@ import (
 	"errors"
 	"io"
 	"net/http"
-	"net/url"
+	neturl "net/url"
 	"plandex-cli/term"
 	"regexp"
 	"strings"
@@ -21,6 +21,20 @@ const (
 )
 
 func FetchURLContent(url string) (string, error) {
+	content, contentType, _, err := fetch(url)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.Contains(contentType, "text/html") {
+		return ExtractTextualContent(string(content)), nil
+	} else {
+		return string(content), nil
+	}
+}
+
+// fetch returns the body, content type, and final url after redirects
+func fetch(url string) ([]byte, string, *neturl.URL, error) {
 	client := &http.Client{
 		Timeout: httpTimeout,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
@@ -33,12 +47,12 @@ func FetchURLContent(url string) (string, error) {
 
 	resp, err := client.Get(url)
 	if err != nil {
-		return "", err
+		return nil, "", nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return "", errors.New("non-2xx HTTP response status: " + resp.Status)
+		return nil, "", nil, errors.New("non-2xx HTTP response status: " + resp.Status)
 	}
 
 	// Limit the response reader to a maximum amount
@@ -46,15 +60,10 @@ func FetchURLContent(url string) (string, error) {
 
 	content, err := io.ReadAll(limitedReader)
 	if err != nil {
-		return "", err
+		return nil, "", nil, err
 	}
 
-	contentType := resp.Header.Get("Content-Type")
-	if strings.Contains(contentType, "text/html") {
-		return ExtractTextualContent(string(content)), nil
-	} else {
-		return string(content), nil
-	}
+	return content, resp.Header.Get("Content-Type"), resp.Request.URL, nil
 }
 
 func ExtractTextualContent(htmlContent string) string {
@@ -86,6 +95,6 @@ func SanitizeURL(url string) string {
 }
 
 func IsValidURL(str string) bool {
-	u, err := url.Parse(str)
+	u, err := neturl.Parse(str)
 	return err == nil && u.Scheme != "" && u.Host != ""
 }
@@ -79,6 +79,19 @@ func CreateBranch(repo *GitRepo, plan *Plan, parentBranch *Branch, name string,
 		return nil, fmt.Errorf("error creating branch: %v", err)
 	}
 
+	if parentBranch != nil {
+		var execer sqlx.Execer = Conn
+		if tx != nil {
+			execer = tx
+		}
+
+		err = copyConvoSearchIndex(execer, parentBranch.Id, branch.Id)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Create the git branch (except for main, which is created by default on repo init)
 	if name != "main" {
 		// parentBranchName := "main"
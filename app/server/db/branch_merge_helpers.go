@@ -0,0 +1,762 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+)
+
+var ErrBranchRefNotFound = errors.New("ref not found")
+
+// cherry-pick refs are convo message ids (uuids) or full or abbreviated commit shas - anything else could be read as a git option or escape the conversation dir
+var cherryPickRefRegex = regexp.MustCompile(`^[0-9a-fA-F]+(-[0-9a-fA-F]+)*$`)
+
+func ValidateCherryPickRef(ref string) error {
+	if !cherryPickRefRegex.MatchString(ref) {
+		return fmt.Errorf("invalid ref '%s' - expected a message id or commit sha", ref)
+	}
+	return nil
+}
+
+type MergeBranchParams struct {
+	Repo         *GitRepo
+	OrgId        string
+	PlanId       string
+	SourceBranch string
+	TargetBranch string
+}
+
+// MergeBranch brings convo messages, context, and results from SourceBranch into TargetBranch. It must be called from a write repo operation on TargetBranch.
+func MergeBranch(params MergeBranchParams) (*shared.BranchMergeResponse, error) {
+	repo := params.Repo
+
+	res := &shared.BranchMergeResponse{
+		SourceBranch: params.SourceBranch,
+		TargetBranch: params.TargetBranch,
+	}
+
+	sourceSha, err := repo.GitResolveCommit(params.SourceBranch, params.SourceBranch)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBranchRefNotFound, err)
+	}
+
+	base, err := repo.GitMergeBase(params.TargetBranch, sourceSha)
+	if err != nil {
+		return nil, err
+	}
+
+	if base == sourceSha {
+		res.UpToDate = true
+		return res, nil
+	}
+
+	changed, err := repo.GitChangedPaths(base, sourceSha)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.GitStartMerge(params.TargetBranch, sourceSha)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(changed))
+	for p := range changed {
+		paths = append(paths, p)
+	}
+
+	err = applyIncoming(applyIncomingParams{
+		repo:        repo,
+		orgId:       params.OrgId,
+		planId:      params.PlanId,
+		branch:      params.TargetBranch,
+		baseRef:     base,
+		incomingRef: sourceSha,
+		paths:       paths,
+		commitMsg:   fmt.Sprintf("🔀 Merged branch %s into %s", params.SourceBranch, params.TargetBranch),
+		isMerge:     true,
+	}, res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+type CherryPickParams struct {
+	Repo         *GitRepo
+	OrgId        string
+	PlanId       string
+	SourceBranch string
+	TargetBranch string
+	Ref          string
+}
+
+// CherryPick brings a single convo message (with its results and description) or a single commit from SourceBranch into TargetBranch. It must be called from a write repo operation on TargetBranch.
+func CherryPick(params CherryPickParams) (*shared.BranchMergeResponse, error) {
+	repo := params.Repo
+
+	err := ValidateCherryPickRef(params.Ref)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBranchRefNotFound, err)
+	}
+
+	res := &shared.BranchMergeResponse{
+		SourceBranch: params.SourceBranch,
+		TargetBranch: params.TargetBranch,
+	}
+
+	sourceSha, err := repo.GitResolveCommit(params.SourceBranch, params.SourceBranch)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBranchRefNotFound, err)
+	}
+
+	var baseRef, incomingRef, commitMsg string
+	var paths []string
+
+	msgPath := path.Join("conversation", params.Ref+".json")
+	_, isMsg, err := repo.GitShowFile(sourceSha, msgPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if isMsg {
+		baseRef, err = repo.GitMergeBase(params.TargetBranch, sourceSha)
+		if err != nil {
+			return nil, err
+		}
+		incomingRef = sourceSha
+
+		paths = []string{msgPath}
+		for _, dir := range []string{"results", "descriptions"} {
+			files, err := repo.GitListFiles(sourceSha, dir)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, file := range files {
+				content, _, err := repo.GitShowFile(sourceSha, file)
+				if err != nil {
+					return nil, err
+				}
+
+				var ref struct {
+					ConvoMessageId string `json:"convoMessageId"`
+				}
+				if err := json.Unmarshal(content, &ref); err != nil {
+					return nil, fmt.Errorf("error unmarshalling %s: %v", file, err)
+				}
+
+				if ref.ConvoMessageId == params.Ref {
+					paths = append(paths, file)
+				}
+			}
+		}
+
+		commitMsg = fmt.Sprintf("🍒 Cherry-picked message %s from branch %s", params.Ref, params.SourceBranch)
+	} else {
+		sha, err := repo.GitResolveCommit(sourceSha, params.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrBranchRefNotFound, err)
+		}
+
+		baseRef, err = repo.GitParentSha(sha)
+		if err != nil {
+			return nil, err
+		}
+		incomingRef = sha
+
+		changed, err := repo.GitChangedPaths(baseRef, sha)
+		if err != nil {
+			return nil, err
+		}
+		for p := range changed {
+			paths = append(paths, p)
+		}
+
+		commitMsg = fmt.Sprintf("🍒 Cherry-picked commit %s from branch %s", params.Ref, params.SourceBranch)
+	}
+
+	err = applyIncoming(applyIncomingParams{
+		repo:        repo,
+		orgId:       params.OrgId,
+		planId:      params.PlanId,
+		branch:      params.TargetBranch,
+		baseRef:     baseRef,
+		incomingRef: incomingRef,
+		paths:       paths,
+		commitMsg:   commitMsg,
+	}, res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+type applyIncomingParams struct {
+	repo        *GitRepo
+	orgId       string
+	planId      string
+	branch      string
+	baseRef     string
+	incomingRef string
+	paths       []string
+	commitMsg   string
+	isMerge     bool
+}
+
+type incomingWrite struct {
+	content   []byte
+	deleted   bool
+	curExists bool
+}
+
+// applyIncoming does a three-way merge of paths between baseRef, incomingRef, and the checked out branch, then commits the result.
+// Paths changed only on the incoming side are taken as-is. Paths changed on both sides are resolved for subtasks (union) and context (newest wins); anything else is a conflict.
+// New convo messages and results are restamped so they come after the branch's existing ones, which keeps convo summaries valid and applies incoming replacements on top of pending ones.
+// If there are conflicts, or incoming pending results can't be applied on top of the branch's pending results for the same file, nothing is changed and res.Conflicts is set.
+func applyIncoming(params applyIncomingParams, res *shared.BranchMergeResponse) (err error) {
+	repo := params.repo
+	planDir := getPlanDir(params.orgId, params.planId)
+
+	defer func() {
+		if err != nil || len(res.Conflicts) > 0 || res.UpToDate {
+			discardErr := repo.GitDiscardChanges(params.branch)
+			if discardErr != nil {
+				log.Printf("Error discarding changes after merge: %v\n", discardErr)
+			}
+		}
+	}()
+
+	sort.Strings(params.paths)
+
+	writes := map[string]*incomingWrite{}
+	bothChangedContextIds := map[string]bool{}
+
+	for _, p := range params.paths {
+		inc, incExists, err := repo.GitShowFile(params.incomingRef, p)
+		if err != nil {
+			return err
+		}
+		base, baseExists, err := repo.GitShowFile(params.baseRef, p)
+		if err != nil {
+			return err
+		}
+		cur, curExists, err := readPlanFile(planDir, p)
+		if err != nil {
+			return err
+		}
+
+		if sameVersion(p, inc, incExists, cur, curExists) {
+			continue
+		}
+
+		if sameVersion(p, cur, curExists, base, baseExists) {
+			if incExists && curExists {
+				// keep the branch's own ordering for messages and results it already has
+				inc, err = copyStamp(p, cur, inc)
+				if err != nil {
+					return err
+				}
+			}
+			writes[p] = &incomingWrite{content: inc, deleted: !incExists, curExists: curExists}
+			continue
+		}
+
+		switch {
+		case p == "subtasks.json":
+			merged, numAdded, err := mergeSubtasks(cur, inc)
+			if err != nil {
+				return err
+			}
+			writes[p] = &incomingWrite{content: merged, curExists: curExists}
+			res.SubtasksAdded = numAdded
+		case strings.HasPrefix(p, "context/"):
+			bothChangedContextIds[contextIdForPath(p)] = true
+		default:
+			res.Conflicts = append(res.Conflicts, &shared.BranchMergeConflict{
+				Path:   p,
+				Reason: "changed on both branches",
+			})
+		}
+	}
+
+	if len(res.Conflicts) > 0 {
+		return nil
+	}
+
+	curContexts, err := GetPlanContexts(params.orgId, params.planId, false, false)
+	if err != nil {
+		return fmt.Errorf("error getting contexts: %v", err)
+	}
+	curContextsById := map[string]*Context{}
+	curContextsByFilePath := map[string]*Context{}
+	for _, context := range curContexts {
+		curContextsById[context.Id] = context
+		if context.FilePath != "" {
+			curContextsByFilePath[context.FilePath] = context
+		}
+	}
+
+	// context that was updated on both sides: the most recently updated version wins
+	for id := range bothChangedContextIds {
+		incMeta, err := getContextMetaAtRef(repo, params.incomingRef, id)
+		if err != nil {
+			return err
+		}
+		curContext := curContextsById[id]
+
+		useIncoming := incMeta != nil && curContext != nil && incMeta.UpdatedAt.After(curContext.UpdatedAt)
+
+		err = setContextWrites(repo, planDir, params.incomingRef, id, useIncoming, writes)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the same file loaded as separate context on each branch: keep only the most recently updated one
+	var newMetaPaths []string
+	for p, write := range writes {
+		if strings.HasPrefix(p, "context/") && strings.HasSuffix(p, ".meta") && !write.curExists && !write.deleted {
+			newMetaPaths = append(newMetaPaths, p)
+		}
+	}
+	for _, p := range newMetaPaths {
+		write, ok := writes[p]
+		if !ok {
+			continue
+		}
+
+		var incContext Context
+		if err := json.Unmarshal(write.content, &incContext); err != nil {
+			return fmt.Errorf("error unmarshalling context %s: %v", p, err)
+		}
+
+		curContext := curContextsByFilePath[incContext.FilePath]
+		if incContext.FilePath == "" || curContext == nil || curContext.Id == incContext.Id {
+			continue
+		}
+
+		if incContext.UpdatedAt.After(curContext.UpdatedAt) {
+			err = setContextWrites(repo, planDir, "", curContext.Id, true, writes)
+		} else {
+			err = setContextWrites(repo, planDir, params.incomingRef, incContext.Id, false, writes)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(writes) == 0 && !params.isMerge {
+		res.UpToDate = true
+		return nil
+	}
+
+	curResults, err := GetPlanFileResults(params.orgId, params.planId)
+	if err != nil {
+		return fmt.Errorf("error getting plan file results: %v", err)
+	}
+	curPendingPaths := map[string]bool{}
+	for _, result := range curResults {
+		if _, ok := writes[path.Join("results", result.Id+".json")]; ok {
+			continue
+		}
+		if result.ToApi().IsPending() {
+			curPendingPaths[result.Path] = true
+		}
+	}
+
+	err = restampNew(params.orgId, params.planId, writes)
+	if err != nil {
+		return err
+	}
+
+	pendingPaths := map[string]bool{}
+
+	for p, write := range writes {
+		if write.deleted {
+			if strings.HasSuffix(p, ".meta") {
+				res.ContextsRemoved++
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(p, "conversation/"):
+			if !write.curExists {
+				res.ConvoMessagesAdded++
+			}
+		case strings.HasPrefix(p, "context/") && strings.HasSuffix(p, ".meta"):
+			if write.curExists {
+				res.ContextsUpdated++
+			} else {
+				res.ContextsAdded++
+			}
+		case strings.HasPrefix(p, "results/"):
+			if write.curExists {
+				res.ResultsUpdated++
+			} else {
+				res.ResultsAdded++
+			}
+
+			var result PlanFileResult
+			if err := json.Unmarshal(write.content, &result); err != nil {
+				return fmt.Errorf("error unmarshalling result %s: %v", p, err)
+			}
+			if result.ToApi().IsPending() {
+				pendingPaths[result.Path] = true
+			}
+		}
+	}
+
+	for p, write := range writes {
+		fullPath := filepath.Join(planDir, filepath.FromSlash(p))
+
+		if write.deleted {
+			err := os.Remove(fullPath)
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("error removing %s: %v", p, err)
+			}
+			continue
+		}
+
+		err := os.MkdirAll(filepath.Dir(fullPath), 0755)
+		if err != nil {
+			return fmt.Errorf("error creating dir for %s: %v", p, err)
+		}
+
+		err = os.WriteFile(fullPath, write.content, 0644)
+		if err != nil {
+			return fmt.Errorf("error writing %s: %v", p, err)
+		}
+	}
+
+	if len(pendingPaths) > 0 {
+		conflicts, err := checkPendingResults(params.orgId, params.planId, pendingPaths, curPendingPaths)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			res.Conflicts = conflicts
+			return nil
+		}
+	}
+
+	for p := range pendingPaths {
+		res.PendingPaths = append(res.PendingPaths, p)
+	}
+	sort.Strings(res.PendingPaths)
+
+	err = repo.GitAddAndCommit(params.branch, params.commitMsg)
+	if err != nil {
+		return fmt.Errorf("error committing merge: %v", err)
+	}
+
+	err = SyncPlanTokens(params.orgId, params.planId, params.branch)
+	if err != nil {
+		return fmt.Errorf("error syncing plan tokens: %v", err)
+	}
+
+	return nil
+}
+
+// checkPendingResults replays the pending results for each path with incoming pending results. Incoming replacements that can't be applied on top of the branch's pending results, or on top of its context for the file, are conflicts.
+func checkPendingResults(orgId, planId string, paths, curPendingPaths map[string]bool) ([]*shared.BranchMergeConflict, error) {
+	results, err := GetPlanFileResults(orgId, planId)
+	if err != nil {
+		return nil, fmt.Errorf("error getting plan file results: %v", err)
+	}
+
+	contexts, err := GetPlanContexts(orgId, planId, true, false)
+	if err != nil {
+		return nil, fmt.Errorf("error getting contexts: %v", err)
+	}
+
+	contextsByPath := map[string]*shared.Context{}
+	for _, context := range contexts {
+		if context.FilePath != "" {
+			contextsByPath[context.FilePath] = context.ToApi()
+		}
+	}
+
+	var conflicts []*shared.BranchMergeConflict
+
+	sortedPaths := make([]string, 0, len(paths))
+	for p := range paths {
+		sortedPaths = append(sortedPaths, p)
+	}
+	sort.Strings(sortedPaths)
+
+	for _, p := range sortedPaths {
+		var pathResults []*shared.PlanFileResult
+		for _, result := range results {
+			if result.Path == p {
+				pathResults = append(pathResults, result.ToApi())
+			}
+		}
+
+		planState := &shared.CurrentPlanState{
+			PlanResult:     GetPlanResult(pathResults),
+			ContextsByPath: contextsByPath,
+		}
+
+		_, err := planState.GetFiles()
+		if err != nil {
+			log.Printf("Pending changes can't be merged for %s: %v\n", p, err)
+			reason := "pending changes don't apply to this branch's version of the file"
+			if curPendingPaths[p] {
+				reason = "pending changes on both branches overlap"
+			}
+			conflicts = append(conflicts, &shared.BranchMergeConflict{
+				Path:   p,
+				Reason: reason,
+			})
+		}
+	}
+
+	return conflicts, nil
+}
+
+// restampNew gives newly added convo messages and results timestamps (and message numbers) after the branch's existing ones, preserving their relative order
+func restampNew(orgId, planId string, writes map[string]*incomingWrite) error {
+	convo, err := GetPlanConvo(orgId, planId)
+	if err != nil {
+		return fmt.Errorf("error getting convo: %v", err)
+	}
+
+	maxNum := 0
+	for _, msg := range convo {
+		maxNum = max(maxNum, msg.Num)
+	}
+
+	var messages []*ConvoMessage
+	var results []*PlanFileResult
+
+	for p, write := range writes {
+		if write.curExists || write.deleted {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(p, "conversation/"):
+			var msg ConvoMessage
+			if err := json.Unmarshal(write.content, &msg); err != nil {
+				return fmt.Errorf("error unmarshalling convo message %s: %v", p, err)
+			}
+			messages = append(messages, &msg)
+		case strings.HasPrefix(p, "results/"):
+			var result PlanFileResult
+			if err := json.Unmarshal(write.content, &result); err != nil {
+				return fmt.Errorf("error unmarshalling result %s: %v", p, err)
+			}
+			results = append(results, &result)
+		}
+	}
+
+	ts := time.Now().UTC()
+
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+	})
+	for i, msg := range messages {
+		msg.CreatedAt = ts.Add(time.Duration(i) * time.Microsecond)
+		msg.Num = maxNum + i + 1
+
+		bytes, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("error marshalling convo message: %v", err)
+		}
+		writes[path.Join("conversation", msg.Id+".json")].content = bytes
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].CreatedAt.Before(results[j].CreatedAt)
+	})
+	for i, result := range results {
+		result.CreatedAt = ts.Add(time.Duration(i) * time.Microsecond)
+
+		bytes, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshalling result: %v", err)
+		}
+		writes[path.Join("results", result.Id+".json")].content = bytes
+	}
+
+	return nil
+}
+
+// setContextWrites sets the writes for all of a context's files. If useRef is true, the files are taken from ref (or deleted if ref is empty or they don't exist there). Otherwise any pending writes are dropped so the branch's version is kept.
+func setContextWrites(repo *GitRepo, planDir, ref, id string, useRef bool, writes map[string]*incomingWrite) error {
+	for _, ext := range []string{".meta", ".body", ".map-parts"} {
+		p := path.Join("context", id+ext)
+
+		if !useRef {
+			delete(writes, p)
+			continue
+		}
+
+		content, exists, err := repo.GitShowFile(ref, p)
+		if err != nil {
+			return err
+		}
+		_, curExists, err := readPlanFile(planDir, p)
+		if err != nil {
+			return err
+		}
+
+		if !exists && !curExists {
+			delete(writes, p)
+			continue
+		}
+
+		writes[p] = &incomingWrite{content: content, deleted: !exists, curExists: curExists}
+	}
+
+	return nil
+}
+
+func getContextMetaAtRef(repo *GitRepo, ref, id string) (*Context, error) {
+	content, exists, err := repo.GitShowFile(ref, path.Join("context", id+".meta"))
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	var context Context
+	if err := json.Unmarshal(content, &context); err != nil {
+		return nil, fmt.Errorf("error unmarshalling context %s: %v", id, err)
+	}
+
+	return &context, nil
+}
+
+func contextIdForPath(p string) string {
+	id, _, _ := strings.Cut(path.Base(p), ".")
+	return id
+}
+
+func readPlanFile(planDir, p string) ([]byte, bool, error) {
+	content, err := os.ReadFile(filepath.Join(planDir, filepath.FromSlash(p)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("error reading %s: %v", p, err)
+	}
+	return content, true, nil
+}
+
+// isStamped is true for files that get restamped when merged, so their timestamps and message numbers are ignored when comparing versions
+func isStamped(p string) bool {
+	return strings.HasPrefix(p, "conversation/") || strings.HasPrefix(p, "results/")
+}
+
+func sameVersion(p string, a []byte, aExists bool, b []byte, bExists bool) bool {
+	if aExists != bExists {
+		return false
+	}
+	if bytes.Equal(a, b) {
+		return true
+	}
+	if !isStamped(p) {
+		return false
+	}
+
+	normalize := func(content []byte) []byte {
+		var m map[string]any
+		if err := json.Unmarshal(content, &m); err != nil {
+			return content
+		}
+		delete(m, "createdAt")
+		delete(m, "num")
+		res, err := json.Marshal(m)
+		if err != nil {
+			return content
+		}
+		return res
+	}
+
+	return bytes.Equal(normalize(a), normalize(b))
+}
+
+// copyStamp keeps cur's timestamp and message number on an incoming update to a message or result
+func copyStamp(p string, cur, inc []byte) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(p, "conversation/"):
+		var curMsg, incMsg ConvoMessage
+		if err := json.Unmarshal(cur, &curMsg); err != nil {
+			return nil, fmt.Errorf("error unmarshalling convo message %s: %v", p, err)
+		}
+		if err := json.Unmarshal(inc, &incMsg); err != nil {
+			return nil, fmt.Errorf("error unmarshalling convo message %s: %v", p, err)
+		}
+		incMsg.CreatedAt = curMsg.CreatedAt
+		incMsg.Num = curMsg.Num
+		return json.Marshal(incMsg)
+	case strings.HasPrefix(p, "results/"):
+		var curResult, incResult PlanFileResult
+		if err := json.Unmarshal(cur, &curResult); err != nil {
+			return nil, fmt.Errorf("error unmarshalling result %s: %v", p, err)
+		}
+		if err := json.Unmarshal(inc, &incResult); err != nil {
+			return nil, fmt.Errorf("error unmarshalling result %s: %v", p, err)
+		}
+		incResult.CreatedAt = curResult.CreatedAt
+		return json.MarshalIndent(incResult, "", "  ")
+	}
+
+	return inc, nil
+}
+
+// mergeSubtasks keeps the branch's subtasks and adds incoming ones by title. A subtask finished on either branch stays finished.
+func mergeSubtasks(cur, inc []byte) ([]byte, int, error) {
+	var curSubtasks, incSubtasks []*Subtask
+
+	if len(cur) > 0 {
+		if err := json.Unmarshal(cur, &curSubtasks); err != nil {
+			return nil, 0, fmt.Errorf("error unmarshalling subtasks: %v", err)
+		}
+	}
+	if len(inc) > 0 {
+		if err := json.Unmarshal(inc, &incSubtasks); err != nil {
+			return nil, 0, fmt.Errorf("error unmarshalling subtasks: %v", err)
+		}
+	}
+
+	byTitle := map[string]*Subtask{}
+	for _, subtask := range curSubtasks {
+		byTitle[subtask.Title] = subtask
+	}
+
+	numAdded := 0
+	for _, subtask := range incSubtasks {
+		existing, ok := byTitle[subtask.Title]
+		if ok {
+			existing.IsFinished = existing.IsFinished || subtask.IsFinished
+			continue
+		}
+		curSubtasks = append(curSubtasks, subtask)
+		byTitle[subtask.Title] = subtask
+		numAdded++
+	}
+
+	res, err := json.Marshal(curSubtasks)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error marshalling subtasks: %v", err)
+	}
+
+	return res, numAdded, nil
+}
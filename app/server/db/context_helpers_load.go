@@ -48,7 +48,7 @@ func LoadContexts(ctx Ctx, params LoadContextsParams) (*shared.LoadContextRespon
 
 	filesToLoad := map[string]string{}
 	for _, context := range *req {
-		if context.ContextType == shared.ContextFileType {
+		if context.ContextType == shared.ContextFileType || context.ContextType == shared.ContextFileRangeType {
 			filesToLoad[context.FilePath] = context.Body
 		}
 	}
@@ -92,6 +92,11 @@ func LoadContexts(ctx Ctx, params LoadContextsParams) (*shared.LoadContextRespon
 	plannerMaxTokens := settings.GetPlannerEffectiveMaxTokens()
 	contextLoaderMaxTokens := settings.GetArchitectEffectiveMaxTokens()
 
+	plannerMaxTokens, contextLoaderMaxTokens, err = applyOrgPolicyTokenLimits(orgId, plannerMaxTokens, contextLoaderMaxTokens)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	mapContextsByFilePath := make(map[string]Context)
 
 	existingContexts, err := GetPlanContexts(orgId, planId, false, false)
@@ -230,6 +235,9 @@ func LoadContexts(ctx Ctx, params LoadContextsParams) (*shared.LoadContextRespon
 			if err != nil {
 				return nil, nil, fmt.Errorf("error getting image num tokens: %v", err)
 			}
+		} else if contextParams.ContextType == shared.ContextFileRangeType {
+			// the full file is stored so builds can apply changes, but only the range is sent to the model
+			numTokens = shared.GetNumTokensEstimate(shared.GetFileRangeBody(contextParams.Body, contextParams.StartLine, contextParams.EndLine))
 		} else {
 			numTokens = shared.GetNumTokensEstimate(contextParams.Body)
 		}
@@ -323,6 +331,15 @@ func LoadContexts(ctx Ctx, params LoadContextsParams) (*shared.LoadContextRespon
 					Body:            loadParams.Body,
 					ForceSkipIgnore: loadParams.ForceSkipIgnore,
 					ImageDetail:     loadParams.ImageDetail,
+					StartLine:       loadParams.StartLine,
+					EndLine:         loadParams.EndLine,
+					Symbol:          loadParams.Symbol,
+					GitDiffMode:     loadParams.GitDiffMode,
+					GitRef:          loadParams.GitRef,
+					DocsBundle:      loadParams.DocsBundle,
+					Command:         loadParams.Command,
+					CommandTimeout:  loadParams.CommandTimeout,
+					DbSource:        loadParams.DbSource,
 					AutoLoaded:      autoLoaded || loadParams.AutoLoaded,
 				}
 			}
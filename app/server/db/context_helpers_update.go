@@ -71,6 +71,11 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 	plannerMaxTokens := settings.GetPlannerEffectiveMaxTokens()
 	contextLoaderMaxTokens := settings.GetArchitectEffectiveMaxTokens()
 
+	plannerMaxTokens, contextLoaderMaxTokens, err = applyOrgPolicyTokenLimits(orgId, plannerMaxTokens, contextLoaderMaxTokens)
+	if err != nil {
+		return nil, err
+	}
+
 	if planConfig.AutoLoadContext {
 		existingContexts, err := GetPlanContexts(orgId, planId, false, false)
 		if err != nil {
@@ -132,6 +137,9 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 	numFiles := 0
 	numUrls := 0
 	numTrees := 0
+	numGitDiffs := 0
+	numCommands := 0
+	numDbSchemas := 0
 	numMaps := 0
 
 	var mu sync.Mutex
@@ -163,6 +171,19 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 			mu.Lock()
 			defer mu.Unlock()
 
+			// ranges are re-resolved client-side when the file changes
+			if context.ContextType == shared.ContextFileRangeType && params.StartLine > 0 {
+				context.StartLine = params.StartLine
+				context.EndLine = params.EndLine
+				context.Name = shared.FileRangeContextName(context.FilePath, context.StartLine, context.EndLine, context.Symbol)
+			}
+
+			// the last part of a document or docs bundle grows when pages or sections are added
+			if (context.ContextType == shared.ContextDocumentType || context.ContextType == shared.ContextDocsType) && params.StartLine > 0 {
+				context.StartLine = params.StartLine
+				context.EndLine = params.EndLine
+			}
+
 			contextsById[id] = context
 			updatedContexts = append(updatedContexts, context.ToApi())
 
@@ -176,6 +197,8 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 						errCh <- fmt.Errorf("error getting num tokens: %v", err)
 						return
 					}
+				} else if context.ContextType == shared.ContextFileRangeType {
+					updateNumTokens = shared.GetNumTokensEstimate(shared.GetFileRangeBody(params.Body, context.StartLine, context.EndLine))
 				} else {
 					updateNumTokens = shared.GetNumTokensEstimate(params.Body)
 					// log.Println("len(params.Body)", len(params.Body))
@@ -196,14 +219,20 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 			}
 
 			switch context.ContextType {
-			case shared.ContextFileType:
+			case shared.ContextFileType, shared.ContextFileRangeType, shared.ContextDocumentType:
 				numFiles++
-			case shared.ContextURLType:
+			case shared.ContextURLType, shared.ContextDocsType:
 				numUrls++
 			case shared.ContextDirectoryTreeType:
 				numTrees++
 			case shared.ContextMapType:
 				numMaps++
+			case shared.ContextGitDiffType:
+				numGitDiffs++
+			case shared.ContextCommandType:
+				numCommands++
+			case shared.ContextDbSchemaType:
+				numDbSchemas++
 			}
 
 			errCh <- nil
@@ -229,7 +258,7 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 	}
 	filesToLoad := map[string]string{}
 	for _, context := range updatedContexts {
-		if context.ContextType == shared.ContextFileType {
+		if context.ContextType == shared.ContextFileType || context.ContextType == shared.ContextFileRangeType {
 			filesToLoad[context.FilePath] = (*req)[context.Id].Body
 		}
 	}
@@ -375,6 +404,9 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 		NumUrls:         numUrls,
 		NumTrees:        numTrees,
 		NumMaps:         numMaps,
+		NumGitDiffs:     numGitDiffs,
+		NumCommands:     numCommands,
+		NumDbSchemas:    numDbSchemas,
 		MaxTokens:       plannerMaxTokens,
 	}
 
@@ -384,12 +416,15 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 	}
 
 	commitMsg := shared.SummaryForUpdateContext(shared.SummaryForUpdateContextParams{
-		NumFiles:    numFiles,
-		NumTrees:    numTrees,
-		NumUrls:     numUrls,
-		NumMaps:     numMaps,
-		TokensDiff:  aggregateTokensDiff,
-		TotalTokens: totalTokens,
+		NumFiles:     numFiles,
+		NumTrees:     numTrees,
+		NumUrls:      numUrls,
+		NumMaps:      numMaps,
+		NumGitDiffs:  numGitDiffs,
+		NumCommands:  numCommands,
+		NumDbSchemas: numDbSchemas,
+		TokensDiff:   aggregateTokensDiff,
+		TotalTokens:  totalTokens,
 	}) + "\n\n" + shared.TableForContextUpdate(updateRes)
 	return &shared.LoadContextResponse{
 		TokensAdded: aggregateTokensDiff,
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	shared "plandex-shared"
+)
+
+func ListContextSets(orgId, projectId string) ([]*shared.ContextSet, error) {
+	var rows []struct {
+		ContextSet shared.ContextSet `db:"context_set"`
+		UpdatedBy  sql.NullString    `db:"updated_by"`
+	}
+	err := Conn.Select(&rows, "SELECT context_set, updated_by FROM context_sets WHERE org_id = $1 AND project_id = $2 ORDER BY name", orgId, projectId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing context sets: %v", err)
+	}
+
+	sets := make([]*shared.ContextSet, 0, len(rows))
+	for _, row := range rows {
+		set := row.ContextSet
+		set.UpdatedBy = row.UpdatedBy.String
+		sets = append(sets, &set)
+	}
+
+	return sets, nil
+}
+
+// UpsertContextSet creates the set, or replaces the project's existing set with the same name
+func UpsertContextSet(orgId, projectId, userId string, set *shared.ContextSet) error {
+	query := `
+	INSERT INTO context_sets (org_id, project_id, name, context_set, updated_by)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (project_id, name) DO UPDATE SET
+		context_set = EXCLUDED.context_set,
+		updated_by = EXCLUDED.updated_by
+	`
+
+	// the user is stored in its own column
+	set.UpdatedBy = ""
+	_, err := Conn.Exec(query, orgId, projectId, set.Name, set, userId)
+
+	if err != nil {
+		return fmt.Errorf("error upserting context set: %v", err)
+	}
+
+	return nil
+}
+
+// DeleteContextSet returns false if the project has no set with the name
+func DeleteContextSet(orgId, projectId, name string) (bool, error) {
+	res, err := Conn.Exec("DELETE FROM context_sets WHERE org_id = $1 AND project_id = $2 AND name = $3", orgId, projectId, name)
+
+	if err != nil {
+		return false, fmt.Errorf("error deleting context set: %v", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error getting rows affected: %v", err)
+	}
+
+	return rowsAffected > 0, nil
+}
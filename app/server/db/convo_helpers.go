@@ -134,6 +134,13 @@ func StoreConvoMessage(repo *GitRepo, message *ConvoMessage, currentUserId, bran
 		return "", fmt.Errorf("error adding convo tokens: %v", err)
 	}
 
+	// search is best effort - a failure to index shouldn't interrupt the plan
+	err = IndexConvoMessage(message, branch)
+
+	if err != nil {
+		log.Printf("Error indexing convo message for search: %v\n", err)
+	}
+
 	var desc string
 	if message.Role == openai.ChatMessageRoleUser {
 		desc = "💬 User prompt"
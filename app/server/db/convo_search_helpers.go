@@ -0,0 +1,294 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const convoSearchHeadlineOpts = `StartSel="**", StopSel="**", MaxFragments=2, MaxWords=25, MinWords=10, FragmentDelimiter=" … "`
+
+const upsertConvoSearchQuery = `
+INSERT INTO convo_messages_search (org_id, plan_id, branch_id, convo_message_id, role, num, message, message_created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (branch_id, convo_message_id) DO UPDATE SET
+	role = EXCLUDED.role,
+	num = EXCLUDED.num,
+	message = EXCLUDED.message,
+	message_created_at = EXCLUDED.message_created_at
+`
+
+// IndexConvoMessage adds a convo message to the search index for the branch
+func IndexConvoMessage(msg *ConvoMessage, branch string) error {
+	dbBranch, err := GetDbBranch(msg.PlanId, branch)
+	if err != nil {
+		return err
+	}
+	if dbBranch == nil {
+		return fmt.Errorf("branch %s not found", branch)
+	}
+
+	return indexConvoMessages(Conn, dbBranch.Id, []*ConvoMessage{msg})
+}
+
+// SyncConvoSearchIndex replaces the branch's indexed messages with convo. It's used after the branch's convo is rewritten by a rewind, merge, or cherry-pick.
+func SyncConvoSearchIndex(planId, branch string, convo []*ConvoMessage) error {
+	dbBranch, err := GetDbBranch(planId, branch)
+	if err != nil {
+		return err
+	}
+	if dbBranch == nil {
+		return fmt.Errorf("branch %s not found", branch)
+	}
+
+	ids := make([]string, len(convo))
+	for i, msg := range convo {
+		ids[i] = msg.Id
+	}
+
+	return WithTx(context.Background(), "sync convo search index", func(tx *sqlx.Tx) error {
+		_, err := tx.Exec("DELETE FROM convo_messages_search WHERE branch_id = $1 AND NOT (convo_message_id = ANY($2::uuid[]))", dbBranch.Id, pq.Array(ids))
+		if err != nil {
+			return fmt.Errorf("error removing convo messages from search index: %v", err)
+		}
+
+		return indexConvoMessages(tx, dbBranch.Id, convo)
+	})
+}
+
+// copyConvoSearchIndex indexes a new branch's convo, which starts as a copy of its parent's
+func copyConvoSearchIndex(execer sqlx.Execer, parentBranchId, branchId string) error {
+	_, err := execer.Exec(`
+	INSERT INTO convo_messages_search (org_id, plan_id, branch_id, convo_message_id, role, num, message, message_created_at)
+	SELECT org_id, plan_id, $2, convo_message_id, role, num, message, message_created_at
+	FROM convo_messages_search WHERE branch_id = $1
+	`, parentBranchId, branchId)
+
+	if err != nil {
+		return fmt.Errorf("error copying convo search index: %v", err)
+	}
+
+	return nil
+}
+
+func indexConvoMessages(execer sqlx.Execer, branchId string, convo []*ConvoMessage) error {
+	for _, msg := range convo {
+		_, err := execer.Exec(upsertConvoSearchQuery, msg.OrgId, msg.PlanId, branchId, msg.Id, msg.Role, msg.Num, msg.Message, msg.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("error indexing convo message %s: %v", msg.Id, err)
+		}
+	}
+
+	return nil
+}
+
+// backfillConvoSearchIndex indexes the convos of plans in the search scope that haven't been indexed yet. Messages are indexed as they're stored, so this only matters for plans with history from before search existed, and runs once per plan.
+func backfillConvoSearchIndex(params SearchConvoParams, projectIds []string) error {
+	var planIds []string
+	err := Conn.Select(&planIds, `
+	SELECT p.id FROM plans p
+	WHERE p.org_id = $1 AND p.owner_id = $2 AND (cardinality($3::uuid[]) = 0 OR p.project_id = ANY($3::uuid[]))
+		AND NOT EXISTS (SELECT 1 FROM convo_search_backfills bf WHERE bf.plan_id = p.id)
+	`, params.OrgId, params.UserId, pq.Array(projectIds))
+
+	if err != nil {
+		return fmt.Errorf("error getting plans to backfill convo search index: %v", err)
+	}
+
+	for _, planId := range planIds {
+		// a plan that fails to backfill is retried on the next search rather than failing this one
+		err := backfillPlanConvoSearchIndex(params.OrgId, planId)
+		if err != nil {
+			log.Printf("Error backfilling convo search index for plan %s: %v\n", planId, err)
+		}
+	}
+
+	return nil
+}
+
+func backfillPlanConvoSearchIndex(orgId, planId string) error {
+	var branches []*Branch
+	err := Conn.Select(&branches, "SELECT * FROM branches WHERE plan_id = $1", planId)
+	if err != nil {
+		return fmt.Errorf("error getting branches: %v", err)
+	}
+
+	repo := &GitRepo{orgId: orgId, planId: planId}
+
+	// branch rows can outlive their git branch
+	gitBranches, err := repo.GitListBranches()
+	if err != nil {
+		return err
+	}
+
+	return WithTx(context.Background(), "backfill convo search index", func(tx *sqlx.Tx) error {
+		for _, branch := range branches {
+			if !slices.Contains(gitBranches, branch.Name) {
+				continue
+			}
+
+			convo, err := getCommittedConvo(repo, branch.Name)
+			if err != nil {
+				return err
+			}
+
+			err = indexConvoMessages(tx, branch.Id, convo)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.Exec("INSERT INTO convo_search_backfills (plan_id) VALUES ($1) ON CONFLICT DO NOTHING", planId)
+		if err != nil {
+			return fmt.Errorf("error recording convo search backfill: %v", err)
+		}
+
+		return nil
+	})
+}
+
+// getCommittedConvo reads a branch's convo from its latest commit. It only reads git objects, so it doesn't need a repo lock or a checkout.
+func getCommittedConvo(repo *GitRepo, branch string) ([]*ConvoMessage, error) {
+	ref := "refs/heads/" + branch
+
+	files, err := repo.GitListFiles(ref, "conversation")
+	if err != nil {
+		return nil, err
+	}
+
+	var convo []*ConvoMessage
+	for _, file := range files {
+		content, exists, err := repo.GitShowFile(ref, file)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+
+		var msg ConvoMessage
+		if err := json.Unmarshal(content, &msg); err != nil {
+			return nil, fmt.Errorf("error unmarshalling %s: %v", file, err)
+		}
+		convo = append(convo, &msg)
+	}
+
+	return convo, nil
+}
+
+type SearchConvoParams struct {
+	OrgId      string
+	UserId     string
+	ProjectIds []string
+	Query      string
+	Limit      int
+}
+
+type convoSearchRow struct {
+	Type           string    `db:"type"`
+	PlanId         string    `db:"plan_id"`
+	PlanName       string    `db:"plan_name"`
+	ProjectId      string    `db:"project_id"`
+	Branch         string    `db:"branch"`
+	ConvoMessageId string    `db:"convo_message_id"`
+	Num            int       `db:"num"`
+	Role           string    `db:"role"`
+	Snippet        string    `db:"snippet"`
+	Rank           float64   `db:"rank"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+// SearchConvo runs a full-text search over convo messages, the latest matching summary of each plan, and plan names. Only plans owned by the user are searched. If ProjectIds is empty, all the user's plans in the org are searched.
+func SearchConvo(params SearchConvoParams) ([]*shared.ConvoSearchResult, error) {
+	query := `
+	WITH q AS (
+		SELECT websearch_to_tsquery('english', $1) AS query
+	),
+	scoped_plans AS (
+		SELECT id, name, project_id, updated_at FROM plans
+		WHERE org_id = $2 AND owner_id = $3 AND (cardinality($4::uuid[]) = 0 OR project_id = ANY($4::uuid[]))
+	)
+	SELECT * FROM (
+		SELECT 'message' AS type, p.id AS plan_id, p.name AS plan_name, p.project_id, b.name AS branch,
+			s.convo_message_id::text AS convo_message_id, s.num, s.role,
+			ts_headline('english', s.message, q.query, $6) AS snippet,
+			ts_rank(s.search_vector, q.query) AS rank,
+			s.message_created_at AS created_at
+		FROM convo_messages_search s
+		JOIN scoped_plans p ON p.id = s.plan_id
+		JOIN branches b ON b.id = s.branch_id
+		CROSS JOIN q
+		WHERE s.search_vector @@ q.query
+
+		UNION ALL
+
+		SELECT 'summary', p.id, p.name, p.project_id, '', '', 0, '',
+			ts_headline('english', cs.summary, q.query, $6),
+			ts_rank(to_tsvector('english', cs.summary), q.query),
+			cs.created_at
+		FROM (
+			SELECT DISTINCT ON (plan_id) plan_id, summary, created_at FROM convo_summaries, q
+			WHERE plan_id IN (SELECT id FROM scoped_plans) AND to_tsvector('english', summary) @@ q.query
+			ORDER BY plan_id, created_at DESC
+		) cs
+		JOIN scoped_plans p ON p.id = cs.plan_id
+		CROSS JOIN q
+
+		UNION ALL
+
+		SELECT 'plan', p.id, p.name, p.project_id, '', '', 0, '',
+			ts_headline('english', p.name, q.query, $6),
+			ts_rank(to_tsvector('english', p.name), q.query),
+			p.updated_at
+		FROM scoped_plans p
+		CROSS JOIN q
+		WHERE to_tsvector('english', p.name) @@ q.query
+	) results
+	ORDER BY rank DESC, created_at DESC
+	LIMIT $5
+	`
+
+	projectIds := params.ProjectIds
+	if projectIds == nil {
+		projectIds = []string{}
+	}
+
+	err := backfillConvoSearchIndex(params, projectIds)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []*convoSearchRow
+	err = Conn.Select(&rows, query, params.Query, params.OrgId, params.UserId, pq.Array(projectIds), params.Limit, convoSearchHeadlineOpts)
+
+	if err != nil {
+		return nil, fmt.Errorf("error searching convo: %v", err)
+	}
+
+	results := make([]*shared.ConvoSearchResult, len(rows))
+	for i, row := range rows {
+		results[i] = &shared.ConvoSearchResult{
+			Type:           shared.ConvoSearchResultType(row.Type),
+			PlanId:         row.PlanId,
+			PlanName:       row.PlanName,
+			ProjectId:      row.ProjectId,
+			Branch:         row.Branch,
+			ConvoMessageId: row.ConvoMessageId,
+			Num:            row.Num,
+			Role:           row.Role,
+			Snippet:        row.Snippet,
+			Rank:           row.Rank,
+			CreatedAt:      row.CreatedAt,
+		}
+	}
+
+	return results, nil
+}
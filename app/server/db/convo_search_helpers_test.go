@@ -0,0 +1,87 @@
+package db
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestGetCommittedConvo(t *testing.T) {
+	origBaseDir := BaseDir
+	BaseDir = t.TempDir()
+	defer func() { BaseDir = origBaseDir }()
+
+	repo := &GitRepo{orgId: "org", planId: "plan"}
+	dir := getPlanDir(repo.orgId, repo.planId)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := initGitRepo(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	git := func(args ...string) {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	writeMsg := func(msg ConvoMessage) {
+		t.Helper()
+		b, err := json.Marshal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(dir, "conversation"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "conversation", msg.Id+".json"), b, 0644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", ".")
+		git("commit", "-m", "add message "+msg.Id)
+	}
+
+	// history written before the search index existed
+	writeMsg(ConvoMessage{Id: "11111111-1111-1111-1111-111111111111", Role: "user", Num: 1, Message: "add a retry loop to the webhook sender"})
+	git("checkout", "-b", "feature")
+	writeMsg(ConvoMessage{Id: "22222222-2222-2222-2222-222222222222", Role: "assistant", Num: 2, Message: "I'll wrap the send in exponential backoff"})
+
+	// uncommitted files aren't part of the branch's history
+	if err := os.WriteFile(filepath.Join(dir, "conversation", "pending.json"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		branch string
+		want   []string
+	}{
+		{branch: "main", want: []string{"add a retry loop to the webhook sender"}},
+		{branch: "feature", want: []string{"add a retry loop to the webhook sender", "I'll wrap the send in exponential backoff"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			convo, err := getCommittedConvo(repo, tt.branch)
+			if err != nil {
+				t.Fatalf("getCommittedConvo: %v", err)
+			}
+
+			sort.Slice(convo, func(i, j int) bool { return convo[i].Num < convo[j].Num })
+
+			if len(convo) != len(tt.want) {
+				t.Fatalf("expected %d messages, got %d", len(tt.want), len(convo))
+			}
+			for i, msg := range convo {
+				if msg.Message != tt.want[i] {
+					t.Errorf("message %d: expected %q, got %q", i, tt.want[i], msg.Message)
+				}
+			}
+		})
+	}
+}
@@ -241,24 +241,51 @@ func (build *PlanBuild) ToApi() *shared.PlanBuild {
 }
 
 type OrgRole struct {
-	Id          string    `db:"id"`
-	OrgId       *string   `db:"org_id"`
-	Name        string    `db:"name"`
-	Label       string    `db:"label"`
-	Description string    `db:"description"`
-	CreatedAt   time.Time `db:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at"`
+	Id                 string             `db:"id"`
+	OrgId              *string            `db:"org_id"`
+	Name               string             `db:"name"`
+	Label              string             `db:"label"`
+	Description        string             `db:"description"`
+	ApprovedModelPacks ApprovedModelPacks `db:"approved_model_packs"`
+	CreatedAt          time.Time          `db:"created_at"`
+	UpdatedAt          time.Time          `db:"updated_at"`
 }
 
 func (role *OrgRole) ToApi() *shared.OrgRole {
 	return &shared.OrgRole{
-		Id:          role.Id,
-		IsDefault:   role.OrgId == nil,
-		Label:       role.Label,
-		Description: role.Description,
+		Id:                 role.Id,
+		IsDefault:          role.OrgId == nil,
+		Name:               role.Name,
+		Label:              role.Label,
+		Description:        role.Description,
+		ApprovedModelPacks: role.ApprovedModelPacks,
 	}
 }
 
+type ApprovedModelPacks []string
+
+func (a *ApprovedModelPacks) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+
+	switch s := src.(type) {
+	case []byte:
+		return json.Unmarshal(s, a)
+	case string:
+		return json.Unmarshal([]byte(s), a)
+	default:
+		return fmt.Errorf("unsupported data type: %T", src)
+	}
+}
+
+func (a ApprovedModelPacks) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return json.Marshal(a)
+}
+
 type ModelStream struct {
 	Id              string     `db:"id"`
 	OrgId           string     `db:"org_id"`
@@ -581,6 +608,15 @@ type Context struct {
 	MapShas         map[string]string     `json:"mapShas,omitempty"`
 	MapTokens       map[string]int        `json:"mapTokens,omitempty"`
 	MapSizes        map[string]int64      `json:"mapSizes,omitempty"`
+	StartLine       int                   `json:"startLine,omitempty"`
+	EndLine         int                   `json:"endLine,omitempty"`
+	Symbol          string                `json:"symbol,omitempty"`
+	GitDiffMode     shared.GitDiffMode    `json:"gitDiffMode,omitempty"`
+	GitRef          string                `json:"gitRef,omitempty"`
+	DocsBundle      string                `json:"docsBundle,omitempty"`
+	Command         string                `json:"command,omitempty"`
+	CommandTimeout  int                   `json:"commandTimeout,omitempty"`
+	DbSource        string                `json:"dbSource,omitempty"`
 	AutoLoaded      bool                  `json:"autoLoaded"`
 	CreatedAt       time.Time             `json:"createdAt"`
 	UpdatedAt       time.Time             `json:"updatedAt"`
@@ -607,6 +643,15 @@ func (context *Context) ToMeta() *Context {
 		MapShas:         context.MapShas,
 		MapTokens:       context.MapTokens,
 		MapSizes:        context.MapSizes,
+		StartLine:       context.StartLine,
+		EndLine:         context.EndLine,
+		Symbol:          context.Symbol,
+		GitDiffMode:     context.GitDiffMode,
+		GitRef:          context.GitRef,
+		DocsBundle:      context.DocsBundle,
+		Command:         context.Command,
+		CommandTimeout:  context.CommandTimeout,
+		DbSource:        context.DbSource,
 		CreatedAt:       context.CreatedAt,
 		UpdatedAt:       context.UpdatedAt,
 	}
@@ -631,6 +676,15 @@ func (context *Context) ToApi() *shared.Context {
 		MapShas:         context.MapShas,
 		MapTokens:       context.MapTokens,
 		MapSizes:        context.MapSizes,
+		StartLine:       context.StartLine,
+		EndLine:         context.EndLine,
+		Symbol:          context.Symbol,
+		GitDiffMode:     context.GitDiffMode,
+		GitRef:          context.GitRef,
+		DocsBundle:      context.DocsBundle,
+		Command:         context.Command,
+		CommandTimeout:  context.CommandTimeout,
+		DbSource:        context.DbSource,
 		CreatedAt:       context.CreatedAt,
 		UpdatedAt:       context.UpdatedAt,
 	}
@@ -800,3 +854,27 @@ func (subtask *Subtask) ToApi() *shared.Subtask {
 		IsFinished:  subtask.IsFinished,
 	}
 }
+
+type OrgSecret struct {
+	Id               string    `db:"id"`
+	OrgId            string    `db:"org_id"`
+	Name             string    `db:"name"`
+	EncryptedDataKey []byte    `db:"encrypted_data_key"`
+	EncryptedValue   []byte    `db:"encrypted_value"`
+	CreatedBy        *string   `db:"created_by"`
+	CreatedAt        time.Time `db:"created_at"`
+	UpdatedAt        time.Time `db:"updated_at"`
+}
+
+func (secret *OrgSecret) ToApi() *shared.OrgSecret {
+	var createdBy string
+	if secret.CreatedBy != nil {
+		createdBy = *secret.CreatedBy
+	}
+	return &shared.OrgSecret{
+		Name:      secret.Name,
+		CreatedBy: createdBy,
+		CreatedAt: secret.CreatedAt,
+		UpdatedAt: secret.UpdatedAt,
+	}
+}
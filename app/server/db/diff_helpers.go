@@ -169,3 +169,80 @@ func GetPlanDiffs(orgId, planId string, plain bool) (string, error) {
 
 	return string(res), nil
 }
+
+// getFilesDiff writes before to a temp git repo and commits it, then replaces it with after and returns the staged diff, in the same format as GetPlanDiffs
+func getFilesDiff(orgId string, before, after map[string]string, plain bool) (string, error) {
+	tempDirPath, err := os.MkdirTemp(getOrgDir(orgId), "tmp-diffs-*")
+	if err != nil {
+		return "", fmt.Errorf("error creating temp dir: %v", err)
+	}
+
+	defer func() {
+		go os.RemoveAll(tempDirPath)
+	}()
+
+	err = initGitRepo(tempDirPath)
+	if err != nil {
+		return "", fmt.Errorf("error initializing git repo: %v", err)
+	}
+
+	writeFiles := func(files map[string]string) error {
+		for path, content := range files {
+			fullPath := filepath.Join(tempDirPath, path)
+			err := os.MkdirAll(filepath.Dir(fullPath), 0755)
+			if err != nil {
+				return fmt.Errorf("error creating directory: %v", err)
+			}
+			err = os.WriteFile(fullPath, []byte(content), 0644)
+			if err != nil {
+				return fmt.Errorf("error writing file: %v", err)
+			}
+		}
+		return nil
+	}
+
+	if len(before) > 0 {
+		err = writeFiles(before)
+		if err != nil {
+			return "", err
+		}
+
+		err = gitAdd(tempDirPath, ".")
+		if err != nil {
+			return "", err
+		}
+
+		err = gitCommit(tempDirPath, "original files")
+		if err != nil {
+			return "", err
+		}
+
+		for path := range before {
+			err = os.Remove(filepath.Join(tempDirPath, path))
+			if err != nil {
+				return "", fmt.Errorf("error removing file: %v", err)
+			}
+		}
+	}
+
+	err = writeFiles(after)
+	if err != nil {
+		return "", err
+	}
+
+	err = gitAdd(tempDirPath, ".")
+	if err != nil {
+		return "", err
+	}
+
+	colorArg := "--color=always"
+	if plain {
+		colorArg = "--no-color"
+	}
+	res, err := exec.Command("git", "-C", tempDirPath, "diff", "--cached", colorArg).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("error getting diffs: %v, output: %s", err, string(res))
+	}
+
+	return string(res), nil
+}
@@ -1,12 +1,15 @@
 package db
 
 import (
+	"archive/tar"
 	"bytes"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"plandex-server/telemetry"
 	"runtime"
 	"runtime/debug"
 	"strconv"
@@ -399,6 +402,203 @@ func (repo *GitRepo) GitCheckoutBranch(branch string) error {
 	return nil
 }
 
+func (repo *GitRepo) GitMergeBase(a, b string) (string, error) {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	res, err := exec.Command("git", "-C", dir, "merge-base", a, b).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("error getting merge base for dir: %s, refs: %s %s, err: %v, output: %s", dir, a, b, err, string(res))
+	}
+
+	return strings.TrimSpace(string(res)), nil
+}
+
+// GitResolveCommit resolves ref to a full commit sha and checks that it's reachable from branch
+func (repo *GitRepo) GitResolveCommit(branch, ref string) (string, error) {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	// refs come from requests, so never let one be read as an option
+	if strings.HasPrefix(ref, "-") || strings.HasPrefix(branch, "-") {
+		return "", fmt.Errorf("invalid ref %s", ref)
+	}
+
+	res, err := exec.Command("git", "-C", dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("commit %s not found", ref)
+	}
+	sha := strings.TrimSpace(string(res))
+
+	err = exec.Command("git", "-C", dir, "merge-base", "--is-ancestor", sha, branch).Run()
+	if err != nil {
+		return "", fmt.Errorf("commit %s is not on branch %s", ref, branch)
+	}
+
+	return sha, nil
+}
+
+// GitChangedPaths returns the paths that differ between two refs, mapped to their git status letter (A, M, or D). If from is empty, all paths in to are returned as added.
+func (repo *GitRepo) GitChangedPaths(from, to string) (map[string]string, error) {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	var cmd *exec.Cmd
+	if from == "" {
+		cmd = exec.Command("git", "-C", dir, "diff-tree", "-r", "--root", "--no-commit-id", "--no-renames", "--name-status", to)
+	} else {
+		cmd = exec.Command("git", "-C", dir, "diff", "--no-renames", "--name-status", from, to)
+	}
+
+	res, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error getting changed paths for dir: %s, refs: %s..%s, err: %v, output: %s", dir, from, to, err, string(res))
+	}
+
+	changed := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(res)), "\n") {
+		status, path, found := strings.Cut(line, "\t")
+		if !found {
+			continue
+		}
+		changed[path] = status[:1]
+	}
+
+	return changed, nil
+}
+
+// GitParentSha returns the first parent of sha, or an empty string for a root commit
+func (repo *GitRepo) GitParentSha(sha string) (string, error) {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	res, err := exec.Command("git", "-C", dir, "rev-list", "--parents", "-n", "1", sha).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("error getting parent commit for dir: %s, sha: %s, err: %v, output: %s", dir, sha, err, string(res))
+	}
+
+	parts := strings.Fields(string(res))
+	if len(parts) < 2 {
+		return "", nil
+	}
+
+	return parts[1], nil
+}
+
+// GitListFiles lists the files under dirPath at ref
+func (repo *GitRepo) GitListFiles(ref, dirPath string) ([]string, error) {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	res, err := exec.Command("git", "-C", dir, "ls-tree", "-r", "--name-only", ref, "--", dirPath).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error listing files for dir: %s, ref: %s, err: %v, output: %s", dir, ref, err, string(res))
+	}
+
+	var paths []string
+	for _, line := range strings.Split(strings.TrimSpace(string(res)), "\n") {
+		if line != "" {
+			paths = append(paths, line)
+		}
+	}
+
+	return paths, nil
+}
+
+// GitShowFile returns the contents of path at ref. The bool result is false if the path doesn't exist at ref.
+func (repo *GitRepo) GitShowFile(ref, path string) ([]byte, bool, error) {
+	if ref == "" {
+		return nil, false, nil
+	}
+
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	err := exec.Command("git", "-C", dir, "cat-file", "-e", ref+":"+path).Run()
+	if err != nil {
+		return nil, false, nil
+	}
+
+	res, err := exec.Command("git", "-C", dir, "show", ref+":"+path).Output()
+	if err != nil {
+		return nil, false, fmt.Errorf("error reading %s at %s for dir: %s, err: %v", path, ref, dir, err)
+	}
+
+	return res, true, nil
+}
+
+// GitReadDir returns the contents of every file under dirPath at ref, keyed by path, using a single git archive rather than a git show per file
+func (repo *GitRepo) GitReadDir(ref, dirPath string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+
+	paths, err := repo.GitListFiles(ref, dirPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		// git archive fails on a path that doesn't exist at ref
+		return files, nil
+	}
+
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	var out, stderr bytes.Buffer
+	cmd := exec.Command("git", "-C", dir, "archive", "--format=tar", ref, "--", dirPath)
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s at %s for dir: %s, err: %v, output: %s", dirPath, ref, dir, err, stderr.String())
+	}
+
+	tr := tar.NewReader(&out)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading archive of %s at %s: %v", dirPath, ref, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s at %s: %v", header.Name, ref, err)
+		}
+		files[header.Name] = content
+	}
+
+	return files, nil
+}
+
+// GitStartMerge records source as a second parent for the next commit on the checked out branch without changing any files, so merged content can be written and committed by the caller
+func (repo *GitRepo) GitStartMerge(branch, source string) error {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	return gitWriteOperation(func() error {
+		res, err := exec.Command("git", "-C", dir, "merge", "--no-ff", "--no-commit", "-s", "ours", source).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("error starting merge for dir: %s, source: %s, err: %v, output: %s", dir, source, err, string(res))
+		}
+		return nil
+	}, dir, fmt.Sprintf("GitStartMerge > gitMerge: plan=%s branch=%s", repo.planId, branch))
+}
+
+// GitDiscardChanges resets the working tree to HEAD, removes untracked files, and clears any merge in progress
+func (repo *GitRepo) GitDiscardChanges(branch string) error {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	return gitWriteOperation(func() error {
+		res, err := exec.Command("git", "-C", dir, "reset", "--hard", "HEAD").CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("error resetting changes for dir: %s, err: %v, output: %s", dir, err, string(res))
+		}
+
+		res, err = exec.Command("git", "-C", dir, "clean", "-d", "-f").CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("error cleaning untracked files for dir: %s, err: %v, output: %s", dir, err, string(res))
+		}
+		return nil
+	}, dir, fmt.Sprintf("GitDiscardChanges > gitReset: plan=%s branch=%s", repo.planId, branch))
+}
+
 func gitAdd(repoDir, path string) error {
 
 	if err := gitRemoveIndexLockFileIfExists(repoDir); err != nil {
@@ -652,9 +852,14 @@ func setGitConfig(repoDir, key, value string) error {
 	return nil
 }
 
-func gitWriteOperation(operation func() error, repoDir, label string) error {
+func gitWriteOperation(operation func() error, repoDir, label string) (err error) {
 	log.Printf("[Git] gitWriteOperation - label: %s", label)
-	var err error
+
+	start := time.Now()
+	defer func() {
+		telemetry.ObserveGitOperation(label, err, start)
+	}()
+
 	for attempt := 0; attempt < maxGitRetries; attempt++ {
 		if attempt > 0 {
 			delay := time.Duration(1<<uint(attempt-1)) * baseGitRetryDelay // Exponential backoff
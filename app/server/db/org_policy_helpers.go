@@ -0,0 +1,56 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	shared "plandex-shared"
+)
+
+// GetOrgPolicy returns the org's policy, or an empty policy if none has been set
+func GetOrgPolicy(orgId string) (*shared.OrgPolicy, error) {
+	var policy shared.OrgPolicy
+	err := Conn.Get(&policy, "SELECT policy FROM org_policies WHERE org_id = $1", orgId)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &shared.OrgPolicy{}, nil
+		}
+		return nil, fmt.Errorf("error getting org policy: %v", err)
+	}
+
+	return &policy, nil
+}
+
+func SetOrgPolicy(orgId, userId string, policy *shared.OrgPolicy) error {
+	query := `
+	INSERT INTO org_policies (org_id, policy, updated_by)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (org_id) DO UPDATE SET
+		policy = EXCLUDED.policy,
+		updated_by = EXCLUDED.updated_by
+	`
+
+	_, err := Conn.Exec(query, orgId, policy, userId)
+
+	if err != nil {
+		return fmt.Errorf("error setting org policy: %v", err)
+	}
+
+	return nil
+}
+
+// applyOrgPolicyTokenLimits lowers the model-derived context limits to the org policy's cap, if one is set
+func applyOrgPolicyTokenLimits(orgId string, plannerMaxTokens, contextLoaderMaxTokens int) (int, int, error) {
+	policy, err := GetOrgPolicy(orgId)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if policy.MaxContextTokens > 0 {
+		plannerMaxTokens = min(plannerMaxTokens, policy.MaxContextTokens)
+		contextLoaderMaxTokens = min(contextLoaderMaxTokens, policy.MaxContextTokens)
+	}
+
+	return plannerMaxTokens, contextLoaderMaxTokens, nil
+}
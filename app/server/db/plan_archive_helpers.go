@@ -0,0 +1,519 @@
+package db
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Plan archives are gzipped tarballs holding a json manifest with everything the plan keeps in
+// postgres, plus a git bundle of the plan repo. The bundle carries every branch along with its
+// history, which includes the conversation, context, results, applies and subtasks.
+// The repo is never copied directly so that git config and hooks can't be carried into another server.
+const (
+	PlanArchiveVersion = 1
+
+	planArchiveManifestName = "manifest.json"
+	planArchiveBundleName   = "repo.bundle"
+
+	// MaxPlanArchiveSize caps the compressed archive in an import request
+	MaxPlanArchiveSize = 512 * 1024 * 1024
+)
+
+// a small archive can still decompress to far more than it holds, so each entry is capped as it's read
+var (
+	maxPlanArchiveManifestSize int64 = 64 * 1024 * 1024
+	maxPlanArchiveBundleSize   int64 = 2 * 1024 * 1024 * 1024
+)
+
+var ErrInvalidPlanArchive = errors.New("invalid plan archive")
+
+type PlanArchiveManifest struct {
+	Version      int                   `json:"version"`
+	ExportedAt   time.Time             `json:"exportedAt"`
+	Name         string                `json:"name"`
+	PlanConfig   *shared.PlanConfig    `json:"planConfig"`
+	TotalReplies int                   `json:"totalReplies"`
+	Branches     []*PlanArchiveBranch  `json:"branches"`
+	Summaries    []*PlanArchiveSummary `json:"summaries"`
+}
+
+type PlanArchiveBranch struct {
+	Name          string            `json:"name"`
+	ParentName    string            `json:"parentName,omitempty"`
+	Status        shared.PlanStatus `json:"status"`
+	ContextTokens int               `json:"contextTokens"`
+	ConvoTokens   int               `json:"convoTokens"`
+}
+
+type PlanArchiveSummary struct {
+	LatestConvoMessageId        string    `json:"latestConvoMessageId"`
+	LatestConvoMessageCreatedAt time.Time `json:"latestConvoMessageCreatedAt"`
+	Summary                     string    `json:"summary"`
+	Tokens                      int       `json:"tokens"`
+	NumMessages                 int       `json:"numMessages"`
+	CreatedAt                   time.Time `json:"createdAt"`
+}
+
+// ExportPlan writes a plan archive to w. It should be called with a repo lock held.
+func ExportPlan(repo *GitRepo, plan *Plan, w io.Writer) error {
+	branches, err := ListPlanBranches(repo, plan.Id)
+	if err != nil {
+		return fmt.Errorf("error listing branches: %v", err)
+	}
+
+	namesById := make(map[string]string, len(branches))
+	for _, branch := range branches {
+		namesById[branch.Id] = branch.Name
+	}
+
+	manifest := PlanArchiveManifest{
+		Version:      PlanArchiveVersion,
+		ExportedAt:   time.Now(),
+		Name:         plan.Name,
+		PlanConfig:   plan.PlanConfig,
+		TotalReplies: plan.TotalReplies,
+	}
+
+	for _, branch := range branches {
+		archiveBranch := &PlanArchiveBranch{
+			Name:          branch.Name,
+			Status:        branch.Status,
+			ContextTokens: branch.ContextTokens,
+			ConvoTokens:   branch.ConvoTokens,
+		}
+		if branch.ParentBranchId != nil {
+			archiveBranch.ParentName = namesById[*branch.ParentBranchId]
+		}
+		manifest.Branches = append(manifest.Branches, archiveBranch)
+	}
+
+	var summaries []*ConvoSummary
+	err = Conn.Select(&summaries, "SELECT * FROM convo_summaries WHERE plan_id = $1 ORDER BY created_at", plan.Id)
+	if err != nil {
+		return fmt.Errorf("error getting plan summaries: %v", err)
+	}
+
+	for _, summary := range summaries {
+		manifest.Summaries = append(manifest.Summaries, &PlanArchiveSummary{
+			LatestConvoMessageId:        summary.LatestConvoMessageId,
+			LatestConvoMessageCreatedAt: summary.LatestConvoMessageCreatedAt,
+			Summary:                     summary.Summary,
+			Tokens:                      summary.Tokens,
+			NumMessages:                 summary.NumMessages,
+			CreatedAt:                   summary.CreatedAt,
+		})
+	}
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling manifest: %v", err)
+	}
+
+	dir := getPlanDir(plan.OrgId, plan.Id)
+
+	// a plan that hasn't committed anything yet has no history to bundle
+	var bundleBytes []byte
+	if _, err := exec.Command("git", "-C", dir, "rev-parse", "--verify", "HEAD").CombinedOutput(); err == nil {
+		tempDir, err := os.MkdirTemp(getOrgDir(plan.OrgId), "tmp-export-*")
+		if err != nil {
+			return fmt.Errorf("error creating temp dir: %v", err)
+		}
+		defer os.RemoveAll(tempDir)
+
+		bundlePath := filepath.Join(tempDir, planArchiveBundleName)
+		res, err := exec.Command("git", "-C", dir, "bundle", "create", bundlePath, "--branches").CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("error creating git bundle for dir: %s, err: %v, output: %s", dir, err, string(res))
+		}
+
+		bundleBytes, err = os.ReadFile(bundlePath)
+		if err != nil {
+			return fmt.Errorf("error reading git bundle: %v", err)
+		}
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	writeEntry := func(name string, data []byte) error {
+		err := tw.WriteHeader(&tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: manifest.ExportedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("error writing archive header for %s: %v", name, err)
+		}
+		_, err = tw.Write(data)
+		if err != nil {
+			return fmt.Errorf("error writing archive entry %s: %v", name, err)
+		}
+		return nil
+	}
+
+	err = writeEntry(planArchiveManifestName, manifestBytes)
+	if err != nil {
+		return err
+	}
+
+	if bundleBytes != nil {
+		err = writeEntry(planArchiveBundleName, bundleBytes)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return fmt.Errorf("error closing archive: %v", err)
+	}
+
+	err = gz.Close()
+	if err != nil {
+		return fmt.Errorf("error closing archive: %v", err)
+	}
+
+	return nil
+}
+
+type ImportPlanParams struct {
+	OrgId     string
+	ProjectId string
+	UserId    string
+
+	// overrides the name in the archive when set
+	Name string
+
+	// makes the final name unique in the project
+	UniqueName func(name string) (string, error)
+
+	Archive io.Reader
+}
+
+// ImportPlan creates a new plan owned by the user from a plan archive
+func ImportPlan(ctx context.Context, params ImportPlanParams) (*Plan, error) {
+	tempDir, err := os.MkdirTemp(getOrgDir(params.OrgId), "tmp-import-*")
+	if err != nil {
+		return nil, fmt.Errorf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	manifest, bundlePath, err := readPlanArchive(params.Archive, tempDir)
+	if err != nil {
+		return nil, err
+	}
+
+	name := params.Name
+	if name == "" {
+		name = manifest.Name
+	}
+
+	if params.UniqueName != nil {
+		name, err = params.UniqueName(name)
+		if err != nil {
+			return nil, fmt.Errorf("error checking if plan exists: %v", err)
+		}
+	}
+
+	planConfig := manifest.PlanConfig
+	if planConfig == nil {
+		planConfig, err = GetDefaultPlanConfig(params.UserId)
+		if err != nil {
+			return nil, fmt.Errorf("error getting default plan config: %v", err)
+		}
+	}
+
+	var plan *Plan
+	var createdPlanDir bool
+	err = WithTx(ctx, "import plan", func(tx *sqlx.Tx) error {
+		plan = &Plan{
+			OrgId:        params.OrgId,
+			OwnerId:      params.UserId,
+			ProjectId:    params.ProjectId,
+			Name:         name,
+			PlanConfig:   planConfig,
+			TotalReplies: manifest.TotalReplies,
+		}
+
+		query := `INSERT INTO plans (org_id, owner_id, project_id, name, plan_config, total_replies, active_branches)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id, created_at, updated_at`
+
+		err := tx.QueryRow(
+			query,
+			plan.OrgId,
+			plan.OwnerId,
+			plan.ProjectId,
+			plan.Name,
+			plan.PlanConfig,
+			plan.TotalReplies,
+			len(manifest.Branches),
+		).Scan(
+			&plan.Id,
+			&plan.CreatedAt,
+			&plan.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("error creating plan: %v", err)
+		}
+		plan.ActiveBranches = len(manifest.Branches)
+
+		_, err = tx.Exec("INSERT INTO lockable_plan_ids (plan_id) VALUES ($1)", plan.Id)
+		if err != nil {
+			return fmt.Errorf("error inserting lockable plan id: %v", err)
+		}
+
+		// branches are exported in creation order, so parents always come before their children
+		idsByName := map[string]string{}
+		for _, branch := range manifest.Branches {
+			var parentId *string
+			if branch.ParentName != "" {
+				id, ok := idsByName[branch.ParentName]
+				if ok {
+					parentId = &id
+				}
+			}
+
+			status := branch.Status
+			if status != shared.PlanStatusFinished && status != shared.PlanStatusStopped && status != shared.PlanStatusError {
+				// any stream that was active at export time didn't come along
+				status = shared.PlanStatusDraft
+			}
+
+			var id string
+			err = tx.QueryRow(
+				`INSERT INTO branches (org_id, owner_id, plan_id, parent_branch_id, name, status, context_tokens, convo_tokens)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING id`,
+				plan.OrgId,
+				plan.OwnerId,
+				plan.Id,
+				parentId,
+				branch.Name,
+				status,
+				branch.ContextTokens,
+				branch.ConvoTokens,
+			).Scan(&id)
+			if err != nil {
+				return fmt.Errorf("error creating branch %s: %v", branch.Name, err)
+			}
+			idsByName[branch.Name] = id
+		}
+
+		for _, summary := range manifest.Summaries {
+			_, err = tx.Exec(
+				"INSERT INTO convo_summaries (org_id, plan_id, latest_convo_message_id, latest_convo_message_created_at, summary, tokens, num_messages, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+				plan.OrgId,
+				plan.Id,
+				summary.LatestConvoMessageId,
+				summary.LatestConvoMessageCreatedAt,
+				summary.Summary,
+				summary.Tokens,
+				summary.NumMessages,
+				summary.CreatedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("error storing summary: %v", err)
+			}
+		}
+
+		createdPlanDir = true
+		err = restorePlanRepo(plan.OrgId, plan.Id, bundlePath, manifest.Branches)
+		if err != nil {
+			return err
+		}
+
+		repo := &GitRepo{orgId: plan.OrgId, planId: plan.Id}
+		for _, branch := range manifest.Branches {
+			files, err := repo.GitReadDir(branch.Name, "conversation")
+			if err != nil {
+				return fmt.Errorf("error reading convo for branch %s: %v", branch.Name, err)
+			}
+
+			var convo []*ConvoMessage
+			for p, content := range files {
+				var msg ConvoMessage
+				err := json.Unmarshal(content, &msg)
+				if err != nil {
+					return fmt.Errorf("error unmarshalling convo message %s: %v", p, err)
+				}
+				// messages keep the ids they had in the exported plan
+				msg.OrgId = plan.OrgId
+				msg.PlanId = plan.Id
+				convo = append(convo, &msg)
+			}
+
+			err = indexConvoMessages(tx, idsByName[branch.Name], convo)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		// the transaction is rolled back, so the repo would be left without a plan
+		if createdPlanDir {
+			if removeErr := DeletePlanDir(plan.OrgId, plan.Id); removeErr != nil {
+				log.Printf("Error removing plan dir after failed import: %v\n", removeErr)
+			}
+		}
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// readPlanArchive extracts the manifest and bundle from an archive. Any other entries are ignored.
+func readPlanArchive(r io.Reader, tempDir string) (*PlanArchiveManifest, string, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrInvalidPlanArchive, err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+
+	var manifest *PlanArchiveManifest
+	var bundlePath string
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %w", ErrInvalidPlanArchive, err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		switch header.Name {
+		case planArchiveManifestName:
+			if header.Size > maxPlanArchiveManifestSize {
+				return nil, "", fmt.Errorf("%w: %s is too large", ErrInvalidPlanArchive, planArchiveManifestName)
+			}
+			manifest = &PlanArchiveManifest{}
+			err = json.NewDecoder(io.LimitReader(tr, maxPlanArchiveManifestSize)).Decode(manifest)
+			if err != nil {
+				return nil, "", fmt.Errorf("%w: error decoding manifest: %w", ErrInvalidPlanArchive, err)
+			}
+
+		case planArchiveBundleName:
+			bundlePath = filepath.Join(tempDir, planArchiveBundleName)
+			f, err := os.Create(bundlePath)
+			if err != nil {
+				return nil, "", fmt.Errorf("error creating bundle file: %v", err)
+			}
+			n, err := io.CopyN(f, tr, maxPlanArchiveBundleSize+1)
+			f.Close()
+			if err != nil && err != io.EOF {
+				return nil, "", fmt.Errorf("error writing bundle file: %w", err)
+			}
+			if n > maxPlanArchiveBundleSize {
+				return nil, "", fmt.Errorf("%w: %s is too large", ErrInvalidPlanArchive, planArchiveBundleName)
+			}
+		}
+	}
+
+	if manifest == nil {
+		return nil, "", fmt.Errorf("%w: missing %s", ErrInvalidPlanArchive, planArchiveManifestName)
+	}
+
+	if manifest.Version < 1 || manifest.Version > PlanArchiveVersion {
+		return nil, "", fmt.Errorf("%w: unsupported archive version %d", ErrInvalidPlanArchive, manifest.Version)
+	}
+
+	hasMain := false
+	for _, branch := range manifest.Branches {
+		if branch.Name == "main" {
+			hasMain = true
+			break
+		}
+	}
+	if !hasMain {
+		return nil, "", fmt.Errorf("%w: missing main branch", ErrInvalidPlanArchive)
+	}
+
+	return manifest, bundlePath, nil
+}
+
+func restorePlanRepo(orgId, planId, bundlePath string, branches []*PlanArchiveBranch) error {
+	dir := getPlanDir(orgId, planId)
+
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("error creating plan dir: %v", err)
+	}
+
+	err = initGitRepo(dir)
+	if err != nil {
+		return fmt.Errorf("error initializing git repo: %v", err)
+	}
+
+	if bundlePath != "" {
+		res, err := exec.Command("git", "-C", dir, "bundle", "verify", bundlePath).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%w: invalid git bundle: %s", ErrInvalidPlanArchive, string(res))
+		}
+
+		// only branch refs are fetched; main is checked out already so the head has to be allowed to move
+		res, err = exec.Command("git", "-C", dir, "fetch", "--update-head-ok", bundlePath, "refs/heads/*:refs/heads/*").CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("error fetching git bundle for dir: %s, err: %v, output: %s", dir, err, string(res))
+		}
+
+		res, err = exec.Command("git", "-C", dir, "reset", "--hard", "main").CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("error checking out main for dir: %s, err: %v, output: %s", dir, err, string(res))
+		}
+
+		gitBranches, err := getGitRepo(orgId, planId).GitListBranches()
+		if err != nil {
+			return fmt.Errorf("error listing git branches: %v", err)
+		}
+
+		gitBranchSet := make(map[string]bool, len(gitBranches))
+		for _, name := range gitBranches {
+			gitBranchSet[name] = true
+		}
+
+		for _, branch := range branches {
+			if !gitBranchSet[branch.Name] {
+				return fmt.Errorf("%w: branch %s is missing from the git bundle", ErrInvalidPlanArchive, branch.Name)
+			}
+		}
+	}
+
+	// git doesn't keep empty dirs
+	for _, subdirFn := range [](func(orgId, planId string) string){
+		getPlanContextDir,
+		getPlanConversationDir,
+		getPlanResultsDir,
+		getPlanDescriptionsDir} {
+		err = os.MkdirAll(subdirFn(orgId, planId), os.ModePerm)
+		if err != nil {
+			return fmt.Errorf("error creating plan subdir: %v", err)
+		}
+	}
+
+	return nil
+}
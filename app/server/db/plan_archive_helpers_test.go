@@ -0,0 +1,111 @@
+package db
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReadPlanArchive(t *testing.T) {
+	origManifestSize, origBundleSize := maxPlanArchiveManifestSize, maxPlanArchiveBundleSize
+	maxPlanArchiveManifestSize, maxPlanArchiveBundleSize = 1024, 64
+	defer func() {
+		maxPlanArchiveManifestSize, maxPlanArchiveBundleSize = origManifestSize, origBundleSize
+	}()
+
+	manifest, err := json.Marshal(PlanArchiveManifest{
+		Version:  PlanArchiveVersion,
+		Name:     "imported",
+		Branches: []*PlanArchiveBranch{{Name: "main"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type entry struct {
+		name    string
+		content string
+	}
+	archive := func(entries ...entry) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		for _, e := range entries {
+			if err := tw.WriteHeader(&tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.content)), Typeflag: tar.TypeReg}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tw.Write([]byte(e.content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	valid := archive(entry{planArchiveManifestName, string(manifest)}, entry{planArchiveBundleName, "bundle"})
+
+	tests := []struct {
+		name        string
+		body        []byte
+		bodyLimit   int64
+		wantInvalid bool
+		wantTooBig  bool
+	}{
+		{name: "valid", body: valid},
+		{name: "bundle over the cap", body: archive(entry{planArchiveManifestName, string(manifest)}, entry{planArchiveBundleName, strings.Repeat("x", 65)}), wantInvalid: true},
+		{name: "bundle at the cap", body: archive(entry{planArchiveManifestName, string(manifest)}, entry{planArchiveBundleName, strings.Repeat("x", 64)})},
+		{name: "manifest over the cap", body: archive(entry{planArchiveManifestName, string(manifest) + strings.Repeat(" ", 1024)}), wantInvalid: true},
+		{name: "missing manifest", body: archive(entry{planArchiveBundleName, "bundle"}), wantInvalid: true},
+		{name: "not gzip", body: []byte("plain text"), wantInvalid: true},
+		{name: "request body over the cap", body: valid, bodyLimit: int64(len(valid) / 2), wantTooBig: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r io.Reader = bytes.NewReader(tt.body)
+			if tt.bodyLimit > 0 {
+				r = http.MaxBytesReader(httptest.NewRecorder(), io.NopCloser(r), tt.bodyLimit)
+			}
+
+			tempDir := t.TempDir()
+			got, bundlePath, err := readPlanArchive(r, tempDir)
+
+			if tt.wantInvalid || tt.wantTooBig {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if tt.wantInvalid && !errors.Is(err, ErrInvalidPlanArchive) {
+					t.Errorf("expected ErrInvalidPlanArchive, got %v", err)
+				}
+				var maxBytesErr *http.MaxBytesError
+				if tt.wantTooBig && !errors.As(err, &maxBytesErr) {
+					t.Errorf("expected a MaxBytesError, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("readPlanArchive: %v", err)
+			}
+			if got.Name != "imported" {
+				t.Errorf("manifest name = %q", got.Name)
+			}
+			if _, err := os.Stat(bundlePath); err != nil {
+				t.Errorf("bundle wasn't written: %v", err)
+			}
+		})
+	}
+}
@@ -246,6 +246,13 @@ func SyncPlanTokens(orgId, planId, branch string) error {
 		return fmt.Errorf("error updating plan tokens: %v", err)
 	}
 
+	// the convo may have been rewound or merged, so the search index is re-synced along with the token counts
+	err = SyncConvoSearchIndex(planId, branch, convos)
+
+	if err != nil {
+		return fmt.Errorf("error syncing convo search index: %v", err)
+	}
+
 	return nil
 }
 
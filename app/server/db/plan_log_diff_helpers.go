@@ -0,0 +1,219 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+var ErrPlanCommitNotFound = errors.New("commit not found")
+
+// planSnapshot is the plan's context, convo, and pending files as of a single commit
+type planSnapshot struct {
+	contextsById   map[string]*Context
+	contextsByPath map[string]*Context
+	convoById      map[string]*ConvoMessage
+	files          *shared.CurrentPlanFiles
+}
+
+// GetPlanLogDiff compares the plan's pending files, context, and convo between two commits on branch. If toRef is empty, the branch's latest commit is used. It must be called from a read repo operation on branch.
+func GetPlanLogDiff(repo *GitRepo, branch, fromRef, toRef string, plain bool) (*shared.PlanLogDiffResponse, error) {
+	if toRef == "" {
+		toRef = branch
+	}
+
+	fromSha, err := repo.GitResolveCommit(branch, fromRef)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPlanCommitNotFound, err)
+	}
+
+	toSha, err := repo.GitResolveCommit(branch, toRef)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPlanCommitNotFound, err)
+	}
+
+	from, err := getPlanSnapshot(repo, fromSha)
+	if err != nil {
+		return nil, fmt.Errorf("error reading plan at %s: %v", fromRef, err)
+	}
+
+	to, err := getPlanSnapshot(repo, toSha)
+	if err != nil {
+		return nil, fmt.Errorf("error reading plan at %s: %v", toRef, err)
+	}
+
+	res := &shared.PlanLogDiffResponse{
+		FromSha: fromSha,
+		ToSha:   toSha,
+	}
+
+	for id, context := range to.contextsById {
+		prev, ok := from.contextsById[id]
+		if !ok {
+			res.ContextChanges = append(res.ContextChanges, newPlanLogContextChange(shared.PlanLogContextAdded, context))
+		} else if prev.Sha != context.Sha || prev.Body != context.Body || prev.NumTokens != context.NumTokens {
+			res.ContextChanges = append(res.ContextChanges, newPlanLogContextChange(shared.PlanLogContextUpdated, context))
+		}
+	}
+	for id, context := range from.contextsById {
+		if _, ok := to.contextsById[id]; !ok {
+			res.ContextChanges = append(res.ContextChanges, newPlanLogContextChange(shared.PlanLogContextRemoved, context))
+		}
+	}
+	sort.Slice(res.ContextChanges, func(i, j int) bool {
+		return res.ContextChanges[i].Context.CreatedAt.Before(res.ContextChanges[j].Context.CreatedAt)
+	})
+
+	for id, msg := range to.convoById {
+		if _, ok := from.convoById[id]; !ok {
+			res.AddedConvoMessages = append(res.AddedConvoMessages, msg.ToApi())
+		}
+	}
+	for id, msg := range from.convoById {
+		if _, ok := to.convoById[id]; !ok {
+			res.RemovedConvoMessages = append(res.RemovedConvoMessages, msg.ToApi())
+		}
+	}
+	for _, msgs := range [][]*shared.ConvoMessage{res.AddedConvoMessages, res.RemovedConvoMessages} {
+		sort.Slice(msgs, func(i, j int) bool {
+			return msgs[i].Num < msgs[j].Num
+		})
+	}
+
+	// only paths with pending changes at either point are compared. A path that isn't pending falls back to its context, so applied or rejected changes diff against what's in context.
+	paths := map[string]bool{}
+	for _, snapshot := range []*planSnapshot{from, to} {
+		for path := range snapshot.files.Files {
+			paths[path] = true
+		}
+		for path := range snapshot.files.Removed {
+			paths[path] = true
+		}
+	}
+
+	before := map[string]string{}
+	after := map[string]string{}
+	for path := range paths {
+		if content, ok := from.fileContent(path); ok {
+			before[path] = content
+		}
+		if content, ok := to.fileContent(path); ok {
+			after[path] = content
+		}
+	}
+
+	res.Diffs, err = getFilesDiff(repo.orgId, before, after, plain)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func newPlanLogContextChange(change shared.PlanLogContextChangeType, context *Context) *shared.PlanLogContextChange {
+	return &shared.PlanLogContextChange{
+		Change:  change,
+		Context: context.ToMeta().ToApi(),
+	}
+}
+
+func (s *planSnapshot) fileContent(path string) (string, bool) {
+	if s.files.Removed[path] {
+		return "", false
+	}
+	if content, ok := s.files.Files[path]; ok {
+		return content, true
+	}
+	if context, ok := s.contextsByPath[path]; ok {
+		return context.Body, true
+	}
+	return "", false
+}
+
+func getPlanSnapshot(repo *GitRepo, ref string) (*planSnapshot, error) {
+	contextFiles, err := repo.GitReadDir(ref, "context")
+	if err != nil {
+		return nil, err
+	}
+
+	convoFiles, err := repo.GitReadDir(ref, "conversation")
+	if err != nil {
+		return nil, err
+	}
+
+	resultFiles, err := repo.GitReadDir(ref, "results")
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &planSnapshot{
+		contextsById:   map[string]*Context{},
+		contextsByPath: map[string]*Context{},
+		convoById:      map[string]*ConvoMessage{},
+	}
+
+	apiContextsByPath := map[string]*shared.Context{}
+	for p, content := range contextFiles {
+		if !strings.HasSuffix(p, ".meta") {
+			continue
+		}
+
+		var context Context
+		err := json.Unmarshal(content, &context)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling context %s: %v", p, err)
+		}
+		context.Body = string(contextFiles[strings.TrimSuffix(p, ".meta")+".body"])
+
+		snapshot.contextsById[context.Id] = &context
+		if context.FilePath != "" {
+			snapshot.contextsByPath[context.FilePath] = &context
+			apiContextsByPath[context.FilePath] = context.ToApi()
+		}
+	}
+
+	for p, content := range convoFiles {
+		var msg ConvoMessage
+		err := json.Unmarshal(content, &msg)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling convo message %s: %v", p, err)
+		}
+		snapshot.convoById[msg.Id] = &msg
+	}
+
+	var results []*PlanFileResult
+	for p, content := range resultFiles {
+		var result PlanFileResult
+		err := json.Unmarshal(content, &result)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling result %s: %v", p, err)
+		}
+		results = append(results, &result)
+	}
+
+	// results for a path are replayed in order, same as GetPlanFileResults
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].CreatedAt.Before(results[j].CreatedAt)
+	})
+
+	var apiResults []*shared.PlanFileResult
+	for _, result := range results {
+		apiResults = append(apiResults, result.ToApi())
+	}
+
+	planState := &shared.CurrentPlanState{
+		PlanResult:     GetPlanResult(apiResults),
+		ContextsByPath: apiContextsByPath,
+	}
+
+	snapshot.files, err = planState.GetFiles()
+	if err != nil {
+		return nil, fmt.Errorf("error getting plan files: %v", err)
+	}
+
+	return snapshot, nil
+}
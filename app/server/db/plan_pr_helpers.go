@@ -0,0 +1,200 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+var ErrNoPlanApplies = errors.New("plan has no applied changes")
+
+const prOtherChangesTitle = "Other changes"
+
+var prBranchNameInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+type prCommitGroup struct {
+	subtask *Subtask
+	title   string
+	paths   []string
+}
+
+// GetPlanPR builds a PR branch name, description, and per-subtask commits from the plan's latest apply. Each applied file is grouped under the subtask that last changed it. It must be called from a read repo operation on the plan.
+func GetPlanPR(orgId string, plan *Plan) (*shared.PlanPRResponse, error) {
+	applies, err := GetPlanApplies(orgId, plan.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(applies) == 0 {
+		return nil, ErrNoPlanApplies
+	}
+
+	sort.Slice(applies, func(i, j int) bool {
+		return applies[i].CreatedAt.Before(applies[j].CreatedAt)
+	})
+	apply := applies[len(applies)-1]
+
+	results, err := GetPlanFileResults(orgId, plan.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	convo, err := GetPlanConvo(orgId, plan.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	resultIds := map[string]bool{}
+	for _, id := range apply.PlanFileResultIds {
+		resultIds[id] = true
+	}
+
+	var applied []*PlanFileResult
+	for _, result := range results {
+		if resultIds[result.Id] {
+			applied = append(applied, result)
+		}
+	}
+
+	sort.Slice(applied, func(i, j int) bool {
+		return applied[i].CreatedAt.Before(applied[j].CreatedAt)
+	})
+
+	convoById := map[string]*ConvoMessage{}
+	var convoIds []string
+	for _, msg := range convo {
+		convoById[msg.Id] = msg
+		convoIds = append(convoIds, msg.Id)
+	}
+
+	// later results win, so a path touched by multiple subtasks goes in the last one's commit
+	groupByPath := map[string]string{}
+	groups := map[string]*prCommitGroup{}
+	var groupOrder []string
+	for _, result := range applied {
+		title := prOtherChangesTitle
+		var subtask *Subtask
+		if msg, ok := convoById[result.ConvoMessageId]; ok && msg.Subtask != nil && msg.Subtask.Title != "" {
+			subtask = msg.Subtask
+			title = subtask.Title
+		}
+
+		if _, ok := groups[title]; !ok {
+			groups[title] = &prCommitGroup{subtask: subtask, title: title}
+			groupOrder = append(groupOrder, title)
+		}
+		groupByPath[result.Path] = title
+	}
+
+	for path, title := range groupByPath {
+		groups[title].paths = append(groups[title].paths, path)
+	}
+
+	// changes that don't belong to a subtask are committed last
+	sort.SliceStable(groupOrder, func(i, j int) bool {
+		return groupOrder[j] == prOtherChangesTitle && groupOrder[i] != prOtherChangesTitle
+	})
+
+	var ordered []*prCommitGroup
+	for _, title := range groupOrder {
+		group := groups[title]
+		if len(group.paths) == 0 {
+			continue
+		}
+		sort.Strings(group.paths)
+		ordered = append(ordered, group)
+	}
+
+	var summary string
+	if len(convoIds) > 0 {
+		summaries, err := GetPlanSummaries(plan.Id, convoIds)
+		if err != nil {
+			return nil, err
+		}
+		if len(summaries) > 0 {
+			summary = summaries[len(summaries)-1].Summary
+		}
+	}
+
+	var prompt string
+	for _, msg := range convo {
+		if msg.Role == "user" {
+			prompt = msg.Message
+			break
+		}
+	}
+
+	title := plan.Name
+	if apply.CommitMsg != "" {
+		title = strings.TrimSpace(strings.SplitN(apply.CommitMsg, "\n", 2)[0])
+	}
+
+	res := &shared.PlanPRResponse{
+		ApplyId:        apply.Id,
+		BranchName:     getPRBranchName(plan.Name),
+		Title:          title,
+		Description:    getPRDescription(plan.Name, prompt, summary, ordered),
+		ApplyCommitMsg: apply.CommitMsg,
+	}
+
+	for _, group := range ordered {
+		msg := group.title
+		if group.subtask != nil && group.subtask.Description != "" {
+			msg += "\n\n" + strings.TrimSpace(group.subtask.Description)
+		}
+		res.Commits = append(res.Commits, &shared.PlanPRCommit{
+			Message: msg,
+			Paths:   group.paths,
+		})
+	}
+
+	return res, nil
+}
+
+func getPRBranchName(planName string) string {
+	slug := strings.Trim(prBranchNameInvalidChars.ReplaceAllString(strings.ToLower(planName), "-"), "-")
+	if slug == "" {
+		slug = "plan"
+	}
+	if len(slug) > 50 {
+		slug = strings.TrimRight(slug[:50], "-")
+	}
+	return "plandex/" + slug
+}
+
+func getPRDescription(planName, prompt, summary string, groups []*prCommitGroup) string {
+	var b strings.Builder
+
+	if prompt != "" {
+		b.WriteString("## Task\n\n")
+		for _, line := range strings.Split(strings.TrimSpace(prompt), "\n") {
+			b.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if summary != "" {
+		b.WriteString("## Summary\n\n")
+		b.WriteString(strings.TrimSpace(summary) + "\n\n")
+	}
+
+	b.WriteString("## Changes\n\n")
+	for _, group := range groups {
+		b.WriteString(fmt.Sprintf("### %s\n\n", group.title))
+		if group.subtask != nil && group.subtask.Description != "" {
+			b.WriteString(strings.TrimSpace(group.subtask.Description) + "\n\n")
+		}
+		for _, path := range group.paths {
+			b.WriteString(fmt.Sprintf("- `%s`\n", path))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(fmt.Sprintf("---\nGenerated from Plandex plan `%s`\n", planName))
+
+	return b.String()
+}
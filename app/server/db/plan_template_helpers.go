@@ -0,0 +1,53 @@
+package db
+
+import (
+	"fmt"
+
+	shared "plandex-shared"
+)
+
+func ListPlanTemplates(orgId string) ([]*shared.PlanTemplate, error) {
+	var templates []*shared.PlanTemplate
+	err := Conn.Select(&templates, "SELECT template FROM plan_templates WHERE org_id = $1 ORDER BY name", orgId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing plan templates: %v", err)
+	}
+
+	return templates, nil
+}
+
+// UpsertPlanTemplate creates the template, or replaces the org's existing template with the same name
+func UpsertPlanTemplate(orgId, userId string, template *shared.PlanTemplate) error {
+	query := `
+	INSERT INTO plan_templates (org_id, name, template, updated_by)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (org_id, name) DO UPDATE SET
+		template = EXCLUDED.template,
+		updated_by = EXCLUDED.updated_by
+	`
+
+	_, err := Conn.Exec(query, orgId, template.Name, template, userId)
+
+	if err != nil {
+		return fmt.Errorf("error upserting plan template: %v", err)
+	}
+
+	return nil
+}
+
+// DeletePlanTemplate returns false if the org has no template with the name
+func DeletePlanTemplate(orgId, name string) (bool, error) {
+	res, err := Conn.Exec("DELETE FROM plan_templates WHERE org_id = $1 AND name = $2", orgId, name)
+
+	if err != nil {
+		return false, fmt.Errorf("error deleting plan template: %v", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error getting rows affected: %v", err)
+	}
+
+	return rowsAffected > 0, nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"plandex-server/telemetry"
 	"runtime/debug"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -175,6 +177,7 @@ func (q *repoQueue) runQueue() {
 					firstOp.planId, firstOp.branch, firstOp.scope)
 			}
 
+			lockStartedAt := time.Now()
 			lockId, err := lockRepoDB(LockRepoParams{
 				OrgId:       firstOp.orgId,
 				UserId:      firstOp.userId,
@@ -186,6 +189,7 @@ func (q *repoQueue) runQueue() {
 				Ctx:         firstOp.ctx,
 				CancelFn:    firstOp.cancelFn,
 			}, 0)
+			telemetry.ObserveRepoLockWait(string(firstOp.scope), err, lockStartedAt)
 
 			if lockId != "" {
 				log.Printf("[Queue] Acquired DB lock %s", lockId)
@@ -1,8 +1,14 @@
 package db
 
 import (
+	"database/sql"
 	"fmt"
 	"log"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 var orgOwnerRoleId string
@@ -74,7 +80,7 @@ func CacheOrgRoleIds() error {
 
 func cacheOrgOwnerRoleId() error {
 	var roleId string
-	err := Conn.Get(&roleId, "SELECT id FROM org_roles WHERE name = 'owner'")
+	err := Conn.Get(&roleId, "SELECT id FROM org_roles WHERE org_id IS NULL AND name = 'owner'")
 
 	if err != nil {
 		return fmt.Errorf("error getting owner role id: %v", err)
@@ -87,7 +93,7 @@ func cacheOrgOwnerRoleId() error {
 
 func cacheOrgMemberRoleId() error {
 	var roleId string
-	err := Conn.Get(&roleId, "SELECT id FROM org_roles WHERE name = 'member'")
+	err := Conn.Get(&roleId, "SELECT id FROM org_roles WHERE org_id IS NULL AND name = 'member'")
 
 	if err != nil {
 		return fmt.Errorf("error getting member role id: %v", err)
@@ -97,3 +103,159 @@ func cacheOrgMemberRoleId() error {
 
 	return nil
 }
+
+func GetOrgRole(orgId, roleId string) (*OrgRole, error) {
+	var role OrgRole
+	err := Conn.Get(&role, "SELECT * FROM org_roles WHERE id = $1 AND (org_id IS NULL OR org_id = $2)", roleId, orgId)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting org role: %v", err)
+	}
+
+	return &role, nil
+}
+
+// GetOrgRolePermissions returns the permissions for each role that aren't scoped to a resource, keyed by role id
+func GetOrgRolePermissions(roleIds []string) (map[string][]shared.Permission, error) {
+	var rows []struct {
+		OrgRoleId string `db:"org_role_id"`
+		Name      string `db:"name"`
+	}
+
+	query := `
+	SELECT orp.org_role_id, p.name
+	FROM org_roles_permissions orp
+	JOIN permissions p ON p.id = orp.permission_id
+	WHERE orp.org_role_id = ANY($1) AND p.resource_id IS NULL
+	ORDER BY p.name
+	`
+
+	err := Conn.Select(&rows, query, pq.Array(roleIds))
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting org role permissions: %v", err)
+	}
+
+	res := make(map[string][]shared.Permission)
+	for _, row := range rows {
+		res[row.OrgRoleId] = append(res[row.OrgRoleId], shared.Permission(row.Name))
+	}
+
+	return res, nil
+}
+
+func CreateOrgRole(orgId string, req *shared.CreateOrgRoleRequest, tx *sqlx.Tx) (*OrgRole, error) {
+	role := OrgRole{
+		OrgId:              &orgId,
+		Name:               req.Name,
+		Label:              req.Label,
+		Description:        req.Description,
+		ApprovedModelPacks: req.ApprovedModelPacks,
+	}
+
+	err := tx.QueryRow(
+		"INSERT INTO org_roles (org_id, name, label, description, approved_model_packs) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at",
+		orgId, role.Name, role.Label, role.Description, role.ApprovedModelPacks,
+	).Scan(&role.Id, &role.CreatedAt, &role.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error creating org role: %v", err)
+	}
+
+	err = setOrgRolePermissions(role.Id, req.Permissions, tx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+func UpdateOrgRole(orgId, roleId string, req *shared.UpdateOrgRoleRequest, tx *sqlx.Tx) error {
+	_, err := tx.Exec(
+		"UPDATE org_roles SET label = $1, description = $2, approved_model_packs = $3 WHERE id = $4 AND org_id = $5",
+		req.Label, req.Description, ApprovedModelPacks(req.ApprovedModelPacks), roleId, orgId,
+	)
+
+	if err != nil {
+		return fmt.Errorf("error updating org role: %v", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM org_roles_permissions WHERE org_role_id = $1", roleId)
+
+	if err != nil {
+		return fmt.Errorf("error clearing org role permissions: %v", err)
+	}
+
+	return setOrgRolePermissions(roleId, req.Permissions, tx)
+}
+
+func DeleteOrgRole(orgId, roleId string, tx *sqlx.Tx) error {
+	memberRoleId, err := GetOrgMemberRoleId()
+
+	if err != nil {
+		return err
+	}
+
+	// invites reference their role with ON DELETE RESTRICT, so accepted invites are moved to the member role before the custom role is removed
+	_, err = tx.Exec("UPDATE invites SET org_role_id = $1 WHERE org_id = $2 AND org_role_id = $3 AND accepted_at IS NOT NULL", memberRoleId, orgId, roleId)
+
+	if err != nil {
+		return fmt.Errorf("error updating accepted invites for org role: %v", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM org_roles WHERE id = $1 AND org_id = $2", roleId, orgId)
+
+	if err != nil {
+		return fmt.Errorf("error deleting org role: %v", err)
+	}
+
+	return nil
+}
+
+func NumInvitesWithRole(orgId, roleId string) (int, error) {
+	var count int
+	err := Conn.Get(&count, "SELECT COUNT(*) FROM invites WHERE org_id = $1 AND org_role_id = $2 AND accepted_at IS NULL", orgId, roleId)
+
+	if err != nil {
+		return 0, fmt.Errorf("error counting invites with role: %v", err)
+	}
+
+	return count, nil
+}
+
+func SetOrgUserRole(orgId, userId, roleId string) error {
+	_, err := Conn.Exec("UPDATE orgs_users SET org_role_id = $1 WHERE org_id = $2 AND user_id = $3", roleId, orgId, userId)
+
+	if err != nil {
+		return fmt.Errorf("error setting org user role: %v", err)
+	}
+
+	return nil
+}
+
+func setOrgRolePermissions(roleId string, permissions []shared.Permission, tx *sqlx.Tx) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(permissions))
+	for i, p := range permissions {
+		names[i] = string(p)
+	}
+
+	_, err := tx.Exec(`
+	INSERT INTO org_roles_permissions (org_role_id, permission_id)
+	SELECT $1, p.id FROM permissions p
+	WHERE p.name = ANY($2) AND p.resource_id IS NULL
+	`, roleId, pq.Array(names))
+
+	if err != nil {
+		return fmt.Errorf("error setting org role permissions: %v", err)
+	}
+
+	return nil
+}
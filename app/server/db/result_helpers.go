@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -988,39 +989,104 @@ func RejectPlanFile(orgId, planId, filePathOrResultId string, now time.Time) err
 	return nil
 }
 
-func RejectReplacement(orgId, planId, resultId, replacementId string) error {
-	resultsDir := getPlanResultsDir(orgId, planId)
-
-	bytes, err := os.ReadFile(filepath.Join(resultsDir, resultId+".json"))
+var ErrReviewedChangesDontApply = errors.New("reviewed changes don't apply")
 
+// ReviewReplacements rejects and edits individual replacements (diff hunks) of pending results.
+// The updated results are only stored if every pending file can still be built from them.
+func ReviewReplacements(orgId, planId string, req shared.ReviewReplacementsRequest, now time.Time) error {
+	params, err := GetFullCurrentPlanStateParams(orgId, planId)
 	if err != nil {
-		return fmt.Errorf("error reading result file: %v", err)
+		return fmt.Errorf("error getting current plan state params: %v", err)
 	}
 
-	var result PlanFileResult
-	err = json.Unmarshal(bytes, &result)
+	resultsById := make(map[string]*PlanFileResult, len(params.PlanFileResults))
+	for _, result := range params.PlanFileResults {
+		resultsById[result.Id] = result
+	}
 
-	if err != nil {
-		return fmt.Errorf("error unmarshalling result file: %v", err)
+	touched := map[string]*PlanFileResult{}
+
+	getReplacement := func(resultId, replacementId string) (*shared.Replacement, error) {
+		result := resultsById[resultId]
+		if result == nil {
+			return nil, fmt.Errorf("result not found: %s", resultId)
+		}
+
+		if result.AppliedAt != nil || result.RejectedAt != nil {
+			return nil, fmt.Errorf("result %s is no longer pending", resultId)
+		}
+
+		for _, replacement := range result.Replacements {
+			if replacement.Id == replacementId {
+				if !replacement.IsPending() {
+					return nil, fmt.Errorf("replacement %s is no longer pending", replacementId)
+				}
+				touched[resultId] = result
+				return replacement, nil
+			}
+		}
+
+		return nil, fmt.Errorf("replacement not found: %s", replacementId)
 	}
 
-	if result.RejectedAt != nil {
-		return nil
+	for _, edit := range req.Edited {
+		replacement, err := getReplacement(edit.ResultId, edit.ReplacementId)
+		if err != nil {
+			return err
+		}
+		replacement.New = edit.New
 	}
 
-	now := time.Now()
+	for _, ref := range req.Rejected {
+		replacement, err := getReplacement(ref.ResultId, ref.ReplacementId)
+		if err != nil {
+			return err
+		}
+		replacement.SetRejected(now)
+	}
+
+	for _, resultId := range req.RejectedResults {
+		result := resultsById[resultId]
+		if result == nil {
+			return fmt.Errorf("result not found: %s", resultId)
+		}
+		if result.AppliedAt != nil || result.RejectedAt != nil {
+			return fmt.Errorf("result %s is no longer pending", resultId)
+		}
+		result.RejectedAt = &now
+		touched[resultId] = result
+	}
+
+	for _, result := range touched {
+		if result.RejectedAt != nil || result.Content != "" || result.RemovedFile {
+			continue
+		}
 
-	foundReplacement := false
-	for _, replacement := range result.Replacements {
-		if replacement.Id == replacementId {
-			replacement.RejectedAt = &now
-			foundReplacement = true
-			break
+		anyRemaining := false
+		for _, replacement := range result.Replacements {
+			if replacement.IsPending() {
+				anyRemaining = true
+				break
+			}
+		}
+
+		// rejecting every hunk rejects the whole result
+		if !anyRemaining {
+			result.RejectedAt = &now
 		}
 	}
 
-	if !foundReplacement {
-		return fmt.Errorf("replacement not found: %s", replacementId)
+	// replacements are shared with the api results, so this rebuilds every pending file with the review applied
+	_, err = GetCurrentPlanState(params)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrReviewedChangesDontApply, err)
+	}
+
+	for _, result := range touched {
+		err = StorePlanResult(result)
+		if err != nil {
+			return fmt.Errorf("error storing plan result: %v", err)
+		}
 	}
 
 	return nil
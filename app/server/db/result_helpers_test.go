@@ -0,0 +1,216 @@
+package db
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+)
+
+func TestReviewReplacements(t *testing.T) {
+	const orgId = "org"
+	const planId = "plan"
+
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// a.go has two hunks in one result, and a later result that depends on the first hunk. b.go is a whole-file write.
+	setup := func(t *testing.T) {
+		t.Helper()
+
+		origBaseDir := BaseDir
+		BaseDir = t.TempDir()
+		t.Cleanup(func() { BaseDir = origBaseDir })
+
+		err := StoreContext(&Context{
+			OrgId:       orgId,
+			PlanId:      planId,
+			ContextType: shared.ContextFileType,
+			FilePath:    "a.go",
+			Body:        "one\ntwo\nthree\nfour\n",
+		}, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		results := []*PlanFileResult{
+			{
+				Id:     "r1",
+				OrgId:  orgId,
+				PlanId: planId,
+				Path:   "a.go",
+				Replacements: []*shared.Replacement{
+					{Id: "h1", Old: "two", New: "TWO"},
+					{Id: "h2", Old: "four", New: "FOUR"},
+				},
+				CreatedAt: created,
+			},
+			{
+				Id:     "r2",
+				OrgId:  orgId,
+				PlanId: planId,
+				Path:   "a.go",
+				Replacements: []*shared.Replacement{
+					{Id: "h3", Old: "TWO", New: "2"},
+				},
+				CreatedAt: created.Add(time.Second),
+			},
+			{
+				Id:        "r3",
+				OrgId:     orgId,
+				PlanId:    planId,
+				Path:      "b.go",
+				Content:   "package b\n",
+				CreatedAt: created.Add(2 * time.Second),
+			},
+		}
+		for _, result := range results {
+			if err := StorePlanResult(result); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	ref := func(resultId, replacementId string) shared.ReplacementRef {
+		return shared.ReplacementRef{ResultId: resultId, ReplacementId: replacementId}
+	}
+
+	tests := []struct {
+		name         string
+		req          shared.ReviewReplacementsRequest
+		wantErr      string
+		wantConflict bool
+		wantFiles    map[string]string
+		wantRejected []string
+	}{
+		{
+			name: "partial accept",
+			req:  shared.ReviewReplacementsRequest{Rejected: []shared.ReplacementRef{ref("r1", "h2")}},
+			wantFiles: map[string]string{
+				"a.go": "one\n2\nthree\nfour\n",
+				"b.go": "package b\n",
+			},
+		},
+		{
+			name: "edited hunk",
+			req: shared.ReviewReplacementsRequest{Edited: []shared.ReplacementEdit{
+				{ResultId: "r1", ReplacementId: "h2", New: "four!"},
+			}},
+			wantFiles: map[string]string{
+				"a.go": "one\n2\nthree\nfour!\n",
+				"b.go": "package b\n",
+			},
+		},
+		{
+			name: "rejecting every hunk rejects the result",
+			req: shared.ReviewReplacementsRequest{Rejected: []shared.ReplacementRef{
+				ref("r1", "h1"), ref("r1", "h2"), ref("r2", "h3"),
+			}},
+			wantFiles: map[string]string{
+				"b.go": "package b\n",
+			},
+			wantRejected: []string{"r1", "r2"},
+		},
+		{
+			name: "whole-file result rejected",
+			req:  shared.ReviewReplacementsRequest{RejectedResults: []string{"r3"}},
+			wantFiles: map[string]string{
+				"a.go": "one\n2\nthree\nFOUR\n",
+			},
+			wantRejected: []string{"r3"},
+		},
+		{
+			name:         "rejecting a hunk a later change depends on",
+			req:          shared.ReviewReplacementsRequest{Rejected: []shared.ReplacementRef{ref("r1", "h1")}},
+			wantConflict: true,
+		},
+		{
+			name:    "stale replacement id",
+			req:     shared.ReviewReplacementsRequest{Rejected: []shared.ReplacementRef{ref("r1", "h9")}},
+			wantErr: "replacement not found",
+		},
+		{
+			name: "replacement that is already rejected",
+			req: shared.ReviewReplacementsRequest{
+				Rejected: []shared.ReplacementRef{ref("r1", "h2"), ref("r1", "h2")},
+			},
+			wantErr: "no longer pending",
+		},
+		{
+			name:    "unknown result",
+			req:     shared.ReviewReplacementsRequest{RejectedResults: []string{"r9"}},
+			wantErr: "result not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setup(t)
+
+			err := ReviewReplacements(orgId, planId, tt.req, created.Add(time.Hour))
+
+			if tt.wantErr != "" || tt.wantConflict {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if tt.wantConflict && !errors.Is(err, ErrReviewedChangesDontApply) {
+					t.Errorf("expected ErrReviewedChangesDontApply, got %v", err)
+				}
+				if tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+
+				// a failed review leaves the stored results untouched
+				results, err := GetPlanFileResults(orgId, planId)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, result := range results {
+					if result.RejectedAt != nil {
+						t.Errorf("result %s was rejected", result.Id)
+					}
+					for _, replacement := range result.Replacements {
+						if replacement.RejectedAt != nil {
+							t.Errorf("replacement %s was rejected", replacement.Id)
+						}
+					}
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ReviewReplacements: %v", err)
+			}
+
+			// reload from disk so only what was stored is checked
+			state, err := GetCurrentPlanState(CurrentPlanStateParams{OrgId: orgId, PlanId: planId})
+			if err != nil {
+				t.Fatalf("GetCurrentPlanState: %v", err)
+			}
+
+			if len(state.CurrentPlanFiles.Files) != len(tt.wantFiles) {
+				t.Errorf("expected %d files, got %d: %v", len(tt.wantFiles), len(state.CurrentPlanFiles.Files), state.CurrentPlanFiles.Files)
+			}
+			for path, want := range tt.wantFiles {
+				if got := state.CurrentPlanFiles.Files[path]; got != want {
+					t.Errorf("%s = %q, want %q", path, got, want)
+				}
+			}
+
+			rejected := map[string]bool{}
+			for _, id := range tt.wantRejected {
+				rejected[id] = true
+			}
+			results, err := GetPlanFileResults(orgId, planId)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, result := range results {
+				if (result.RejectedAt != nil) != rejected[result.Id] {
+					t.Errorf("result %s rejected = %v, want %v", result.Id, result.RejectedAt != nil, rejected[result.Id])
+				}
+			}
+		})
+	}
+}
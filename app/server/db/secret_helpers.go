@@ -0,0 +1,195 @@
+package db
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Org secrets use envelope encryption. Each secret gets its own random data key, which encrypts the value.
+// The data key is then encrypted with the master key from PLANDEX_SECRETS_MASTER_KEY (32 bytes, base64-encoded).
+// Rotating the master key only requires re-encrypting the data keys, and a leaked data key only exposes a single secret.
+
+const SecretsMasterKeyEnvVar = "PLANDEX_SECRETS_MASTER_KEY"
+
+var ErrSecretsVaultNotConfigured = errors.New("org secrets vault is not configured—set " + SecretsMasterKeyEnvVar + " on the server")
+
+func OrgSecretsVaultEnabled() bool {
+	return os.Getenv(SecretsMasterKeyEnvVar) != ""
+}
+
+func getSecretsMasterKey() ([]byte, error) {
+	encoded := os.Getenv(SecretsMasterKeyEnvVar)
+	if encoded == "" {
+		return nil, ErrSecretsVaultNotConfigured
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s: %v", SecretsMasterKeyEnvVar, err)
+	}
+
+	if len(key) != 32 {
+		return nil, fmt.Errorf("%s must be 32 bytes (base64-encoded), got %d bytes", SecretsMasterKeyEnvVar, len(key))
+	}
+
+	return key, nil
+}
+
+func SetOrgSecret(orgId, userId, name, value string) error {
+	masterKey, err := getSecretsMasterKey()
+	if err != nil {
+		return err
+	}
+
+	encryptedDataKey, encryptedValue, err := encryptSecret(masterKey, []byte(value))
+	if err != nil {
+		return fmt.Errorf("error encrypting org secret: %v", err)
+	}
+
+	_, err = Conn.Exec(`
+INSERT INTO org_secrets (org_id, name, encrypted_data_key, encrypted_value, created_by)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (org_id, name)
+DO UPDATE SET
+	encrypted_data_key = EXCLUDED.encrypted_data_key,
+	encrypted_value    = EXCLUDED.encrypted_value,
+	created_by         = EXCLUDED.created_by
+`, orgId, name, encryptedDataKey, encryptedValue, userId)
+
+	if err != nil {
+		return fmt.Errorf("error setting org secret: %v", err)
+	}
+
+	return nil
+}
+
+func ListOrgSecrets(orgId string) ([]*OrgSecret, error) {
+	var secrets []*OrgSecret
+	err := Conn.Select(&secrets, "SELECT * FROM org_secrets WHERE org_id = $1 ORDER BY name", orgId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing org secrets: %v", err)
+	}
+
+	return secrets, nil
+}
+
+func DeleteOrgSecret(orgId, name string) (bool, error) {
+	res, err := Conn.Exec("DELETE FROM org_secrets WHERE org_id = $1 AND name = $2", orgId, name)
+	if err != nil {
+		return false, fmt.Errorf("error deleting org secret: %v", err)
+	}
+
+	numDeleted, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error getting rows affected: %v", err)
+	}
+
+	return numDeleted > 0, nil
+}
+
+// GetOrgSecretValues decrypts all secrets for an org. If the vault isn't configured, it returns an empty map.
+func GetOrgSecretValues(orgId string) (map[string]string, error) {
+	res := map[string]string{}
+
+	if !OrgSecretsVaultEnabled() {
+		return res, nil
+	}
+
+	masterKey, err := getSecretsMasterKey()
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, err := ListOrgSecrets(orgId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, secret := range secrets {
+		value, err := decryptSecret(masterKey, secret.EncryptedDataKey, secret.EncryptedValue)
+		if err != nil {
+			return nil, fmt.Errorf("error decrypting org secret %s: %v", secret.Name, err)
+		}
+		res[secret.Name] = string(value)
+	}
+
+	return res, nil
+}
+
+func encryptSecret(masterKey, plaintext []byte) (encryptedDataKey, encryptedValue []byte, err error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, fmt.Errorf("error generating data key: %v", err)
+	}
+
+	encryptedValue, err = sealAESGCM(dataKey, plaintext)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error encrypting value: %v", err)
+	}
+
+	encryptedDataKey, err = sealAESGCM(masterKey, dataKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error encrypting data key: %v", err)
+	}
+
+	return encryptedDataKey, encryptedValue, nil
+}
+
+func decryptSecret(masterKey, encryptedDataKey, encryptedValue []byte) ([]byte, error) {
+	dataKey, err := openAESGCM(masterKey, encryptedDataKey)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting data key: %v", err)
+	}
+
+	plaintext, err := openAESGCM(dataKey, encryptedValue)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting value: %v", err)
+	}
+
+	return plaintext, nil
+}
+
+// sealed output is nonce || ciphertext
+func sealAESGCM(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func openAESGCM(key, sealed []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
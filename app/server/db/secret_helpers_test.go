@@ -0,0 +1,43 @@
+package db
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestEncryptDecryptSecret(t *testing.T) {
+	masterKey := make([]byte, 32)
+	if _, err := rand.Read(masterKey); err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext := []byte("sk-test-1234567890")
+
+	encryptedDataKey, encryptedValue, err := encryptSecret(masterKey, plaintext)
+	if err != nil {
+		t.Fatalf("encryptSecret: %v", err)
+	}
+
+	if bytes.Contains(encryptedValue, plaintext) {
+		t.Fatalf("encrypted value contains plaintext")
+	}
+
+	decrypted, err := decryptSecret(masterKey, encryptedDataKey, encryptedValue)
+	if err != nil {
+		t.Fatalf("decryptSecret: %v", err)
+	}
+
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Fatalf("expected %q, got %q", plaintext, decrypted)
+	}
+
+	wrongKey := make([]byte, 32)
+	if _, err := rand.Read(wrongKey); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := decryptSecret(wrongKey, encryptedDataKey, encryptedValue); err == nil {
+		t.Fatalf("expected error decrypting with wrong master key")
+	}
+}
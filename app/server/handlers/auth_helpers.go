@@ -10,6 +10,7 @@ import (
 	"plandex-server/db"
 	"plandex-server/hooks"
 	"plandex-server/types"
+	"slices"
 	"strings"
 	"time"
 
@@ -749,3 +750,173 @@ func authorizePlanArchive(w http.ResponseWriter, planId string, auth *types.Serv
 
 	return plan
 }
+
+func authorizePlanApply(w http.ResponseWriter, planId string, auth *types.ServerAuth) *db.Plan {
+	plan := authorizePlan(w, planId, auth)
+
+	if plan == nil {
+		return nil
+	}
+
+	if !auth.HasPermission(shared.PermissionApplyPlan) {
+		log.Println("User does not have permission to apply plan")
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusForbidden,
+			Msg:    "Your org role doesn't allow applying changes",
+		})
+		return nil
+	}
+
+	return plan
+}
+
+func authorizeExecCommands(w http.ResponseWriter, auth *types.ServerAuth) bool {
+	if !auth.HasPermission(shared.PermissionExecCommands) {
+		log.Println("User does not have permission to execute commands")
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusForbidden,
+			Msg:    "Your org role doesn't allow executing commands",
+		})
+		return false
+	}
+
+	return true
+}
+
+// authorizeExecConfig rejects plan config updates that enable command execution for users whose role doesn't allow it
+func authorizeExecConfig(w http.ResponseWriter, auth *types.ServerAuth, config *shared.PlanConfig) bool {
+	if config == nil || !(config.CanExec || config.AutoExec) {
+		return true
+	}
+
+	return authorizeExecCommands(w, auth)
+}
+
+// maskExecConfig turns off command execution in a plan config returned to users whose role doesn't allow it, so the CLI doesn't offer it
+func maskExecConfig(auth *types.ServerAuth, config *shared.PlanConfig) {
+	if config == nil || auth.HasPermission(shared.PermissionExecCommands) {
+		return
+	}
+
+	config.CanExec = false
+	config.AutoExec = false
+}
+
+func authorizeModelPack(w http.ResponseWriter, auth *types.ServerAuth, req shared.UpdateSettingsRequest) bool {
+	if auth.HasPermission(shared.PermissionSelectAnyModelPack) {
+		return true
+	}
+
+	deny := func(msg string) bool {
+		log.Println(msg)
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusForbidden,
+			Msg:    msg,
+		})
+		return false
+	}
+
+	if !auth.HasPermission(shared.PermissionSelectApprovedModelPack) {
+		return deny("Your org role doesn't allow changing the model pack")
+	}
+
+	// inline model packs can be defined arbitrarily, so only named packs can be checked against the approved list
+	if req.ModelPackName == "" {
+		return deny("Your org role only allows selecting an approved model pack by name")
+	}
+
+	approved, ok := getApprovedModelPacks(w, auth)
+	if !ok {
+		return false
+	}
+
+	if slices.Contains(approved, req.ModelPackName) {
+		return true
+	}
+
+	return deny(fmt.Sprintf("Model pack '%s' isn't approved for your org role", req.ModelPackName))
+}
+
+// authorizeResolvedModelPack re-checks a plan's model pack when it's resolved for a model request, since the plan may have been configured by someone with broader permissions or before the user's role or the org policy changed
+func authorizeResolvedModelPack(w http.ResponseWriter, auth *types.ServerAuth, policy *shared.OrgPolicy, settings *shared.PlanSettings) bool {
+	err := policy.CheckModelPack(settings)
+	if err != nil {
+		writeOrgPolicyError(w, err)
+		return false
+	}
+
+	if auth.HasPermission(shared.PermissionSelectAnyModelPack) {
+		return true
+	}
+
+	approved, ok := getApprovedModelPacks(w, auth)
+	if !ok {
+		return false
+	}
+
+	// roles without approved packs don't restrict which pack a plan uses, only whether it can be changed
+	if len(approved) == 0 {
+		return true
+	}
+
+	// an inline pack could reuse an approved pack's name, so only packs chosen by name can match
+	name := "custom"
+	if settings.ModelPack == nil {
+		name = settings.GetModelPack().Name
+		if slices.Contains(approved, name) {
+			return true
+		}
+	}
+
+	msg := fmt.Sprintf("This plan uses the '%s' model pack, which isn't approved for your org role. Approved model packs: %s", name, strings.Join(approved, ", "))
+	log.Println(msg)
+	writeApiError(w, shared.ApiError{
+		Type:   shared.ApiErrorTypeOther,
+		Status: http.StatusForbidden,
+		Msg:    msg,
+	})
+	return false
+}
+
+func getApprovedModelPacks(w http.ResponseWriter, auth *types.ServerAuth) ([]string, bool) {
+	orgUser, err := db.GetOrgUser(auth.User.Id, auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting org user: %v\n", err)
+		http.Error(w, "Error getting org user: "+err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	role, err := db.GetOrgRole(auth.OrgId, orgUser.OrgRoleId)
+	if err != nil {
+		log.Printf("Error getting org role: %v\n", err)
+		http.Error(w, "Error getting org role: "+err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	if role == nil {
+		return nil, true
+	}
+
+	return role.ApprovedModelPacks, true
+}
+
+// canActOnOrgRole checks a role-scoped user management permission (invite_user, remove_user, set_user_role) for the given role. Custom roles don't have role-scoped permissions, so users who can manage org roles can act on them.
+func canActOnOrgRole(auth *types.ServerAuth, permission shared.Permission, roleId string) (bool, error) {
+	if auth.HasPermissionForResource(permission, roleId) {
+		return true, nil
+	}
+
+	if !auth.HasPermission(shared.PermissionManageOrgRoles) {
+		return false, nil
+	}
+
+	role, err := db.GetOrgRole(auth.OrgId, roleId)
+	if err != nil {
+		return false, fmt.Errorf("error getting org role: %v", err)
+	}
+
+	return role != nil && role.OrgId != nil, nil
+}
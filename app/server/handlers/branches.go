@@ -3,11 +3,13 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"plandex-server/db"
+	"plandex-server/types"
 
 	shared "plandex-shared"
 
@@ -128,7 +130,7 @@ func CreateBranchHandler(w http.ResponseWriter, r *http.Request) {
 		OrgId:    auth.OrgId,
 		UserId:   auth.User.Id,
 		PlanId:   planId,
-		Branch:   "main",
+		Branch:   branch,
 		Reason:   "create branch",
 		Scope:    db.LockScopeWrite,
 		Ctx:      ctx,
@@ -205,3 +207,141 @@ func DeleteBranchHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("Successfully deleted branch")
 }
+
+func MergeBranchHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for MergeBranchHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+
+	log.Println("planId: ", planId, "branch: ", branch)
+
+	if authorizePlan(w, planId, auth) == nil {
+		return
+	}
+
+	var req shared.MergeBranchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.SourceBranch == "" || req.SourceBranch == branch {
+		log.Println("Invalid source branch")
+		http.Error(w, "Source branch must be set and different from the current branch", http.StatusBadRequest)
+		return
+	}
+
+	execBranchMerge(w, r, auth, planId, branch, "merge branch", func(repo *db.GitRepo) (*shared.BranchMergeResponse, error) {
+		return db.MergeBranch(db.MergeBranchParams{
+			Repo:         repo,
+			OrgId:        auth.OrgId,
+			PlanId:       planId,
+			SourceBranch: req.SourceBranch,
+			TargetBranch: branch,
+		})
+	})
+}
+
+func CherryPickHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CherryPickHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+
+	log.Println("planId: ", planId, "branch: ", branch)
+
+	if authorizePlan(w, planId, auth) == nil {
+		return
+	}
+
+	var req shared.CherryPickRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.SourceBranch == "" || req.SourceBranch == branch || req.Ref == "" {
+		log.Println("Invalid cherry-pick request")
+		http.Error(w, "Source branch and ref must be set, and the source branch must be different from the current branch", http.StatusBadRequest)
+		return
+	}
+
+	if err := db.ValidateCherryPickRef(req.Ref); err != nil {
+		log.Printf("Invalid cherry-pick ref: %v\n", err)
+		http.Error(w, shared.Capitalize(err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	execBranchMerge(w, r, auth, planId, branch, "cherry-pick", func(repo *db.GitRepo) (*shared.BranchMergeResponse, error) {
+		return db.CherryPick(db.CherryPickParams{
+			Repo:         repo,
+			OrgId:        auth.OrgId,
+			PlanId:       planId,
+			SourceBranch: req.SourceBranch,
+			TargetBranch: branch,
+			Ref:          req.Ref,
+		})
+	})
+}
+
+func execBranchMerge(w http.ResponseWriter, r *http.Request, auth *types.ServerAuth, planId, branch, reason string, fn func(repo *db.GitRepo) (*shared.BranchMergeResponse, error)) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	var res *shared.BranchMergeResponse
+
+	err := db.ExecRepoOperation(db.ExecRepoOperationParams{
+		OrgId:    auth.OrgId,
+		UserId:   auth.User.Id,
+		PlanId:   planId,
+		Branch:   branch,
+		Reason:   reason,
+		Scope:    db.LockScopeWrite,
+		Ctx:      ctx,
+		CancelFn: cancel,
+	}, func(repo *db.GitRepo) error {
+		var err error
+		res, err = fn(repo)
+		return err
+	})
+
+	if err != nil {
+		log.Printf("Error during %s: %v\n", reason, err)
+		if errors.Is(err, db.ErrBranchRefNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Error during %s: %v", reason, err), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(res.Conflicts) > 0 {
+		log.Printf("%s stopped with %d conflicts\n", reason, len(res.Conflicts))
+	} else {
+		log.Printf("Successfully completed %s\n", reason)
+	}
+
+	w.Write(bytes)
+}
@@ -48,6 +48,20 @@ func initClients(params initClientsParams) initClientsResult {
 		}
 	}
 
+	// credentials stored in the org vault fill in anything the client didn't send
+	// values sent by the client take precedence so users can still override them locally
+	orgSecrets, err := db.GetOrgSecretValues(params.auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting org secrets: %v\n", err)
+		http.Error(w, "Error getting org secrets", http.StatusInternalServerError)
+		return initClientsResult{}
+	}
+	for name, value := range orgSecrets {
+		if authVars[name] == "" {
+			authVars[name] = value
+		}
+	}
+
 	hookResult, apiErr := hooks.ExecHook(hooks.GetIntegratedModels, hooks.HookParams{
 		Auth: params.auth,
 		Plan: params.plan,
@@ -75,6 +89,26 @@ func initClients(params initClientsParams) initClientsResult {
 		return initClientsResult{}
 	}
 
+	// the org policy can restrict which providers models are resolved to
+	policy, err := db.GetOrgPolicy(params.auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting org policy: %v\n", err)
+		http.Error(w, "Error getting org policy", http.StatusInternalServerError)
+		return initClientsResult{}
+	}
+
+	if !authorizeResolvedModelPack(w, params.auth, policy, settings) {
+		return initClientsResult{}
+	}
+
+	authVars = policy.FilterAuthVars(authVars, settings)
+
+	err = policy.CheckResolvedProviders(authVars, settings, orgUserConfig)
+	if err != nil {
+		writeOrgPolicyError(w, err)
+		return initClientsResult{}
+	}
+
 	clients := model.InitClients(authVars, settings, orgUserConfig)
 
 	return initClientsResult{
@@ -122,6 +122,10 @@ func loadContexts(
 				},
 			)
 
+			if res.clients == nil {
+				return nil, nil
+			}
+
 			clients = res.clients
 			authVars = res.authVars
 
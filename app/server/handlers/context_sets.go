@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"plandex-server/db"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+// context sets are scoped to a project rather than guarded by a permission - any org member with access to the project can save, use, or remove them
+
+func ListContextSetsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListContextSetsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	projectId := mux.Vars(r)["projectId"]
+
+	log.Println("projectId: ", projectId)
+
+	if !authorizeProject(w, projectId, auth) {
+		return
+	}
+
+	sets, err := db.ListContextSets(auth.OrgId, projectId)
+	if err != nil {
+		log.Printf("Error listing context sets: %v\n", err)
+		http.Error(w, "Error listing context sets: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(sets)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Printf("Successfully listed %d context sets\n", len(sets))
+}
+
+func UpsertContextSetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for UpsertContextSetHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	projectId := mux.Vars(r)["projectId"]
+
+	log.Println("projectId: ", projectId)
+
+	if !authorizeProject(w, projectId, auth) {
+		return
+	}
+
+	var req shared.UpsertContextSetRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error unmarshalling request: %v\n", err)
+		http.Error(w, "Error unmarshalling request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.ContextSet == nil {
+		http.Error(w, "Context set is required", http.StatusBadRequest)
+		return
+	}
+
+	err = req.ContextSet.Validate()
+	if err != nil {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusBadRequest,
+			Msg:    "Invalid context set: " + err.Error(),
+		})
+		return
+	}
+
+	err = db.UpsertContextSet(auth.OrgId, projectId, auth.User.Id, req.ContextSet)
+	if err != nil {
+		log.Printf("Error saving context set: %v\n", err)
+		http.Error(w, "Error saving context set: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Successfully saved context set %s\n", req.ContextSet.Name)
+}
+
+func DeleteContextSetHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteContextSetHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	projectId := vars["projectId"]
+	name := vars["name"]
+
+	log.Println("projectId: ", projectId, "name: ", name)
+
+	if !authorizeProject(w, projectId, auth) {
+		return
+	}
+
+	deleted, err := db.DeleteContextSet(auth.OrgId, projectId, name)
+	if err != nil {
+		log.Printf("Error deleting context set: %v\n", err)
+		http.Error(w, "Error deleting context set: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !deleted {
+		http.Error(w, "Context set not found", http.StatusNotFound)
+		return
+	}
+
+	log.Printf("Successfully deleted context set %s\n", name)
+}
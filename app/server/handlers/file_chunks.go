@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"plandex-server/syntax/file_map"
+	"sync"
+
+	shared "plandex-shared"
+)
+
+func GetFileChunksHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetFileChunksHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	var req shared.GetFileChunksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Error decoding request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// chunking uses the same parsing as mapping, so the same batch limits apply
+	if len(req.ChunkInputs) > shared.ContextMapMaxBatchSize {
+		http.Error(w, fmt.Sprintf("Batch contains too many files: %d (max %d)", len(req.ChunkInputs), shared.ContextMapMaxBatchSize), http.StatusBadRequest)
+		return
+	}
+
+	if req.ChunkInputs.TotalSize() > shared.ContextMapMaxBatchBytes {
+		http.Error(w, fmt.Sprintf("Batch size too large: %d bytes (max %d bytes)", req.ChunkInputs.TotalSize(), shared.ContextMapMaxBatchBytes), http.StatusBadRequest)
+		return
+	}
+
+	for path, input := range req.ChunkInputs {
+		if len(input) > shared.MaxFileMapInputSize(path) {
+			http.Error(w, fmt.Sprintf("File %s is too large: %d (max %d)", path, len(input), shared.MaxFileMapInputSize(path)), http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), mapJobTimeout)
+	defer cancel()
+
+	chunksByPath := make(map[string][]*shared.FileChunk, len(req.ChunkInputs))
+	var mu sync.Mutex
+	wg := sync.WaitGroup{}
+
+	for path, input := range req.ChunkInputs {
+		wg.Add(1)
+		go func(path, input string) {
+			defer wg.Done()
+
+			// share the mapping CPU limit so chunking doesn't starve map jobs
+			select {
+			case mapCPUSem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-mapCPUSem }()
+
+			chunks, err := file_map.ChunkFile(ctx, path, []byte(input))
+			if err != nil {
+				// files that can't be parsed are left out, same as with mapping
+				log.Printf("Error chunking file %s: %v", path, err)
+				return
+			}
+
+			mu.Lock()
+			chunksByPath[path] = chunks
+			mu.Unlock()
+		}(path, input)
+	}
+
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		http.Error(w, "Chunking timed out", http.StatusRequestTimeout)
+		return
+	}
+
+	bytes, err := json.Marshal(shared.GetFileChunksResponse{ChunksByPath: chunksByPath})
+	if err != nil {
+		log.Printf("Error marshalling response: %v", err)
+		http.Error(w, fmt.Sprintf("Error marshalling response: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(bytes)
+
+	log.Printf("GetFileChunksHandler success - chunked %d files", len(chunksByPath))
+}
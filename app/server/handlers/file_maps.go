@@ -40,8 +40,8 @@ func GetFileMapHandler(w http.ResponseWriter, r *http.Request) {
 	totalSize := 0
 	for path, input := range req.MapInputs {
 		// the client should be truncating inputs to the max size, but we'll check here too
-		if len(input) > shared.MaxContextMapSingleInputSize {
-			http.Error(w, fmt.Sprintf("File %s is too large: %d (max %d)", path, len(input), shared.MaxContextMapSingleInputSize), http.StatusBadRequest)
+		if len(input) > shared.MaxFileMapInputSize(path) {
+			http.Error(w, fmt.Sprintf("File %s is too large: %d (max %d)", path, len(input), shared.MaxFileMapInputSize(path)), http.StatusBadRequest)
 			return
 		}
 		totalSize += len(input)
@@ -87,7 +87,7 @@ func mapWorker(job projectMapJob) {
 	log.Printf("mapWorker: len(job.inputs): %d", len(job.inputs))
 
 	for path, input := range job.inputs {
-		if !shared.HasFileMapSupport(path) {
+		if !shared.HasContentFileMapSupport(path, []byte(input)) {
 			mu.Lock()
 			maps[path] = "[NO MAP]"
 			mu.Unlock()
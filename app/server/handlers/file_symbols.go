@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"plandex-server/syntax/file_map"
+	"sync"
+
+	shared "plandex-shared"
+)
+
+func GetFileSymbolsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetFileSymbolsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	var req shared.GetFileSymbolsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Error decoding request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// symbol lookup uses the same parsing as mapping, so the same batch limits apply
+	if len(req.Lookups) > shared.ContextMapMaxBatchSize {
+		http.Error(w, fmt.Sprintf("Batch contains too many symbols: %d (max %d)", len(req.Lookups), shared.ContextMapMaxBatchSize), http.StatusBadRequest)
+		return
+	}
+
+	if req.SymbolInputs.TotalSize() > shared.ContextMapMaxBatchBytes {
+		http.Error(w, fmt.Sprintf("Batch size too large: %d bytes (max %d bytes)", req.SymbolInputs.TotalSize(), shared.ContextMapMaxBatchBytes), http.StatusBadRequest)
+		return
+	}
+
+	for path, input := range req.SymbolInputs {
+		if len(input) > shared.MaxFileMapInputSize(path) {
+			http.Error(w, fmt.Sprintf("File %s is too large: %d (max %d)", path, len(input), shared.MaxFileMapInputSize(path)), http.StatusBadRequest)
+			return
+		}
+	}
+
+	for _, lookup := range req.Lookups {
+		if _, ok := req.SymbolInputs[lookup.Path]; !ok {
+			http.Error(w, fmt.Sprintf("No content for file %s", lookup.Path), http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), mapJobTimeout)
+	defer cancel()
+
+	ranges := make([]*shared.FileChunk, len(req.Lookups))
+	wg := sync.WaitGroup{}
+
+	for i, lookup := range req.Lookups {
+		wg.Add(1)
+		go func(i int, lookup *shared.FileSymbolLookup) {
+			defer wg.Done()
+
+			select {
+			case mapCPUSem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-mapCPUSem }()
+
+			res, err := file_map.FindSymbol(ctx, lookup.Path, []byte(req.SymbolInputs[lookup.Path]), lookup.Symbol)
+			if err != nil {
+				// unresolved symbols are returned as nil so the client can report them
+				log.Printf("Error finding symbol %s in %s: %v", lookup.Symbol, lookup.Path, err)
+				return
+			}
+
+			// each goroutine writes its own index
+			ranges[i] = res
+		}(i, lookup)
+	}
+
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		http.Error(w, "Symbol lookup timed out", http.StatusRequestTimeout)
+		return
+	}
+
+	bytes, err := json.Marshal(shared.GetFileSymbolsResponse{Ranges: ranges})
+	if err != nil {
+		log.Printf("Error marshalling response: %v", err)
+		http.Error(w, fmt.Sprintf("Error marshalling response: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(bytes)
+
+	log.Printf("GetFileSymbolsHandler success - looked up %d symbols", len(req.Lookups))
+}
@@ -63,9 +63,15 @@ func InviteUserHandler(w http.ResponseWriter, r *http.Request) {
 	req.Email = strings.ToLower(req.Email)
 
 	// ensure current user can invite target user
-	permission := shared.Permission(strings.Join([]string{string(shared.PermissionInviteUser), req.OrgRoleId}, "|"))
+	canInvite, err := canActOnOrgRole(auth, shared.PermissionInviteUser, req.OrgRoleId)
 
-	if !auth.HasPermission(permission) {
+	if err != nil {
+		log.Printf("Error checking invite permission: %v\n", err)
+		http.Error(w, "Error checking invite permission: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !canInvite {
 		log.Printf("User does not have permission to invite user with role: %v\n", req.OrgRoleId)
 		http.Error(w, "User does not have permission to invite user with role: "+req.OrgRoleId, http.StatusForbidden)
 		return
@@ -384,12 +390,23 @@ func DeleteInviteHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// ensure current user can remove target invite
-	removePermission := shared.Permission(strings.Join([]string{string(shared.PermissionRemoveUser), invite.OrgRoleId}, "|"))
+	canRemove, err := canActOnOrgRole(auth, shared.PermissionRemoveUser, invite.OrgRoleId)
+
+	if err != nil {
+		log.Printf("Error checking remove permission: %v\n", err)
+		http.Error(w, "Error checking remove permission: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	canInvite, err := canActOnOrgRole(auth, shared.PermissionInviteUser, invite.OrgRoleId)
 
-	invitePermission := shared.Permission(strings.Join([]string{string(shared.PermissionInviteUser), invite.OrgRoleId}, "|"))
+	if err != nil {
+		log.Printf("Error checking invite permission: %v\n", err)
+		http.Error(w, "Error checking invite permission: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-	if !(auth.HasPermission(removePermission) ||
-		(auth.User.Id == invite.InviterId && auth.HasPermission(invitePermission))) {
+	if !(canRemove || (auth.User.Id == invite.InviterId && canInvite)) {
 		log.Printf("User does not have permission to remove invite with role: %v\n", invite.OrgRoleId)
 		http.Error(w, "User does not have permission to remove invite with role: "+invite.OrgRoleId, http.StatusForbidden)
 		return
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"plandex-server/db"
+	"plandex-server/types"
+
+	shared "plandex-shared"
+)
+
+func GetOrgPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetOrgPolicyHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	// any org member can read the policy so the CLI can explain what's allowed
+	policy, err := db.GetOrgPolicy(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting org policy: %v\n", err)
+		http.Error(w, "Error getting org policy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(policy)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully got org policy")
+}
+
+func UpdateOrgPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for UpdateOrgPolicyHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManageOrgPolicy) {
+		log.Println("User cannot manage org policy")
+		http.Error(w, "User cannot manage org policy", http.StatusForbidden)
+		return
+	}
+
+	var req shared.UpdateOrgPolicyRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error unmarshalling request: %v\n", err)
+		http.Error(w, "Error unmarshalling request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Policy == nil {
+		req.Policy = &shared.OrgPolicy{}
+	}
+
+	err = req.Policy.Validate()
+	if err != nil {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusBadRequest,
+			Msg:    "Invalid org policy: " + err.Error(),
+		})
+		return
+	}
+
+	err = db.SetOrgPolicy(auth.OrgId, auth.User.Id, req.Policy)
+	if err != nil {
+		log.Printf("Error setting org policy: %v\n", err)
+		http.Error(w, "Error setting org policy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully updated org policy")
+}
+
+func authorizePlanConfigPolicy(w http.ResponseWriter, auth *types.ServerAuth, config *shared.PlanConfig) bool {
+	policy := getOrgPolicy(w, auth)
+	if policy == nil {
+		return false
+	}
+
+	err := policy.CheckPlanConfig(config)
+	if err != nil {
+		writeOrgPolicyError(w, err)
+		return false
+	}
+
+	return true
+}
+
+func authorizeModelPackPolicy(w http.ResponseWriter, auth *types.ServerAuth, req shared.UpdateSettingsRequest) bool {
+	policy := getOrgPolicy(w, auth)
+	if policy == nil {
+		return false
+	}
+
+	if policy.IsEmpty() {
+		return true
+	}
+
+	customModels, err := db.GetApiCustomModels(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting custom models: %v\n", err)
+		http.Error(w, "Error getting custom models: "+err.Error(), http.StatusInternalServerError)
+		return false
+	}
+
+	// resolve the requested model pack the same way plan settings are resolved so custom packs and providers are checked too
+	settings := &shared.PlanSettings{}
+	if req.ModelPackName != "" {
+		settings.SetModelPackByName(req.ModelPackName)
+	} else {
+		settings.SetCustomModelPack(req.ModelPack)
+	}
+	settings.Configure(customModels.CustomModelPacks, customModels.CustomModels, customModels.CustomProviders, os.Getenv("PLANDEX_CLOUD") != "")
+
+	err = policy.CheckModelPack(settings)
+	if err != nil {
+		writeOrgPolicyError(w, err)
+		return false
+	}
+
+	return true
+}
+
+// authorizeExecRequest rejects requests that would execute commands when either the user's org role or the org policy doesn't allow it
+func authorizeExecRequest(w http.ResponseWriter, auth *types.ServerAuth) bool {
+	if !authorizeExecCommands(w, auth) {
+		return false
+	}
+
+	policy := getOrgPolicy(w, auth)
+	if policy == nil {
+		return false
+	}
+
+	if policy.DisableExec {
+		writeOrgPolicyError(w, fmt.Errorf("org policy doesn't allow executing commands"))
+		return false
+	}
+
+	return true
+}
+
+func getOrgPolicy(w http.ResponseWriter, auth *types.ServerAuth) *shared.OrgPolicy {
+	policy, err := db.GetOrgPolicy(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting org policy: %v\n", err)
+		http.Error(w, "Error getting org policy: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+	return policy
+}
+
+func writeOrgPolicyError(w http.ResponseWriter, err error) {
+	log.Printf("Org policy violation: %v\n", err)
+	writeApiError(w, shared.ApiError{
+		Type:   shared.ApiErrorTypeOrgPolicyViolation,
+		Status: http.StatusForbidden,
+		Msg:    shared.Capitalize(err.Error()),
+	})
+}
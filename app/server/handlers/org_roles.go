@@ -0,0 +1,387 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"plandex-server/db"
+	"plandex-server/types"
+	"regexp"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+	"github.com/jmoiron/sqlx"
+)
+
+var orgRoleNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// names of the built-in roles can't be reused by custom roles
+var reservedOrgRoleNames = map[string]bool{
+	"owner":         true,
+	"admin":         true,
+	"member":        true,
+	"billing_admin": true,
+}
+
+func CreateOrgRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CreateOrgRoleHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeManageOrgRoles(w, auth) {
+		return
+	}
+
+	var req shared.CreateOrgRoleRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error unmarshalling request: %v\n", err)
+		http.Error(w, "Error unmarshalling request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !orgRoleNameRegex.MatchString(req.Name) || reservedOrgRoleNames[req.Name] {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusBadRequest,
+			Msg:    fmt.Sprintf("Invalid role name '%s'. Use lowercase letters, numbers, dashes, and underscores, and don't reuse a built-in role name", req.Name),
+		})
+		return
+	}
+
+	if req.Label == "" {
+		req.Label = req.Name
+	}
+
+	if !validateOrgRolePermissions(w, req.Permissions) {
+		return
+	}
+
+	existing, err := db.ListOrgRoles(auth.OrgId)
+	if err != nil {
+		log.Printf("Error listing org roles: %v\n", err)
+		http.Error(w, "Error listing org roles: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for _, role := range existing {
+		if role.Name == req.Name {
+			writeApiError(w, shared.ApiError{
+				Type:   shared.ApiErrorTypeOther,
+				Status: http.StatusConflict,
+				Msg:    fmt.Sprintf("A role named '%s' already exists", req.Name),
+			})
+			return
+		}
+	}
+
+	var role *db.OrgRole
+	err = db.WithTx(r.Context(), "create org role", func(tx *sqlx.Tx) error {
+		var err error
+		role, err = db.CreateOrgRole(auth.OrgId, &req, tx)
+		return err
+	})
+
+	if err != nil {
+		log.Printf("Error creating org role: %v\n", err)
+		http.Error(w, "Error creating org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	apiRole := role.ToApi()
+	apiRole.Permissions = req.Permissions
+
+	bytes, err := json.Marshal(apiRole)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully created org role")
+}
+
+func UpdateOrgRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for UpdateOrgRoleHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeManageOrgRoles(w, auth) {
+		return
+	}
+
+	roleId := mux.Vars(r)["roleId"]
+	log.Println("roleId: ", roleId)
+
+	if getCustomOrgRole(w, auth, roleId) == nil {
+		return
+	}
+
+	var req shared.UpdateOrgRoleRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error unmarshalling request: %v\n", err)
+		http.Error(w, "Error unmarshalling request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Label == "" {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusBadRequest,
+			Msg:    "Role label is required",
+		})
+		return
+	}
+
+	if !validateOrgRolePermissions(w, req.Permissions) {
+		return
+	}
+
+	err = db.WithTx(r.Context(), "update org role", func(tx *sqlx.Tx) error {
+		return db.UpdateOrgRole(auth.OrgId, roleId, &req, tx)
+	})
+
+	if err != nil {
+		log.Printf("Error updating org role: %v\n", err)
+		http.Error(w, "Error updating org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully updated org role")
+}
+
+func DeleteOrgRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteOrgRoleHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeManageOrgRoles(w, auth) {
+		return
+	}
+
+	roleId := mux.Vars(r)["roleId"]
+	log.Println("roleId: ", roleId)
+
+	role := getCustomOrgRole(w, auth, roleId)
+	if role == nil {
+		return
+	}
+
+	numUsers, err := db.NumUsersWithRole(auth.OrgId, roleId)
+	if err != nil {
+		log.Printf("Error counting users with role: %v\n", err)
+		http.Error(w, "Error counting users with role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	numInvites, err := db.NumInvitesWithRole(auth.OrgId, roleId)
+	if err != nil {
+		log.Printf("Error counting invites with role: %v\n", err)
+		http.Error(w, "Error counting invites with role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if numUsers > 0 || numInvites > 0 {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusConflict,
+			Msg:    fmt.Sprintf("Role '%s' is assigned to %d user(s) and %d pending invite(s). Assign them a different role before deleting it.", role.Label, numUsers, numInvites),
+		})
+		return
+	}
+
+	err = db.WithTx(r.Context(), "delete org role", func(tx *sqlx.Tx) error {
+		return db.DeleteOrgRole(auth.OrgId, roleId, tx)
+	})
+
+	if err != nil {
+		log.Printf("Error deleting org role: %v\n", err)
+		http.Error(w, "Error deleting org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully deleted org role")
+}
+
+func SetOrgUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for SetOrgUserRoleHandler")
+
+	if os.Getenv("GOENV") == "development" && os.Getenv("LOCAL_MODE") == "1" {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusForbidden,
+			Msg:    "Local mode is not supported for user management",
+		})
+		return
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	userId := mux.Vars(r)["userId"]
+	log.Println("userId: ", userId)
+
+	var req shared.SetOrgUserRoleRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error unmarshalling request: %v\n", err)
+		http.Error(w, "Error unmarshalling request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	orgUser, err := db.GetOrgUser(userId, auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting org user: %v\n", err)
+		http.Error(w, "Error getting org user: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if orgUser == nil {
+		log.Printf("User %s is not a member of org %s\n", userId, auth.OrgId)
+		http.Error(w, "User "+userId+" is not a member of org "+auth.OrgId, http.StatusNotFound)
+		return
+	}
+
+	role, err := db.GetOrgRole(auth.OrgId, req.OrgRoleId)
+	if err != nil {
+		log.Printf("Error getting org role: %v\n", err)
+		http.Error(w, "Error getting org role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if role == nil {
+		log.Printf("Org role %s not found\n", req.OrgRoleId)
+		http.Error(w, "Org role not found", http.StatusNotFound)
+		return
+	}
+
+	// the current user needs permission over both the user's current role and the new role
+	for _, roleId := range []string{orgUser.OrgRoleId, req.OrgRoleId} {
+		canSet, err := canActOnOrgRole(auth, shared.PermissionSetUserRole, roleId)
+		if err != nil {
+			log.Printf("Error checking set role permission: %v\n", err)
+			http.Error(w, "Error checking set role permission: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if !canSet {
+			log.Printf("User does not have permission to set user role: %v\n", roleId)
+			http.Error(w, "User does not have permission to set user role: "+roleId, http.StatusForbidden)
+			return
+		}
+	}
+
+	orgOwnerRoleId, err := db.GetOrgOwnerRoleId()
+	if err != nil {
+		log.Printf("Error getting org owner role id: %v\n", err)
+		http.Error(w, "Error getting org owner role id: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if orgUser.OrgRoleId == orgOwnerRoleId && req.OrgRoleId != orgOwnerRoleId {
+		numOwners, err := db.NumUsersWithRole(auth.OrgId, orgOwnerRoleId)
+		if err != nil {
+			log.Printf("Error getting number of org owners: %v\n", err)
+			http.Error(w, "Error getting number of org owners: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if numOwners == 1 {
+			log.Println("Cannot change the role of the only org owner")
+			http.Error(w, "Cannot change the role of the only org owner", http.StatusForbidden)
+			return
+		}
+	}
+
+	err = db.SetOrgUserRole(auth.OrgId, userId, req.OrgRoleId)
+	if err != nil {
+		log.Printf("Error setting org user role: %v\n", err)
+		http.Error(w, "Error setting org user role: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully set org user role")
+}
+
+func authorizeManageOrgRoles(w http.ResponseWriter, auth *types.ServerAuth) bool {
+	org, err := db.GetOrg(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting org: %v\n", err)
+		http.Error(w, "Error getting org: "+err.Error(), http.StatusInternalServerError)
+		return false
+	}
+
+	if org.IsTrial {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeTrialActionNotAllowed,
+			Status: http.StatusForbidden,
+			Msg:    "Trial user can't manage org roles",
+		})
+		return false
+	}
+
+	if !auth.HasPermission(shared.PermissionManageOrgRoles) {
+		log.Println("User cannot manage org roles")
+		http.Error(w, "User cannot manage org roles", http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+func getCustomOrgRole(w http.ResponseWriter, auth *types.ServerAuth, roleId string) *db.OrgRole {
+	role, err := db.GetOrgRole(auth.OrgId, roleId)
+	if err != nil {
+		log.Printf("Error getting org role: %v\n", err)
+		http.Error(w, "Error getting org role: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	if role == nil {
+		log.Printf("Org role %s not found\n", roleId)
+		http.Error(w, "Org role not found", http.StatusNotFound)
+		return nil
+	}
+
+	if role.OrgId == nil {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusForbidden,
+			Msg:    "Built-in roles can't be modified",
+		})
+		return nil
+	}
+
+	return role
+}
+
+func validateOrgRolePermissions(w http.ResponseWriter, permissions []shared.Permission) bool {
+	for _, p := range permissions {
+		if !shared.IsCustomRolePermission(p) {
+			writeApiError(w, shared.ApiError{
+				Type:   shared.ApiErrorTypeOther,
+				Status: http.StatusBadRequest,
+				Msg:    fmt.Sprintf("Permission '%s' can't be granted to a custom role", p),
+			})
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"plandex-server/db"
+	"plandex-server/types"
+	"regexp"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+// secret names are used as auth var names (e.g. OPENAI_API_KEY), so they follow env var naming rules
+var orgSecretNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func ListOrgSecretsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListOrgSecretsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	// any org member can list secret names (values are never returned) so the CLI knows which credentials are covered by the vault
+	secrets, err := db.ListOrgSecrets(auth.OrgId)
+
+	if err != nil {
+		log.Printf("Error listing org secrets: %v\n", err)
+		http.Error(w, "Error listing org secrets: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	apiSecrets := []*shared.OrgSecret{}
+	if db.OrgSecretsVaultEnabled() {
+		for _, secret := range secrets {
+			apiSecrets = append(apiSecrets, secret.ToApi())
+		}
+	}
+
+	bytes, err := json.Marshal(apiSecrets)
+
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully listed org secrets")
+}
+
+func SetOrgSecretHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for SetOrgSecretHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeManageOrgSecrets(w, auth) {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Error reading request body: %v\n", err)
+		http.Error(w, "Error reading request body: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var req shared.SetOrgSecretRequest
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		log.Printf("Error unmarshalling request: %v\n", err)
+		http.Error(w, "Error unmarshalling request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !orgSecretNameRegex.MatchString(req.Name) {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusBadRequest,
+			Msg:    "Secret name must be a valid environment variable name, e.g. OPENAI_API_KEY",
+		})
+		return
+	}
+
+	if req.Value == "" {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusBadRequest,
+			Msg:    "Secret value is required",
+		})
+		return
+	}
+
+	err = db.SetOrgSecret(auth.OrgId, auth.User.Id, req.Name, req.Value)
+
+	if err != nil {
+		log.Printf("Error setting org secret: %v\n", err)
+		http.Error(w, "Error setting org secret: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully set org secret")
+}
+
+func DeleteOrgSecretHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteOrgSecretHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !authorizeManageOrgSecrets(w, auth) {
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+
+	deleted, err := db.DeleteOrgSecret(auth.OrgId, name)
+
+	if err != nil {
+		log.Printf("Error deleting org secret: %v\n", err)
+		http.Error(w, "Error deleting org secret: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !deleted {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusNotFound,
+			Msg:    "No org secret named " + name,
+		})
+		return
+	}
+
+	log.Println("Successfully deleted org secret")
+}
+
+func authorizeManageOrgSecrets(w http.ResponseWriter, auth *types.ServerAuth) bool {
+	if os.Getenv("IS_CLOUD") != "" {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusBadRequest,
+			Msg:    "The org secrets vault is not supported on Plandex Cloud",
+		})
+		return false
+	}
+
+	if !db.OrgSecretsVaultEnabled() {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusBadRequest,
+			Msg:    "The org secrets vault is not configured—set " + db.SecretsMasterKeyEnvVar + " on the server",
+		})
+		return false
+	}
+
+	if !auth.HasPermission(shared.PermissionManageOrgSecrets) {
+		log.Println("User does not have permission to manage org secrets")
+		http.Error(w, "User does not have permission to manage org secrets", http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
@@ -238,9 +238,24 @@ func ListOrgRolesHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	roleIds := make([]string, len(roles))
+	for i, role := range roles {
+		roleIds[i] = role.Id
+	}
+
+	permissionsByRoleId, err := db.GetOrgRolePermissions(roleIds)
+
+	if err != nil {
+		log.Printf("Error getting org role permissions: %v\n", err)
+		http.Error(w, "Error getting org role permissions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	var apiRoles []*shared.OrgRole
 	for _, role := range roles {
-		apiRoles = append(apiRoles, role.ToApi())
+		apiRole := role.ToApi()
+		apiRole.Permissions = permissionsByRoleId[role.Id]
+		apiRoles = append(apiRoles, apiRole)
 	}
 
 	bytes, err := json.Marshal(apiRoles)
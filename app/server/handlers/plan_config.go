@@ -38,6 +38,13 @@ func GetPlanConfigHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	policy := getOrgPolicy(w, auth)
+	if policy == nil {
+		return
+	}
+	policy.ClampPlanConfig(config)
+	maskExecConfig(auth, config)
+
 	res := shared.GetPlanConfigResponse{
 		Config: config,
 	}
@@ -79,6 +86,14 @@ func UpdatePlanConfigHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !authorizeExecConfig(w, auth, req.Config) {
+		return
+	}
+
+	if !authorizePlanConfigPolicy(w, auth, req.Config) {
+		return
+	}
+
 	err = db.StorePlanConfig(planId, req.Config)
 	if err != nil {
 		log.Println("Error storing plan config: ", err)
@@ -104,6 +119,13 @@ func GetDefaultPlanConfigHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	policy := getOrgPolicy(w, auth)
+	if policy == nil {
+		return
+	}
+	policy.ClampPlanConfig(config)
+	maskExecConfig(auth, config)
+
 	res := shared.GetDefaultPlanConfigResponse{
 		Config: config,
 	}
@@ -135,6 +157,14 @@ func UpdateDefaultPlanConfigHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !authorizeExecConfig(w, auth, req.Config) {
+		return
+	}
+
+	if !authorizePlanConfigPolicy(w, auth, req.Config) {
+		return
+	}
+
 	err = db.WithTx(r.Context(), "update default plan config", func(tx *sqlx.Tx) error {
 
 		err := db.StoreDefaultPlanConfig(auth.User.Id, req.Config, tx)
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"plandex-server/db"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+func ListPlanTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListPlanTemplatesHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	// any org member can list templates so they can start plans from them
+	templates, err := db.ListPlanTemplates(auth.OrgId)
+	if err != nil {
+		log.Printf("Error listing plan templates: %v\n", err)
+		http.Error(w, "Error listing plan templates: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(templates)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Printf("Successfully listed %d plan templates\n", len(templates))
+}
+
+func UpsertPlanTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for UpsertPlanTemplateHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManagePlanTemplates) {
+		log.Println("User cannot manage plan templates")
+		http.Error(w, "User cannot manage plan templates", http.StatusForbidden)
+		return
+	}
+
+	var req shared.UpsertPlanTemplateRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error unmarshalling request: %v\n", err)
+		http.Error(w, "Error unmarshalling request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Template == nil {
+		http.Error(w, "Template is required", http.StatusBadRequest)
+		return
+	}
+
+	err = req.Template.Validate()
+	if err != nil {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusBadRequest,
+			Msg:    "Invalid plan template: " + err.Error(),
+		})
+		return
+	}
+
+	err = db.UpsertPlanTemplate(auth.OrgId, auth.User.Id, req.Template)
+	if err != nil {
+		log.Printf("Error saving plan template: %v\n", err)
+		http.Error(w, "Error saving plan template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Successfully saved plan template %s\n", req.Template.Name)
+}
+
+func DeletePlanTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeletePlanTemplateHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionManagePlanTemplates) {
+		log.Println("User cannot manage plan templates")
+		http.Error(w, "User cannot manage plan templates", http.StatusForbidden)
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+
+	log.Println("name: ", name)
+
+	deleted, err := db.DeletePlanTemplate(auth.OrgId, name)
+	if err != nil {
+		log.Printf("Error deleting plan template: %v\n", err)
+		http.Error(w, "Error deleting plan template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !deleted {
+		http.Error(w, "Plan template not found", http.StatusNotFound)
+		return
+	}
+
+	log.Printf("Successfully deleted plan template %s\n", name)
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/hooks"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+func ExportPlanHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ExportPlanHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+
+	log.Println("planId: ", planId)
+
+	plan := authorizePlan(w, planId, auth)
+	if plan == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+
+	var archive bytes.Buffer
+
+	err := db.ExecRepoOperation(db.ExecRepoOperationParams{
+		OrgId:    auth.OrgId,
+		UserId:   auth.User.Id,
+		PlanId:   planId,
+		Branch:   "main",
+		Scope:    db.LockScopeRead,
+		Ctx:      ctx,
+		CancelFn: cancel,
+		Reason:   "export plan",
+	}, func(repo *db.GitRepo) error {
+		return db.ExportPlan(repo, plan, &archive)
+	})
+
+	if err != nil {
+		log.Printf("Error exporting plan: %v\n", err)
+		http.Error(w, "Error exporting plan: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", plan.Name+".plandex.tar.gz"))
+	w.Write(archive.Bytes())
+
+	log.Printf("Successfully exported plan %s (%d bytes)\n", planId, archive.Len())
+}
+
+func ImportPlanHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ImportPlanHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionCreatePlan) {
+		log.Println("User does not have permission to create a plan")
+		http.Error(w, "User does not have permission to create a plan", http.StatusForbidden)
+		return
+	}
+
+	vars := mux.Vars(r)
+	projectId := vars["projectId"]
+
+	log.Println("projectId: ", projectId)
+
+	if !authorizeProject(w, projectId, auth) {
+		return
+	}
+
+	_, apiErr := hooks.ExecHook(hooks.WillCreatePlan, hooks.HookParams{Auth: auth})
+	if apiErr != nil {
+		writeApiError(w, *apiErr)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, db.MaxPlanArchiveSize)
+	defer r.Body.Close()
+
+	// the archive's own name is used unless one is passed, then made unique in the project either way
+	name := r.URL.Query().Get("name")
+
+	plan, err := db.ImportPlan(r.Context(), db.ImportPlanParams{
+		OrgId:     auth.OrgId,
+		ProjectId: projectId,
+		UserId:    auth.User.Id,
+		Name:      name,
+		Archive:   r.Body,
+		UniqueName: func(name string) (string, error) {
+			return getUniquePlanName(projectId, auth.User.Id, name)
+		},
+	})
+
+	if err != nil {
+		log.Printf("Error importing plan: %v\n", err)
+		status := http.StatusInternalServerError
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			status = http.StatusRequestEntityTooLarge
+		} else if errors.Is(err, db.ErrInvalidPlanArchive) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, "Error importing plan: "+err.Error(), status)
+		return
+	}
+
+	resp := shared.CreatePlanResponse{
+		Id:   plan.Id,
+		Name: plan.Name,
+	}
+
+	jsonBytes, err := json.Marshal(resp)
+
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(jsonBytes)
+
+	log.Printf("Successfully imported plan: %s - %s\n", plan.Id, plan.Name)
+}
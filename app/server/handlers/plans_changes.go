@@ -3,12 +3,14 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"plandex-server/db"
 	modelPlan "plandex-server/model/plan"
+	"plandex-server/telemetry"
 	"time"
 
 	shared "plandex-shared"
@@ -116,7 +118,7 @@ func ApplyPlanHandler(w http.ResponseWriter, r *http.Request) {
 	branch := vars["branch"]
 	log.Println("planId: ", planId, "branch: ", branch)
 
-	plan := authorizePlan(w, planId, auth)
+	plan := authorizePlanApply(w, planId, auth)
 	if plan == nil {
 		return
 	}
@@ -139,10 +141,15 @@ func ApplyPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	spanCtx, span := telemetry.StartSpan(r.Context(), "plan.apply", telemetry.PlanAttrs(planId, branch)...)
+	defer func() {
+		telemetry.EndSpan(span, err)
+	}()
+
 	// Just in case this was sent immediately after a stream finished, wait a little before locking to allow for cleanup
 	time.Sleep(100 * time.Millisecond)
 
-	ctx, cancel := context.WithCancel(r.Context())
+	ctx, cancel := context.WithCancel(spanCtx)
 
 	var settings *shared.PlanSettings
 	var currentPlanParams db.CurrentPlanStateParams
@@ -196,6 +203,10 @@ func ApplyPlanHandler(w http.ResponseWriter, r *http.Request) {
 		},
 	)
 
+	if res.clients == nil {
+		return
+	}
+
 	clients := res.clients
 	authVars := res.authVars
 
@@ -207,7 +218,7 @@ func ApplyPlanHandler(w http.ResponseWriter, r *http.Request) {
 		Current:   currentPlan,
 		AuthVars:  authVars,
 		SessionId: requestBody.SessionId,
-		Ctx:       r.Context(),
+		Ctx:       spanCtx,
 	})
 
 	if err != nil {
@@ -429,6 +440,96 @@ func RejectFilesHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Successfully rejected plan files", req.Paths)
 }
 
+func ReviewReplacementsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ReviewReplacementsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+
+	log.Println("planId: ", planId, "branch: ", branch)
+
+	if authorizePlan(w, planId, auth) == nil {
+		return
+	}
+
+	var req shared.ReviewReplacementsRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error decoding request: %v\n", err)
+		http.Error(w, "Error decoding request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Rejected) == 0 && len(req.Edited) == 0 && len(req.RejectedResults) == 0 {
+		http.Error(w, "No rejected or edited changes", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+
+	err = db.ExecRepoOperation(db.ExecRepoOperationParams{
+		OrgId:          auth.OrgId,
+		UserId:         auth.User.Id,
+		PlanId:         planId,
+		Branch:         branch,
+		Scope:          db.LockScopeWrite,
+		Ctx:            ctx,
+		CancelFn:       cancel,
+		ClearRepoOnErr: true,
+		Reason:         "review replacements",
+	}, func(repo *db.GitRepo) error {
+		err := db.ReviewReplacements(auth.OrgId, planId, req, time.Now())
+		if err != nil {
+			return err
+		}
+
+		msg := "🔍 Reviewed pending changes"
+		if len(req.Rejected) > 0 {
+			msg += fmt.Sprintf("\n • Rejected %d hunk", len(req.Rejected))
+			if len(req.Rejected) > 1 {
+				msg += "s"
+			}
+		}
+		if len(req.Edited) > 0 {
+			msg += fmt.Sprintf("\n • Edited %d hunk", len(req.Edited))
+			if len(req.Edited) > 1 {
+				msg += "s"
+			}
+		}
+		if len(req.RejectedResults) > 0 {
+			msg += fmt.Sprintf("\n • Rejected %d whole-file change", len(req.RejectedResults))
+			if len(req.RejectedResults) > 1 {
+				msg += "s"
+			}
+		}
+
+		err = repo.GitAddAndCommit(branch, msg)
+		if err != nil {
+			return fmt.Errorf("error committing reviewed changes: %v", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Error reviewing changes: %v\n", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, db.ErrReviewedChangesDontApply) {
+			status = http.StatusConflict
+		}
+		http.Error(w, "Error reviewing changes: "+err.Error(), status)
+		return
+	}
+
+	log.Printf("Successfully reviewed changes - rejected: %d, edited: %d, rejected results: %d\n", len(req.Rejected), len(req.Edited), len(req.RejectedResults))
+}
+
 func ArchivePlanHandler(w http.ResponseWriter, r *http.Request) {
 	auth := Authenticate(w, r, true)
 	if auth == nil {
@@ -577,3 +678,62 @@ func GetPlanDiffsHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("Successfully retrieved plan diffs")
 }
+
+func GetPlanPRHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetPlanPRHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+
+	log.Println("planId: ", planId, "branch: ", branch)
+
+	plan := authorizePlan(w, planId, auth)
+	if plan == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	var res *shared.PlanPRResponse
+
+	err := db.ExecRepoOperation(db.ExecRepoOperationParams{
+		OrgId:    auth.OrgId,
+		UserId:   auth.User.Id,
+		PlanId:   planId,
+		Branch:   branch,
+		Reason:   "get plan pr",
+		Scope:    db.LockScopeRead,
+		Ctx:      ctx,
+		CancelFn: cancel,
+	}, func(repo *db.GitRepo) error {
+		var err error
+		res, err = db.GetPlanPR(auth.OrgId, plan)
+		return err
+	})
+
+	if err != nil {
+		log.Printf("Error getting plan pr: %v\n", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, db.ErrNoPlanApplies) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, "Error getting plan pr: "+err.Error(), status)
+		return
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully processed request for GetPlanPRHandler")
+}
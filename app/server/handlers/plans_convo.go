@@ -6,6 +6,8 @@ import (
 	"log"
 	"net/http"
 	"plandex-server/db"
+	"strconv"
+	"strings"
 
 	shared "plandex-shared"
 
@@ -158,3 +160,66 @@ func GetPlanStatusHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("Successfully processed request for GetPlanStatusHandler")
 }
+
+const defaultConvoSearchLimit = 20
+const maxConvoSearchLimit = 100
+
+func SearchConvoHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received a request for SearchConvoHandler")
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "q is required", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultConvoSearchLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxConvoSearchLimit)
+	}
+
+	// with no projectId, all the user's plans in the org are searched
+	projectIds := r.URL.Query()["projectId"]
+	for _, projectId := range projectIds {
+		if !authorizeProject(w, projectId, auth) {
+			return
+		}
+	}
+
+	log.Println("projectIds: ", projectIds, "limit: ", limit)
+
+	results, err := db.SearchConvo(db.SearchConvoParams{
+		OrgId:      auth.OrgId,
+		UserId:     auth.User.Id,
+		ProjectIds: projectIds,
+		Query:      query,
+		Limit:      limit,
+	})
+
+	if err != nil {
+		log.Println("Error searching convo: ", err)
+		http.Error(w, "Error searching convo: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(results)
+
+	if err != nil {
+		log.Println("Error marshalling search results: ", err)
+		http.Error(w, "Error marshalling search results: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Printf("Successfully processed request for SearchConvoHandler - %d results\n", len(results))
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -66,6 +67,10 @@ func TellPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if requestBody.ExecEnabled && !authorizeExecRequest(w, auth) {
+		return
+	}
+
 	_, apiErr := hooks.ExecHook(hooks.WillTellPlan, hooks.HookParams{
 		Auth: auth,
 		Plan: plan,
@@ -95,6 +100,16 @@ func TellPlanHandler(w http.ResponseWriter, r *http.Request) {
 			orgUserConfig: orgUserConfig,
 		},
 	)
+
+	if res.clients == nil {
+		return
+	}
+
+	release, ok := acquireRateLimit(w, r, auth)
+	if !ok {
+		return
+	}
+
 	err = modelPlan.Tell(modelPlan.TellParams{
 		Clients:  res.clients,
 		Plan:     plan,
@@ -103,6 +118,7 @@ func TellPlanHandler(w http.ResponseWriter, r *http.Request) {
 		Req:      &requestBody,
 		AuthVars: res.authVars,
 	})
+	release()
 
 	if err != nil {
 		log.Printf("Error telling plan: %v\n", err)
@@ -169,6 +185,11 @@ func BuildPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	policy := getOrgPolicy(w, auth)
+	if policy == nil {
+		return
+	}
+
 	res := initClients(
 		initClientsParams{
 			w:             w,
@@ -181,6 +202,16 @@ func BuildPlanHandler(w http.ResponseWriter, r *http.Request) {
 			orgUserConfig: orgUserConfig,
 		},
 	)
+
+	if res.clients == nil {
+		return
+	}
+
+	release, ok := acquireRateLimit(w, r, auth)
+	if !ok {
+		return
+	}
+
 	numBuilds, err := modelPlan.Build(modelPlan.BuildParams{
 		Clients:       res.clients,
 		AuthVars:      res.authVars,
@@ -190,7 +221,18 @@ func BuildPlanHandler(w http.ResponseWriter, r *http.Request) {
 		SessionId:     requestBody.SessionId,
 		OrgUserConfig: orgUserConfig,
 		Settings:      settings,
+		ExecAllowed:   auth.HasPermission(shared.PermissionExecCommands) && !policy.DisableExec,
 	})
+	release()
+
+	if errors.Is(err, modelPlan.ErrApplyScriptNotAllowed) {
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusForbidden,
+			Msg:    "This plan has pending commands in _apply.sh, but executing commands isn't allowed by your org role or org policy. Reject _apply.sh with 'plandex reject _apply.sh' to build the rest of the plan.",
+		})
+		return
+	}
 
 	if err != nil {
 		log.Printf("Error building plan: %v\n", err)
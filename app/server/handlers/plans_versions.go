@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"net/http"
@@ -79,6 +80,74 @@ func ListLogsHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Successfully processed request for ListLogsHandler")
 }
 
+func GetLogDiffHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetLogDiffHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	plain := r.URL.Query().Get("plain") == "true"
+
+	log.Println("planId: ", planId, "branch: ", branch, "from: ", from, "to: ", to)
+
+	if from == "" {
+		http.Error(w, "from is required", http.StatusBadRequest)
+		return
+	}
+
+	if authorizePlan(w, planId, auth) == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+
+	var res *shared.PlanLogDiffResponse
+
+	err := db.ExecRepoOperation(db.ExecRepoOperationParams{
+		OrgId:    auth.OrgId,
+		UserId:   auth.User.Id,
+		PlanId:   planId,
+		Branch:   branch,
+		Reason:   "log diff",
+		Scope:    db.LockScopeRead,
+		Ctx:      ctx,
+		CancelFn: cancel,
+	}, func(repo *db.GitRepo) error {
+		var err error
+		res, err = db.GetPlanLogDiff(repo, branch, from, to, plain)
+		return err
+	})
+
+	if err != nil {
+		log.Println("Error getting log diff: ", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, db.ErrPlanCommitNotFound) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, "Error getting log diff: "+err.Error(), status)
+		return
+	}
+
+	bytes, err := json.Marshal(res)
+
+	if err != nil {
+		log.Println("Error marshalling log diff: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully processed request for GetLogDiffHandler")
+}
+
 func RewindPlanHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Received request for RewindPlanHandler")
 
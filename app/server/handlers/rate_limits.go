@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	modelPlan "plandex-server/model/plan"
+	"plandex-server/ratelimit"
+	"plandex-server/types"
+)
+
+func GetRateLimitStatusHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetRateLimitStatusHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	status := ratelimit.GetStatus(auth.OrgId, auth.User.Id, countActivePlansFn(auth))
+
+	bytes, err := json.Marshal(status)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully got rate limit status")
+}
+
+// acquireRateLimit waits for or rejects a new plan stream based on the user's and org's limits. Call release once the stream has been started.
+func acquireRateLimit(w http.ResponseWriter, r *http.Request, auth *types.ServerAuth) (release func(), ok bool) {
+	release, apiErr := ratelimit.Acquire(ratelimit.AcquireParams{
+		Ctx:         r.Context(),
+		OrgId:       auth.OrgId,
+		UserId:      auth.User.Id,
+		CountActive: countActivePlansFn(auth),
+	})
+
+	if apiErr != nil {
+		writeApiError(w, *apiErr)
+		return nil, false
+	}
+
+	return release, true
+}
+
+func countActivePlansFn(auth *types.ServerAuth) ratelimit.CountActiveFn {
+	return func() (int, int) {
+		return modelPlan.CountActivePlans(auth.OrgId, auth.User.Id)
+	}
+}
@@ -112,6 +112,14 @@ func UpdateSettingsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !authorizeModelPack(w, auth, req) {
+		return
+	}
+
+	if !authorizeModelPackPolicy(w, auth, req) {
+		return
+	}
+
 	if req.ModelPackName != "" {
 		if mp, builtIn := shared.BuiltInModelPacksByName[req.ModelPackName]; builtIn {
 			if os.Getenv("IS_CLOUD") != "" && mp.LocalProvider != "" {
@@ -275,6 +283,14 @@ func UpdateDefaultSettingsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !authorizeModelPack(w, auth, req) {
+		return
+	}
+
+	if !authorizeModelPackPolicy(w, auth, req) {
+		return
+	}
+
 	var originalSettings *shared.PlanSettings
 	var settings *shared.PlanSettings
 
@@ -7,7 +7,6 @@ import (
 	"net/http"
 	"os"
 	"plandex-server/db"
-	"strings"
 
 	shared "plandex-shared"
 
@@ -137,9 +136,15 @@ func DeleteOrgUserHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// ensure current user can remove target user
-	removePermission := shared.Permission(strings.Join([]string{string(shared.PermissionRemoveUser), orgUser.OrgRoleId}, "|"))
+	canRemove, err := canActOnOrgRole(auth, shared.PermissionRemoveUser, orgUser.OrgRoleId)
 
-	if !auth.HasPermission(removePermission) {
+	if err != nil {
+		log.Printf("Error checking remove permission: %v\n", err)
+		http.Error(w, "Error checking remove permission: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !canRemove {
 		log.Printf("User does not have permission to remove user with role: %v\n", orgUser.OrgRoleId)
 		http.Error(w, "User does not have permission to remove user with role: "+orgUser.OrgRoleId, http.StatusForbidden)
 		return
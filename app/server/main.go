@@ -32,6 +32,8 @@ func main() {
 	routes.AddApiRoutes(r)
 	routes.AddProxyableApiRoutes(r)
 	setup.MustLoadIp()
+	setup.MustLoadRateLimits()
+	setup.MustInitTelemetry()
 	setup.MustInitDb()
 	setup.StartServer(r, nil, nil)
 	os.Exit(0)
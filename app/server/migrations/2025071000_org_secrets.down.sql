@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'manage_org_secrets';
+
+DROP TABLE IF EXISTS org_secrets;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS org_secrets (
+  id                 UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id             UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  name               VARCHAR(255) NOT NULL,
+
+  -- envelope encryption: the value is encrypted with a per-secret data key,
+  -- and the data key is encrypted with the server's master key
+  encrypted_data_key BYTEA NOT NULL,
+  encrypted_value    BYTEA NOT NULL,
+
+  created_by         UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at         TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at         TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS org_secrets_unique_idx ON org_secrets(org_id, name);
+CREATE TRIGGER org_secrets_modtime BEFORE UPDATE ON org_secrets
+  FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO permissions (name, description, resource_id) VALUES
+  ('manage_org_secrets', 'Add, update, or remove provider credentials in the org vault', NULL);
+
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT r.id, p.id
+FROM org_roles r, permissions p
+WHERE r.org_id IS NULL
+  AND r.name IN ('owner', 'admin')
+  AND p.name = 'manage_org_secrets';
@@ -0,0 +1,16 @@
+-- users and invites with custom roles fall back to the built-in member role
+UPDATE orgs_users SET org_role_id = (SELECT id FROM org_roles WHERE org_id IS NULL AND name = 'member')
+WHERE org_role_id IN (SELECT id FROM org_roles WHERE org_id IS NOT NULL);
+UPDATE invites SET org_role_id = (SELECT id FROM org_roles WHERE org_id IS NULL AND name = 'member')
+WHERE org_role_id IN (SELECT id FROM org_roles WHERE org_id IS NOT NULL);
+DELETE FROM org_roles WHERE org_id IS NOT NULL;
+
+DELETE FROM permissions WHERE name IN (
+  'manage_org_roles',
+  'apply_plan',
+  'exec_commands',
+  'select_any_model_pack',
+  'select_approved_model_pack'
+);
+
+ALTER TABLE org_roles DROP COLUMN IF EXISTS approved_model_packs;
@@ -0,0 +1,22 @@
+ALTER TABLE org_roles ADD COLUMN approved_model_packs JSON;
+
+INSERT INTO permissions (name, description, resource_id) VALUES
+  ('manage_org_roles', 'Create, update, or delete custom org roles and assign them to users', NULL),
+  ('apply_plan', 'Apply pending changes to project files', NULL),
+  ('exec_commands', 'Execute commands after applying changes', NULL),
+  ('select_any_model_pack', 'Select any model pack', NULL),
+  ('select_approved_model_pack', 'Select model packs approved for the role', NULL);
+
+-- built-in roles keep their existing behavior
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT r.id, p.id
+FROM org_roles r, permissions p
+WHERE r.org_id IS NULL
+  AND p.name IN ('apply_plan', 'exec_commands', 'select_any_model_pack');
+
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT r.id, p.id
+FROM org_roles r, permissions p
+WHERE r.org_id IS NULL
+  AND r.name IN ('owner', 'admin')
+  AND p.name = 'manage_org_roles';
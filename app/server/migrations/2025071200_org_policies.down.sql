@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'manage_org_policy';
+
+DROP TABLE IF EXISTS org_policies;
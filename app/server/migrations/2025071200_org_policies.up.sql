@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS org_policies (
+  org_id     UUID PRIMARY KEY REFERENCES orgs(id) ON DELETE CASCADE,
+  policy     JSON NOT NULL,
+  updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER org_policies_modtime BEFORE UPDATE ON org_policies
+  FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO permissions (name, description, resource_id) VALUES
+  ('manage_org_policy', 'Set the org policy for allowed providers, model packs, auto mode, command execution, and context size', NULL);
+
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT r.id, p.id
+FROM org_roles r, permissions p
+WHERE r.org_id IS NULL
+  AND r.name IN ('owner', 'admin')
+  AND p.name = 'manage_org_policy';
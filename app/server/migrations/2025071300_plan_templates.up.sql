@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS plan_templates (
+  id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id     UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  name       VARCHAR(255) NOT NULL,
+  template   JSON NOT NULL,
+  updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  UNIQUE (org_id, name)
+);
+CREATE TRIGGER plan_templates_modtime BEFORE UPDATE ON plan_templates
+  FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO permissions (name, description, resource_id) VALUES
+  ('manage_plan_templates', 'Create, update, or delete org plan templates', NULL);
+
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT r.id, p.id
+FROM org_roles r, permissions p
+WHERE r.org_id IS NULL
+  AND r.name IN ('owner', 'admin')
+  AND p.name = 'manage_plan_templates';
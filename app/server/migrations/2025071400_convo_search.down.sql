@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS plans_name_search_idx;
+DROP INDEX IF EXISTS convo_summaries_search_idx;
+
+DROP TABLE IF EXISTS convo_messages_search;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS convo_messages_search (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  plan_id UUID NOT NULL REFERENCES plans(id) ON DELETE CASCADE,
+  branch_id UUID NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+  convo_message_id UUID NOT NULL,
+  role VARCHAR(255) NOT NULL,
+  num INTEGER NOT NULL,
+  message TEXT NOT NULL,
+  search_vector TSVECTOR GENERATED ALWAYS AS (to_tsvector('english', message)) STORED,
+  message_created_at TIMESTAMP NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  UNIQUE (branch_id, convo_message_id)
+);
+CREATE TRIGGER convo_messages_search_modtime BEFORE UPDATE ON convo_messages_search
+  FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX convo_messages_search_vector_idx ON convo_messages_search USING GIN (search_vector);
+CREATE INDEX convo_messages_search_plan_idx ON convo_messages_search(plan_id);
+
+CREATE INDEX convo_summaries_search_idx ON convo_summaries USING GIN (to_tsvector('english', summary));
+CREATE INDEX plans_name_search_idx ON plans USING GIN (to_tsvector('english', name));
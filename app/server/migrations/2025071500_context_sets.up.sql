@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS context_sets (
+  id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id      UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  project_id  UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  name        VARCHAR(255) NOT NULL,
+  context_set JSON NOT NULL,
+  updated_by  UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+  UNIQUE (project_id, name)
+);
+CREATE TRIGGER context_sets_modtime BEFORE UPDATE ON context_sets
+  FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS convo_search_backfills;
@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS convo_search_backfills (
+  plan_id    UUID PRIMARY KEY REFERENCES plans(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
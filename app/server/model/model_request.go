@@ -7,6 +7,8 @@ import (
 	"plandex-server/db"
 	"plandex-server/hooks"
 	"plandex-server/notify"
+	"plandex-server/ratelimit"
+	"plandex-server/telemetry"
 	"plandex-server/types"
 	shared "plandex-shared"
 	"runtime/debug"
@@ -170,9 +172,17 @@ func ModelRequest(
 		}
 	}
 
-	res, err := CreateChatCompletionWithInternalStream(clients, authVars, modelConfig, settings, orgUserConfig, currentOrgId, currentUserId, ctx, req, onStream, reqStarted)
+	spanCtx, span := telemetry.StartSpan(ctx, "model.request", telemetry.ModelAttrs(modelConfig.Role, baseModelConfig.ModelId, baseModelConfig.Provider, purpose)...)
+	res, err := CreateChatCompletionWithInternalStream(clients, authVars, modelConfig, settings, orgUserConfig, currentOrgId, currentUserId, spanCtx, req, onStream, reqStarted)
+	telemetry.EndSpan(span, err)
 
 	if err != nil {
+		telemetry.ObserveModelRequest(telemetry.ModelRequestMetrics{
+			Role:      string(modelConfig.Role),
+			Provider:  string(baseModelConfig.Provider),
+			StartedAt: reqStarted,
+			HadError:  true,
+		})
 		return nil, err
 	}
 
@@ -215,6 +225,16 @@ func ModelRequest(
 		}
 	}
 
+	ratelimit.RecordTokens(currentOrgId, currentUserId, inputTokens+outputTokens)
+	telemetry.ObserveModelRequest(telemetry.ModelRequestMetrics{
+		Role:         string(modelConfig.Role),
+		Provider:     string(baseModelConfig.Provider),
+		StartedAt:    reqStarted,
+		FirstTokenAt: res.FirstTokenAt,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+	})
+
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
@@ -1,6 +1,7 @@
 package plan
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -25,8 +26,12 @@ type BuildParams struct {
 	SessionId     string
 	OrgUserConfig *shared.OrgUserConfig
 	Settings      *shared.PlanSettings
+	// false if the user's org role or the org policy doesn't allow executing commands
+	ExecAllowed bool
 }
 
+var ErrApplyScriptNotAllowed = errors.New("executing commands isn't allowed, so _apply.sh can't be built")
+
 func Build(params BuildParams) (int, error) {
 	clients := params.Clients
 	authVars := params.AuthVars
@@ -76,6 +81,11 @@ func Build(params BuildParams) (int, error) {
 		return 0, nil
 	}
 
+	// commands written before exec was disabled for this user shouldn't make it into a script they can apply
+	if _, ok := pendingBuildsByPath["_apply.sh"]; ok && !params.ExecAllowed {
+		return onErr(ErrApplyScriptNotAllowed)
+	}
+
 	err = db.SetPlanStatus(plan.Id, branch, shared.PlanStatusBuilding, "")
 
 	if err != nil {
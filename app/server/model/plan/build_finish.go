@@ -8,6 +8,7 @@ import (
 	"plandex-server/db"
 	"plandex-server/hooks"
 	"plandex-server/notify"
+	"plandex-server/telemetry"
 	"plandex-server/types"
 	"strings"
 	"time"
@@ -289,6 +290,10 @@ func (fileState *activeBuildStreamFileState) onBuildFileError(err error) {
 
 	log.Printf("Error for file %s: %v\n", filePath, err)
 
+	if fileState.span != nil {
+		telemetry.RecordSpanError(fileState.span, err)
+	}
+
 	activeBuild.Success = false
 	activeBuild.Error = err
 
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"plandex-server/syntax"
+	"plandex-server/telemetry"
 	"plandex-server/utils"
 	"runtime"
 	"runtime/debug"
@@ -16,6 +17,8 @@ import (
 type raceResult struct {
 	content string
 	valid   bool
+	// which build strategy produced the result, for metrics
+	source string
 }
 
 type buildRaceParams struct {
@@ -106,7 +109,7 @@ func (fileState *activeBuildStreamFileState) buildRace(
 				sendErr(fmt.Errorf("error building whole file: %w", err))
 			} else {
 				log.Printf("buildRace - whole file build succeeded")
-				sendRes(raceResult{content: content, valid: true})
+				sendRes(raceResult{content: content, valid: true, source: "whole_file"})
 			}
 		}()
 	}
@@ -189,7 +192,7 @@ func (fileState *activeBuildStreamFileState) buildRace(
 			if validateResult.valid {
 				log.Printf("buildRace - fast apply validation succeeded")
 				fileState.builderRun.FastApplySuccess = true
-				sendRes(raceResult{content: validateResult.updated, valid: validateResult.valid})
+				sendRes(raceResult{content: validateResult.updated, valid: validateResult.valid, source: "fast_apply"})
 			} else {
 				log.Printf("buildRace - fast apply validation failed with problem: %s", validateResult.problem)
 				fileState.builderRun.FastApplyFailureResponse = validateResult.problem
@@ -260,7 +263,7 @@ func (fileState *activeBuildStreamFileState) buildRace(
 			log.Printf("buildRace - validation loop finished, valid: %v", validateResult.valid)
 			if validateResult.valid {
 				log.Printf("buildRace - validation loop succeeded, valid: %v", validateResult.valid)
-				sendRes(raceResult{content: validateResult.updated, valid: validateResult.valid})
+				sendRes(raceResult{content: validateResult.updated, valid: validateResult.valid, source: "validation"})
 			} else {
 				log.Printf("buildRace - validation loop failed, valid: %v", validateResult.valid)
 				sendErr(fmt.Errorf("validation loop failed: %s", validateResult.problem))
@@ -286,6 +289,7 @@ func (fileState *activeBuildStreamFileState) buildRace(
 
 			if errChNumReceived >= maxErrs {
 				log.Printf("buildRace - all attempts failed with %d errors", len(errs))
+				telemetry.IncBuildRaceOutcome("failed")
 				return raceResult{}, fmt.Errorf("all build attempts failed: %v", errs)
 			}
 
@@ -295,6 +299,7 @@ func (fileState *activeBuildStreamFileState) buildRace(
 			}
 		case res := <-resCh:
 			log.Printf("buildRace - got successful result")
+			telemetry.IncBuildRaceOutcome(res.source)
 			return res, nil
 		}
 	}
@@ -9,6 +9,7 @@ import (
 	shared "plandex-shared"
 
 	sitter "github.com/smacker/go-tree-sitter"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const MaxBuildErrorRetries = 3 // uses semi-exponential backoff so be careful with this
@@ -46,4 +47,5 @@ type activeBuildStreamFileState struct {
 	contextPart                *db.Context
 
 	builderRun hooks.DidFinishBuilderRunParams
+	span       trace.Span
 }
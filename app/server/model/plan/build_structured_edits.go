@@ -8,6 +8,7 @@ import (
 	diff_pkg "plandex-server/diff"
 	"plandex-server/hooks"
 	"plandex-server/syntax"
+	"plandex-server/telemetry"
 	"plandex-server/utils"
 	"runtime"
 	"runtime/debug"
@@ -15,6 +16,8 @@ import (
 	"time"
 
 	shared "plandex-shared"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 func (fileState *activeBuildStreamFileState) buildStructuredEdits() {
@@ -36,7 +39,11 @@ func (fileState *activeBuildStreamFileState) buildStructuredEdits() {
 		return
 	}
 
-	buildCtx, cancelBuild := context.WithCancel(activePlan.Ctx)
+	spanCtx, span := telemetry.StartSpan(activePlan.Ctx, "build.file", append(telemetry.PlanAttrs(planId, branch), attribute.String("plandex.path", filePath))...)
+	fileState.span = span
+	defer span.End()
+
+	buildCtx, cancelBuild := context.WithCancel(spanCtx)
 
 	proposedContent := activeBuild.FileContent
 	desc := activeBuild.FileDescription
@@ -139,6 +146,7 @@ func (fileState *activeBuildStreamFileState) buildStructuredEdits() {
 	if autoApplyIsValid {
 		log.Printf("buildStructuredEdits - %s - changes are valid, using ApplyChanges result\n", filePath)
 		fileState.builderRun.AutoApplySuccess = true
+		telemetry.IncBuildRaceOutcome("auto_apply")
 	} else {
 		log.Printf("buildStructuredEdits - %s - auto apply has syntax errors or NeedsVerifyReasons", filePath)
 		fileState.builderRun.AutoApplyValidationReasons = make([]string, len(autoApplyRes.NeedsVerifyReasons))
@@ -10,6 +10,7 @@ import (
 	"plandex-server/model"
 	"plandex-server/model/prompts"
 	"plandex-server/syntax"
+	"plandex-server/telemetry"
 	"plandex-server/types"
 	"plandex-server/utils"
 	shared "plandex-shared"
@@ -137,6 +138,7 @@ func (fileState *activeBuildStreamFileState) buildValidateLoop(
 
 		if res.valid && len(syntaxErrors) == 0 {
 			log.Printf("Validation succeeded in attempt %d", currentAttempt)
+			telemetry.ObserveBuildValidationAttempts(currentAttempt, true)
 			return buildValidateLoopResult{
 				valid:   res.valid,
 				updated: res.updated,
@@ -151,6 +153,7 @@ func (fileState *activeBuildStreamFileState) buildValidateLoop(
 	}
 
 	log.Printf("Validation failed after %d attempts", MaxValidationFixAttempts)
+	telemetry.ObserveBuildValidationAttempts(numAttempts, false)
 	return buildValidateLoopResult{
 		valid:   false,
 		updated: updated,
@@ -456,6 +459,7 @@ func (fileState *activeBuildStreamFileState) validationRetryOrError(buildCtx con
 	log.Printf("Handling validation error for file: %s", fileState.filePath)
 	if fileState.validationNumRetry < MaxBuildErrorRetries {
 		fileState.validationNumRetry++
+		telemetry.IncBuildValidationErrorRetries()
 
 		log.Printf("Retrying validation (attempt %d/%d) due to error: %v",
 			fileState.validationNumRetry, MaxBuildErrorRetries, err)
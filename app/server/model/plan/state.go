@@ -7,6 +7,7 @@ import (
 	"plandex-server/db"
 	"plandex-server/notify"
 	"plandex-server/shutdown"
+	"plandex-server/telemetry"
 	"plandex-server/types"
 	"strings"
 	"time"
@@ -34,6 +35,15 @@ func CreateActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, a
 			case <-activePlan.Ctx.Done():
 				log.Printf("case <-activePlan.Ctx.Done(): %s\n", planId)
 
+				var spanErr error
+				outcome := "stopped"
+				if activePlan.Ctx.Err() == context.DeadlineExceeded {
+					spanErr = activePlan.Ctx.Err()
+					outcome = "timeout"
+				}
+				telemetry.ObserveStreamDuration(activePlan.BuildOnly, outcome, activePlan.StartedAt)
+				telemetry.EndSpan(activePlan.Span, spanErr)
+
 				err := db.SetPlanStatus(planId, branch, shared.PlanStatusStopped, "")
 				if err != nil {
 					log.Printf("Error setting plan %s status to stopped: %v\n", planId, err)
@@ -49,6 +59,9 @@ func CreateActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, a
 				if apiErr == nil {
 					log.Printf("Plan %s stream completed successfully", planId)
 
+					telemetry.ObserveStreamDuration(activePlan.BuildOnly, "finished", activePlan.StartedAt)
+					telemetry.EndSpan(activePlan.Span, nil)
+
 					err := db.SetPlanStatus(planId, branch, shared.PlanStatusFinished, "")
 					if err != nil {
 						log.Printf("Error setting plan %s status to ready: %v\n", planId, err)
@@ -62,6 +75,9 @@ func CreateActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, a
 				} else {
 					log.Printf("Error streaming plan %s: %v\n", planId, apiErr)
 
+					telemetry.ObserveStreamDuration(activePlan.BuildOnly, "error", activePlan.StartedAt)
+					telemetry.EndSpan(activePlan.Span, fmt.Errorf("%s", apiErr.Msg))
+
 					go notify.NotifyErr(notify.SeverityError, fmt.Errorf("error streaming plan %s: %v", planId, apiErr))
 
 					err := db.SetPlanStatus(planId, branch, shared.PlanStatusError, apiErr.Msg)
@@ -176,3 +192,18 @@ func UnsubscribePlan(planId, branch, subscriptionId string) {
 func NumActivePlans() int {
 	return activePlans.Len()
 }
+
+// CountActivePlans returns the number of active plans on this host started by the user and by the org
+func CountActivePlans(orgId, userId string) (int, int) {
+	var userActive, orgActive int
+	for _, activePlan := range activePlans.Values() {
+		if activePlan.OrgId != orgId {
+			continue
+		}
+		orgActive++
+		if activePlan.UserId == userId {
+			userActive++
+		}
+	}
+	return userActive, orgActive
+}
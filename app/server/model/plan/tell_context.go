@@ -24,6 +24,8 @@ type formatModelContextParams struct {
 	activatePaths        map[string]bool
 	activatePathsOrdered []string
 	maxTokens            int
+	// when set, context is ranked by relevance and pruned to fit this many tokens
+	pruneBudget int
 }
 
 func (state *activeTellStreamState) formatModelContext(params formatModelContextParams) []*types.ExtendedChatMessagePart {
@@ -77,17 +79,7 @@ func (state *activeTellStreamState) formatModelContext(params formatModelContext
 
 	totalTokens := 0
 
-	type toLoad struct {
-		FilePath    string
-		Name        string
-		Url         string
-		NumTokens   int
-		Body        string
-		ContextType shared.ContextType
-		ImageDetail openai.ImageURLDetail
-		IsPending   bool
-	}
-	var toLoadAll []toLoad
+	var toLoadAll []contextToLoad
 
 	for _, part := range state.modelContext {
 		if verboseLogging {
@@ -109,7 +101,7 @@ func (state *activeTellStreamState) formatModelContext(params formatModelContext
 			}
 		}
 
-		if currentStage.TellStage == shared.TellStageImplementation && smartContextEnabled && state.currentSubtask != nil && part.ContextType == shared.ContextFileType && !uses[part.FilePath] {
+		if currentStage.TellStage == shared.TellStageImplementation && smartContextEnabled && state.currentSubtask != nil && (part.ContextType == shared.ContextFileType || part.ContextType == shared.ContextFileRangeType) && !uses[part.FilePath] {
 			if verboseLogging {
 				log.Println("Tell plan - formatModelContext - skipping part -- currentStage.TellStage == shared.TellStageImplementation && smartContextEnabled && state.currentSubtask != nil && part.ContextType == shared.ContextFileType && !uses[part.FilePath]")
 			}
@@ -130,7 +122,7 @@ func (state *activeTellStreamState) formatModelContext(params formatModelContext
 			continue
 		}
 
-		toLoadAll = append(toLoadAll, toLoad{
+		toLoadAll = append(toLoadAll, contextToLoad{
 			FilePath:    part.FilePath,
 			NumTokens:   part.NumTokens,
 			Body:        part.Body,
@@ -138,6 +130,9 @@ func (state *activeTellStreamState) formatModelContext(params formatModelContext
 			Name:        part.Name,
 			Url:         part.Url,
 			ImageDetail: part.ImageDetail,
+			StartLine:   part.StartLine,
+			EndLine:     part.EndLine,
+			UpdatedAt:   part.UpdatedAt,
 		})
 
 		if part.ContextType == shared.ContextFileType {
@@ -170,7 +165,7 @@ func (state *activeTellStreamState) formatModelContext(params formatModelContext
 
 			numTokens := shared.GetNumTokensEstimate(body)
 
-			toLoadAll = append(toLoadAll, toLoad{
+			toLoadAll = append(toLoadAll, contextToLoad{
 				FilePath:    filePath,
 				NumTokens:   numTokens,
 				Body:        body,
@@ -215,6 +210,30 @@ func (state *activeTellStreamState) formatModelContext(params formatModelContext
 		})
 	}
 
+	if params.pruneBudget > 0 {
+		// files the current subtask uses, files activated by the reply, and files with pending changes are always kept
+		keepPaths := map[string]bool{}
+		for path := range uses {
+			keepPaths[path] = true
+		}
+		for path := range activatePaths {
+			keepPaths[path] = true
+		}
+		for path := range pendingFiles {
+			keepPaths[path] = true
+		}
+
+		var pruned []*shared.PrunedContext
+		toLoadAll, pruned = state.pruneContext(toLoadAll, params.pruneBudget, keepPaths)
+
+		if len(pruned) > 0 && state.activePlan != nil {
+			state.activePlan.Stream(shared.StreamMessage{
+				Type:          shared.StreamMessagePrunedContext,
+				PrunedContext: pruned,
+			})
+		}
+	}
+
 	for _, part := range toLoadAll {
 		totalTokens += part.NumTokens
 
@@ -259,6 +278,33 @@ func (state *activeTellStreamState) formatModelContext(params formatModelContext
 
 				args = append(args, part.FilePath, body)
 			}
+		} else if part.ContextType == shared.ContextFileRangeType {
+			// the rest of the file isn't shown to the model - if the file has pending changes, the full pending file is included separately
+			numLines := strings.Count(part.Body, "\n") + 1
+			fmtStr = "\n\n- %s | lines %d-%d of %d (only this part of the file is loaded in context):\n\n```\n%s\n```"
+			args = append(args, part.FilePath, part.StartLine, min(part.EndLine, numLines), numLines, shared.GetFileRangeBody(part.Body, part.StartLine, part.EndLine))
+		} else if part.ContextType == shared.ContextGitDiffType {
+			fmtStr = "\n\n- %s:\n\n```diff\n%s\n```"
+			args = append(args, part.Name, part.Body)
+		} else if part.ContextType == shared.ContextDocumentType {
+			// text extracted from a pdf, docx, or odt file - the body starts with the pages or sections it covers
+			fmtStr = "\n\n- %s | document:\n\n```\n%s\n```"
+			args = append(args, part.Name, part.Body)
+		} else if part.ContextType == shared.ContextDocsType {
+			// pages crawled from a documentation site, converted to markdown
+			fmtStr = "\n\n- %s | docs crawled from %s:\n\n```\n%s\n```"
+			args = append(args, part.Name, part.Url, part.Body)
+		} else if part.ContextType == shared.ContextCommandType {
+			// the name is the command - the body is its output from the last time context was checked
+			fmtStr = "\n\n- %s | command output:\n\n```\n%s\n```"
+			args = append(args, part.Name, part.Body)
+		} else if part.ContextType == shared.ContextDbSchemaType {
+			// tables, columns, keys, and indexes introspected from a database - the name is its connection string with any password removed
+			fmtStr = "\n\n- %s | database schema:\n\n```\n%s\n```"
+			args = append(args, part.Name, part.Body)
+		} else if part.ContextType == shared.ContextMapType && part.Downgraded {
+			fmtStr = "\n\n- %s | map (the full file was left out of context to fit the token limit):\n\n```\n%s\n```"
+			args = append(args, part.FilePath, part.Body)
 		} else if part.ContextType == shared.ContextMapType {
 			fmtStr = "\n\n- %s | map:\n\n```\n%s\n```"
 			args = append(args, part.FilePath, part.Body)
@@ -367,6 +413,9 @@ func (state *activeTellStreamState) formatModelContext(params formatModelContext
 
 var pathRegex = regexp.MustCompile("`(.+?)`")
 
+// api spec items and other parts of files can be loaded on their own with 'path::Symbol' or 'path#L120-240'
+var fileRangeRefRegex = regexp.MustCompile(`^(.+?)(?:::[A-Za-z_$][\w$.-]*|#L\d+(?:-L?\d+)?)$`)
+
 type checkAutoLoadContextResult struct {
 	autoLoadPaths        []string
 	activatePaths        map[string]bool
@@ -394,6 +443,13 @@ func (state *activeTellStreamState) checkAutoLoadContext() checkAutoLoadContextR
 
 	log.Printf("%d existing contexts by path\n", len(contextsByPath))
 
+	loadedRanges := map[string]bool{}
+	for _, context := range activePlan.Contexts {
+		if context.ContextType == shared.ContextFileRangeType {
+			loadedRanges[context.Name] = true
+		}
+	}
+
 	// pick out all potential file paths within backticks
 	matches := pathRegex.FindAllStringSubmatch(activePlan.CurrentReplyContent, -1)
 
@@ -421,6 +477,21 @@ func (state *activeTellStreamState) checkAutoLoadContext() checkAutoLoadContextR
 				}
 
 			}
+		} else if m := fileRangeRefRegex.FindStringSubmatch(trimmed); m != nil && req.ProjectPaths[m[1]] {
+			path := m[1]
+			if !allSet[trimmed] {
+				allFiles = append(allFiles, trimmed)
+				allSet[trimmed] = true
+
+				if !toActivate[path] {
+					toActivate[path] = true
+					toActivateOrdered = append(toActivateOrdered, path)
+				}
+				// the whole file may already be loaded, in which case the range isn't needed
+				if !loadedRanges[trimmed] && (contextsByPath[path] == nil || contextsByPath[path].ContextType == shared.ContextFileRangeType) {
+					toAutoLoad[trimmed] = true
+				}
+			}
 		}
 	}
 
@@ -0,0 +1,258 @@
+package plan
+
+import (
+	"log"
+	"path/filepath"
+	"plandex-server/db"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+type contextToLoad struct {
+	FilePath    string
+	Name        string
+	Url         string
+	NumTokens   int
+	Body        string
+	ContextType shared.ContextType
+	ImageDetail openai.ImageURLDetail
+	StartLine   int
+	EndLine     int
+	IsPending   bool
+	UpdatedAt   time.Time
+	// a file that's sent as its map to fit the token budget
+	Downgraded bool
+}
+
+// only the most recent messages are checked for references
+const pruneMaxConvoMessages = 50
+
+var identifierRegex = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]{3,}`)
+
+// notes, piped data, images, and maps are never pruned - maps are what pruned files fall back to
+var prunableContextTypes = map[shared.ContextType]bool{
+	shared.ContextFileType:          true,
+	shared.ContextFileRangeType:     true,
+	shared.ContextDirectoryTreeType: true,
+	shared.ContextURLType:           true,
+	shared.ContextGitDiffType:       true,
+	shared.ContextDocumentType:      true,
+	shared.ContextDocsType:          true,
+	shared.ContextCommandType:       true,
+	shared.ContextDbSchemaType:      true,
+}
+
+// pruneContext fits context into a token budget. Parts are ranked by relevance to the prompt and current subtask, then the least relevant files are downgraded to their maps, and if that isn't enough, the least relevant parts are left out. Parts for paths in keepPaths are never pruned.
+func (state *activeTellStreamState) pruneContext(parts []contextToLoad, budget int, keepPaths map[string]bool) ([]contextToLoad, []*shared.PrunedContext) {
+	total := 0
+	for _, part := range parts {
+		total += part.NumTokens
+	}
+	if total <= budget {
+		return parts, nil
+	}
+
+	log.Printf("Tell plan - pruneContext - %d tokens of context exceeds budget of %d\n", total, budget)
+
+	includedMaps := map[string]bool{}
+	for _, part := range parts {
+		if part.ContextType == shared.ContextMapType {
+			includedMaps[part.FilePath] = true
+		}
+	}
+
+	mapPartsByPath := map[string]string{}
+	// files whose map is already part of this context can just be left out
+	inIncludedMap := map[string]bool{}
+	for _, c := range state.modelContext {
+		if c.ContextType == shared.ContextMapType {
+			for path, body := range c.MapParts {
+				mapPartsByPath[path] = body
+				if includedMaps[c.FilePath] {
+					inIncludedMap[path] = true
+				}
+			}
+		}
+	}
+
+	focusText := state.userPrompt
+	if state.currentSubtask != nil {
+		focusText += "\n" + state.currentSubtask.Title + "\n" + state.currentSubtask.Description + "\n" + strings.Join(state.currentSubtask.UsesFiles, "\n")
+	}
+	focusIds := map[string]bool{}
+	for _, id := range identifierRegex.FindAllString(focusText, -1) {
+		focusIds[id] = true
+	}
+
+	// newest first
+	var convo []*db.ConvoMessage
+	for i := len(state.convo) - 1; i >= 0 && len(convo) < pruneMaxConvoMessages; i-- {
+		convo = append(convo, state.convo[i])
+	}
+
+	type candidate struct {
+		index int
+		score float64
+	}
+	var candidates []candidate
+	for i, part := range parts {
+		if !prunableContextTypes[part.ContextType] || part.IsPending || (part.FilePath != "" && keepPaths[part.FilePath]) {
+			continue
+		}
+		score := contextRelevance(part, mapPartsByPath[part.FilePath], focusText, focusIds, convo)
+		candidates = append(candidates, candidate{index: i, score: score})
+	}
+
+	// least relevant first - larger parts go first on ties since they free up more room
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score < candidates[j].score
+		}
+		return parts[candidates[i].index].NumTokens > parts[candidates[j].index].NumTokens
+	})
+
+	tokensSaved := map[int]int{}
+
+	// first downgrade files to their maps, which keeps their signatures in view
+	for _, c := range candidates {
+		if total <= budget {
+			break
+		}
+		part := &parts[c.index]
+		mapPart := mapPartsByPath[part.FilePath]
+		if part.ContextType != shared.ContextFileType || mapPart == "" {
+			continue
+		}
+		mapTokens := shared.GetNumTokensEstimate(mapPart)
+		if inIncludedMap[part.FilePath] {
+			mapTokens = 0
+		} else if mapTokens >= part.NumTokens {
+			continue
+		}
+
+		saved := part.NumTokens - mapTokens
+		part.ContextType = shared.ContextMapType
+		part.Body = mapPart
+		part.NumTokens = mapTokens
+		part.Downgraded = true
+		tokensSaved[c.index] = saved
+		total -= saved
+	}
+
+	// then leave out whole parts
+	dropped := map[int]bool{}
+	for i, part := range parts {
+		if part.Downgraded && inIncludedMap[part.FilePath] {
+			dropped[i] = true
+		}
+	}
+	for _, c := range candidates {
+		if total <= budget {
+			break
+		}
+		part := parts[c.index]
+		dropped[c.index] = true
+		tokensSaved[c.index] += part.NumTokens
+		total -= part.NumTokens
+	}
+
+	var pruned []*shared.PrunedContext
+	for _, c := range candidates {
+		saved, ok := tokensSaved[c.index]
+		if !ok {
+			continue
+		}
+		part := parts[c.index]
+		contextType := part.ContextType
+		if part.Downgraded {
+			contextType = shared.ContextFileType
+		}
+		name := part.Name
+		if part.FilePath != "" {
+			name = part.FilePath
+		}
+		pruned = append(pruned, &shared.PrunedContext{
+			Name:        name,
+			ContextType: contextType,
+			Downgraded:  part.Downgraded && (!dropped[c.index] || inIncludedMap[part.FilePath]),
+			TokensSaved: saved,
+		})
+	}
+
+	var res []contextToLoad
+	for i, part := range parts {
+		if !dropped[i] {
+			res = append(res, part)
+		}
+	}
+
+	log.Printf("Tell plan - pruneContext - pruned %d parts, %d tokens of context remaining\n", len(pruned), total)
+
+	return res, pruned
+}
+
+// contextRelevance scores how relevant a part of context is to the prompt and current subtask, based on mentions in the prompt and subtask, references in recent messages (weighted toward the latest), overlap between the file's map and identifiers in the prompt, and how recently the context changed
+func contextRelevance(part contextToLoad, mapPart, focusText string, focusIds map[string]bool, convo []*db.ConvoMessage) float64 {
+	var keys []string
+	if part.FilePath != "" {
+		keys = append(keys, part.FilePath)
+		// file names are often mentioned without their directory
+		if base := filepath.Base(part.FilePath); base != part.FilePath && len(base) >= 5 {
+			keys = append(keys, base)
+		}
+	}
+	if part.Url != "" {
+		keys = append(keys, part.Url)
+	}
+	if part.Name != "" && part.Name != part.FilePath {
+		keys = append(keys, part.Name)
+	}
+
+	mentioned := func(text string) bool {
+		for _, key := range keys {
+			if strings.Contains(text, key) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var score float64
+
+	if mentioned(focusText) {
+		score += 10
+	}
+
+	for age, msg := range convo {
+		weight := 1 / float64(1+age)
+		if part.FilePath != "" && msg.ActivatedPaths[part.FilePath] {
+			score += 3 * weight
+		}
+		if mentioned(msg.Message) {
+			score += 5 * weight
+		}
+	}
+
+	if mapPart != "" && len(focusIds) > 0 {
+		seen := map[string]bool{}
+		for _, id := range identifierRegex.FindAllString(mapPart, -1) {
+			if focusIds[id] && !seen[id] {
+				seen[id] = true
+			}
+		}
+		score += 0.5 * float64(min(len(seen), 10))
+	}
+
+	if !part.UpdatedAt.IsZero() {
+		days := time.Since(part.UpdatedAt).Hours() / 24
+		score += 1 / (1 + max(days, 0))
+	}
+
+	return score
+}
@@ -11,6 +11,7 @@ import (
 	"plandex-server/hooks"
 	"plandex-server/model"
 	"plandex-server/notify"
+	"plandex-server/telemetry"
 	"plandex-server/types"
 
 	shared "plandex-shared"
@@ -208,6 +209,15 @@ func execTellPlan(params execTellPlanParams) {
 		return
 	}
 
+	// with auto-prune-context, loaded context is pruned to fit what's left of the limit after the conversation and prompt
+	var pruneBudget int
+	if state.plan.PlanConfig != nil && state.plan.PlanConfig.AutoPruneContext {
+		pruneBudget = max(int(float64(tentativeMaxTokens-tokensWithoutContext)*0.95), 1) // leave a little extra room
+		if budget := state.plan.PlanConfig.ContextBudget; budget > 0 {
+			pruneBudget = min(pruneBudget, budget)
+		}
+	}
+
 	var planStageSharedMsgs []*types.ExtendedChatMessagePart
 	var planningPhaseOnlyMsgs []*types.ExtendedChatMessagePart
 	var implementationMsgs []*types.ExtendedChatMessagePart
@@ -217,6 +227,7 @@ func execTellPlan(params execTellPlanParams) {
 			includeMaps:         false,
 			smartContextEnabled: req.SmartContext,
 			includeApplyScript:  req.ExecEnabled,
+			pruneBudget:         pruneBudget,
 		})
 	} else if state.currentStage.TellStage == shared.TellStagePlanning {
 		// add the shared context between planning and context phases first so it can be cached
@@ -227,6 +238,7 @@ func execTellPlan(params execTellPlanParams) {
 			includeApplyScript:  req.ExecEnabled,
 			baseOnly:            true,
 			cacheControl:        true,
+			pruneBudget:         pruneBudget,
 		})
 
 		if state.currentStage.PlanningPhase == shared.PlanningPhaseTasks {
@@ -264,12 +276,26 @@ func execTellPlan(params execTellPlanParams) {
 					maxTokens:            int(float64(tokensRemaining) * 0.95), // leave a little extra room
 				})
 			} else {
+				// the auto contexts share the prune budget with what's already in planStageSharedMsgs
+				autoPruneBudget := pruneBudget
+				if pruneBudget > 0 {
+					msg := types.ExtendedChatMessage{
+						Role:    openai.ChatMessageRoleSystem,
+						Content: []types.ExtendedChatMessagePart{},
+					}
+					for _, part := range planStageSharedMsgs {
+						msg.Content = append(msg.Content, *part)
+					}
+					autoPruneBudget = max(pruneBudget-model.GetMessagesTokenEstimate(msg), 1)
+				}
+
 				// if auto context is disabled, just dump in any remaining auto contexts, since all basic contexts have already been added in planStageSharedMsgs
 				planningPhaseOnlyMsgs = state.formatModelContext(formatModelContextParams{
 					includeMaps:         false,
 					smartContextEnabled: req.SmartContext,
 					includeApplyScript:  false, // already included in planStageSharedMsgs
 					autoOnly:            true,
+					pruneBudget:         autoPruneBudget,
 				})
 			}
 		}
@@ -565,8 +591,12 @@ func (state *activeTellStreamState) doTellRequest() {
 		state.numErrorRetry, state.numFallbackRetry, baseModelConfig.ModelName)
 
 	// start the stream
-	stream, err := model.CreateChatCompletionStream(clients, authVars, modelConfig, state.settings, state.orgUserConfig, state.currentOrgId, state.currentUserId, active.ModelStreamCtx, modelReq)
+	streamCtx, span := telemetry.StartSpan(active.ModelStreamCtx, "model.request", telemetry.ModelAttrs(modelConfig.Role, baseModelConfig.ModelId, baseModelConfig.Provider, "Response")...)
+	state.requestSpan = span
+
+	stream, err := model.CreateChatCompletionStream(clients, authVars, modelConfig, state.settings, state.orgUserConfig, state.currentOrgId, state.currentUserId, streamCtx, modelReq)
 	if err != nil {
+		telemetry.EndSpan(span, err)
 		log.Printf("Error starting reply stream: %v\n", err)
 		go notify.NotifyErr(notify.SeverityError, fmt.Errorf("error starting reply stream: %v", err))
 		active.StreamDoneCh <- &shared.ApiError{
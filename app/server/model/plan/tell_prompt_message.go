@@ -177,6 +177,17 @@ func (state *activeTellStreamState) resolvePromptMessage(
 		}
 	}
 
+	// retrieval results are offered alongside the project map when choosing context
+	if state.currentStage.TellStage == shared.TellStagePlanning &&
+		state.currentStage.PlanningPhase == shared.PlanningPhaseContext &&
+		len(req.RetrievedChunks) > 0 {
+		log.Printf("Adding %d retrieved chunks to context phase prompt", len(req.RetrievedChunks))
+		promptMessage.Content = append(promptMessage.Content, types.ExtendedChatMessagePart{
+			Type: openai.ChatMessagePartTypeText,
+			Text: prompts.GetRetrievedChunksPrompt(req.RetrievedChunks),
+		})
+	}
+
 	// log.Println("Prompt message:", promptMessage.Content)
 
 	return promptMessage, true
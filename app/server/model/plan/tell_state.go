@@ -9,6 +9,7 @@ import (
 	shared "plandex-shared"
 
 	"github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type activeTellStreamState struct {
@@ -52,6 +53,7 @@ type activeTellStreamState struct {
 
 	requestStartedAt time.Time
 	firstTokenAt     time.Time
+	requestSpan      trace.Span
 	originalReq      *types.ExtendedChatCompletionRequest
 	modelConfig      *shared.ModelRoleConfig
 	baseModelConfig  *shared.BaseModelConfig
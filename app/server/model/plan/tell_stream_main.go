@@ -19,6 +19,7 @@ import (
 
 func (state *activeTellStreamState) listenStream(stream *model.ExtendedChatCompletionStream) {
 	defer stream.Close()
+	defer state.requestSpan.End()
 
 	plan := state.plan
 	planId := plan.Id
@@ -5,6 +5,8 @@ import (
 	"log"
 	"plandex-server/hooks"
 	"plandex-server/notify"
+	"plandex-server/ratelimit"
+	"plandex-server/telemetry"
 	"runtime/debug"
 
 	"github.com/davecgh/go-spew/spew"
@@ -29,6 +31,16 @@ func (state *activeTellStreamState) handleUsageChunk(usage *openai.Usage) {
 	modelConfig := state.modelConfig
 	baseModelConfig := modelConfig.GetBaseModelConfig(state.authVars, state.settings, state.orgUserConfig)
 
+	ratelimit.RecordTokens(auth.OrgId, auth.User.Id, usage.PromptTokens+usage.CompletionTokens)
+	telemetry.ObserveModelRequest(telemetry.ModelRequestMetrics{
+		Role:         string(modelConfig.Role),
+		Provider:     string(baseModelConfig.Provider),
+		StartedAt:    state.requestStartedAt,
+		FirstTokenAt: state.firstTokenAt,
+		InputTokens:  usage.PromptTokens,
+		OutputTokens: usage.CompletionTokens,
+	})
+
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
@@ -92,6 +104,18 @@ func (state *activeTellStreamState) execHookOnStop(sendStreamErr bool) {
 	modelConfig := state.modelConfig
 	baseModelConfig := modelConfig.GetBaseModelConfig(state.authVars, state.settings, state.orgUserConfig)
 
+	ratelimit.RecordTokens(auth.OrgId, auth.User.Id, state.totalRequestTokens+active.NumTokens)
+	telemetry.ObserveModelRequest(telemetry.ModelRequestMetrics{
+		Role:         string(modelConfig.Role),
+		Provider:     string(baseModelConfig.Provider),
+		StartedAt:    state.requestStartedAt,
+		FirstTokenAt: state.firstTokenAt,
+		InputTokens:  state.totalRequestTokens,
+		OutputTokens: active.NumTokens,
+		HadError:     sendStreamErr,
+		StoppedEarly: true,
+	})
+
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
@@ -1,6 +1,10 @@
 package prompts
 
-import "strconv"
+import (
+	"strconv"
+
+	shared "plandex-shared"
+)
 
 func GetArchitectContextSummary(tokenLimit int) string {
 	return `
@@ -238,6 +242,8 @@ When choosing which files to load, you MUST:
 
 IMPORTANT NOTE ON CODEBASE MAPS:
 For many file types, codebase maps will include files in the project, along with important symbols and definitions from those files. For other file types, the file path will be listed with '[NO MAP]' below it. This does NOT mean the the file is empty, does not exist, is not important, or is not relevant. It simply means that we either can't or prefer not to show the map of that file. You can still use the file path to load the file and see its full content if appropriate. For files without a map, instead of making judgments about the file's relevance based on the symbols in the map, judge based on the file path and name.
+
+API specs (OpenAPI/Swagger, GraphQL schemas, and protobuf files) are mapped as an outline of their operations and types. API specs are often very large, so rather than loading the whole file, load only the operations, schemas, types, services, or messages you need by listing them in the '### Files' section as ` + "`path/to/spec.yaml::Name`" + ` (for example ` + "`api/openapi.yaml::createPet`" + ` or ` + "`schema.graphql::Query.user`" + `). Operations without a name can be loaded by line range as ` + "`path/to/spec.yaml#L120-180`" + ` using the line range shown in the outline. Only load the full spec if you really need all of it.
 --
 
 When assessing relevant context, you MUST follow these rules:
@@ -325,3 +331,26 @@ Important: your response should address the user! Don't say things like "The use
 
 	return s
 }
+
+func GetRetrievedChunksPrompt(chunks []*shared.RetrievedChunk) string {
+	if len(chunks) == 0 {
+		return ""
+	}
+
+	s := `
+## Related code found by semantic search
+
+These sections of the codebase were found by an embeddings search for code similar to the user's prompt. They may find relevant code whose names don't match the task, but they aren't always relevant. Consider them alongside the codebase map when deciding which files to include in the '### Files' section—only include a file if it's actually needed.
+
+`
+
+	for _, chunk := range chunks {
+		s += "- `" + chunk.Path + "` lines " + strconv.Itoa(chunk.StartLine) + "-" + strconv.Itoa(chunk.EndLine)
+		if chunk.Symbol != "" {
+			s += " — " + chunk.Symbol
+		}
+		s += "\n"
+	}
+
+	return s
+}
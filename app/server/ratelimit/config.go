@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	shared "plandex-shared"
+)
+
+const defaultWaitTimeout = 2 * time.Minute
+
+type config struct {
+	mode        shared.RateLimitMode
+	waitTimeout time.Duration
+	user        shared.RateLimits
+	org         shared.RateLimits
+}
+
+var cfg = config{
+	mode:        shared.RateLimitModeReject,
+	waitTimeout: defaultWaitTimeout,
+}
+
+// LoadConfig reads limits from the environment. Limits are tracked in memory, so with multiple server instances each instance enforces them separately.
+func LoadConfig() error {
+	c := config{
+		mode:        shared.RateLimitModeReject,
+		waitTimeout: defaultWaitTimeout,
+	}
+
+	mode := os.Getenv("PLANDEX_RATE_LIMIT_MODE")
+	if mode != "" {
+		c.mode = shared.RateLimitMode(mode)
+		if c.mode != shared.RateLimitModeReject && c.mode != shared.RateLimitModeWait {
+			return fmt.Errorf("invalid PLANDEX_RATE_LIMIT_MODE '%s' - must be 'reject' or 'wait'", mode)
+		}
+	}
+
+	waitTimeoutSeconds, err := getEnvInt("PLANDEX_RATE_LIMIT_WAIT_TIMEOUT")
+	if err != nil {
+		return err
+	}
+	if waitTimeoutSeconds > 0 {
+		c.waitTimeout = time.Duration(waitTimeoutSeconds) * time.Second
+	}
+
+	vars := []struct {
+		name string
+		dst  *int
+	}{
+		{"PLANDEX_USER_MAX_CONCURRENT_PLANS", &c.user.MaxConcurrentPlans},
+		{"PLANDEX_USER_MAX_REQUESTS_PER_MINUTE", &c.user.MaxRequestsPerMinute},
+		{"PLANDEX_USER_MAX_TOKENS_PER_MINUTE", &c.user.MaxTokensPerMinute},
+		{"PLANDEX_ORG_MAX_CONCURRENT_PLANS", &c.org.MaxConcurrentPlans},
+		{"PLANDEX_ORG_MAX_REQUESTS_PER_MINUTE", &c.org.MaxRequestsPerMinute},
+		{"PLANDEX_ORG_MAX_TOKENS_PER_MINUTE", &c.org.MaxTokensPerMinute},
+	}
+
+	for _, v := range vars {
+		n, err := getEnvInt(v.name)
+		if err != nil {
+			return err
+		}
+		*v.dst = n
+	}
+
+	cfg = c
+
+	return nil
+}
+
+func Enabled() bool {
+	return !cfg.user.IsEmpty() || !cfg.org.IsEmpty()
+}
+
+func getEnvInt(name string) (int, error) {
+	s := os.Getenv(name)
+	if s == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s '%s' - must be a non-negative integer", name, s)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,216 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	shared "plandex-shared"
+)
+
+const pollInterval = time.Second
+
+// CountActiveFn returns the number of active plan streams for the user and for the org
+type CountActiveFn func() (userActive, orgActive int)
+
+type AcquireParams struct {
+	Ctx         context.Context
+	OrgId       string
+	UserId      string
+	CountActive CountActiveFn
+}
+
+type tokenEvent struct {
+	at        time.Time
+	numTokens int
+}
+
+// window tracks requests and tokens over the last minute, plus streams that were admitted but aren't active plans yet
+type window struct {
+	requests []time.Time
+	tokens   []tokenEvent
+	pending  int
+}
+
+var (
+	mu      sync.Mutex
+	windows = map[string]*window{}
+)
+
+// Acquire checks the user's and org's limits before a plan stream starts. In wait mode it blocks until the stream fits, the wait timeout is reached, or the context is done.
+// On success, call the returned release func once the stream's active plan has been created (or failed to start) so the admitted stream isn't counted twice.
+func Acquire(params AcquireParams) (func(), *shared.ApiError) {
+	if !Enabled() {
+		return func() {}, nil
+	}
+
+	deadline := time.Now().Add(cfg.waitTimeout)
+	userKey := "user|" + params.UserId
+	orgKey := "org|" + params.OrgId
+	loggedWait := false
+
+	for {
+		exceeded := tryAcquire(params, userKey, orgKey)
+
+		if exceeded == "" {
+			var once sync.Once
+			return func() {
+				once.Do(func() {
+					mu.Lock()
+					defer mu.Unlock()
+					getWindow(userKey).pending--
+					getWindow(orgKey).pending--
+					cleanup(userKey)
+					cleanup(orgKey)
+				})
+			}, nil
+		}
+
+		if cfg.mode != shared.RateLimitModeWait || time.Now().After(deadline) {
+			log.Printf("Rate limit reached for user %s, org %s: %s\n", params.UserId, params.OrgId, exceeded)
+			return nil, &shared.ApiError{
+				Type:   shared.ApiErrorTypeRateLimited,
+				Status: http.StatusTooManyRequests,
+				Msg:    fmt.Sprintf("Rate limit reached: %s. Try again shortly.", exceeded),
+			}
+		}
+
+		if !loggedWait {
+			log.Printf("Rate limit reached for user %s, org %s: %s - waiting\n", params.UserId, params.OrgId, exceeded)
+			loggedWait = true
+		}
+
+		select {
+		case <-params.Ctx.Done():
+			return nil, &shared.ApiError{
+				Type:   shared.ApiErrorTypeRateLimited,
+				Status: http.StatusTooManyRequests,
+				Msg:    "Request cancelled while waiting for rate limit",
+			}
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// RecordTokens adds a model request's tokens to the user's and org's per-minute usage
+func RecordTokens(orgId, userId string, numTokens int) {
+	if !Enabled() || numTokens <= 0 {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	now := time.Now()
+	for _, key := range []string{"user|" + userId, "org|" + orgId} {
+		w := getWindow(key)
+		w.tokens = append(w.tokens, tokenEvent{at: now, numTokens: numTokens})
+		prune(w, now)
+	}
+}
+
+func GetStatus(orgId, userId string, countActive CountActiveFn) shared.RateLimitStatusResponse {
+	mu.Lock()
+	defer mu.Unlock()
+
+	userUsage, orgUsage := getUsage("user|"+userId, "org|"+orgId, countActive)
+
+	return shared.RateLimitStatusResponse{
+		Mode:               cfg.mode,
+		WaitTimeoutSeconds: int(cfg.waitTimeout.Seconds()),
+		UserLimits:         cfg.user,
+		OrgLimits:          cfg.org,
+		User:               userUsage,
+		Org:                orgUsage,
+	}
+}
+
+func tryAcquire(params AcquireParams, userKey, orgKey string) string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for key := range windows {
+		cleanup(key)
+	}
+
+	userUsage, orgUsage := getUsage(userKey, orgKey, params.CountActive)
+
+	if exceeded := cfg.user.Exceeded(userUsage); exceeded != "" {
+		return "user limit of " + exceeded
+	}
+	if exceeded := cfg.org.Exceeded(orgUsage); exceeded != "" {
+		return "org limit of " + exceeded
+	}
+
+	now := time.Now()
+	for _, key := range []string{userKey, orgKey} {
+		w := getWindow(key)
+		w.requests = append(w.requests, now)
+		w.pending++
+	}
+
+	return ""
+}
+
+// getUsage must be called with mu held
+func getUsage(userKey, orgKey string, countActive CountActiveFn) (shared.RateLimitUsage, shared.RateLimitUsage) {
+	userActive, orgActive := countActive()
+
+	now := time.Now()
+	usage := func(key string, active int) shared.RateLimitUsage {
+		res := shared.RateLimitUsage{ActivePlans: active}
+		w, ok := windows[key]
+		if !ok {
+			return res
+		}
+		prune(w, now)
+		res.ActivePlans += w.pending
+		res.RequestsLastMinute = len(w.requests)
+		for _, t := range w.tokens {
+			res.TokensLastMinute += t.numTokens
+		}
+		return res
+	}
+
+	return usage(userKey, userActive), usage(orgKey, orgActive)
+}
+
+func getWindow(key string) *window {
+	w, ok := windows[key]
+	if !ok {
+		w = &window{}
+		windows[key] = w
+	}
+	return w
+}
+
+func prune(w *window, now time.Time) {
+	cutoff := now.Add(-time.Minute)
+
+	i := 0
+	for i < len(w.requests) && w.requests[i].Before(cutoff) {
+		i++
+	}
+	w.requests = w.requests[i:]
+
+	i = 0
+	for i < len(w.tokens) && w.tokens[i].at.Before(cutoff) {
+		i++
+	}
+	w.tokens = w.tokens[i:]
+}
+
+// cleanup must be called with mu held. It drops windows with nothing left to track so idle users and orgs don't accumulate
+func cleanup(key string) {
+	w, ok := windows[key]
+	if !ok {
+		return
+	}
+	prune(w, time.Now())
+	if w.pending <= 0 && len(w.requests) == 0 && len(w.tokens) == 0 {
+		delete(windows, key)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"plandex-server/handlers"
 	"plandex-server/hooks"
+	"plandex-server/telemetry"
 
 	"github.com/gorilla/mux"
 )
@@ -68,6 +69,8 @@ func AddHealthRoutes(r *mux.Router) {
 
 		fmt.Fprint(w, string(bytes))
 	})
+
+	HandlePlandexFn(r, "/metrics", false, telemetry.MetricsHandler().ServeHTTP)
 }
 
 func AddApiRoutes(r *mux.Router) {
@@ -103,6 +106,17 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	HandlePlandexFn(r, prefix+"/users", false, handlers.ListUsersHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/orgs/users/{userId}", false, handlers.DeleteOrgUserHandler).Methods("DELETE")
 	HandlePlandexFn(r, prefix+"/orgs/roles", false, handlers.ListOrgRolesHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/orgs/roles", false, handlers.CreateOrgRoleHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/orgs/roles/{roleId}", false, handlers.UpdateOrgRoleHandler).Methods("PUT")
+	HandlePlandexFn(r, prefix+"/orgs/roles/{roleId}", false, handlers.DeleteOrgRoleHandler).Methods("DELETE")
+	HandlePlandexFn(r, prefix+"/orgs/users/{userId}/role", false, handlers.SetOrgUserRoleHandler).Methods("PUT")
+	HandlePlandexFn(r, prefix+"/orgs/policy", false, handlers.GetOrgPolicyHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/orgs/policy", false, handlers.UpdateOrgPolicyHandler).Methods("PUT")
+	HandlePlandexFn(r, prefix+"/orgs/plan_templates", false, handlers.ListPlanTemplatesHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/orgs/plan_templates", false, handlers.UpsertPlanTemplateHandler).Methods("PUT")
+	HandlePlandexFn(r, prefix+"/orgs/plan_templates/{name}", false, handlers.DeletePlanTemplateHandler).Methods("DELETE")
+
+	HandlePlandexFn(r, prefix+"/rate_limits", false, handlers.GetRateLimitStatusHandler).Methods("GET")
 
 	HandlePlandexFn(r, prefix+"/invites", false, handlers.InviteUserHandler).Methods("POST")
 	HandlePlandexFn(r, prefix+"/invites/pending", false, handlers.ListPendingInvitesHandler).Methods("GET")
@@ -114,6 +128,9 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	HandlePlandexFn(r, prefix+"/projects", false, handlers.ListProjectsHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/projects/{projectId}/set_plan", false, handlers.ProjectSetPlanHandler).Methods("PUT")
 	HandlePlandexFn(r, prefix+"/projects/{projectId}/rename", false, handlers.RenameProjectHandler).Methods("PUT")
+	HandlePlandexFn(r, prefix+"/projects/{projectId}/context_sets", false, handlers.ListContextSetsHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/projects/{projectId}/context_sets", false, handlers.UpsertContextSetHandler).Methods("PUT")
+	HandlePlandexFn(r, prefix+"/projects/{projectId}/context_sets/{name}", false, handlers.DeleteContextSetHandler).Methods("DELETE")
 
 	HandlePlandexFn(r, prefix+"/projects/{projectId}/plans/current_branches", false, handlers.GetCurrentBranchByPlanIdHandler).Methods("POST")
 
@@ -122,6 +139,7 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	HandlePlandexFn(r, prefix+"/plans/ps", false, handlers.ListPlansRunningHandler).Methods("GET")
 
 	HandlePlandexFn(r, prefix+"/projects/{projectId}/plans", false, handlers.CreatePlanHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/projects/{projectId}/plans/import", false, handlers.ImportPlanHandler).Methods("POST")
 
 	HandlePlandexFn(r, prefix+"/projects/{projectId}/plans", false, handlers.DeleteAllPlansHandler).Methods("DELETE")
 
@@ -131,6 +149,7 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	HandlePlandexFn(r, prefix+"/plans/{planId}/current_plan/{sha}", false, handlers.CurrentPlanHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/current_plan", false, handlers.CurrentPlanHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/apply", false, handlers.ApplyPlanHandler).Methods("PATCH")
+	HandlePlandexFn(r, prefix+"/plans/{planId}/export", false, handlers.ExportPlanHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/archive", false, handlers.ArchivePlanHandler).Methods("PATCH")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/unarchive", false, handlers.UnarchivePlanHandler).Methods("PATCH")
 
@@ -138,7 +157,9 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/reject_all", false, handlers.RejectAllChangesHandler).Methods("PATCH")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/reject_file", false, handlers.RejectFileHandler).Methods("PATCH")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/reject_files", false, handlers.RejectFilesHandler).Methods("PATCH")
+	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/review_replacements", false, handlers.ReviewReplacementsHandler).Methods("PATCH")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/diffs", false, handlers.GetPlanDiffsHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/pr", false, handlers.GetPlanPRHandler).Methods("GET")
 
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/context", false, handlers.ListContextHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/context", false, handlers.LoadContextHandler).Methods("POST")
@@ -147,12 +168,16 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/context", false, handlers.DeleteContextHandler).Methods("DELETE")
 
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/convo", false, handlers.ListConvoHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/search", false, handlers.SearchConvoHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/rewind", false, handlers.RewindPlanHandler).Methods("PATCH")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/logs", false, handlers.ListLogsHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/logs/diff", false, handlers.GetLogDiffHandler).Methods("GET")
 
 	HandlePlandexFn(r, prefix+"/plans/{planId}/branches", false, handlers.ListBranchesHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/branches/{branch}", false, handlers.DeleteBranchHandler).Methods("DELETE")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/branches", false, handlers.CreateBranchHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/merge", false, handlers.MergeBranchHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/cherry_pick", false, handlers.CherryPickHandler).Methods("POST")
 
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/settings", false, handlers.GetSettingsHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/settings", false, handlers.UpdateSettingsHandler).Methods("PUT")
@@ -177,6 +202,8 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	HandlePlandexFn(r, prefix+"/default_settings", false, handlers.UpdateDefaultSettingsHandler).Methods("PUT")
 
 	HandlePlandexFn(r, prefix+"/file_map", false, handlers.GetFileMapHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/file_chunks", false, handlers.GetFileChunksHandler).Methods("POST")
+	HandlePlandexFn(r, prefix+"/file_symbols", false, handlers.GetFileSymbolsHandler).Methods("POST")
 	HandlePlandexFn(r, prefix+"/plans/{planId}/{branch}/load_cached_file_map", false, handlers.LoadCachedFileMapHandler).Methods("POST")
 
 	HandlePlandexFn(r, prefix+"/plans/{planId}/config", false, handlers.GetPlanConfigHandler).Methods("GET")
@@ -187,6 +214,10 @@ func addApiRoutes(r *mux.Router, prefix string) {
 
 	HandlePlandexFn(r, prefix+"/org_user_config", false, handlers.GetOrgUserConfigHandler).Methods("GET")
 	HandlePlandexFn(r, prefix+"/org_user_config", false, handlers.UpdateOrgUserConfigHandler).Methods("PUT")
+
+	HandlePlandexFn(r, prefix+"/org_secrets", false, handlers.ListOrgSecretsHandler).Methods("GET")
+	HandlePlandexFn(r, prefix+"/org_secrets", false, handlers.SetOrgSecretHandler).Methods("PUT")
+	HandlePlandexFn(r, prefix+"/org_secrets/{name}", false, handlers.DeleteOrgSecretHandler).Methods("DELETE")
 }
 
 func addProxyableApiRoutes(r *mux.Router, prefix string) {
@@ -11,7 +11,9 @@ import (
 	"plandex-server/host"
 	"plandex-server/model/plan"
 	"plandex-server/notify"
+	"plandex-server/ratelimit"
 	"plandex-server/shutdown"
+	"plandex-server/telemetry"
 	"runtime/debug"
 	"syscall"
 	"time"
@@ -41,6 +43,31 @@ func MustInitDb() {
 	}
 }
 
+func MustLoadRateLimits() {
+	err := ratelimit.LoadConfig()
+	if err != nil {
+		log.Fatal("Error loading rate limits: ", err)
+	}
+}
+
+func MustInitTelemetry() {
+	shutdownTracing, err := telemetry.InitTracing(context.Background())
+	if err != nil {
+		log.Fatal("Error initializing tracing: ", err)
+	}
+
+	RegisterShutdownHook(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := shutdownTracing(ctx)
+		if err != nil {
+			log.Printf("Error flushing traces: %v", err)
+		}
+	})
+
+	telemetry.RegisterActivePlansGauge(plan.NumActivePlans)
+}
+
 var shutdownHooks []func()
 
 func RegisterShutdownHook(hook func()) {
@@ -50,7 +77,7 @@ func RegisterShutdownHook(hook func()) {
 func loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Skip logging for monitoring endpoints
-		if r.URL.Path == "/health" || r.URL.Path == "/version" {
+		if r.URL.Path == "/health" || r.URL.Path == "/version" || r.URL.Path == "/metrics" {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -0,0 +1,785 @@
+package file_map
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	shared "plandex-shared"
+
+	"gopkg.in/yaml.v3"
+)
+
+// past this many properties or enum values, schema signatures are cut off
+const apiSpecMaxListed = 12
+
+var openApiMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// components that are listed by name only - schemas get their own definitions with their properties
+var openApiComponentSections = []string{"parameters", "requestBodies", "responses", "headers", "securitySchemes", "examples", "links", "callbacks", "pathItems"}
+var swaggerComponentSections = []string{"parameters", "responses", "securityDefinitions"}
+
+var graphqlDefKeywords = map[string]bool{
+	"type":      true,
+	"interface": true,
+	"input":     true,
+	"enum":      true,
+	"union":     true,
+	"scalar":    true,
+	"directive": true,
+	"schema":    true,
+	"extend":    true,
+}
+
+// apiSpecItem is an operation, type, or field in an API spec. Items with a name can be loaded on their own with 'path::name'.
+type apiSpecItem struct {
+	name      string
+	typ       string
+	signature string
+	start     int
+	end       int
+	// listed in the parent's signature instead of on their own line
+	hidden   bool
+	children []*apiSpecItem
+}
+
+// mapApiSpec maps an API spec to an outline of its operations and types instead of its syntax tree, so a large spec can stay in context as a map while individual items are loaded on demand
+func mapApiSpec(filename string, content []byte, kind shared.ApiSpecKind) []Definition {
+	items, err := parseApiSpec(content, kind)
+	if err != nil {
+		log.Printf("mapApiSpec - error parsing %s spec %s: %v", kind, filename, err)
+		return []Definition{}
+	}
+
+	hint := fmt.Sprintf("[%s spec outline - load an item on its own with `%s::Name`", kind, filename)
+	if kind == shared.ApiSpecOpenApi {
+		hint += fmt.Sprintf(", or by line range with `%s#L<start>-<end>` for operations without an operationId", filename)
+	}
+	hint += "]"
+
+	defs := []Definition{{Type: "api_spec_hint", Signature: hint, Line: 1}}
+	defs = append(defs, apiSpecDefinitions(items)...)
+	return defs
+}
+
+func apiSpecDefinitions(items []*apiSpecItem) []Definition {
+	var defs []Definition
+	for _, item := range items {
+		if item.hidden {
+			continue
+		}
+		defs = append(defs, Definition{
+			Type:      item.typ,
+			Signature: item.signature,
+			Line:      item.start,
+			Children:  apiSpecDefinitions(item.children),
+		})
+	}
+	return defs
+}
+
+// findApiSpecSymbol resolves an operationId, schema, type, service, or message name to its line range. Names that are nested in a type can be qualified like 'Query.user' or 'Greeter.SayHello', and unqualified names match the least nested item.
+func findApiSpecSymbol(content []byte, kind shared.ApiSpecKind, symbol string) (*shared.FileChunk, error) {
+	items, err := parseApiSpec(content, kind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s spec: %v", kind, err)
+	}
+
+	symbol = strings.TrimSpace(symbol)
+	match := func(matches func(item *apiSpecItem) bool) *apiSpecItem {
+		level := items
+		for len(level) > 0 {
+			var next []*apiSpecItem
+			for _, item := range level {
+				if item.name != "" && matches(item) {
+					return item
+				}
+				next = append(next, item.children...)
+			}
+			level = next
+		}
+		return nil
+	}
+
+	found := match(func(item *apiSpecItem) bool { return item.name == symbol })
+	if found == nil {
+		found = match(func(item *apiSpecItem) bool { return strings.HasSuffix(item.name, "."+symbol) })
+	}
+	if found == nil {
+		return nil, nil
+	}
+
+	return &shared.FileChunk{
+		StartLine: found.start,
+		EndLine:   found.end,
+		Symbol:    symbol,
+	}, nil
+}
+
+func parseApiSpec(content []byte, kind shared.ApiSpecKind) ([]*apiSpecItem, error) {
+	lines := strings.Split(string(content), "\n")
+	switch kind {
+	case shared.ApiSpecOpenApi:
+		return parseOpenApiSpec(content, lines)
+	case shared.ApiSpecGraphql:
+		return parseGraphqlSpec(content, lines), nil
+	case shared.ApiSpecProtobuf:
+		return parseProtoSpec(content, lines), nil
+	}
+	return nil, fmt.Errorf("unsupported api spec kind: %s", kind)
+}
+
+type specEntry struct {
+	key   *yaml.Node
+	value *yaml.Node
+	start int
+	end   int
+}
+
+// specEntries lists a mapping's keys with their line ranges - each entry runs until the next key, or the end of its parent for the last one
+func specEntries(node *yaml.Node, end int, lines []string) []specEntry {
+	node = resolveSpecNode(node)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	var res []specEntry
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		entry := specEntry{
+			key:   node.Content[i],
+			value: resolveSpecNode(node.Content[i+1]),
+			start: node.Content[i].Line,
+			end:   end,
+		}
+		if i+2 < len(node.Content) {
+			entry.end = max(node.Content[i+2].Line-1, entry.start)
+		}
+		entry.end = trimSpecEnd(lines, entry.start, entry.end)
+		res = append(res, entry)
+	}
+	return res
+}
+
+func resolveSpecNode(node *yaml.Node) *yaml.Node {
+	for node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+func specValue(node *yaml.Node, key string) *yaml.Node {
+	node = resolveSpecNode(node)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return resolveSpecNode(node.Content[i+1])
+		}
+	}
+	return nil
+}
+
+func specScalar(node *yaml.Node, key string) string {
+	value := specValue(node, key)
+	if value == nil || value.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return value.Value
+}
+
+func parseOpenApiSpec(content []byte, lines []string) ([]*apiSpecItem, error) {
+	var doc yaml.Node
+	err := yaml.Unmarshal(content, &doc)
+	if err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+	numLines := len(lines)
+
+	header := &apiSpecItem{typ: "api_spec", start: 1, end: 1}
+	if version := specScalar(root, "openapi"); version != "" {
+		header.signature = "openapi " + version
+	} else {
+		header.signature = "swagger " + specScalar(root, "swagger")
+	}
+	if title := specScalar(specValue(root, "info"), "title"); title != "" {
+		header.signature += " | " + title
+		if version := specScalar(specValue(root, "info"), "version"); version != "" {
+			header.signature += " " + version
+		}
+	}
+	items := []*apiSpecItem{header}
+
+	for _, entry := range specEntries(root, numLines, lines) {
+		switch entry.key.Value {
+		case "paths", "webhooks":
+			items = append(items, openApiOperations(entry, lines))
+		case "components":
+			for _, section := range specEntries(entry.value, entry.end, lines) {
+				if section.key.Value == "schemas" {
+					items = append(items, openApiSchemas("components.schemas", section, lines))
+				} else if containsString(openApiComponentSections, section.key.Value) {
+					items = append(items, openApiNamedSection("components."+section.key.Value, section, lines))
+				}
+			}
+		case "definitions":
+			items = append(items, openApiSchemas("definitions", entry, lines))
+		default:
+			if specScalar(root, "swagger") != "" && containsString(swaggerComponentSections, entry.key.Value) {
+				items = append(items, openApiNamedSection(entry.key.Value, entry, lines))
+			}
+		}
+	}
+
+	return items, nil
+}
+
+func openApiOperations(entry specEntry, lines []string) *apiSpecItem {
+	section := &apiSpecItem{typ: "section", signature: entry.key.Value, start: entry.start, end: entry.end}
+
+	for _, path := range specEntries(entry.value, entry.end, lines) {
+		for _, method := range specEntries(path.value, path.end, lines) {
+			if !containsString(openApiMethods, strings.ToLower(method.key.Value)) {
+				continue
+			}
+			operationId := specScalar(method.value, "operationId")
+
+			signature := strings.ToUpper(method.key.Value) + " " + path.key.Value
+			if operationId != "" {
+				signature += fmt.Sprintf(" (%s)", operationId)
+			} else {
+				signature += fmt.Sprintf(" (L%d-%d)", method.start, method.end)
+			}
+			if summary := specScalar(method.value, "summary"); summary != "" {
+				signature += " - " + firstSpecLine(summary)
+			}
+			if specScalar(method.value, "deprecated") == "true" {
+				signature += " [deprecated]"
+			}
+
+			section.children = append(section.children, &apiSpecItem{
+				name:      operationId,
+				typ:       "operation",
+				signature: signature,
+				start:     method.start,
+				end:       method.end,
+			})
+		}
+	}
+
+	return section
+}
+
+func openApiSchemas(label string, entry specEntry, lines []string) *apiSpecItem {
+	section := &apiSpecItem{typ: "section", signature: label, start: entry.start, end: entry.end}
+
+	for _, schema := range specEntries(entry.value, entry.end, lines) {
+		section.children = append(section.children, &apiSpecItem{
+			name:      schema.key.Value,
+			typ:       "schema",
+			signature: schema.key.Value + openApiSchemaSummary(schema.value),
+			start:     schema.start,
+			end:       schema.end,
+		})
+	}
+
+	return section
+}
+
+// openApiSchemaSummary describes a schema's shape in a few words, like ': object {id, name, tag}' or ': string enum [a, b]'
+func openApiSchemaSummary(schema *yaml.Node) string {
+	if ref := specScalar(schema, "$ref"); ref != "" {
+		return " = " + refName(ref)
+	}
+
+	var parts []string
+	if t := specScalar(schema, "type"); t != "" {
+		parts = append(parts, t)
+	}
+
+	if enum := specValue(schema, "enum"); enum != nil && enum.Kind == yaml.SequenceNode {
+		var values []string
+		for _, value := range enum.Content {
+			values = append(values, value.Value)
+		}
+		parts = append(parts, "enum ["+joinSpecList(values)+"]")
+	}
+
+	for _, combinator := range []string{"allOf", "oneOf", "anyOf"} {
+		list := specValue(schema, combinator)
+		if list == nil || list.Kind != yaml.SequenceNode {
+			continue
+		}
+		var names []string
+		for _, item := range list.Content {
+			if ref := specScalar(item, "$ref"); ref != "" {
+				names = append(names, refName(ref))
+			} else {
+				names = append(names, "{...}")
+			}
+		}
+		parts = append(parts, combinator+"("+joinSpecList(names)+")")
+	}
+
+	if props := specValue(schema, "properties"); props != nil && props.Kind == yaml.MappingNode {
+		required := map[string]bool{}
+		if list := specValue(schema, "required"); list != nil && list.Kind == yaml.SequenceNode {
+			for _, item := range list.Content {
+				required[item.Value] = true
+			}
+		}
+		var names []string
+		for i := 0; i < len(props.Content); i += 2 {
+			name := props.Content[i].Value
+			if required[name] {
+				name += "*"
+			}
+			names = append(names, name)
+		}
+		parts = append(parts, "{"+joinSpecList(names)+"}")
+	}
+
+	if items := specValue(schema, "items"); items != nil {
+		if ref := specScalar(items, "$ref"); ref != "" {
+			parts = append(parts, "of "+refName(ref))
+		} else if t := specScalar(items, "type"); t != "" {
+			parts = append(parts, "of "+t)
+		}
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+	return ": " + strings.Join(parts, " ")
+}
+
+func openApiNamedSection(label string, entry specEntry, lines []string) *apiSpecItem {
+	section := &apiSpecItem{typ: "section", start: entry.start, end: entry.end}
+
+	var names []string
+	for _, item := range specEntries(entry.value, entry.end, lines) {
+		names = append(names, item.key.Value)
+		section.children = append(section.children, &apiSpecItem{
+			name:   item.key.Value,
+			typ:    "component",
+			start:  item.start,
+			end:    item.end,
+			hidden: true,
+		})
+	}
+	section.signature = label + ": " + strings.Join(names, ", ")
+
+	return section
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+func joinSpecList(values []string) string {
+	if len(values) > apiSpecMaxListed {
+		return strings.Join(values[:apiSpecMaxListed], ", ") + fmt.Sprintf(", ... (%d more)", len(values)-apiSpecMaxListed)
+	}
+	return strings.Join(values, ", ")
+}
+
+func firstSpecLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, "\n"); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+type specToken struct {
+	text  string
+	str   bool
+	line  int
+	start int
+	end   int
+}
+
+// tokenizeSpec splits graphql or protobuf source into names, strings, and punctuation. Comments, whitespace, and commas are dropped. Names include dots so qualified protobuf types are a single token.
+func tokenizeSpec(content []byte, hashComments bool) []specToken {
+	var toks []specToken
+	line := 1
+	i := 0
+	n := len(content)
+
+	for i < n {
+		c := content[i]
+
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			i++
+		case hashComments && c == '#', !hashComments && c == '/' && i+1 < n && content[i+1] == '/':
+			for i < n && content[i] != '\n' {
+				i++
+			}
+		case !hashComments && c == '/' && i+1 < n && content[i+1] == '*':
+			i += 2
+			for i < n && !(content[i] == '*' && i+1 < n && content[i+1] == '/') {
+				if content[i] == '\n' {
+					line++
+				}
+				i++
+			}
+			i += 2
+		case c == '"' || (!hashComments && c == '\''):
+			start, startLine := i, line
+			if hashComments && i+2 < n && content[i+1] == '"' && content[i+2] == '"' {
+				i += 3
+				for i < n && !(content[i] == '"' && i+2 < n && content[i+1] == '"' && content[i+2] == '"') {
+					if content[i] == '\n' {
+						line++
+					}
+					i++
+				}
+				i = min(i+3, n)
+			} else {
+				i++
+				for i < n && content[i] != c && content[i] != '\n' {
+					if content[i] == '\\' {
+						i++
+					}
+					i++
+				}
+				i = min(i+1, n)
+			}
+			toks = append(toks, specToken{text: string(content[start:i]), str: true, line: startLine, start: start, end: i})
+		case isSpecNameChar(c):
+			start := i
+			for i < n && isSpecNameChar(content[i]) {
+				i++
+			}
+			toks = append(toks, specToken{text: string(content[start:i]), line: line, start: start, end: i})
+		default:
+			toks = append(toks, specToken{text: string(c), line: line, start: i, end: i + 1})
+			i++
+		}
+	}
+
+	return toks
+}
+
+func isSpecNameChar(c byte) bool {
+	return c == '_' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// specSource returns the source between two tokens on a single line, like 'user(id: ID!): User'
+func specSource(content []byte, from, to specToken) string {
+	s := strings.Join(strings.Fields(string(content[from.start:to.end])), " ")
+	s = strings.ReplaceAll(s, "( ", "(")
+	s = strings.ReplaceAll(s, " )", ")")
+	return s
+}
+
+// matchingSpecBrace returns the index of the token that closes the brace, paren, or bracket at i
+func matchingSpecBrace(toks []specToken, i int) int {
+	depth := 0
+	for j := i; j < len(toks); j++ {
+		if toks[j].str {
+			continue
+		}
+		switch toks[j].text {
+		case "{", "(", "[":
+			depth++
+		case "}", ")", "]":
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return len(toks) - 1
+}
+
+// specStartLine moves a definition's start up to include comments directly above it
+func specStartLine(lines []string, start int, prefixes ...string) int {
+	for start > 1 {
+		prev := strings.TrimSpace(lines[start-2])
+		isComment := false
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(prev, prefix) {
+				isComment = true
+				break
+			}
+		}
+		if !isComment {
+			break
+		}
+		start--
+	}
+	return start
+}
+
+func trimSpecEnd(lines []string, start, end int) int {
+	end = min(end, len(lines))
+	for end > start && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	return end
+}
+
+func parseGraphqlSpec(content []byte, lines []string) []*apiSpecItem {
+	toks := tokenizeSpec(content, true)
+	var items []*apiSpecItem
+
+	i := 0
+	for i < len(toks) {
+		first := i
+		// descriptions
+		for i < len(toks) && toks[i].str {
+			i++
+		}
+		if i >= len(toks) {
+			break
+		}
+		if !graphqlDefKeywords[toks[i].text] {
+			i++
+			continue
+		}
+
+		kwIndex := i
+		keyword := toks[i].text
+		if keyword == "extend" && i+1 < len(toks) {
+			i++
+			keyword = toks[i].text
+		}
+		i++
+
+		name := keyword
+		if keyword == "directive" && i+1 < len(toks) && toks[i].text == "@" {
+			name = "@" + toks[i+1].text
+			i += 2
+		} else if keyword != "schema" && i < len(toks) {
+			name = toks[i].text
+			i++
+		}
+
+		// the header runs until the body or the next definition
+		headerEnd := i - 1
+		for i < len(toks) && toks[i].text != "{" {
+			if toks[i].str || graphqlDefKeywords[toks[i].text] {
+				break
+			}
+			if toks[i].text == "(" {
+				i = matchingSpecBrace(toks, i)
+			}
+			headerEnd = i
+			i++
+		}
+
+		item := &apiSpecItem{
+			name:      name,
+			typ:       keyword,
+			signature: specSource(content, toks[kwIndex], toks[headerEnd]),
+			start:     specStartLine(lines, toks[first].line, "#"),
+			end:       toks[headerEnd].line,
+		}
+		if toks[kwIndex].text == "extend" {
+			// extensions share their type's name, so they're left out of lookups
+			item.name = ""
+		}
+
+		if i < len(toks) && toks[i].text == "{" {
+			closeIndex := matchingSpecBrace(toks, i)
+			if keyword == "enum" {
+				item.children = graphqlEnumValues(toks[i+1 : closeIndex])
+			} else {
+				item.children = graphqlFields(content, lines, toks[i+1:closeIndex], item.name)
+			}
+			item.end = toks[closeIndex].line
+			i = closeIndex + 1
+		}
+
+		items = append(items, item)
+	}
+
+	return items
+}
+
+func graphqlFields(content []byte, lines []string, toks []specToken, typeName string) []*apiSpecItem {
+	// a field starts at its description or its name, which is followed by arguments or a colon
+	var starts, nameIndexes []int
+	descStart := -1
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		if t.str {
+			if i == 0 || toks[i-1].text != "=" {
+				if descStart == -1 {
+					descStart = i
+				}
+			}
+			continue
+		}
+		if t.text == "(" || t.text == "[" || t.text == "{" {
+			i = matchingSpecBrace(toks, i)
+			continue
+		}
+		if i+1 < len(toks) && (toks[i+1].text == ":" || toks[i+1].text == "(") && isSpecNameChar(t.text[0]) && (i == 0 || toks[i-1].text != "@") {
+			start := i
+			if descStart != -1 {
+				start = descStart
+			}
+			starts = append(starts, start)
+			nameIndexes = append(nameIndexes, i)
+		}
+		descStart = -1
+	}
+
+	var fields []*apiSpecItem
+	for k, nameIndex := range nameIndexes {
+		end := len(toks) - 1
+		if k+1 < len(starts) {
+			end = starts[k+1] - 1
+		}
+		field := &apiSpecItem{
+			typ:       "field",
+			signature: specSource(content, toks[nameIndex], toks[end]),
+			start:     specStartLine(lines, toks[starts[k]].line, "#"),
+			end:       toks[end].line,
+		}
+		if typeName != "" {
+			field.name = typeName + "." + toks[nameIndex].text
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func graphqlEnumValues(toks []specToken) []*apiSpecItem {
+	var values []string
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		if t.str {
+			continue
+		}
+		if t.text == "(" {
+			i = matchingSpecBrace(toks, i)
+			continue
+		}
+		if isSpecNameChar(t.text[0]) && (i == 0 || toks[i-1].text != "@") {
+			values = append(values, t.text)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return []*apiSpecItem{{typ: "enum_values", signature: joinSpecList(values), start: toks[0].line, end: toks[len(toks)-1].line}}
+}
+
+var protoBlockKeywords = map[string]bool{
+	"message": true,
+	"enum":    true,
+	"service": true,
+	"oneof":   true,
+	"extend":  true,
+}
+
+var protoSkippedStatements = map[string]bool{
+	"syntax":     true,
+	"edition":    true,
+	"import":     true,
+	"option":     true,
+	"reserved":   true,
+	"extensions": true,
+}
+
+func parseProtoSpec(content []byte, lines []string) []*apiSpecItem {
+	toks := tokenizeSpec(content, false)
+	return parseProtoBlock(content, lines, toks, "")
+}
+
+// parseProtoBlock parses the statements in a file or in a message, enum, or service body. Names of nested items are qualified with their parent's name.
+func parseProtoBlock(content []byte, lines []string, toks []specToken, parent string) []*apiSpecItem {
+	var items []*apiSpecItem
+
+	i := 0
+	for i < len(toks) {
+		t := toks[i]
+		if t.text == ";" || t.str {
+			i++
+			continue
+		}
+
+		// a statement runs until a semicolon or a body at depth 0
+		end := i
+		for end < len(toks) && toks[end].text != ";" && toks[end].text != "{" {
+			if toks[end].text == "(" || toks[end].text == "[" {
+				end = matchingSpecBrace(toks, end)
+			}
+			end++
+		}
+		hasBody := end < len(toks) && toks[end].text == "{"
+		last := min(end, len(toks)-1)
+		closeIndex := last
+		if hasBody {
+			closeIndex = matchingSpecBrace(toks, end)
+		}
+
+		start := specStartLine(lines, t.line, "//", "/*", "*")
+
+		switch {
+		case protoSkippedStatements[t.text]:
+		case t.text == "package" && parent == "":
+			items = append(items, &apiSpecItem{typ: "package", signature: specSource(content, t, toks[max(last-1, i)]), start: start, end: toks[last].line})
+		case protoBlockKeywords[t.text] && hasBody && i+1 < end:
+			name := toks[i+1].text
+			if parent != "" {
+				name = parent + "." + name
+			}
+			item := &apiSpecItem{
+				name:      name,
+				typ:       t.text,
+				signature: specSource(content, t, toks[end-1]),
+				start:     start,
+				end:       toks[closeIndex].line,
+			}
+			if t.text == "extend" {
+				item.name = ""
+			}
+			childParent := name
+			if t.text == "oneof" {
+				// oneof fields belong to the message
+				childParent = parent
+			}
+			item.children = parseProtoBlock(content, lines, toks[end+1:closeIndex], childParent)
+			items = append(items, item)
+		case end > i:
+			item := &apiSpecItem{
+				typ:       "field",
+				signature: specSource(content, t, toks[end-1]),
+				start:     start,
+				end:       toks[closeIndex].line,
+			}
+			if t.text == "rpc" && i+1 < end {
+				item.typ = "rpc"
+				item.name = toks[i+1].text
+				if parent != "" {
+					item.name = parent + "." + item.name
+				}
+			}
+			items = append(items, item)
+		}
+
+		i = closeIndex + 1
+	}
+
+	return items
+}
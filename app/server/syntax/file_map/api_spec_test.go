@@ -0,0 +1,290 @@
+package file_map
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+const testOpenApiSpec = `openapi: 3.0.0
+info:
+  title: Pets
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      summary: List pets
+      responses:
+        '200':
+          description: ok
+    post:
+      operationId: createPet
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        '201':
+          description: created
+  /pets/{id}:
+    delete:
+      responses:
+        '204':
+          description: deleted
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        id:
+          type: integer
+        name:
+          type: string
+        tag:
+          type: string
+  parameters:
+    PetId:
+      name: id
+      in: path
+`
+
+const testSwaggerJsonSpec = `{
+  "swagger": "2.0",
+  "info": {"title": "Legacy"},
+  "paths": {
+    "/users": {
+      "get": {"operationId": "getUsers", "responses": {"200": {"description": "ok"}}}
+    }
+  },
+  "definitions": {
+    "User": {"type": "object", "properties": {"id": {"type": "string"}}}
+  }
+}`
+
+const testGraphqlSpec = `schema {
+  query: Query
+}
+
+"""A pet"""
+type Pet {
+  id: ID!
+  name: String
+}
+
+type Query {
+  pet(id: ID!): Pet
+  pets(first: Int = 10): [Pet!]!
+}
+
+enum Species {
+  CAT
+  DOG
+}
+
+input NewPet {
+  name: String!
+}
+`
+
+const testProtoSpec = `syntax = "proto3";
+
+package pets.v1;
+
+service PetService {
+  rpc GetPet(GetPetRequest) returns (Pet);
+  rpc ListPets(ListPetsRequest) returns (stream Pet) {
+    option deprecated = true;
+  }
+}
+
+message Pet {
+  string id = 1;
+  string name = 2;
+  message Owner {
+    string email = 1;
+  }
+  Owner owner = 3;
+}
+
+enum Species {
+  SPECIES_UNSPECIFIED = 0;
+  CAT = 1;
+}
+`
+
+// flattenApiSpecItems lists each visible item as 'signature L<start>-<end>', indented by depth
+func flattenApiSpecItems(items []*apiSpecItem, depth int) []string {
+	var res []string
+	for _, item := range items {
+		if item.hidden {
+			continue
+		}
+		res = append(res, fmt.Sprintf("%s%s L%d-%d", strings.Repeat("  ", depth), item.signature, item.start, item.end))
+		res = append(res, flattenApiSpecItems(item.children, depth+1)...)
+	}
+	return res
+}
+
+func TestParseApiSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    shared.ApiSpecKind
+		content string
+		want    []string
+	}{
+		{
+			name:    "openapi yaml",
+			kind:    shared.ApiSpecOpenApi,
+			content: testOpenApiSpec,
+			want: []string{
+				"openapi 3.0.0 | Pets L1-1",
+				"paths L4-26",
+				"  GET /pets (listPets) - List pets L6-11",
+				"  POST /pets (createPet) L12-21",
+				"  DELETE /pets/{id} (L23-26) L23-26",
+				"components.schemas L28-38",
+				"  Pet: object {id, name*, tag} L29-38",
+				"components.parameters: PetId L39-42",
+			},
+		},
+		{
+			name:    "swagger json",
+			kind:    shared.ApiSpecOpenApi,
+			content: testSwaggerJsonSpec,
+			want: []string{
+				"swagger 2.0 | Legacy L1-1",
+				"paths L4-8",
+				"  GET /users (getUsers) L6-8",
+				"definitions L9-12",
+				"  User: object {id} L10-12",
+			},
+		},
+		{
+			name:    "graphql",
+			kind:    shared.ApiSpecGraphql,
+			content: testGraphqlSpec,
+			want: []string{
+				"schema L1-3",
+				"  query: Query L2-2",
+				"type Pet L5-9",
+				"  id: ID! L7-7",
+				"  name: String L8-8",
+				"type Query L11-14",
+				"  pet(id: ID!): Pet L12-12",
+				"  pets(first: Int = 10): [Pet!]! L13-13",
+				"enum Species L16-19",
+				"  CAT, DOG L17-18",
+				"input NewPet L21-23",
+				"  name: String! L22-22",
+			},
+		},
+		{
+			name:    "protobuf",
+			kind:    shared.ApiSpecProtobuf,
+			content: testProtoSpec,
+			want: []string{
+				"package pets.v1 L3-3",
+				"service PetService L5-10",
+				"  rpc GetPet(GetPetRequest) returns (Pet) L6-6",
+				"  rpc ListPets(ListPetsRequest) returns (stream Pet) L7-9",
+				"message Pet L12-19",
+				"  string id = 1 L13-13",
+				"  string name = 2 L14-14",
+				"  message Owner L15-17",
+				"    string email = 1 L16-16",
+				"  Owner owner = 3 L18-18",
+				"enum Species L21-24",
+				"  SPECIES_UNSPECIFIED = 0 L22-22",
+				"  CAT = 1 L23-23",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := parseApiSpec([]byte(tt.content), tt.kind)
+			if err != nil {
+				t.Fatalf("parseApiSpec: %v", err)
+			}
+			got := flattenApiSpecItems(items, 0)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestFindApiSpecSymbol(t *testing.T) {
+	tests := []struct {
+		name      string
+		kind      shared.ApiSpecKind
+		content   string
+		symbol    string
+		wantStart int
+		wantEnd   int
+		notFound  bool
+	}{
+		{name: "operationId", kind: shared.ApiSpecOpenApi, content: testOpenApiSpec, symbol: "createPet", wantStart: 12, wantEnd: 21},
+		{name: "schema", kind: shared.ApiSpecOpenApi, content: testOpenApiSpec, symbol: "Pet", wantStart: 29, wantEnd: 38},
+		{name: "listed component", kind: shared.ApiSpecOpenApi, content: testOpenApiSpec, symbol: "PetId", wantStart: 40, wantEnd: 42},
+		{name: "missing operation", kind: shared.ApiSpecOpenApi, content: testOpenApiSpec, symbol: "deletePet", notFound: true},
+		{name: "graphql type includes description", kind: shared.ApiSpecGraphql, content: testGraphqlSpec, symbol: "Pet", wantStart: 5, wantEnd: 9},
+		{name: "qualified graphql field", kind: shared.ApiSpecGraphql, content: testGraphqlSpec, symbol: "Query.pets", wantStart: 13, wantEnd: 13},
+		{name: "unqualified graphql field", kind: shared.ApiSpecGraphql, content: testGraphqlSpec, symbol: "pets", wantStart: 13, wantEnd: 13},
+		{name: "type wins over field with the same name", kind: shared.ApiSpecGraphql, content: testGraphqlSpec, symbol: "Species", wantStart: 16, wantEnd: 19},
+		{name: "qualified rpc", kind: shared.ApiSpecProtobuf, content: testProtoSpec, symbol: "PetService.ListPets", wantStart: 7, wantEnd: 9},
+		{name: "nested message", kind: shared.ApiSpecProtobuf, content: testProtoSpec, symbol: "Owner", wantStart: 15, wantEnd: 17},
+		{name: "missing message", kind: shared.ApiSpecProtobuf, content: testProtoSpec, symbol: "Owner.Address", notFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunk, err := findApiSpecSymbol([]byte(tt.content), tt.kind, tt.symbol)
+			if err != nil {
+				t.Fatalf("findApiSpecSymbol: %v", err)
+			}
+			if tt.notFound {
+				if chunk != nil {
+					t.Fatalf("expected no match, got L%d-%d", chunk.StartLine, chunk.EndLine)
+				}
+				return
+			}
+			if chunk == nil {
+				t.Fatalf("expected a match for %s", tt.symbol)
+			}
+			if chunk.StartLine != tt.wantStart || chunk.EndLine != tt.wantEnd {
+				t.Errorf("got L%d-%d, want L%d-%d", chunk.StartLine, chunk.EndLine, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestParseApiSpecMalformed(t *testing.T) {
+	inputs := []string{
+		"",
+		"openapi: [",
+		"paths: 5\ncomponents: []",
+		"{{{",
+		"}}} {",
+		"type Q { a: [",
+		`"""unterminated`,
+		"service X { rpc",
+		"message { } } }",
+		"enum E { A = ; }",
+	}
+
+	// malformed specs can fail to parse, but must not panic
+	for _, kind := range []shared.ApiSpecKind{shared.ApiSpecOpenApi, shared.ApiSpecGraphql, shared.ApiSpecProtobuf} {
+		for _, input := range inputs {
+			t.Run(fmt.Sprintf("%s %q", kind, input), func(t *testing.T) {
+				parseApiSpec([]byte(input), kind)
+				mapApiSpec("spec", []byte(input), kind)
+			})
+		}
+	}
+}
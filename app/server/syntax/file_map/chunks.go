@@ -0,0 +1,138 @@
+package file_map
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+const (
+	chunkMaxLines     = 80
+	chunkMinLines     = 8
+	chunkMaxSymbolLen = 100
+)
+
+// ChunkFile splits a file into line ranges for embedding. Chunk boundaries follow the definitions found by MapFile, so each function, class, or section is kept together where possible. Definitions that are too large are split at their nested definitions, and files without a map are split into fixed windows.
+func ChunkFile(ctx context.Context, filename string, content []byte) ([]*shared.FileChunk, error) {
+	numLines := bytes.Count(content, []byte("\n"))
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		numLines++
+	}
+	if numLines == 0 {
+		return nil, nil
+	}
+
+	var defs []Definition
+	if shared.HasContentFileMapSupport(filename, content) {
+		fileMap, err := MapFile(ctx, filename, content)
+		if err != nil {
+			return nil, err
+		}
+		defs = fileMap.Definitions
+	}
+
+	return mergeSmallChunks(chunkDefinitions(defs, 1, numLines)), nil
+}
+
+func chunkDefinitions(defs []Definition, start, end int) []*shared.FileChunk {
+	var inRange []Definition
+	for _, def := range defs {
+		if def.Line >= start && def.Line <= end {
+			inRange = append(inRange, def)
+		}
+	}
+	sort.SliceStable(inRange, func(i, j int) bool {
+		return inRange[i].Line < inRange[j].Line
+	})
+
+	if len(inRange) == 0 {
+		return windowChunks(start, end, "")
+	}
+
+	var res []*shared.FileChunk
+
+	// imports, package docs, etc. before the first definition
+	if inRange[0].Line > start {
+		res = append(res, windowChunks(start, inRange[0].Line-1, "")...)
+	}
+
+	for i, def := range inRange {
+		defEnd := end
+		if i+1 < len(inRange) {
+			defEnd = inRange[i+1].Line - 1
+		}
+		// another definition starts on the same line
+		if defEnd < def.Line {
+			continue
+		}
+
+		symbol := definitionSymbol(def)
+
+		if defEnd-def.Line+1 > chunkMaxLines && len(def.Children) > 0 {
+			for _, chunk := range chunkDefinitions(def.Children, def.Line, defEnd) {
+				if chunk.Symbol == "" {
+					chunk.Symbol = symbol
+				} else if symbol != "" {
+					chunk.Symbol = symbol + " › " + chunk.Symbol
+				}
+				res = append(res, chunk)
+			}
+			continue
+		}
+
+		res = append(res, windowChunks(def.Line, defEnd, symbol)...)
+	}
+
+	return res
+}
+
+func windowChunks(start, end int, symbol string) []*shared.FileChunk {
+	var res []*shared.FileChunk
+	for s := start; s <= end; s += chunkMaxLines {
+		res = append(res, &shared.FileChunk{
+			StartLine: s,
+			EndLine:   min(s+chunkMaxLines-1, end),
+			Symbol:    symbol,
+		})
+	}
+	return res
+}
+
+// mergeSmallChunks folds short chunks (one-line declarations, small helpers) into the chunk before them
+func mergeSmallChunks(chunks []*shared.FileChunk) []*shared.FileChunk {
+	var res []*shared.FileChunk
+	for _, chunk := range chunks {
+		if len(res) > 0 {
+			prev := res[len(res)-1]
+			prevLines := prev.EndLine - prev.StartLine + 1
+			lines := chunk.EndLine - chunk.StartLine + 1
+			if (lines < chunkMinLines || prevLines < chunkMinLines) && prevLines+lines <= chunkMaxLines {
+				prev.EndLine = chunk.EndLine
+				switch {
+				case prev.Symbol == "":
+					prev.Symbol = chunk.Symbol
+				case chunk.Symbol != "" && len(prev.Symbol)+len(chunk.Symbol) < chunkMaxSymbolLen:
+					prev.Symbol += ", " + chunk.Symbol
+				}
+				continue
+			}
+		}
+		res = append(res, chunk)
+	}
+	return res
+}
+
+func definitionSymbol(def Definition) string {
+	if def.Type == "no_map" {
+		return ""
+	}
+	symbol := strings.TrimSpace(strings.SplitN(def.Signature, "\n", 2)[0])
+	symbol = strings.TrimSpace(strings.TrimSuffix(symbol, "{"))
+	if len(symbol) > chunkMaxSymbolLen {
+		symbol = symbol[:chunkMaxSymbolLen] + "…"
+	}
+	return symbol
+}
@@ -37,6 +37,12 @@ type Node struct {
 }
 
 func MapFile(ctx context.Context, filename string, content []byte) (*FileMap, error) {
+	if kind := shared.GetApiSpecKind(filename, content); kind != "" {
+		return &FileMap{
+			Definitions: mapApiSpec(filename, content, kind),
+		}, nil
+	}
+
 	if !shared.HasFileMapSupport(filename) {
 		// return nil, fmt.Errorf("unsupported file type: %s", filename)
 		return &FileMap{
@@ -35,13 +35,13 @@ func ProcessMapFiles(ctx context.Context, inputs map[string]string) (shared.File
 	sem := make(chan struct{}, maxWorkers)
 
 	for path, content := range inputs {
-		if !shared.HasFileMapSupport(path) {
+		if !shared.HasContentFileMapSupport(path, []byte(content)) {
 			mu.Lock()
 			bodies[path] = "[NO MAP]"
 			mu.Unlock()
 			errCh <- nil
 			continue
-		} else if len(content) > shared.MaxContextMapSingleInputSize { // 1MB
+		} else if len(content) > shared.MaxFileMapInputSize(path) {
 			mu.Lock()
 			bodies[path] = "[NO MAP - TOO LARGE]"
 			mu.Unlock()
@@ -0,0 +1,200 @@
+package file_map
+
+import (
+	"context"
+	"fmt"
+	"plandex-server/syntax"
+	"strings"
+
+	shared "plandex-shared"
+
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+// node types that can define a named symbol - matched as substrings so the same list works across grammars
+var symbolNodeTypeParts = []string{
+	"declaration",
+	"definition",
+	"declarator",
+	"spec",
+	"item",
+	"class",
+	"function",
+	"method",
+	"module",
+	"interface",
+	"struct",
+	"enum",
+	"trait",
+	"impl",
+	"constructor",
+	"signature",
+}
+
+// FindSymbol resolves a symbol name like "FuncName" or "Type.Method" to the line range of its definition, including any doc comments directly above it. Line numbers are 1-based and inclusive. Returns nil if the symbol isn't found.
+func FindSymbol(ctx context.Context, filename string, content []byte, symbol string) (*shared.FileChunk, error) {
+	parts := strings.Split(strings.TrimSpace(symbol), ".")
+	name := parts[len(parts)-1]
+	qualifiers := parts[:len(parts)-1]
+	if name == "" {
+		return nil, fmt.Errorf("invalid symbol: %s", symbol)
+	}
+
+	if kind := shared.GetApiSpecKind(filename, content); kind != "" {
+		return findApiSpecSymbol(content, kind, symbol)
+	}
+
+	parser, _, fallbackParser, _ := syntax.GetParserForPath(filename)
+	if parser == nil && fallbackParser == nil {
+		return nil, fmt.Errorf("symbol lookup isn't supported for %s", filename)
+	}
+	if parser != nil {
+		defer parser.Close()
+	}
+	if fallbackParser != nil {
+		defer fallbackParser.Close()
+	}
+
+	var tree *tree_sitter.Tree
+	var err error
+	if parser != nil {
+		tree, err = parser.ParseCtx(ctx, nil, content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse file: %v", err)
+		}
+		defer tree.Close()
+	}
+
+	if (tree == nil || tree.RootNode().Type() == "error") && fallbackParser != nil {
+		fallbackTree, err := fallbackParser.ParseCtx(ctx, nil, content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse file: %v", err)
+		}
+		defer fallbackTree.Close()
+		tree = fallbackTree
+	}
+
+	root := tree.RootNode()
+	found := findSymbolNode(root, content, name, qualifiers)
+	if found == nil {
+		return nil, nil
+	}
+
+	start, end := symbolNodeRange(found, root)
+
+	startLine := int(start.StartPoint().Row) + 1
+	endLine := int(end.EndPoint().Row) + 1
+	if end.EndPoint().Column == 0 && endLine > startLine {
+		endLine--
+	}
+
+	return &shared.FileChunk{
+		StartLine: startLine,
+		EndLine:   endLine,
+		Symbol:    symbol,
+	}, nil
+}
+
+// findSymbolNode returns the least nested matching definition, so 'run' finds a top-level function before a method with the same name
+func findSymbolNode(root *tree_sitter.Node, content []byte, name string, qualifiers []string) *tree_sitter.Node {
+	level := []*tree_sitter.Node{root}
+	for len(level) > 0 {
+		var next []*tree_sitter.Node
+		for _, node := range level {
+			if isSymbolNode(node) {
+				nameNode := node.ChildByFieldName("name")
+				if nameNode != nil && nameNode.Content(content) == name && matchesQualifiers(node, content, qualifiers) {
+					return node
+				}
+			}
+			for i := 0; i < int(node.NamedChildCount()); i++ {
+				next = append(next, node.NamedChild(i))
+			}
+		}
+		level = next
+	}
+	return nil
+}
+
+func isSymbolNode(node *tree_sitter.Node) bool {
+	t := node.Type()
+	for _, part := range symbolNodeTypeParts {
+		if strings.Contains(t, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesQualifiers checks that each qualifier names an enclosing definition, or the receiver type for Go methods
+func matchesQualifiers(node *tree_sitter.Node, content []byte, qualifiers []string) bool {
+	if len(qualifiers) == 0 {
+		return true
+	}
+
+	names := map[string]bool{}
+
+	if receiver := node.ChildByFieldName("receiver"); receiver != nil {
+		for _, id := range findNodesOfType(receiver, "type_identifier") {
+			names[id.Content(content)] = true
+		}
+	}
+
+	for parent := node.Parent(); parent != nil; parent = parent.Parent() {
+		if nameNode := parent.ChildByFieldName("name"); nameNode != nil {
+			names[nameNode.Content(content)] = true
+		}
+		// rust impl blocks are named by their type
+		if typeNode := parent.ChildByFieldName("type"); typeNode != nil && parent.Type() == "impl_item" {
+			names[typeNode.Content(content)] = true
+		}
+	}
+
+	for _, q := range qualifiers {
+		if !names[q] {
+			return false
+		}
+	}
+	return true
+}
+
+func findNodesOfType(node *tree_sitter.Node, t string) []*tree_sitter.Node {
+	if node.Type() == t {
+		return []*tree_sitter.Node{node}
+	}
+	var res []*tree_sitter.Node
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		res = append(res, findNodesOfType(node.NamedChild(i), t)...)
+	}
+	return res
+}
+
+// symbolNodeRange expands a definition to its wrapping declaration (e.g. 'type X struct' for a Go type spec, or an export statement) and any comments, decorators, or attributes directly above it
+func symbolNodeRange(node, root *tree_sitter.Node) (*tree_sitter.Node, *tree_sitter.Node) {
+	for {
+		parent := node.Parent()
+		if parent == nil || parent.Equal(root) {
+			break
+		}
+		t := parent.Type()
+		isContainer := strings.HasSuffix(t, "body") || strings.HasSuffix(t, "block") || strings.HasSuffix(t, "list")
+		if t == "decorated_definition" || (!isContainer && parent.NamedChildCount() == 1 && parent.ChildByFieldName("name") == nil) {
+			node = parent
+			continue
+		}
+		break
+	}
+
+	start := node
+	for prev := start.PrevSibling(); prev != nil; prev = prev.PrevSibling() {
+		t := prev.Type()
+		isLeading := strings.Contains(t, "comment") || strings.Contains(t, "attribute") || t == "decorator"
+		// only attach comments that are directly above, not separated by a blank line
+		if !isLeading || prev.EndPoint().Row+1 < start.StartPoint().Row {
+			break
+		}
+		start = prev
+	}
+
+	return start, node
+}
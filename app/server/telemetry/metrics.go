@@ -0,0 +1,174 @@
+package telemetry
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "plandex"
+
+var (
+	streamDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "plan_stream_duration_seconds",
+		Help:      "Duration of plan streams from activation until they finish, error, or are stopped.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+	}, []string{"kind", "outcome"})
+
+	modelRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "model_request_duration_seconds",
+		Help:      "Duration of model requests from send until the response is complete.",
+		Buckets:   []float64{0.5, 1, 2, 5, 10, 20, 30, 60, 120, 300, 600},
+	}, []string{"role", "provider", "outcome"})
+
+	modelTimeToFirstToken = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "model_time_to_first_token_seconds",
+		Help:      "Time from sending a streaming model request until the first token is received.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 20, 30, 60},
+	}, []string{"role", "provider"})
+
+	modelTokens = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "model_tokens_total",
+		Help:      "Tokens sent to and received from models.",
+	}, []string{"role", "provider", "direction"})
+
+	buildRaceOutcomes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "build_race_outcomes_total",
+		Help:      "Results of file build races, labeled by the strategy that produced the result.",
+	}, []string{"outcome"})
+
+	buildValidationAttempts = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "build_validation_attempts",
+		Help:      "Validation and fix attempts used per build validation loop.",
+		Buckets:   []float64{1, 2, 3, 4, 5},
+	}, []string{"valid"})
+
+	buildValidationErrorRetries = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "build_validation_error_retries_total",
+		Help:      "Validation model requests retried after an error.",
+	})
+
+	repoLockWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "repo_lock_wait_seconds",
+		Help:      "Time spent acquiring plan repo locks.",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10, 30, 60},
+	}, []string{"scope", "outcome"})
+
+	gitOperationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "git_operation_duration_seconds",
+		Help:      "Duration of git write operations on plan repos, including retries.",
+		Buckets:   []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10},
+	}, []string{"operation", "outcome"})
+)
+
+// RegisterActivePlansGauge exposes the number of active plans on this host. numActive is called on each scrape.
+func RegisterActivePlansGauge(numActive func() int) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_plans",
+		Help:      "Plan streams currently active on this host.",
+	}, func() float64 {
+		return float64(numActive())
+	})
+}
+
+func ObserveStreamDuration(buildOnly bool, outcome string, startedAt time.Time) {
+	kind := "tell"
+	if buildOnly {
+		kind = "build"
+	}
+	streamDuration.WithLabelValues(kind, outcome).Observe(time.Since(startedAt).Seconds())
+}
+
+type ModelRequestMetrics struct {
+	Role         string
+	Provider     string
+	StartedAt    time.Time
+	FirstTokenAt time.Time
+	InputTokens  int
+	OutputTokens int
+	HadError     bool
+	StoppedEarly bool
+}
+
+func ObserveModelRequest(m ModelRequestMetrics) {
+	outcome := "success"
+	if m.HadError {
+		outcome = "error"
+	} else if m.StoppedEarly {
+		outcome = "stopped"
+	}
+
+	if !m.StartedAt.IsZero() {
+		modelRequestDuration.WithLabelValues(m.Role, m.Provider, outcome).Observe(time.Since(m.StartedAt).Seconds())
+
+		if !m.FirstTokenAt.IsZero() {
+			modelTimeToFirstToken.WithLabelValues(m.Role, m.Provider).Observe(m.FirstTokenAt.Sub(m.StartedAt).Seconds())
+		}
+	}
+
+	modelTokens.WithLabelValues(m.Role, m.Provider, "input").Add(float64(m.InputTokens))
+	modelTokens.WithLabelValues(m.Role, m.Provider, "output").Add(float64(m.OutputTokens))
+}
+
+func IncBuildRaceOutcome(outcome string) {
+	buildRaceOutcomes.WithLabelValues(outcome).Inc()
+}
+
+func ObserveBuildValidationAttempts(numAttempts int, valid bool) {
+	label := "false"
+	if valid {
+		label = "true"
+	}
+	buildValidationAttempts.WithLabelValues(label).Observe(float64(numAttempts))
+}
+
+func IncBuildValidationErrorRetries() {
+	buildValidationErrorRetries.Inc()
+}
+
+func ObserveRepoLockWait(scope string, err error, startedAt time.Time) {
+	repoLockWait.WithLabelValues(scope, outcomeLabel(err)).Observe(time.Since(startedAt).Seconds())
+}
+
+// ObserveGitOperation records a git write operation. Labels look like "GitAddAndCommit > gitAdd: plan=... branch=..." so the plan-specific suffix is dropped to keep cardinality low.
+func ObserveGitOperation(label string, err error, startedAt time.Time) {
+	operation, _, _ := strings.Cut(label, ":")
+	gitOperationDuration.WithLabelValues(strings.TrimSpace(operation), outcomeLabel(err)).Observe(time.Since(startedAt).Seconds())
+}
+
+// MetricsHandler serves metrics in the Prometheus format. If PLANDEX_METRICS_TOKEN is set, scrapers must send it as a bearer token.
+func MetricsHandler() http.Handler {
+	handler := promhttp.Handler()
+	token := os.Getenv("PLANDEX_METRICS_TOKEN")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func outcomeLabel(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
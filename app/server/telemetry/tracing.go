@@ -0,0 +1,94 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	shared "plandex-shared"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "plandex-server"
+
+// the global tracer delegates to the provider set in InitTracing, so it's safe to use before init; spans are no-ops until then
+var tracer = otel.Tracer(tracerName)
+
+// InitTracing exports spans over OTLP/HTTP when OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set. The exporter reads the standard OTEL_EXPORTER_OTLP_* variables for headers, TLS, etc.
+// The returned func flushes and stops the exporter.
+func InitTracing(ctx context.Context) (func(context.Context) error, error) {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error creating OTLP trace exporter: %v", err)
+	}
+
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = tracerName
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("error creating trace resource: %v", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan records err on the span, if any, and ends it
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		RecordSpanError(span, err)
+	}
+	span.End()
+}
+
+func PlanAttrs(planId, branch string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("plandex.plan_id", planId),
+		attribute.String("plandex.branch", branch),
+	}
+}
+
+func ModelAttrs(role shared.ModelRole, modelId shared.ModelId, provider shared.ModelProvider, purpose string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("plandex.model_role", string(role)),
+		attribute.String("plandex.model_id", string(modelId)),
+		attribute.String("plandex.model_provider", string(provider)),
+		attribute.String("plandex.purpose", purpose),
+	}
+}
+
+// RecordSpanError marks a span as failed without ending it, for spans that are ended elsewhere
+func RecordSpanError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -9,6 +9,7 @@ import (
 	"plandex-server/db"
 	"plandex-server/notify"
 	"plandex-server/shutdown"
+	"plandex-server/telemetry"
 	"sync"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const MaxStreamRate = 70 * time.Millisecond
@@ -82,6 +84,8 @@ type ActivePlan struct {
 	StoredReplyIds        []string
 	DidEditFiles          bool
 	SessionId             string
+	StartedAt             time.Time
+	Span                  trace.Span
 
 	subscriptions  map[string]*subscription
 	subscriptionMu sync.Mutex
@@ -93,12 +97,19 @@ type ActivePlan struct {
 }
 
 func NewActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, autoContext bool, sessionId string) *ActivePlan {
-	ctx, cancel := context.WithTimeout(shutdown.ShutdownCtx, ActivePlanTimeout)
+	spanName := "plan.tell"
+	if buildOnly {
+		spanName = "plan.build"
+	}
+	// plan contexts carry the stream's span so model requests and builds started under them are traced as its children
+	traceCtx, span := telemetry.StartSpan(shutdown.ShutdownCtx, spanName, telemetry.PlanAttrs(planId, branch)...)
+
+	ctx, cancel := context.WithTimeout(traceCtx, ActivePlanTimeout)
 	// child context for model stream so we can cancel it separately if needed
 	modelStreamCtx, cancelModelStream := context.WithCancel(ctx)
 
 	// we don't want to cancel summaries unless the whole plan is stopped or there's an error -- if the active plan finishes, we want summaries to continue -- so they get their own context
-	summaryCtx, cancelSummary := context.WithCancel(shutdown.ShutdownCtx)
+	summaryCtx, cancelSummary := context.WithCancel(traceCtx)
 
 	active := ActivePlan{
 		Id:                    planId,
@@ -126,6 +137,8 @@ func NewActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, auto
 		AllowOverwritePaths:   map[string]bool{},
 		SkippedPaths:          map[string]bool{},
 		SessionId:             sessionId,
+		StartedAt:             time.Now(),
+		Span:                  span,
 		streamCh:              make(chan string),
 		subscriptions:         map[string]*subscription{},
 		subscriptionMu:        sync.Mutex{},
@@ -56,6 +56,16 @@ func (sm *SafeMap[V]) Keys() []string {
 	return keys
 }
 
+func (sm *SafeMap[V]) Values() []V {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	values := make([]V, 0, len(sm.items))
+	for _, v := range sm.items {
+		values = append(values, v)
+	}
+	return values
+}
+
 func (sm *SafeMap[V]) Len() int {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
@@ -0,0 +1,72 @@
+package shared
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type ApiSpecKind string
+
+const (
+	ApiSpecOpenApi  ApiSpecKind = "openapi"
+	ApiSpecGraphql  ApiSpecKind = "graphql"
+	ApiSpecProtobuf ApiSpecKind = "protobuf"
+)
+
+// API specs are mapped to an outline of their operations and types, so they can be much larger than other mapped files
+const MaxApiSpecMapInputSize = 5 * 1024 * 1024 // 5MB
+
+// only the start of a json or yaml file is checked for the openapi or swagger version key
+const ApiSpecSniffSize = 8 * 1024
+
+var openApiYamlRegex = regexp.MustCompile(`(?m)^["']?(openapi|swagger)["']?\s*:`)
+var openApiJsonRegex = regexp.MustCompile(`"(openapi|swagger)"\s*:\s*"\d`)
+
+// GetApiSpecKind returns the kind of API spec a file is, or "" if it isn't one. GraphQL and protobuf are detected by extension. JSON and YAML files are only OpenAPI (or Swagger) specs if their content has a top-level version key.
+func GetApiSpecKind(path string, content []byte) ApiSpecKind {
+	switch LanguageByExtension[strings.ToLower(filepath.Ext(path))] {
+	case LanguageGraphql:
+		return ApiSpecGraphql
+	case LanguageProtobuf:
+		return ApiSpecProtobuf
+	case LanguageJson:
+		if openApiJsonRegex.Match(apiSpecHead(content)) {
+			return ApiSpecOpenApi
+		}
+	case LanguageYaml:
+		if openApiYamlRegex.Match(apiSpecHead(content)) {
+			return ApiSpecOpenApi
+		}
+	}
+	return ""
+}
+
+// MayBeApiSpec returns true for paths that could be API specs depending on their content
+func MayBeApiSpec(path string) bool {
+	switch LanguageByExtension[strings.ToLower(filepath.Ext(path))] {
+	case LanguageGraphql, LanguageProtobuf, LanguageJson, LanguageYaml:
+		return true
+	}
+	return false
+}
+
+// HasContentFileMapSupport is like HasFileMapSupport, but also maps json and yaml files that turn out to be OpenAPI specs
+func HasContentFileMapSupport(path string, content []byte) bool {
+	return HasFileMapSupport(path) || GetApiSpecKind(path, content) != ""
+}
+
+// MaxFileMapInputSize returns the most of a file that's read for mapping and symbol lookups
+func MaxFileMapInputSize(path string) int {
+	if MayBeApiSpec(path) {
+		return MaxApiSpecMapInputSize
+	}
+	return MaxContextMapSingleInputSize
+}
+
+func apiSpecHead(content []byte) []byte {
+	if len(content) > ApiSpecSniffSize {
+		return content[:ApiSpecSniffSize]
+	}
+	return content
+}
@@ -30,6 +30,10 @@ const (
 	ApiErrorTypeCloudSubscriptionPaused  ApiErrorType = "cloud_subscription_paused"
 	ApiErrorTypeCloudSubscriptionOverdue ApiErrorType = "cloud_subscription_overdue"
 
+	ApiErrorTypeOrgPolicyViolation ApiErrorType = "org_policy_violation"
+
+	ApiErrorTypeRateLimited ApiErrorType = "rate_limited"
+
 	ApiErrorTypeOther ApiErrorType = "other"
 )
 
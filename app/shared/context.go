@@ -31,6 +31,9 @@ type ContextUpdateResult struct {
 	NumImages       int
 	NumTrees        int
 	NumMaps         int
+	NumGitDiffs     int
+	NumCommands     int
+	NumDbSchemas    int
 	MaxTokens       int
 }
 
@@ -59,6 +62,24 @@ func (c *Context) TypeAndIcon() (string, string) {
 	case ContextMapType:
 		icon = "🗺️ "
 		t = "map"
+	case ContextFileRangeType:
+		icon = "✂️ "
+		t = "range"
+	case ContextGitDiffType:
+		icon = "🔀"
+		t = "git"
+	case ContextDocumentType:
+		icon = "📑"
+		t = "doc"
+	case ContextDocsType:
+		icon = "📚"
+		t = "docs"
+	case ContextCommandType:
+		icon = "⚡️"
+		t = "cmd"
+	case ContextDbSchemaType:
+		icon = "🗄️ "
+		t = "db"
 	}
 
 	return t, icon
@@ -117,6 +138,13 @@ func SummaryForLoadContext(contexts []*Context, tokensAdded, totalTokens int) st
 	var numTrees int
 	var numUrls int
 	var numMaps int
+	var numRanges int
+	var numGitDiffs int
+	var numDocuments int
+	var numCommands int
+	var numDbSchemas int
+	// docs bundles can be split into several parts, so they're counted by bundle
+	docsBundles := map[string]bool{}
 
 	for _, context := range contexts {
 		switch context.ContextType {
@@ -132,6 +160,18 @@ func SummaryForLoadContext(contexts []*Context, tokensAdded, totalTokens int) st
 			hasPiped = true
 		case ContextMapType:
 			numMaps++
+		case ContextFileRangeType:
+			numRanges++
+		case ContextGitDiffType:
+			numGitDiffs++
+		case ContextDocumentType:
+			numDocuments++
+		case ContextCommandType:
+			numCommands++
+		case ContextDbSchemaType:
+			numDbSchemas++
+		case ContextDocsType:
+			docsBundles[context.DocsBundle] = true
 		}
 	}
 
@@ -150,6 +190,27 @@ func SummaryForLoadContext(contexts []*Context, tokensAdded, totalTokens int) st
 		}
 		added = append(added, fmt.Sprintf("%d %s", numFiles, label))
 	}
+	if numRanges > 0 {
+		label := "file range"
+		if numRanges > 1 {
+			label = "file ranges"
+		}
+		added = append(added, fmt.Sprintf("%d %s", numRanges, label))
+	}
+	if numGitDiffs > 0 {
+		label := "git diff"
+		if numGitDiffs > 1 {
+			label = "git diffs"
+		}
+		added = append(added, fmt.Sprintf("%d %s", numGitDiffs, label))
+	}
+	if numDocuments > 0 {
+		label := "document"
+		if numDocuments > 1 {
+			label = "documents"
+		}
+		added = append(added, fmt.Sprintf("%d %s", numDocuments, label))
+	}
 	if numTrees > 0 {
 		label := "directory tree"
 		if numTrees > 1 {
@@ -171,6 +232,27 @@ func SummaryForLoadContext(contexts []*Context, tokensAdded, totalTokens int) st
 		}
 		added = append(added, fmt.Sprintf("%d %s", numMaps, label))
 	}
+	if numCommands > 0 {
+		label := "command"
+		if numCommands > 1 {
+			label = "commands"
+		}
+		added = append(added, fmt.Sprintf("%d %s", numCommands, label))
+	}
+	if numDbSchemas > 0 {
+		label := "db schema"
+		if numDbSchemas > 1 {
+			label = "db schemas"
+		}
+		added = append(added, fmt.Sprintf("%d %s", numDbSchemas, label))
+	}
+	if len(docsBundles) > 0 {
+		label := "docs bundle"
+		if len(docsBundles) > 1 {
+			label = "docs bundles"
+		}
+		added = append(added, fmt.Sprintf("%d %s", len(docsBundles), label))
+	}
 
 	msg := "Loaded "
 
@@ -235,12 +317,15 @@ func SummaryForRemoveContext(contexts []*Context, previousTotalTokens int) strin
 }
 
 type SummaryForUpdateContextParams struct {
-	NumFiles    int
-	NumTrees    int
-	NumUrls     int
-	NumMaps     int
-	TokensDiff  int
-	TotalTokens int
+	NumFiles     int
+	NumTrees     int
+	NumUrls      int
+	NumMaps      int
+	NumGitDiffs  int
+	NumCommands  int
+	NumDbSchemas int
+	TokensDiff   int
+	TotalTokens  int
 }
 
 func SummaryForUpdateContext(params SummaryForUpdateContextParams) string {
@@ -248,6 +333,9 @@ func SummaryForUpdateContext(params SummaryForUpdateContextParams) string {
 	numTrees := params.NumTrees
 	numUrls := params.NumUrls
 	numMaps := params.NumMaps
+	numGitDiffs := params.NumGitDiffs
+	numCommands := params.NumCommands
+	numDbSchemas := params.NumDbSchemas
 	tokensDiff := params.TokensDiff
 	totalTokens := params.TotalTokens
 
@@ -281,6 +369,27 @@ func SummaryForUpdateContext(params SummaryForUpdateContextParams) string {
 		}
 		toAdd = append(toAdd, fmt.Sprintf("%d map%s", numMaps, postfix))
 	}
+	if numGitDiffs > 0 {
+		postfix := "s"
+		if numGitDiffs == 1 {
+			postfix = ""
+		}
+		toAdd = append(toAdd, fmt.Sprintf("%d git diff%s", numGitDiffs, postfix))
+	}
+	if numCommands > 0 {
+		postfix := "s"
+		if numCommands == 1 {
+			postfix = ""
+		}
+		toAdd = append(toAdd, fmt.Sprintf("%d command%s", numCommands, postfix))
+	}
+	if numDbSchemas > 0 {
+		postfix := "s"
+		if numDbSchemas == 1 {
+			postfix = ""
+		}
+		toAdd = append(toAdd, fmt.Sprintf("%d db schema%s", numDbSchemas, postfix))
+	}
 
 	if len(toAdd) <= 2 {
 		msg += " " + strings.Join(toAdd, " and ")
@@ -348,3 +457,26 @@ func TableForContextUpdate(updateRes *ContextUpdateResult) string {
 
 	return tableString.String()
 }
+
+// FileRangeContextName is the display name for a file range context - symbol ranges are named by symbol since their lines move as the file changes
+func FileRangeContextName(path string, startLine, endLine int, symbol string) string {
+	if symbol != "" {
+		return fmt.Sprintf("%s::%s", path, symbol)
+	}
+	return fmt.Sprintf("%s#L%d-%d", path, startLine, endLine)
+}
+
+// GetFileRangeBody returns lines startLine through endLine (1-based, inclusive) of a file range context's body, clamped to the file's length
+func GetFileRangeBody(body string, startLine, endLine int) string {
+	lines := strings.Split(body, "\n")
+	if startLine < 1 {
+		startLine = 1
+	}
+	if endLine > len(lines) || endLine < 1 {
+		endLine = len(lines)
+	}
+	if startLine > endLine {
+		return ""
+	}
+	return strings.Join(lines[startLine-1:endLine], "\n")
+}
@@ -0,0 +1,77 @@
+package shared
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ContextSet is a named group of context for one area of a project (e.g. 'payments-backend'). Sets are stored per project on the server so they're shared with the org, and are re-resolved against the current tree each time they're applied with 'plandex context use'.
+type ContextSet struct {
+	Name string `json:"name"`
+	// files, directories, or globs relative to the project root - '**' matches any number of directories
+	Paths []string `json:"paths,omitempty"`
+	// directories to load as maps, relative to the project root
+	Maps  []string `json:"maps,omitempty"`
+	Notes []string `json:"notes,omitempty"`
+	Urls  []string `json:"urls,omitempty"`
+
+	// id of the user who last saved the set - set by the server
+	UpdatedBy string `json:"updatedBy,omitempty"`
+}
+
+func (s *ContextSet) Validate() error {
+	if !planTemplateNameRegex.MatchString(s.Name) {
+		return fmt.Errorf("invalid context set name '%s' - use letters, numbers, '-', '_', or '.'", s.Name)
+	}
+
+	if len(s.Paths) == 0 && len(s.Maps) == 0 && len(s.Notes) == 0 && len(s.Urls) == 0 {
+		return fmt.Errorf("context set '%s' is empty", s.Name)
+	}
+
+	for _, path := range append(append([]string{}, s.Paths...), s.Maps...) {
+		if strings.TrimSpace(path) == "" {
+			return fmt.Errorf("context set '%s' has an empty path", s.Name)
+		}
+		if !IsProjectRelativePath(path) {
+			return fmt.Errorf("path '%s' must be relative to the project root", path)
+		}
+	}
+
+	return nil
+}
+
+// IsProjectRelativePath returns false for absolute paths, windows volume paths, and paths that leave the directory they're relative to once cleaned (like 'src/../../file')
+func IsProjectRelativePath(p string) bool {
+	p = strings.ReplaceAll(p, "\\", "/")
+	if strings.HasPrefix(p, "/") || (len(p) >= 2 && p[1] == ':') {
+		return false
+	}
+	for _, segment := range strings.Split(path.Clean(p), "/") {
+		if segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *ContextSet) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	default:
+		return fmt.Errorf("unsupported data type: %T", src)
+	}
+}
+
+func (s ContextSet) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
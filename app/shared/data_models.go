@@ -92,6 +92,21 @@ const (
 	ContextPipedDataType     ContextType = "piped data"
 	ContextImageType         ContextType = "image"
 	ContextMapType           ContextType = "map"
+	ContextFileRangeType     ContextType = "file range"
+	ContextGitDiffType       ContextType = "git diff"
+	ContextDocumentType      ContextType = "document"
+	ContextDocsType          ContextType = "docs"
+	ContextCommandType       ContextType = "command"
+	ContextDbSchemaType      ContextType = "db schema"
+)
+
+// GitDiffMode is the source of a git diff context
+type GitDiffMode string
+
+const (
+	GitDiffModeDiff   GitDiffMode = "diff"   // working tree compared to a base ref
+	GitDiffModeCommit GitDiffMode = "commit" // a single commit
+	GitDiffModeStaged GitDiffMode = "staged" // staged changes
 )
 
 type FileMapBodies map[string]string
@@ -113,6 +128,15 @@ type Context struct {
 	MapShas         map[string]string     `json:"mapShas,omitempty"`
 	MapTokens       map[string]int        `json:"mapTokens,omitempty"`
 	MapSizes        map[string]int64      `json:"mapSizes,omitempty"`
+	StartLine       int                   `json:"startLine,omitempty"`
+	EndLine         int                   `json:"endLine,omitempty"`
+	Symbol          string                `json:"symbol,omitempty"`
+	GitDiffMode     GitDiffMode           `json:"gitDiffMode,omitempty"`
+	GitRef          string                `json:"gitRef,omitempty"`
+	DocsBundle      string                `json:"docsBundle,omitempty"`
+	Command         string                `json:"command,omitempty"`
+	CommandTimeout  int                   `json:"commandTimeout,omitempty"`
+	DbSource        string                `json:"dbSource,omitempty"`
 	AutoLoaded      bool                  `json:"autoLoaded"`
 	CreatedAt       time.Time             `json:"createdAt"`
 	UpdatedAt       time.Time             `json:"updatedAt"`
@@ -303,8 +327,12 @@ type CurrentPlanState struct {
 type OrgRole struct {
 	Id          string `json:"id"`
 	IsDefault   bool   `json:"isDefault"`
+	Name        string `json:"name"`
 	Label       string `json:"label"`
 	Description string `json:"description"`
+
+	Permissions        []Permission `json:"permissions,omitempty"`
+	ApprovedModelPacks []string     `json:"approvedModelPacks,omitempty"`
 }
 
 type CloudBillingFields struct {
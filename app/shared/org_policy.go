@@ -0,0 +1,313 @@
+package shared
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// OrgPolicy is set by org admins and enforced by the server for all org members. Zero values mean no restriction.
+type OrgPolicy struct {
+	// built-in provider ids (e.g. "openai", "anthropic") or custom provider names
+	AllowedProviders  []string     `json:"allowedProviders,omitempty"`
+	AllowedModelPacks []string     `json:"allowedModelPacks,omitempty"`
+	MaxAutoMode       AutoModeType `json:"maxAutoMode,omitempty"`
+	DisableExec       bool         `json:"disableExec,omitempty"`
+	MaxContextTokens  int          `json:"maxContextTokens,omitempty"`
+}
+
+// ordered from least to most automated
+var autoModeLevels = map[AutoModeType]int{
+	AutoModeNone:  0,
+	AutoModeBasic: 1,
+	AutoModePlus:  2,
+	AutoModeSemi:  3,
+	AutoModeFull:  4,
+}
+
+func (p *OrgPolicy) IsEmpty() bool {
+	return p == nil || (len(p.AllowedProviders) == 0 &&
+		len(p.AllowedModelPacks) == 0 &&
+		p.MaxAutoMode == "" &&
+		!p.DisableExec &&
+		p.MaxContextTokens == 0)
+}
+
+func (p *OrgPolicy) Validate() error {
+	if p.MaxAutoMode != "" {
+		if _, ok := autoModeLevels[p.MaxAutoMode]; !ok {
+			return fmt.Errorf("invalid max auto mode '%s' - must be one of: none, basic, plus, semi, full", p.MaxAutoMode)
+		}
+	}
+
+	if p.MaxContextTokens < 0 {
+		return fmt.Errorf("max context tokens can't be negative")
+	}
+
+	return nil
+}
+
+func (p *OrgPolicy) IsProviderAllowed(provider ModelProviderConfigSchema) bool {
+	if p == nil || len(p.AllowedProviders) == 0 {
+		return true
+	}
+
+	for _, allowed := range p.AllowedProviders {
+		if provider.CustomProvider != nil {
+			if allowed == *provider.CustomProvider {
+				return true
+			}
+		} else if ModelProvider(allowed) == provider.Provider {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *OrgPolicy) IsModelPackAllowed(name string) bool {
+	if p == nil || len(p.AllowedModelPacks) == 0 {
+		return true
+	}
+
+	for _, allowed := range p.AllowedModelPacks {
+		if allowed == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckPlanConfig returns an error describing the first way the config exceeds the policy, or nil if it's allowed
+func (p *OrgPolicy) CheckPlanConfig(config *PlanConfig) error {
+	if p == nil || config == nil {
+		return nil
+	}
+
+	if p.DisableExec && (config.CanExec || config.AutoExec) {
+		return fmt.Errorf("org policy doesn't allow executing commands")
+	}
+
+	if p.MaxAutoMode != "" {
+		if autoModeLevel(config) > autoModeLevels[p.MaxAutoMode] {
+			return fmt.Errorf("org policy doesn't allow an auto mode above '%s'", p.MaxAutoMode)
+		}
+	}
+
+	return nil
+}
+
+// ClampPlanConfig lowers a stored config to the policy's limits, for configs saved before the policy was set
+func (p *OrgPolicy) ClampPlanConfig(config *PlanConfig) {
+	if p == nil || config == nil {
+		return
+	}
+
+	if p.MaxAutoMode != "" && autoModeLevel(config) > autoModeLevels[p.MaxAutoMode] {
+		config.SetAutoMode(p.MaxAutoMode)
+	}
+
+	if p.DisableExec {
+		config.CanExec = false
+		config.AutoExec = false
+		config.AutoDebug = false
+	}
+}
+
+// CheckModelPack returns an error if the settings' model pack isn't allowed, or if any of its models can't be served by an allowed provider
+func (p *OrgPolicy) CheckModelPack(settings *PlanSettings) error {
+	if p == nil || settings == nil {
+		return nil
+	}
+
+	modelPack := settings.GetModelPack()
+	if modelPack == nil {
+		return nil
+	}
+
+	if !p.IsModelPackAllowed(modelPack.Name) {
+		return fmt.Errorf("org policy doesn't allow the '%s' model pack. Allowed model packs: %s", modelPack.Name, strings.Join(p.AllowedModelPacks, ", "))
+	}
+
+	if len(p.AllowedProviders) == 0 {
+		return nil
+	}
+
+	for _, roleConfig := range modelPackRoleConfigs(modelPack) {
+		modelId := roleConfig.GetModelId()
+
+		usesProviders := append([]BaseModelUsesProvider{}, BuiltInModelProvidersByModelId[modelId]...)
+		usesProviders = append(usesProviders, settings.UsesCustomProviderByModelId[modelId]...)
+
+		found := false
+		for _, usesProvider := range usesProviders {
+			if p.IsProviderAllowed(ModelProviderConfigSchema{Provider: usesProvider.Provider, CustomProvider: usesProvider.CustomProvider}) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("org policy doesn't allow any provider for model '%s' (%s role). Allowed providers: %s", modelId, roleConfig.Role, strings.Join(p.AllowedProviders, ", "))
+		}
+	}
+
+	return nil
+}
+
+// FilterAuthVars drops credentials that are only used by providers the policy doesn't allow, so model resolution can't select those providers
+func (p *OrgPolicy) FilterAuthVars(authVars map[string]string, settings *PlanSettings) map[string]string {
+	if p == nil || len(p.AllowedProviders) == 0 {
+		return authVars
+	}
+
+	allowedVars := map[string]bool{}
+	disallowedVars := map[string]bool{}
+
+	for _, provider := range policyProviderConfigs(settings) {
+		vars := []string{}
+		if provider.ApiKeyEnvVar != "" {
+			vars = append(vars, provider.ApiKeyEnvVar)
+		}
+		for _, extraAuthVar := range provider.ExtraAuthVars {
+			vars = append(vars, extraAuthVar.Var)
+		}
+		if provider.HasClaudeMaxAuth {
+			vars = append(vars, AnthropicClaudeMaxTokenEnvVar)
+		}
+
+		for _, v := range vars {
+			if p.IsProviderAllowed(provider) {
+				allowedVars[v] = true
+			} else {
+				disallowedVars[v] = true
+			}
+		}
+	}
+
+	res := make(map[string]string, len(authVars))
+	for k, v := range authVars {
+		if disallowedVars[k] && !allowedVars[k] {
+			continue
+		}
+		res[k] = v
+	}
+
+	return res
+}
+
+// CheckResolvedProviders returns an error if model resolution would select a provider the policy doesn't allow for any role in the model pack. Call after FilterAuthVars — this catches providers that don't require credentials, like local providers.
+func (p *OrgPolicy) CheckResolvedProviders(authVars map[string]string, settings *PlanSettings, orgUserConfig *OrgUserConfig) error {
+	if p == nil || len(p.AllowedProviders) == 0 || settings == nil {
+		return nil
+	}
+
+	modelPack := settings.GetModelPack()
+	if modelPack == nil {
+		return nil
+	}
+
+	for _, roleConfig := range modelPackRoleConfigs(modelPack) {
+		provider := roleConfig.GetFirstProviderForAuthVars(authVars, settings, orgUserConfig)
+		if provider != nil && !p.IsProviderAllowed(*provider) {
+			return fmt.Errorf("org policy doesn't allow the '%s' provider for model '%s' (%s role). Allowed providers: %s", provider.ToComposite(), roleConfig.GetModelId(), roleConfig.Role, strings.Join(p.AllowedProviders, ", "))
+		}
+	}
+
+	return nil
+}
+
+func (p *OrgPolicy) Describe() string {
+	if p.IsEmpty() {
+		return "No restrictions"
+	}
+
+	var lines []string
+	if len(p.AllowedProviders) > 0 {
+		lines = append(lines, "Allowed providers: "+strings.Join(p.AllowedProviders, ", "))
+	}
+	if len(p.AllowedModelPacks) > 0 {
+		lines = append(lines, "Allowed model packs: "+strings.Join(p.AllowedModelPacks, ", "))
+	}
+	if p.MaxAutoMode != "" {
+		lines = append(lines, "Max auto mode: "+string(p.MaxAutoMode))
+	}
+	if p.DisableExec {
+		lines = append(lines, "Command execution: disabled")
+	}
+	if p.MaxContextTokens > 0 {
+		lines = append(lines, fmt.Sprintf("Max context tokens: %d", p.MaxContextTokens))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func modelPackRoleConfigs(modelPack *ModelPack) []ModelRoleConfig {
+	return []ModelRoleConfig{
+		modelPack.Planner.ModelRoleConfig,
+		modelPack.GetCoder(),
+		modelPack.PlanSummary,
+		modelPack.Builder,
+		modelPack.GetWholeFileBuilder(),
+		modelPack.Namer,
+		modelPack.CommitMsg,
+		modelPack.ExecStatus,
+		modelPack.GetArchitect(),
+	}
+}
+
+func policyProviderConfigs(settings *PlanSettings) []ModelProviderConfigSchema {
+	providers := []ModelProviderConfigSchema{}
+	for _, providerConfig := range BuiltInModelProviderConfigs {
+		providers = append(providers, providerConfig)
+	}
+	if settings != nil {
+		for _, customProvider := range settings.CustomProviders {
+			providers = append(providers, customProvider.ToModelProviderConfigSchema())
+		}
+	}
+	return providers
+}
+
+// custom configs are ranked by the most automated setting they enable
+func autoModeLevel(config *PlanConfig) int {
+	if config.AutoMode != AutoModeCustom {
+		if level, ok := autoModeLevels[config.AutoMode]; ok {
+			return level
+		}
+	}
+
+	switch {
+	case config.AutoApply || config.AutoExec || config.AutoDebug:
+		return autoModeLevels[AutoModeFull]
+	case config.AutoLoadContext:
+		return autoModeLevels[AutoModeSemi]
+	case config.AutoUpdateContext || config.SmartContext:
+		return autoModeLevels[AutoModePlus]
+	case config.AutoContinue || config.AutoBuild:
+		return autoModeLevels[AutoModeBasic]
+	}
+
+	return autoModeLevels[AutoModeNone]
+}
+
+func (p *OrgPolicy) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+
+	switch s := src.(type) {
+	case []byte:
+		return json.Unmarshal(s, p)
+	case string:
+		return json.Unmarshal([]byte(s), p)
+	default:
+		return fmt.Errorf("unsupported data type: %T", src)
+	}
+}
+
+func (p OrgPolicy) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
@@ -66,6 +66,18 @@ type PlanConfig struct {
 	AutoLoadContext   bool `json:"autoContext"`
 	SmartContext      bool `json:"smartContext"`
 
+	// re-run commands loaded with 'plandex load --cmd' each time context is checked
+	CommandContext bool `json:"commandContext"`
+
+	// rank loaded context by relevance and downgrade or drop the least relevant parts when it doesn't fit
+	AutoPruneContext bool `json:"autoPruneContext"`
+	// max tokens of loaded context sent with each prompt when pruning - 0 fits context to the model's limit
+	ContextBudget int `json:"contextBudget"`
+
+	Retrieval         bool   `json:"retrieval"`
+	EmbeddingsBaseUrl string `json:"embeddingsBaseUrl"`
+	EmbeddingsModel   string `json:"embeddingsModel"`
+
 	// AutoApproveContext bool `json:"autoApproveContext"`
 	// QuietContext       bool `json:"quietContext"`
 
@@ -87,6 +99,10 @@ type PlanConfig struct {
 
 	SkipChangesMenu bool `json:"skipChangesMenu"`
 
+	StreamSplitView bool `json:"streamSplitView"`
+
+	PRRemote string `json:"prRemote"`
+
 	// ReplMode    bool     `json:"replMode"`
 	// DefaultRepl ReplType `json:"defaultRepl"`
 
@@ -98,6 +114,37 @@ type PlanConfig struct {
 
 var DefaultPlanConfig = PlanConfig{}
 
+const DefaultPRRemote = "origin"
+
+var PRRemoteChoices = []string{DefaultPRRemote, "upstream"}
+
+func (p *PlanConfig) GetPRRemote() string {
+	if p.PRRemote == "" {
+		return DefaultPRRemote
+	}
+	return p.PRRemote
+}
+
+const DefaultEmbeddingsBaseUrl = "https://api.openai.com/v1"
+const DefaultEmbeddingsModel = "text-embedding-3-small"
+
+var EmbeddingsBaseUrlChoices = []string{DefaultEmbeddingsBaseUrl, "http://localhost:11434/v1"}
+var EmbeddingsModelChoices = []string{DefaultEmbeddingsModel, "text-embedding-3-large", "nomic-embed-text"}
+
+func (p *PlanConfig) GetEmbeddingsBaseUrl() string {
+	if p.EmbeddingsBaseUrl == "" {
+		return DefaultEmbeddingsBaseUrl
+	}
+	return p.EmbeddingsBaseUrl
+}
+
+func (p *PlanConfig) GetEmbeddingsModel() string {
+	if p.EmbeddingsModel == "" {
+		return DefaultEmbeddingsModel
+	}
+	return p.EmbeddingsModel
+}
+
 func (p *PlanConfig) Scan(src interface{}) error {
 	if src == nil {
 		*p = DefaultPlanConfig
@@ -486,6 +533,95 @@ var ConfigSettingsByKey = map[string]ConfigSetting{
 			return fmt.Sprintf("%t", p.SkipChangesMenu)
 		},
 	},
+	"commandcontext": {
+		Name: "command-context",
+		Desc: "Allow loading command output as context with 'plandex load --cmd', and re-run those commands whenever context is checked for updates",
+		BoolSetter: func(p *PlanConfig, enabled bool) {
+			p.CommandContext = enabled
+		},
+		Getter: func(p *PlanConfig) string {
+			return fmt.Sprintf("%t", p.CommandContext)
+		},
+	},
+	"autoprunecontext": {
+		Name: "auto-prune-context",
+		Desc: "When loaded context doesn't fit with the conversation, rank it by relevance to the prompt and current task, then send the least relevant files as maps or leave them out",
+		BoolSetter: func(p *PlanConfig, enabled bool) {
+			p.AutoPruneContext = enabled
+		},
+		Getter: func(p *PlanConfig) string {
+			return fmt.Sprintf("%t", p.AutoPruneContext)
+		},
+	},
+	"contextbudget": {
+		Name: "context-budget",
+		Desc: "Max tokens of loaded context sent with each prompt when auto-prune-context is on (0 fits context to the model's limit)",
+		Visible: func(p *PlanConfig) bool {
+			return p.AutoPruneContext
+		},
+		IntSetter: func(p *PlanConfig, value int) {
+			p.ContextBudget = max(value, 0)
+		},
+		Getter: func(p *PlanConfig) string {
+			return fmt.Sprintf("%d", p.ContextBudget)
+		},
+	},
+	"retrieval": {
+		Name: "retrieval",
+		Desc: "Search a local embeddings index of the project for code related to the prompt, and offer it alongside the project map when auto-loading context",
+		BoolSetter: func(p *PlanConfig, enabled bool) {
+			p.Retrieval = enabled
+		},
+		Getter: func(p *PlanConfig) string {
+			return fmt.Sprintf("%t", p.Retrieval)
+		},
+	},
+	"embeddingsbaseurl": {
+		Name: "embeddings-base-url",
+		Desc: "Base URL of the OpenAI-compatible embeddings endpoint used for retrieval",
+		StringSetter: func(p *PlanConfig, value string) {
+			p.EmbeddingsBaseUrl = strings.TrimRight(strings.TrimSpace(value), "/")
+		},
+		Getter: func(p *PlanConfig) string {
+			return p.GetEmbeddingsBaseUrl()
+		},
+		Choices:         &EmbeddingsBaseUrlChoices,
+		HasCustomChoice: true,
+	},
+	"embeddingsmodel": {
+		Name: "embeddings-model",
+		Desc: "Embeddings model used for retrieval",
+		StringSetter: func(p *PlanConfig, value string) {
+			p.EmbeddingsModel = strings.TrimSpace(value)
+		},
+		Getter: func(p *PlanConfig) string {
+			return p.GetEmbeddingsModel()
+		},
+		Choices:         &EmbeddingsModelChoices,
+		HasCustomChoice: true,
+	},
+	"splitview": {
+		Name: "split-view",
+		Desc: "Show the reply, file build status, and a live diff of the selected file side by side while streaming",
+		BoolSetter: func(p *PlanConfig, enabled bool) {
+			p.StreamSplitView = enabled
+		},
+		Getter: func(p *PlanConfig) string {
+			return fmt.Sprintf("%t", p.StreamSplitView)
+		},
+	},
+	"prremote": {
+		Name: "pr-remote",
+		Desc: "Git remote to push to with 'plandex pr --push'",
+		StringSetter: func(p *PlanConfig, value string) {
+			p.PRRemote = strings.TrimSpace(value)
+		},
+		Getter: func(p *PlanConfig) string {
+			return p.GetPRRemote()
+		},
+		Choices:         &PRRemoteChoices,
+		HasCustomChoice: true,
+	},
 }
 
 func init() {
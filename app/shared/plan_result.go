@@ -12,6 +12,19 @@ func (rep *Replacement) SetRejected(t time.Time) {
 	rep.RejectedAt = &t
 }
 
+// UnrejectedReplacements returns the replacements that should still be applied
+// when computing the result's updated file, skipping any that were rejected
+// individually during review
+func (res *PlanFileResult) UnrejectedReplacements() []*Replacement {
+	replacements := make([]*Replacement, 0, len(res.Replacements))
+	for _, rep := range res.Replacements {
+		if rep.RejectedAt == nil {
+			replacements = append(replacements, rep)
+		}
+	}
+	return replacements
+}
+
 func (res *PlanFileResult) NumPendingReplacements() int {
 	numPending := 0
 	for _, rep := range res.Replacements {
@@ -87,7 +100,8 @@ func (p PlanFileResultsByPath) ConflictedPaths(filesByPath map[string]string) ma
 		for _, res := range planRes {
 
 			// log.Println("res:", res.Id)
-			if len(res.Replacements) == 0 {
+			replacements := res.UnrejectedReplacements()
+			if len(replacements) == 0 {
 				continue
 			}
 
@@ -97,7 +111,7 @@ func (p PlanFileResultsByPath) ConflictedPaths(filesByPath map[string]string) ma
 			}
 
 			var succeeded bool
-			updated, succeeded = ApplyReplacements(maybeWithLineNums, res.Replacements, false)
+			updated, succeeded = ApplyReplacements(maybeWithLineNums, replacements, false)
 
 			updated = RemoveLineNums(LineNumberedTextType(updated))
 
@@ -222,6 +222,10 @@ func (planState *CurrentPlanState) GetFilesBeforeReplacement(
 					foundTarget = true
 					break
 				}
+				if replacement.RejectedAt != nil {
+					// rejected individually during review
+					continue
+				}
 				replacements = append(replacements, replacement)
 			}
 
@@ -0,0 +1,197 @@
+package shared
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// PlanTemplate is a reusable multi-step task. Templates are stored per org on the server or as json files in a project's .plandex-templates directory, and are instantiated with 'plandex new --template'.
+type PlanTemplate struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Vars        []PlanTemplateVar `json:"vars,omitempty"`
+	// files, directories, globs, or urls to load into context - may include {{var}} placeholders
+	Context   []string `json:"context,omitempty"`
+	ModelPack string   `json:"modelPack,omitempty"`
+	// a partial PlanConfig - only the fields it includes are applied over the default config
+	PlanConfig json.RawMessage    `json:"planConfig,omitempty"`
+	Steps      []PlanTemplateStep `json:"steps"`
+}
+
+// PlanTemplateVar is a parameter referenced as {{name}} in a template's prompts and context patterns. Vars without a default are required.
+type PlanTemplateVar struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Default     string `json:"default,omitempty"`
+}
+
+type PlanTemplateStep struct {
+	Title  string `json:"title,omitempty"`
+	Prompt string `json:"prompt"`
+}
+
+var planTemplateNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+var planTemplateVarRegex = regexp.MustCompile(`{{\s*([a-zA-Z0-9_-]+)\s*}}`)
+
+func (t *PlanTemplate) Validate() error {
+	if !planTemplateNameRegex.MatchString(t.Name) {
+		return fmt.Errorf("invalid template name '%s' - use letters, numbers, '-', '_', or '.'", t.Name)
+	}
+
+	if len(t.Steps) == 0 {
+		return fmt.Errorf("template must have at least one step")
+	}
+
+	declared := map[string]bool{}
+	for _, v := range t.Vars {
+		if !planTemplateVarRegex.MatchString("{{" + v.Name + "}}") {
+			return fmt.Errorf("invalid var name '%s' - use letters, numbers, '-', or '_'", v.Name)
+		}
+		if declared[v.Name] {
+			return fmt.Errorf("var '%s' is declared more than once", v.Name)
+		}
+		declared[v.Name] = true
+	}
+
+	checkRefs := func(s string) error {
+		for _, match := range planTemplateVarRegex.FindAllStringSubmatch(s, -1) {
+			if !declared[match[1]] {
+				return fmt.Errorf("'{{%s}}' isn't a declared var", match[1])
+			}
+		}
+		return nil
+	}
+
+	for i, step := range t.Steps {
+		if strings.TrimSpace(step.Prompt) == "" {
+			return fmt.Errorf("step %d has no prompt", i+1)
+		}
+		if err := checkRefs(step.Title); err != nil {
+			return fmt.Errorf("step %d title: %v", i+1, err)
+		}
+		if err := checkRefs(step.Prompt); err != nil {
+			return fmt.Errorf("step %d prompt: %v", i+1, err)
+		}
+	}
+
+	for _, pattern := range t.Context {
+		if err := checkRefs(pattern); err != nil {
+			return fmt.Errorf("context pattern '%s': %v", pattern, err)
+		}
+	}
+
+	if len(t.PlanConfig) > 0 {
+		var config PlanConfig
+		if err := json.Unmarshal(t.PlanConfig, &config); err != nil {
+			return fmt.Errorf("invalid plan config: %v", err)
+		}
+		if config.AutoMode != "" {
+			if _, ok := autoModeLevels[config.AutoMode]; !ok {
+				return fmt.Errorf("invalid auto mode '%s' - must be one of: none, basic, plus, semi, full", config.AutoMode)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Render returns a copy of the template with {{var}} placeholders replaced. Vars that aren't passed fall back to their defaults, and it's an error to leave a required var unset or to pass one the template doesn't declare.
+func (t *PlanTemplate) Render(vars map[string]string) (*PlanTemplate, error) {
+	values := map[string]string{}
+	var missing []string
+
+	for _, v := range t.Vars {
+		if value, ok := vars[v.Name]; ok {
+			values[v.Name] = value
+		} else if v.Default != "" {
+			values[v.Name] = v.Default
+		} else {
+			missing = append(missing, v.Name)
+		}
+	}
+
+	for name := range vars {
+		if _, ok := values[name]; !ok {
+			return nil, fmt.Errorf("template '%s' has no var '%s'", t.Name, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("template '%s' is missing required vars: %s", t.Name, strings.Join(missing, ", "))
+	}
+
+	replace := func(s string) string {
+		return planTemplateVarRegex.ReplaceAllStringFunc(s, func(match string) string {
+			name := planTemplateVarRegex.FindStringSubmatch(match)[1]
+			if value, ok := values[name]; ok {
+				return value
+			}
+			return match
+		})
+	}
+
+	rendered := *t
+	rendered.Context = make([]string, len(t.Context))
+	for i, pattern := range t.Context {
+		rendered.Context[i] = replace(pattern)
+	}
+	rendered.Steps = make([]PlanTemplateStep, len(t.Steps))
+	for i, step := range t.Steps {
+		rendered.Steps[i] = PlanTemplateStep{
+			Title:  replace(step.Title),
+			Prompt: replace(step.Prompt),
+		}
+	}
+
+	return &rendered, nil
+}
+
+// ApplyPlanConfig returns a copy of config with the template's partial config applied. An auto mode in the template is applied first so that any other fields it sets take precedence over the auto mode's defaults.
+func (t *PlanTemplate) ApplyPlanConfig(config *PlanConfig) (*PlanConfig, error) {
+	updated := *config
+	// cloned so decoding into the copy can't reuse the original's backing array
+	updated.EditorArgs = slices.Clone(config.EditorArgs)
+	if len(t.PlanConfig) == 0 {
+		return &updated, nil
+	}
+
+	var partial struct {
+		AutoMode AutoModeType `json:"autoMode"`
+	}
+	if err := json.Unmarshal(t.PlanConfig, &partial); err != nil {
+		return nil, fmt.Errorf("invalid plan config: %v", err)
+	}
+
+	if partial.AutoMode != "" {
+		updated.SetAutoMode(partial.AutoMode)
+	}
+
+	if err := json.Unmarshal(t.PlanConfig, &updated); err != nil {
+		return nil, fmt.Errorf("invalid plan config: %v", err)
+	}
+
+	return &updated, nil
+}
+
+func (t *PlanTemplate) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+
+	switch s := src.(type) {
+	case []byte:
+		return json.Unmarshal(s, t)
+	case string:
+		return json.Unmarshal([]byte(s), t)
+	default:
+		return fmt.Errorf("unsupported data type: %T", src)
+	}
+}
+
+func (t PlanTemplate) Value() (driver.Value, error) {
+	return json.Marshal(t)
+}
@@ -0,0 +1,52 @@
+package shared
+
+import "fmt"
+
+type RateLimitMode string
+
+const (
+	// RateLimitModeReject fails requests that are over a limit
+	RateLimitModeReject RateLimitMode = "reject"
+	// RateLimitModeWait queues requests that are over a limit until they fit or the wait timeout is reached
+	RateLimitModeWait RateLimitMode = "wait"
+)
+
+// RateLimits are enforced by the server before starting plan streams. Zero values mean no limit.
+type RateLimits struct {
+	MaxConcurrentPlans   int `json:"maxConcurrentPlans,omitempty"`
+	MaxRequestsPerMinute int `json:"maxRequestsPerMinute,omitempty"`
+	MaxTokensPerMinute   int `json:"maxTokensPerMinute,omitempty"`
+}
+
+func (l RateLimits) IsEmpty() bool {
+	return l.MaxConcurrentPlans == 0 && l.MaxRequestsPerMinute == 0 && l.MaxTokensPerMinute == 0
+}
+
+type RateLimitUsage struct {
+	ActivePlans        int `json:"activePlans"`
+	RequestsLastMinute int `json:"requestsLastMinute"`
+	TokensLastMinute   int `json:"tokensLastMinute"`
+}
+
+type RateLimitStatusResponse struct {
+	Mode               RateLimitMode  `json:"mode"`
+	WaitTimeoutSeconds int            `json:"waitTimeoutSeconds"`
+	UserLimits         RateLimits     `json:"userLimits"`
+	OrgLimits          RateLimits     `json:"orgLimits"`
+	User               RateLimitUsage `json:"user"`
+	Org                RateLimitUsage `json:"org"`
+}
+
+// Exceeded returns a description of the first limit the usage has reached, or an empty string if it's within all limits
+func (l RateLimits) Exceeded(usage RateLimitUsage) string {
+	if l.MaxConcurrentPlans > 0 && usage.ActivePlans >= l.MaxConcurrentPlans {
+		return fmt.Sprintf("%d concurrent plan streams", l.MaxConcurrentPlans)
+	}
+	if l.MaxRequestsPerMinute > 0 && usage.RequestsLastMinute >= l.MaxRequestsPerMinute {
+		return fmt.Sprintf("%d requests per minute", l.MaxRequestsPerMinute)
+	}
+	if l.MaxTokensPerMinute > 0 && usage.TokensLastMinute >= l.MaxTokensPerMinute {
+		return fmt.Sprintf("%d tokens per minute", l.MaxTokensPerMinute)
+	}
+	return ""
+}
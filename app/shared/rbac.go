@@ -7,27 +7,86 @@ import (
 type Permission string
 
 const (
-	PermissionDeleteOrg             Permission = "delete_org"
-	PermissionManageEmailDomainAuth Permission = "manage_email_domain_auth"
-	PermissionManageBilling         Permission = "manage_billing"
-	PermissionInviteUser            Permission = "invite_user"
-	PermissionRemoveUser            Permission = "remove_user"
-	PermissionSetUserRole           Permission = "set_user_role"
-	PermissionListOrgRoles          Permission = "list_org_roles"
-	PermissionCreateProject         Permission = "create_project"
-	PermissionRenameAnyProject      Permission = "rename_any_project"
-	PermissionDeleteAnyProject      Permission = "delete_any_project"
-	PermissionCreatePlan            Permission = "create_plan"
-	PermissionManageAnyPlanShares   Permission = "manage_any_plan_shares"
-	PermissionRenameAnyPlan         Permission = "rename_any_plan"
-	PermissionDeleteAnyPlan         Permission = "delete_any_plan"
-	PermissionUpdateAnyPlan         Permission = "update_any_plan"
-	PermissionArchiveAnyPlan        Permission = "archive_any_plan"
+	PermissionDeleteOrg               Permission = "delete_org"
+	PermissionManageEmailDomainAuth   Permission = "manage_email_domain_auth"
+	PermissionManageBilling           Permission = "manage_billing"
+	PermissionInviteUser              Permission = "invite_user"
+	PermissionRemoveUser              Permission = "remove_user"
+	PermissionSetUserRole             Permission = "set_user_role"
+	PermissionListOrgRoles            Permission = "list_org_roles"
+	PermissionCreateProject           Permission = "create_project"
+	PermissionRenameAnyProject        Permission = "rename_any_project"
+	PermissionDeleteAnyProject        Permission = "delete_any_project"
+	PermissionCreatePlan              Permission = "create_plan"
+	PermissionManageAnyPlanShares     Permission = "manage_any_plan_shares"
+	PermissionRenameAnyPlan           Permission = "rename_any_plan"
+	PermissionDeleteAnyPlan           Permission = "delete_any_plan"
+	PermissionUpdateAnyPlan           Permission = "update_any_plan"
+	PermissionArchiveAnyPlan          Permission = "archive_any_plan"
+	PermissionManageOrgSecrets        Permission = "manage_org_secrets"
+	PermissionManageOrgRoles          Permission = "manage_org_roles"
+	PermissionApplyPlan               Permission = "apply_plan"
+	PermissionExecCommands            Permission = "exec_commands"
+	PermissionSelectAnyModelPack      Permission = "select_any_model_pack"
+	PermissionSelectApprovedModelPack Permission = "select_approved_model_pack"
+	PermissionManageOrgPolicy         Permission = "manage_org_policy"
+	PermissionManagePlanTemplates     Permission = "manage_plan_templates"
 )
 
+// CustomRolePermissions are the permissions that can be granted to a custom org role. Org-level permissions like deleting the org or managing billing, and the role-scoped user management permissions, are reserved for the built-in roles.
+var CustomRolePermissions = []Permission{
+	PermissionListOrgRoles,
+	PermissionCreateProject,
+	PermissionRenameAnyProject,
+	PermissionDeleteAnyProject,
+	PermissionCreatePlan,
+	PermissionManageAnyPlanShares,
+	PermissionRenameAnyPlan,
+	PermissionDeleteAnyPlan,
+	PermissionUpdateAnyPlan,
+	PermissionArchiveAnyPlan,
+	PermissionApplyPlan,
+	PermissionExecCommands,
+	PermissionSelectAnyModelPack,
+	PermissionSelectApprovedModelPack,
+	PermissionManagePlanTemplates,
+}
+
+var CustomRolePermissionDescriptions = map[Permission]string{
+	PermissionListOrgRoles:            "List org roles",
+	PermissionCreateProject:           "Create a project",
+	PermissionRenameAnyProject:        "Rename any project",
+	PermissionDeleteAnyProject:        "Delete any project",
+	PermissionCreatePlan:              "Create a plan",
+	PermissionManageAnyPlanShares:     "Unshare a plan any user shared",
+	PermissionRenameAnyPlan:           "Rename any plan",
+	PermissionDeleteAnyPlan:           "Delete any plan",
+	PermissionUpdateAnyPlan:           "Update any plan",
+	PermissionArchiveAnyPlan:          "Archive any plan",
+	PermissionApplyPlan:               "Apply pending changes to project files",
+	PermissionExecCommands:            "Execute commands after applying changes",
+	PermissionSelectAnyModelPack:      "Select any model pack",
+	PermissionSelectApprovedModelPack: "Select model packs approved for the role",
+	PermissionManagePlanTemplates:     "Create, update, or delete org plan templates",
+}
+
+func IsCustomRolePermission(permission Permission) bool {
+	for _, p := range CustomRolePermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
 type Permissions map[string]bool
 
 func (perms Permissions) HasPermission(permission Permission) bool {
+	// composite permissions like "invite_user|roleId" must match exactly
+	if strings.Contains(string(permission), "|") {
+		return perms[string(permission)]
+	}
+
 	for p := range perms {
 		split := strings.Split(p, "|")
 		perm := Permission(split[0])
@@ -42,6 +101,9 @@ func (perms Permissions) HasPermission(permission Permission) bool {
 func (perms Permissions) HasPermissionForResource(permission Permission, resourceId string) bool {
 	for p := range perms {
 		split := strings.Split(p, "|")
+		if len(split) < 2 {
+			continue
+		}
 		perm := Permission(split[0])
 		resId := split[1]
 
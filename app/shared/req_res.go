@@ -73,6 +73,37 @@ type InviteRequest struct {
 	OrgRoleId string `json:"orgRoleId"`
 }
 
+type CreateOrgRoleRequest struct {
+	Name               string       `json:"name"`
+	Label              string       `json:"label"`
+	Description        string       `json:"description"`
+	Permissions        []Permission `json:"permissions"`
+	ApprovedModelPacks []string     `json:"approvedModelPacks"`
+}
+
+type UpdateOrgRoleRequest struct {
+	Label              string       `json:"label"`
+	Description        string       `json:"description"`
+	Permissions        []Permission `json:"permissions"`
+	ApprovedModelPacks []string     `json:"approvedModelPacks"`
+}
+
+type SetOrgUserRoleRequest struct {
+	OrgRoleId string `json:"orgRoleId"`
+}
+
+type UpdateOrgPolicyRequest struct {
+	Policy *OrgPolicy `json:"policy"`
+}
+
+type UpsertPlanTemplateRequest struct {
+	Template *PlanTemplate `json:"template"`
+}
+
+type UpsertContextSetRequest struct {
+	ContextSet *ContextSet `json:"contextSet"`
+}
+
 type CreateProjectRequest struct {
 	Name string `json:"name"`
 }
@@ -136,10 +167,11 @@ type TellPlanRequest struct {
 
 	AuthVars map[string]string `json:"authVars"`
 
-	ProjectPaths           map[string]bool `json:"projectPaths"`
-	IsImplementationOfChat bool            `json:"isImplementationOfChat"`
-	IsGitRepo              bool            `json:"isGitRepo"`
-	SessionId              string          `json:"sessionId"`
+	ProjectPaths           map[string]bool   `json:"projectPaths"`
+	RetrievedChunks        []*RetrievedChunk `json:"retrievedChunks,omitempty"`
+	IsImplementationOfChat bool              `json:"isImplementationOfChat"`
+	IsGitRepo              bool              `json:"isGitRepo"`
+	SessionId              string            `json:"sessionId"`
 }
 
 type BuildPlanRequest struct {
@@ -194,6 +226,26 @@ type LoadContextParams struct {
 	ImageDetail     openai.ImageURLDetail `json:"imageDetail"`
 	AutoLoaded      bool                  `json:"autoLoaded"`
 
+	// For file ranges - line numbers are 1-based and inclusive, and the body is the full file
+	// For documents - the 1-based inclusive range of pages or sections in this part, and the body is the extracted text
+	StartLine int    `json:"startLine"`
+	EndLine   int    `json:"endLine"`
+	Symbol    string `json:"symbol"`
+
+	// For git diffs - the mode and ref are kept so the diff can be refreshed
+	GitDiffMode GitDiffMode `json:"gitDiffMode"`
+	GitRef      string      `json:"gitRef"`
+
+	// For docs - the name of the crawled docs bundle cached in the project, which the body is rendered from
+	DocsBundle string `json:"docsBundle"`
+
+	// For commands - the shell command that's re-run when context is checked, and its timeout in seconds
+	Command        string `json:"command"`
+	CommandTimeout int    `json:"commandTimeout"`
+
+	// For database schemas - the connection string with any password removed. Full connection strings are only kept locally by the cli.
+	DbSource string `json:"dbSource"`
+
 	InputShas   map[string]string `json:"inputShas"`
 	InputTokens map[string]int    `json:"inputTokens"`
 	InputSizes  map[string]int64  `json:"inputSizes"`
@@ -226,6 +278,8 @@ type UpdateContextParams struct {
 	InputSizes      map[string]int64  `json:"inputSizes"`
 	MapBodies       FileMapBodies     `json:"mapBodies"`
 	RemovedMapPaths []string          `json:"removedMapPaths"`
+	StartLine       int               `json:"startLine"`
+	EndLine         int               `json:"endLine"`
 }
 
 type GetFileMapRequest struct {
@@ -236,6 +290,45 @@ type GetFileMapResponse struct {
 	MapBodies FileMapBodies `json:"mapBodies"`
 }
 
+// FileChunk is a range of lines in a file that's embedded as a unit for retrieval. Line numbers are 1-based and inclusive.
+type FileChunk struct {
+	StartLine int    `json:"startLine"`
+	EndLine   int    `json:"endLine"`
+	Symbol    string `json:"symbol,omitempty"`
+}
+
+type GetFileChunksRequest struct {
+	ChunkInputs FileMapInputs `json:"chunkInputs"`
+}
+
+type GetFileChunksResponse struct {
+	ChunksByPath map[string][]*FileChunk `json:"chunksByPath"`
+}
+
+// RetrievedChunk is a chunk found by embeddings search that's offered to the model alongside the project map
+type RetrievedChunk struct {
+	Path      string  `json:"path"`
+	StartLine int     `json:"startLine"`
+	EndLine   int     `json:"endLine"`
+	Symbol    string  `json:"symbol,omitempty"`
+	Score     float64 `json:"score"`
+}
+
+type FileSymbolLookup struct {
+	Path   string `json:"path"`
+	Symbol string `json:"symbol"`
+}
+
+type GetFileSymbolsRequest struct {
+	SymbolInputs FileMapInputs       `json:"symbolInputs"`
+	Lookups      []*FileSymbolLookup `json:"lookups"`
+}
+
+// GetFileSymbolsResponse has a range for each lookup in the request, in the same order. Symbols that weren't found are nil.
+type GetFileSymbolsResponse struct {
+	Ranges []*FileChunk `json:"ranges"`
+}
+
 type LoadCachedFileMapRequest struct {
 	FilePaths []string `json:"filePaths"`
 }
@@ -275,6 +368,25 @@ type RejectFilesRequest struct {
 	Paths []string `json:"paths"`
 }
 
+type ReplacementRef struct {
+	ResultId      string `json:"resultId"`
+	ReplacementId string `json:"replacementId"`
+}
+
+type ReplacementEdit struct {
+	ResultId      string `json:"resultId"`
+	ReplacementId string `json:"replacementId"`
+	New           string `json:"new"`
+}
+
+type ReviewReplacementsRequest struct {
+	Rejected []ReplacementRef  `json:"rejected"`
+	Edited   []ReplacementEdit `json:"edited"`
+
+	// results without replacements (full file writes or removals) can only be rejected as a whole
+	RejectedResults []string `json:"rejectedResults"`
+}
+
 type RewindPlanRequest struct {
 	Sha string `json:"sha"`
 }
@@ -289,10 +401,106 @@ type LogResponse struct {
 	Body string   `json:"body"`
 }
 
+type PlanLogContextChangeType string
+
+const (
+	PlanLogContextAdded   PlanLogContextChangeType = "added"
+	PlanLogContextRemoved PlanLogContextChangeType = "removed"
+	PlanLogContextUpdated PlanLogContextChangeType = "updated"
+)
+
+type PlanLogContextChange struct {
+	Change PlanLogContextChangeType `json:"change"`
+	// context metadata only - the body isn't included
+	Context *Context `json:"context"`
+}
+
+type PlanLogDiffResponse struct {
+	FromSha string `json:"fromSha"`
+	ToSha   string `json:"toSha"`
+	// git diff of the plan's pending files at FromSha and ToSha
+	Diffs                string                  `json:"diffs"`
+	ContextChanges       []*PlanLogContextChange `json:"contextChanges"`
+	AddedConvoMessages   []*ConvoMessage         `json:"addedConvoMessages"`
+	RemovedConvoMessages []*ConvoMessage         `json:"removedConvoMessages"`
+}
+
+type PlanPRCommit struct {
+	Message string `json:"message"`
+	// paths relative to the project root. A path is only included in the last commit that touched it.
+	Paths []string `json:"paths"`
+}
+
+type PlanPRResponse struct {
+	ApplyId    string `json:"applyId"`
+	BranchName string `json:"branchName"`
+	Title      string `json:"title"`
+	// markdown
+	Description string `json:"description"`
+	// the commit message for the apply, as used for auto-commit
+	ApplyCommitMsg string          `json:"applyCommitMsg"`
+	Commits        []*PlanPRCommit `json:"commits"`
+}
+
+type ConvoSearchResultType string
+
+const (
+	ConvoSearchResultMessage ConvoSearchResultType = "message"
+	ConvoSearchResultSummary ConvoSearchResultType = "summary"
+	ConvoSearchResultPlan    ConvoSearchResultType = "plan"
+)
+
+type ConvoSearchResult struct {
+	Type      ConvoSearchResultType `json:"type"`
+	PlanId    string                `json:"planId"`
+	PlanName  string                `json:"planName"`
+	ProjectId string                `json:"projectId"`
+	// only set for message results
+	Branch         string `json:"branch,omitempty"`
+	ConvoMessageId string `json:"convoMessageId,omitempty"`
+	Num            int    `json:"num,omitempty"`
+	Role           string `json:"role,omitempty"`
+	// matching terms are wrapped in ** **
+	Snippet   string    `json:"snippet"`
+	Rank      float64   `json:"rank"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
 type CreateBranchRequest struct {
 	Name string `json:"name"`
 }
 
+type MergeBranchRequest struct {
+	SourceBranch string `json:"sourceBranch"`
+}
+
+type CherryPickRequest struct {
+	SourceBranch string `json:"sourceBranch"`
+	// Ref is either a convo message id or a commit sha from the source branch's log
+	Ref string `json:"ref"`
+}
+
+type BranchMergeConflict struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// BranchMergeResponse summarizes a merge or cherry-pick. If Conflicts is non-empty, nothing was changed.
+type BranchMergeResponse struct {
+	SourceBranch       string                 `json:"sourceBranch"`
+	TargetBranch       string                 `json:"targetBranch"`
+	UpToDate           bool                   `json:"upToDate"`
+	ConvoMessagesAdded int                    `json:"convoMessagesAdded"`
+	ContextsAdded      int                    `json:"contextsAdded"`
+	ContextsUpdated    int                    `json:"contextsUpdated"`
+	ContextsRemoved    int                    `json:"contextsRemoved"`
+	ResultsAdded       int                    `json:"resultsAdded"`
+	ResultsUpdated     int                    `json:"resultsUpdated"`
+	SubtasksAdded      int                    `json:"subtasksAdded"`
+	PendingPaths       []string               `json:"pendingPaths"`
+	Conflicts          []*BranchMergeConflict `json:"conflicts"`
+}
+
 type UpdateSettingsRequest struct {
 	ModelPackName string     `json:"modelPackName"`
 	ModelPack     *ModelPack `json:"modelPack"`
@@ -378,3 +586,8 @@ type CreditsSummaryResponse struct {
 type GetBalanceResponse struct {
 	Balance decimal.Decimal `json:"balance"`
 }
+
+type SetOrgSecretRequest struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
@@ -0,0 +1,23 @@
+package shared
+
+import "time"
+
+// CredentialSourceType identifies where a provider credential was resolved from.
+// The CLI checks sources in the order below, stopping at the first one that has a value.
+// The org vault is resolved server-side, so the CLI only needs to know which names are stored there.
+type CredentialSourceType string
+
+const (
+	CredentialSourceEnv      CredentialSourceType = "env"
+	CredentialSourceKeyring  CredentialSourceType = "keyring"
+	CredentialSourceVault    CredentialSourceType = "vault"
+	CredentialSourceOrgVault CredentialSourceType = "org-vault"
+)
+
+// OrgSecret is the client-side view of a secret stored in the org vault—the value never leaves the server
+type OrgSecret struct {
+	Name      string    `json:"name"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
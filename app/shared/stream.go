@@ -9,6 +9,15 @@ type BuildInfo struct {
 	Removed   bool   `json:"removed,omitempty"`
 }
 
+// PrunedContext is a piece of loaded context that was downgraded to its map or left out of a prompt to fit the token budget
+type PrunedContext struct {
+	Name        string      `json:"name"`
+	ContextType ContextType `json:"contextType"`
+	// true if the file was sent as its map rather than left out
+	Downgraded  bool `json:"downgraded,omitempty"`
+	TokensSaved int  `json:"tokensSaved"`
+}
+
 type StreamMessageType string
 
 const (
@@ -21,6 +30,7 @@ const (
 	StreamMessageBuildInfo         StreamMessageType = "buildInfo"
 	StreamMessagePromptMissingFile StreamMessageType = "promptMissingFile"
 	StreamMessageLoadContext       StreamMessageType = "loadContext"
+	StreamMessagePrunedContext     StreamMessageType = "prunedContext"
 	StreamMessageAborted           StreamMessageType = "aborted"
 	StreamMessageFinished          StreamMessageType = "finished"
 	StreamMessageError             StreamMessageType = "error"
@@ -40,6 +50,7 @@ type StreamMessage struct {
 	MissingFileAutoContext bool                     `json:"missingFileAutoContext,omitempty"`
 	ModelStreamId          string                   `json:"modelStreamId,omitempty"`
 	LoadContextFiles       []string                 `json:"loadContextFiles,omitempty"`
+	PrunedContext          []*PrunedContext         `json:"prunedContext,omitempty"`
 	InitPrompt             string                   `json:"initPrompt,omitempty"`
 	InitReplies            []string                 `json:"initReplies,omitempty"`
 	InitBuildOnly          bool                     `json:"initBuildOnly,omitempty"`
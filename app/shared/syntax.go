@@ -18,6 +18,7 @@ const (
 	LanguageElixir     Language = "elixir"
 	LanguageElm        Language = "elm"
 	LanguageGo         Language = "go"
+	LanguageGraphql    Language = "graphql"
 	LanguageGroovy     Language = "groovy"
 	LanguageHcl        Language = "hcl"
 	LanguageHtml       Language = "html"
@@ -54,6 +55,7 @@ var Languages = []Language{
 	LanguageElixir,
 	LanguageElm,
 	LanguageGo,
+	LanguageGraphql,
 	LanguageGroovy,
 	LanguageHcl,
 	LanguageHtml,
@@ -86,7 +88,6 @@ var lacksFileMapSupport = []Language{
 	LanguageToml,
 	LanguageCue,
 	LanguageJson,
-	LanguageProtobuf,
 
 	// these just need more work for mapping
 	LanguageGroovy,
@@ -95,6 +96,7 @@ var lacksFileMapSupport = []Language{
 
 var SkipTreeSitter = map[Language]bool{
 	LanguageMarkdown: true,
+	LanguageGraphql:  true,
 }
 
 var LanguageSet = map[Language]bool{}
@@ -131,43 +133,46 @@ func HasFileMapSupport(path string) bool {
 }
 
 var LanguageByExtension = map[string]Language{
-	".sh":     LanguageBash,
-	".bash":   LanguageBash,
-	".c":      LanguageC,
-	".h":      LanguageC,
-	".cpp":    LanguageCpp,
-	".cc":     LanguageCpp,
-	".cs":     LanguageCsharp,
-	".css":    LanguageCss,
-	".cue":    LanguageCue,
-	".ex":     LanguageElixir,
-	".exs":    LanguageElixir,
-	".elm":    LanguageElm,
-	".go":     LanguageGo,
-	".groovy": LanguageGroovy,
-	".hcl":    LanguageHcl,
-	".html":   LanguageHtml,
-	".java":   LanguageJava,
-	".js":     LanguageJavascript,
-	".json":   LanguageJson,
-	".jsx":    LanguageTsx,
-	".kt":     LanguageKotlin,
-	".lua":    LanguageLua,
-	".ml":     LanguageOCaml,
-	".php":    LanguagePhp,
-	".proto":  LanguageProtobuf,
-	".py":     LanguagePython,
-	".rb":     LanguageRuby,
-	".rs":     LanguageRust,
-	".scala":  LanguageScala,
-	".svelte": LanguageSvelte,
-	".swift":  LanguageSwift,
-	".toml":   LanguageToml,
-	".ts":     LanguageTypescript,
-	".tsx":    LanguageTsx,
-	".yaml":   LanguageYaml,
-	".yml":    LanguageYaml,
-	".md":     LanguageMarkdown,
+	".sh":       LanguageBash,
+	".bash":     LanguageBash,
+	".c":        LanguageC,
+	".h":        LanguageC,
+	".cpp":      LanguageCpp,
+	".cc":       LanguageCpp,
+	".cs":       LanguageCsharp,
+	".css":      LanguageCss,
+	".cue":      LanguageCue,
+	".ex":       LanguageElixir,
+	".exs":      LanguageElixir,
+	".elm":      LanguageElm,
+	".go":       LanguageGo,
+	".graphql":  LanguageGraphql,
+	".graphqls": LanguageGraphql,
+	".gql":      LanguageGraphql,
+	".groovy":   LanguageGroovy,
+	".hcl":      LanguageHcl,
+	".html":     LanguageHtml,
+	".java":     LanguageJava,
+	".js":       LanguageJavascript,
+	".json":     LanguageJson,
+	".jsx":      LanguageTsx,
+	".kt":       LanguageKotlin,
+	".lua":      LanguageLua,
+	".ml":       LanguageOCaml,
+	".php":      LanguagePhp,
+	".proto":    LanguageProtobuf,
+	".py":       LanguagePython,
+	".rb":       LanguageRuby,
+	".rs":       LanguageRust,
+	".scala":    LanguageScala,
+	".svelte":   LanguageSvelte,
+	".swift":    LanguageSwift,
+	".toml":     LanguageToml,
+	".ts":       LanguageTypescript,
+	".tsx":      LanguageTsx,
+	".yaml":     LanguageYaml,
+	".yml":      LanguageYaml,
+	".md":       LanguageMarkdown,
 }
 
 var LanguageFallbackByExtension = map[string]Language{